@@ -0,0 +1,136 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// Loading a page in a browser and installing the audit's packages take longer than a script
+	defaultAuditTimeout = 5 * time.Minute
+
+	defaultAuditImage  = "mcr.microsoft.com/playwright:v1.38.0-jammy"
+	defaultAuditFailOn = "serious"
+)
+
+// The impacts axe-core gives violations, from least to most severe
+var axeImpacts = []string{"minor", "moderate", "serious", "critical"}
+
+// Runs axe-core on the page in a headless Chromium. Violations are printed one per line, so the last lines of
+// the log, which become the Job's termination message, say which rules failed.
+const axeAuditScript = `
+const { chromium } = require('playwright');
+const { default: AxeBuilder } = require('@axe-core/playwright');
+const impacts = ['minor', 'moderate', 'serious', 'critical'];
+(async () => {
+  const browser = await chromium.launch();
+  const page = await browser.newPage();
+  await page.goto(process.env.TARGET_URL, { waitUntil: 'load' });
+  let builder = new AxeBuilder({ page });
+  if (process.env.AXE_TAGS) builder = builder.withTags(process.env.AXE_TAGS.split(','));
+  if (process.env.AXE_DISABLE_RULES) builder = builder.disableRules(process.env.AXE_DISABLE_RULES.split(','));
+  const results = await builder.analyze();
+  await browser.close();
+  const threshold = impacts.indexOf(process.env.AXE_FAIL_ON);
+  const failing = results.violations.filter(v => impacts.indexOf(v.impact) >= threshold);
+  for (const v of results.violations) console.log(v.impact + ' ' + v.id + ': ' + v.nodes.length + ' elements');
+  if (failing.length > 0) {
+    console.log(failing.length + ' accessibility violations at or above ' + process.env.AXE_FAIL_ON + ': ' +
+      failing.map(v => v.id).join(', '));
+    process.exit(1);
+  }
+})().catch(err => { console.log(String(err)); process.exit(2); });
+`
+
+// Images with the packages already installed skip the install
+const axeAuditCommand = `cd /tmp && (node -e "require.resolve('@axe-core/playwright')" 2>/dev/null ||
+  npm install --no-save --silent playwright@1.38.0 @axe-core/playwright@4.8) && node -e "$AUDIT_SCRIPT"`
+
+// Audit the page at the request's URL for accessibility with axe-core, in a headless browser run by a Job. The
+// step fails on violations of fail_on's impact or worse.
+type AccessibilityAudit struct {
+	// The least impact that fails the step. Default is serious
+	// +kubebuilder:validation:Enum=minor;moderate;serious;critical
+	FailOn string `json:"fail_on,omitempty"`
+
+	// Only run the rules with these tags, like wcag2a, wcag2aa or wcag21aa. Default is every rule
+	Tags []string `json:"tags,omitempty"`
+
+	// Rules not to run, like ones for known issues, so only new violations fail the step
+	DisableRules []string `json:"disable_rules,omitempty"`
+
+	// The image to run the audit in. It needs Node.js and Chromium, and the playwright and @axe-core/playwright
+	// packages, which are installed at the start of the audit if the image does not have them. Default is the
+	// official Playwright image
+	Image string `json:"image,omitempty"`
+}
+
+func (a *AccessibilityAudit) validate() error {
+	for _, rule := range append(append([]string(nil), a.Tags...), a.DisableRules...) {
+		if rule == "" || strings.Contains(rule, ",") {
+			return fmt.Errorf("invalid accessibility: tags and rules cannot be empty or have commas, %q does", rule)
+		}
+	}
+	return nil
+}
+
+func (a *AccessibilityAudit) failOn() string {
+	if a.FailOn == "" {
+		return defaultAuditFailOn
+	}
+	return a.FailOn
+}
+
+func (a *AccessibilityAudit) image() string {
+	if a.Image == "" {
+		return defaultAuditImage
+	}
+	return a.Image
+}
+
+// The Job that audits the page
+func (h *HttpMonitor) accessibilityJob(r *HttpRequest) *batchv1.Job {
+	container := corev1.Container{
+		Name:    "axe",
+		Image:   r.Accessibility.image(),
+		Command: []string{"sh", "-c", axeAuditCommand},
+		Env: []corev1.EnvVar{
+			{Name: "AXE_FAIL_ON", Value: r.Accessibility.failOn()},
+			{Name: "AXE_TAGS", Value: strings.Join(r.Accessibility.Tags, ",")},
+			{Name: "AXE_DISABLE_RULES", Value: strings.Join(r.Accessibility.DisableRules, ",")},
+			{Name: "AUDIT_SCRIPT", Value: axeAuditScript},
+		},
+		// The violations are at the end of the log
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+	return h.stepJob(r, "a11y", container, nil)
+}
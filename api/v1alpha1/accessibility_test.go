@@ -0,0 +1,83 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHttpMonitor_accessibilityJob(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		Audit         AccessibilityAudit
+		ExpectedImage string
+		ExpectedEnv   map[string]string
+	}{
+		{"defaults", AccessibilityAudit{}, defaultAuditImage,
+			map[string]string{"AXE_FAIL_ON": "serious", "AXE_TAGS": "", "AXE_DISABLE_RULES": ""}},
+		{"overridden", AccessibilityAudit{FailOn: "moderate", Tags: []string{"wcag2a", "wcag2aa"},
+			DisableRules: []string{"color-contrast"}, Image: "registry.example.com/axe:4.8"},
+			"registry.example.com/axe:4.8",
+			map[string]string{"AXE_FAIL_ON": "moderate", "AXE_TAGS": "wcag2a,wcag2aa", "AXE_DISABLE_RULES": "color-contrast"}},
+	}
+
+	for _, testdata := range tests {
+		h := &HttpMonitor{ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "web"}}
+		audit := testdata.Audit
+		r := &HttpRequest{Name: "home page", Url: "https://{host}/", Accessibility: &audit,
+			AvailableVariables: VariableList{{Name: "host", Value: "morphic.example.com"}}}
+		job := h.accessibilityJob(r)
+
+		if job.Namespace != "web" || job.GenerateName != "home-a11y-" {
+			t.Errorf("[%s] unexpected job metadata: %s %s", testdata.TestName, job.Namespace, job.GenerateName)
+		}
+		if *job.Spec.ActiveDeadlineSeconds != int64(defaultAuditTimeout.Seconds()) {
+			t.Errorf("[%s] unexpected deadline %d", testdata.TestName, *job.Spec.ActiveDeadlineSeconds)
+		}
+		container := job.Spec.Template.Spec.Containers[0]
+		if container.Image != testdata.ExpectedImage {
+			t.Errorf("[%s] unexpected image. Got: %s, expected: %s", testdata.TestName, container.Image, testdata.ExpectedImage)
+		}
+		if container.TerminationMessagePolicy != corev1.TerminationMessageFallbackToLogsOnError {
+			t.Errorf("[%s] unexpected termination message policy %s", testdata.TestName, container.TerminationMessagePolicy)
+		}
+		env := make(map[string]string)
+		for _, variable := range container.Env {
+			if variable.Name != "AUDIT_SCRIPT" {
+				env[variable.Name] = variable.Value
+			}
+		}
+		testdata.ExpectedEnv["TARGET_URL"] = "https://morphic.example.com/"
+		testdata.ExpectedEnv["VAR_HOST"] = "morphic.example.com"
+		if !reflect.DeepEqual(env, testdata.ExpectedEnv) {
+			t.Errorf("[%s] unexpected env. Got: %v, expected: %v", testdata.TestName, env, testdata.ExpectedEnv)
+		}
+	}
+}
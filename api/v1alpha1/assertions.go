@@ -0,0 +1,156 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// A response did not meet one of the request's assertions
+type assertionError struct {
+	assertion string
+	err       error
+}
+
+func (e *assertionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *assertionError) Unwrap() error {
+	return e.err
+}
+
+// An assertion that did not hold, recorded instead of failing the request in observe-only mode
+// +kubebuilder:object:generate=false
+type Violation struct {
+	Assertion string
+	Err       error
+}
+
+type assertion struct {
+	name  string
+	check func() error
+}
+
+// The assertions that apply to a response, in the order they are checked
+func (r *HttpRequest) assertions(ctx context.Context, client *http.Client, req *http.Request, resp *http.Response, result *RequestResult) []assertion {
+	assertions := []assertion{
+		{"expected_response_codes", func() error {
+			if !r.ExpectedResponseCodes.Matches(resp.StatusCode) {
+				return fmt.Errorf("not an expected error code: %d is not in %s", resp.StatusCode, r.ExpectedResponseCodes)
+			}
+			return nil
+		}},
+	}
+	if r.Redirects != nil {
+		assertions = append(assertions, assertion{"redirects", func() error { return r.Redirects.verify(resp) }})
+	}
+	if r.MaxDuration != nil {
+		assertions = append(assertions, assertion{"max_duration", func() error {
+			if result.ResponseTime > r.MaxDuration.Duration {
+				return fmt.Errorf("response took %s, longer than the maximum of %s", result.ResponseTime.Round(time.Millisecond), r.MaxDuration.Duration)
+			}
+			return nil
+		}})
+	}
+	if r.ExpectConnectionReused != nil {
+		assertions = append(assertions, assertion{"expect_connection_reused", func() error {
+			switch reused := result.Timings.ConnReused; {
+			case *r.ExpectConnectionReused && !reused:
+				return errors.New("opened a new connection instead of reusing a kept-alive one")
+			case !*r.ExpectConnectionReused && reused:
+				return fmt.Errorf("reused a connection that was idle for %s instead of opening a new one",
+					result.Timings.ConnIdle.Round(time.Millisecond))
+			}
+			return nil
+		}})
+	}
+	if r.ResponseAssertions != nil {
+		assertions = append(assertions, assertion{"response_assertions", func() error {
+			return r.ResponseAssertions.verify(resp.Header, readBodyAndReset(resp))
+		}})
+	}
+	if r.Language != nil {
+		assertions = append(assertions, assertion{"language", func() error {
+			return r.Language.verify(resp.Header, readBodyAndReset(resp))
+		}})
+	}
+	if r.Compliance != nil {
+		assertions = append(assertions, assertion{"compliance", func() error { return r.Compliance.verify(resp) }})
+	}
+	if r.EgressIp != nil {
+		assertions = append(assertions, assertion{"egress_ip", func() error {
+			return r.EgressIp.verify(readBodyAndReset(resp))
+		}})
+	}
+	if r.Jwt != nil {
+		assertions = append(assertions, assertion{"jwt", func() error { return r.Jwt.verify(ctx, client, resp) }})
+	}
+	if r.OidcDiscovery != nil {
+		assertions = append(assertions, assertion{"oidc_discovery", func() error {
+			return r.OidcDiscovery.verify(ctx, client, req.URL, readBodyAndReset(resp))
+		}})
+	}
+	if r.Saml != nil {
+		assertions = append(assertions, assertion{"saml", func() error { return r.Saml.verify(ctx, client, resp) }})
+	}
+	if r.MinCertificateRemaining != nil {
+		assertions = append(assertions, assertion{"min_certificate_remaining", func() error {
+			return checkCertificateExpiry(resp.TLS, r.MinCertificateRemaining.Duration, time.Now())
+		}})
+	}
+	if r.RequireHttps {
+		assertions = append(assertions, assertion{"require_https", func() error {
+			return checkCleartextRefused(ctx, client, req.URL)
+		}})
+	}
+	return assertions
+}
+
+// Check the response against the request's assertions. Usually the first one that does not hold fails the
+// request. In observe-only mode every assertion is checked and violations are only recorded. A challenge page
+// from bot protection fails the request in either mode, since the application was never reached.
+func (r *HttpRequest) verifyResponse(ctx context.Context, client *http.Client, req *http.Request, resp *http.Response, result *RequestResult) error {
+	if vendor := detectBotChallenge(resp); vendor != "" {
+		return &botChallengeError{vendor: vendor, status: resp.StatusCode}
+	}
+	for _, a := range r.assertions(ctx, client, req, resp, result) {
+		err := a.check()
+		if err == nil {
+			continue
+		}
+		if r.ObserveOnly {
+			result.Violations = append(result.Violations, Violation{Assertion: a.name, Err: err})
+			continue
+		}
+		return &assertionError{assertion: a.name, err: err}
+	}
+	return nil
+}
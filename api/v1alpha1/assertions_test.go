@@ -0,0 +1,114 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHttpRequest_verifyResponse(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://test.com", nil)
+	resp := &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{}}
+
+	enforced := &HttpRequest{ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}}
+	err := enforced.verifyResponse(context.Background(), http.DefaultClient, req, resp, &RequestResult{})
+	var assertionErr *assertionError
+	if !errors.As(err, &assertionErr) || assertionErr.assertion != "expected_response_codes" {
+		t.Errorf("expected the response code assertion to fail the request, got %v", err)
+	}
+
+	observed := &HttpRequest{ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, ObserveOnly: true}
+	result := &RequestResult{}
+	if err := observed.verifyResponse(context.Background(), http.DefaultClient, req, resp, result); err != nil {
+		t.Errorf("observe-only assertions should not fail the request, got %v", err)
+	}
+	if len(result.Violations) != 1 || result.Violations[0].Assertion != "expected_response_codes" {
+		t.Errorf("expected the violation to be recorded, got %+v", result.Violations)
+	}
+}
+
+func TestHttpRequest_verifyResponseMaxDuration(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://test.com", nil)
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	r := &HttpRequest{ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, MaxDuration: &metav1.Duration{Duration: 800 * time.Millisecond}}
+
+	if err := r.verifyResponse(context.Background(), http.DefaultClient, req, resp, &RequestResult{ResponseTime: 750 * time.Millisecond}); err != nil {
+		t.Errorf("expected a fast response to pass, got %v", err)
+	}
+	err := r.verifyResponse(context.Background(), http.DefaultClient, req, resp, &RequestResult{ResponseTime: 1200 * time.Millisecond})
+	if err == nil || err.Error() != "response took 1.2s, longer than the maximum of 800ms" {
+		t.Errorf("expected a slow response to fail, got %v", err)
+	}
+}
+
+func TestHttpRequest_expectConnectionReused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/close" {
+			w.Header().Set("Connection", "close")
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	httpclient.Initialize(0)
+	yes, no := true, false
+
+	// Each pair of requests is sent in order, the second one with the expectation
+	tests := []struct {
+		TestName       string
+		FirstPath      string
+		Expected       *bool
+		ExpectedFailed bool
+	}{
+		{"kept alive", "/", &yes, false},
+		{"closed by the server", "/close", &yes, true},
+		{"expected a new connection", "/", &no, true},
+		{"new connection", "/close", &no, false},
+		{"no expectation", "/close", nil, false},
+	}
+
+	for _, testdata := range tests {
+		first := HttpRequest{Name: "first", Method: http.MethodGet, Url: server.URL + testdata.FirstPath,
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}}
+		if _, result := first.timedSendRequest(httpclient.GetClient()); result.Err != nil {
+			t.Fatalf("[%s] unexpected error: %s", testdata.TestName, result.Err)
+		}
+		second := HttpRequest{Name: "second", Method: http.MethodGet, Url: server.URL,
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, ExpectConnectionReused: testdata.Expected}
+		_, result := second.timedSendRequest(httpclient.GetClient())
+		if (result.Err != nil) != testdata.ExpectedFailed {
+			t.Errorf("[%s] unexpected result. Got: %v, expected a failure: %t", testdata.TestName, result.Err,
+				testdata.ExpectedFailed)
+		}
+	}
+}
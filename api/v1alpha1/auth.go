@@ -0,0 +1,164 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultUsernameKey = "username"
+	defaultPasswordKey = "password"
+)
+
+// How a request authenticates, with credentials kept in Secrets instead of the spec. Set one of basic, bearer,
+// oauth2 and aws_sigv4.
+type RequestAuth struct {
+	Basic *BasicAuth `json:"basic,omitempty"`
+
+	Bearer *BearerAuth `json:"bearer,omitempty"`
+
+	OAuth2 *OAuth2ClientCredentials `json:"oauth2,omitempty"`
+
+	AwsSigV4 *AwsSigV4Auth `json:"aws_sigv4,omitempty"`
+}
+
+// HTTP basic authentication, with the Authorization header set when the request is sent
+type BasicAuth struct {
+	// A Secret with the username and password, like one of type kubernetes.io/basic-auth
+	SecretRef corev1.LocalObjectReference `json:"secret_ref"`
+
+	// The keys of the username and password in the Secret. Defaults are username and password
+	UsernameKey string `json:"username_key,omitempty"`
+	PasswordKey string `json:"password_key,omitempty"`
+
+	// The credentials, loaded by the controller
+	Username string `json:"-"`
+	Password string `json:"-"`
+	Loaded   bool   `json:"-"`
+}
+
+// A bearer token, sent as Authorization: Bearer <token>
+type BearerAuth struct {
+	// The Secret key holding the token
+	SecretRef corev1.SecretKeySelector `json:"secret_ref"`
+
+	// The token, loaded by the controller
+	Token  string `json:"-"`
+	Loaded bool   `json:"-"`
+}
+
+// Where the controller reads the username
+func (b *BasicAuth) UsernameSecretKey() *corev1.SecretKeySelector {
+	return secretKey(b.SecretRef, b.UsernameKey, defaultUsernameKey)
+}
+
+// Where the controller reads the password
+func (b *BasicAuth) PasswordSecretKey() *corev1.SecretKeySelector {
+	return secretKey(b.SecretRef, b.PasswordKey, defaultPasswordKey)
+}
+
+func secretKey(secret corev1.LocalObjectReference, key, fallback string) *corev1.SecretKeySelector {
+	if key == "" {
+		key = fallback
+	}
+	return &corev1.SecretKeySelector{LocalObjectReference: secret, Key: key}
+}
+
+func (a *RequestAuth) validate() error {
+	set := 0
+	for _, mode := range []bool{a.Basic != nil, a.Bearer != nil, a.OAuth2 != nil, a.AwsSigV4 != nil} {
+		if mode {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("invalid auth: set exactly one of basic, bearer, oauth2 and aws_sigv4")
+	}
+	if a.Basic != nil && a.Basic.SecretRef.Name == "" {
+		return fmt.Errorf("invalid auth: basic needs the name of a secret in secret_ref")
+	}
+	if a.Bearer != nil && (a.Bearer.SecretRef.Name == "" || a.Bearer.SecretRef.Key == "") {
+		return fmt.Errorf("invalid auth: bearer needs the name and key of a secret in secret_ref")
+	}
+	if a.OAuth2 != nil {
+		return a.OAuth2.validate()
+	}
+	if a.AwsSigV4 != nil {
+		return a.AwsSigV4.validate()
+	}
+	return nil
+}
+
+// Set the Authorization header on a request about to be sent. Signatures cover the whole request, so this comes
+// last.
+func (a *RequestAuth) apply(req *http.Request) error {
+	if a == nil {
+		return nil
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	switch {
+	case a.Basic != nil:
+		if !a.Basic.Loaded {
+			return fmt.Errorf("the credentials in secret %s were not loaded", a.Basic.SecretRef.Name)
+		}
+		req.SetBasicAuth(a.Basic.Username, a.Basic.Password)
+	case a.Bearer != nil:
+		if !a.Bearer.Loaded {
+			return fmt.Errorf("the token in secret %s, key %s, was not loaded", a.Bearer.SecretRef.Name,
+				a.Bearer.SecretRef.Key)
+		}
+		req.Header.Set("Authorization", "Bearer "+a.Bearer.Token)
+	case a.OAuth2 != nil:
+		token, err := a.OAuth2.token(req.Context())
+		if err != nil {
+			return err
+		}
+		token.SetAuthHeader(req)
+	case a.AwsSigV4 != nil:
+		return a.AwsSigV4.apply(req)
+	}
+	return nil
+}
+
+func (r *HttpRequest) setsAuthorization() bool {
+	return r.setsHeader("Authorization")
+}
+
+// Header names in a spec keep the case they were written in, so they are compared without it
+func (r *HttpRequest) setsHeader(name string) bool {
+	for key := range r.Headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHttpMonitor_prepareRequest_auth(t *testing.T) {
+	secret := corev1.LocalObjectReference{Name: "creds"}
+	monitorAuth := &RequestAuth{Basic: &BasicAuth{SecretRef: secret, Username: "monitor", Password: "s3cret", Loaded: true}}
+	requestAuth := &RequestAuth{Basic: &BasicAuth{SecretRef: secret, Username: "admin", Password: "hunter2", Loaded: true}}
+	monitor := &HttpMonitor{Spec: HttpMonitorSpec{Auth: monitorAuth}}
+
+	tests := []struct {
+		TestName string
+		Request  HttpRequest
+		Username string
+		Password string
+		Header   string
+		Err      string
+	}{
+		{"monitor auth", HttpRequest{}, "monitor", "s3cret", "", ""},
+		{"request auth", HttpRequest{Auth: requestAuth}, "admin", "hunter2", "", ""},
+		{"bearer", HttpRequest{Auth: &RequestAuth{Bearer: &BearerAuth{Token: "eyJhbGciOi", Loaded: true}}}, "", "",
+			"Bearer eyJhbGciOi", ""},
+		{"bearer not loaded", HttpRequest{Auth: &RequestAuth{Bearer: &BearerAuth{SecretRef: corev1.SecretKeySelector{
+			LocalObjectReference: secret, Key: "token"}}}}, "", "", "", "the token in secret creds, key token, was not loaded"},
+		{"own header", HttpRequest{Headers: http.Header{"authorization": {"Bearer token"}}}, "", "", "Bearer token", ""},
+		{"not loaded", HttpRequest{Auth: &RequestAuth{Basic: &BasicAuth{SecretRef: secret}}}, "", "", "",
+			"the credentials in secret creds were not loaded"},
+	}
+	for _, testdata := range tests {
+		request := testdata.Request
+		request.Method, request.Url = http.MethodGet, "https://example.com/admin"
+		monitor.prepareRequest(&request, nil)
+		req, err := request.BuildRequest()
+		if testdata.Err != "" {
+			if err == nil || !strings.Contains(err.Error(), testdata.Err) {
+				t.Errorf("[%s] unexpected error %v", testdata.TestName, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[%s] unexpected error %s", testdata.TestName, err)
+		}
+		username, password, ok := req.BasicAuth()
+		if ok != (testdata.Username != "") || username != testdata.Username || password != testdata.Password {
+			t.Errorf("[%s] unexpected credentials %q:%q", testdata.TestName, username, password)
+		}
+		if header := req.Header.Get("Authorization"); testdata.Header != "" && header != testdata.Header {
+			t.Errorf("[%s] unexpected Authorization header %q", testdata.TestName, header)
+		}
+	}
+}
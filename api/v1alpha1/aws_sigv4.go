@@ -0,0 +1,240 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat = "20060102T150405Z"
+)
+
+// The keys of the credentials in the Secret of an aws_sigv4 auth. Only temporary credentials have a session token.
+const (
+	AwsAccessKeyIdKey     = "access_key_id"
+	AwsSecretAccessKeyKey = "secret_access_key"
+	AwsSessionTokenKey    = "session_token"
+)
+
+// Headers that proxies and the HTTP client may change after signing, so they are left out of the signature
+var sigV4UnsignedHeaders = map[string]bool{"authorization": true, "user-agent": true, "expect": true,
+	"x-amzn-trace-id": true}
+
+// Sign requests with AWS Signature Version 4, for endpoints like API Gateway with IAM authorization and S3
+type AwsSigV4Auth struct {
+	// The region of the endpoint, like us-east-1
+	// +kubebuilder:validation:MinLength=1
+	Region string `json:"region"`
+
+	// The service to sign for, like execute-api for API Gateway or s3
+	// +kubebuilder:validation:MinLength=1
+	Service string `json:"service"`
+
+	// A Secret with access_key_id and secret_access_key keys, and session_token for temporary credentials. Without
+	// one, the controller's IAM role for service accounts is used, if the controller allows it for the monitor's namespace.
+	SecretRef *corev1.LocalObjectReference `json:"secret_ref,omitempty"`
+
+	// The credentials, loaded by the controller
+	AccessKeyId     string `json:"-"`
+	SecretAccessKey string `json:"-"`
+	SessionToken    string `json:"-"`
+	Loaded          bool   `json:"-"`
+}
+
+func (a *AwsSigV4Auth) validate() error {
+	if a.Region == "" || a.Service == "" {
+		return fmt.Errorf("invalid auth: aws_sigv4 needs a region and a service")
+	}
+	if a.SecretRef != nil && a.SecretRef.Name == "" {
+		return fmt.Errorf("invalid auth: aws_sigv4 needs the name of a secret in secret_ref")
+	}
+	return nil
+}
+
+func (a *AwsSigV4Auth) describe() string {
+	if a.SecretRef != nil {
+		return "in secret " + a.SecretRef.Name
+	}
+	return "of the service account"
+}
+
+func (a *AwsSigV4Auth) apply(req *http.Request) error {
+	if !a.Loaded {
+		return fmt.Errorf("the AWS credentials %s were not loaded", a.describe())
+	}
+	var payload []byte
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		if payload, err = ioutil.ReadAll(body); err != nil {
+			return err
+		}
+	}
+	a.sign(req, payload, time.Now())
+	return nil
+}
+
+// Add the X-Amz-Date, X-Amz-Security-Token and Authorization headers. Every other header already set is signed,
+// so the request must be complete.
+func (a *AwsSigV4Auth) sign(req *http.Request, payload []byte, now time.Time) {
+	now = now.UTC()
+	payloadHash := sha256Hex(payload)
+	req.Header.Set("X-Amz-Date", now.Format(sigV4TimeFormat))
+	if a.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.SessionToken)
+	}
+	// S3 refuses requests without it, and other services ignore it
+	if a.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	headers := map[string]string{"host": req.Host}
+	if req.Host == "" {
+		headers["host"] = req.URL.Host
+	}
+	for key, values := range req.Header {
+		name := strings.ToLower(key)
+		if sigV4UnsignedHeaders[name] {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[name] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		a.canonicalPath(req.URL),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	date := now.Format("20060102")
+	scope := strings.Join([]string{date, a.Region, a.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{sigV4Algorithm, now.Format(sigV4TimeFormat), scope,
+		sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + a.SecretAccessKey)
+	for _, part := range []string{date, a.Region, a.Service, "aws4_request"} {
+		key = hmacSha256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, a.AccessKeyId, scope, signedHeaders, signature))
+}
+
+// S3 signs the path as it is sent. Every other service escapes it once more.
+func (a *AwsSigV4Auth) canonicalPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	if a.Service == "s3" {
+		return path
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = sigV4Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// Sorted by escaped key, and then by escaped value. Sorting the whole pairs would put a key before another
+// it is a prefix of only by accident, since "-" sorts before "=".
+func canonicalQuery(query url.Values) string {
+	type pair struct{ key, value string }
+	var pairs []pair
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, pair{sigV4Escape(key), sigV4Escape(value)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+		return pairs[i].value < pairs[j].value
+	})
+	encoded := make([]string, len(pairs))
+	for i, p := range pairs {
+		encoded[i] = p.key + "=" + p.value
+	}
+	return strings.Join(encoded, "&")
+}
+
+// Escape everything but the unreserved characters of RFC 3986, as AWS expects
+func sigV4Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' ||
+			c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
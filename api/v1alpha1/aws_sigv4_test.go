@@ -0,0 +1,154 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The vectors come from the AWS Signature Version 4 test suite and the IAM example of the AWS documentation
+func TestAwsSigV4Auth_sign(t *testing.T) {
+	signedAt := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	tests := []struct {
+		TestName      string
+		Service       string
+		Url           string
+		Headers       http.Header
+		SessionToken  string
+		Authorization string
+	}{
+		{"get-vanilla", "service", "https://example.amazonaws.com/", nil, "",
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, " +
+				"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"get-vanilla-query-order-key-case", "service", "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			nil, "",
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, " +
+				"Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+		{"get-vanilla-query-order-value", "service", "https://example.amazonaws.com/?Param1=value2&Param1=value1",
+			nil, "",
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, " +
+				"Signature=5772eed61e12b33fae39ee5e7012498b51d56abc0abb7c60486157bd471c4694"},
+		{"get-vanilla-query-order-key", "service", "https://example.amazonaws.com/?Param1=value2&Param1=Value1",
+			nil, "",
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, " +
+				"Signature=eedbc4e291e521cf13422ffca22be7d2eb8146eecf653089df300a15b2382bd1"},
+		{"get-vanilla-query-unreserved", "service", "https://example.amazonaws.com/?-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz=-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+			nil, "",
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, " +
+				"Signature=9c3e54bfcdf0b19771a7f523ee5669cdf59bc7cc0884027167c21bb143a40197"},
+		{"iam list users", "iam", "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			http.Header{"Content-Type": {"application/x-www-form-urlencoded; charset=utf-8"}}, "",
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+				"SignedHeaders=content-type;host;x-amz-date, " +
+				"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"},
+	}
+	for _, testdata := range tests {
+		req, err := http.NewRequest(http.MethodGet, testdata.Url, nil)
+		if err != nil {
+			t.Fatalf("[%s] unexpected error %s", testdata.TestName, err)
+		}
+		if testdata.Headers != nil {
+			req.Header = testdata.Headers
+		}
+		auth := &AwsSigV4Auth{Region: "us-east-1", Service: testdata.Service, AccessKeyId: "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", SessionToken: testdata.SessionToken}
+		auth.sign(req, nil, signedAt)
+		if got := req.Header.Get("Authorization"); got != testdata.Authorization {
+			t.Errorf("[%s] unexpected Authorization header\nGot:      %s\nExpected: %s", testdata.TestName, got,
+				testdata.Authorization)
+		}
+	}
+}
+
+func TestHttpRequest_BuildRequest_awsSigV4(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		Auth          AwsSigV4Auth
+		SignedHeaders string
+		Err           string
+	}{
+		{"api gateway", AwsSigV4Auth{Region: "eu-west-1", Service: "execute-api", Loaded: true},
+			"SignedHeaders=content-type;host;x-amz-date,", ""},
+		{"s3 session", AwsSigV4Auth{Region: "eu-west-1", Service: "s3", SessionToken: "FwoGZXIvYXdzE", Loaded: true},
+			"SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token,", ""},
+		{"not loaded", AwsSigV4Auth{Region: "eu-west-1", Service: "s3"}, "",
+			"the AWS credentials of the service account were not loaded"},
+	}
+	for _, testdata := range tests {
+		auth := testdata.Auth
+		auth.AccessKeyId, auth.SecretAccessKey = "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+		request := HttpRequest{Method: http.MethodPost, Url: "https://api.example.com/prod/orders",
+			Headers: http.Header{"Content-Type": {"application/json"}}, Body: `{"id": 1}`,
+			QueryParams: url.Values{"dry_run": {"true"}}, Auth: &RequestAuth{AwsSigV4: &auth}}
+		req, err := request.BuildRequest()
+		if testdata.Err != "" {
+			if err == nil || !strings.Contains(err.Error(), testdata.Err) {
+				t.Errorf("[%s] unexpected error %v", testdata.TestName, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[%s] unexpected error %s", testdata.TestName, err)
+		}
+		if header := req.Header.Get("Authorization"); !strings.Contains(header, testdata.SignedHeaders) {
+			t.Errorf("[%s] unexpected Authorization header %q", testdata.TestName, header)
+		}
+		if req.Header.Get("X-Amz-Date") == "" || req.URL.RawQuery != "dry_run=true" {
+			t.Errorf("[%s] unexpected request %v", testdata.TestName, req)
+		}
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Query    string
+		Expected string
+	}{
+		{"empty", "", ""},
+		{"values of a key", "b=2&a=3&a=1", "a=1&a=3&b=2"},
+		{"key that is a prefix of another", "a-b=1&a=2", "a=2&a-b=1"},
+		{"escaped", "q=a b&k~=x/y", "k~=x%2Fy&q=a%20b"},
+	}
+	for _, testdata := range tests {
+		query, err := url.ParseQuery(testdata.Query)
+		if err != nil {
+			t.Fatalf("[%s] unexpected error %s", testdata.TestName, err)
+		}
+		if got := canonicalQuery(query); got != testdata.Expected {
+			t.Errorf("[%s] unexpected canonical query. Got: %q, expected: %q", testdata.TestName, got, testdata.Expected)
+		}
+	}
+}
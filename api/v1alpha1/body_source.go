@@ -0,0 +1,65 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"strings"
+)
+
+// A request body kept in a ConfigMap or a Secret key instead of the spec, for large payloads or ones with
+// credentials. Set exactly one of the refs.
+type BodySource struct {
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"config_map_ref,omitempty"`
+
+	SecretRef *corev1.SecretKeySelector `json:"secret_ref,omitempty"`
+
+	// The content of the key, loaded by the controller
+	Loaded []byte `json:"-"`
+}
+
+func (b *BodySource) validate() error {
+	if (b.ConfigMapRef == nil) == (b.SecretRef == nil) {
+		return fmt.Errorf("invalid body_from: set exactly one of config_map_ref and secret_ref")
+	}
+	return nil
+}
+
+func (b *BodySource) describe() string {
+	if b.SecretRef != nil {
+		return fmt.Sprintf("secret %s, key %s", b.SecretRef.Name, b.SecretRef.Key)
+	}
+	return fmt.Sprintf("configmap %s, key %s", b.ConfigMapRef.Name, b.ConfigMapRef.Key)
+}
+
+// The body with variables substituted, like an inline one
+func (b *BodySource) content(replacer *strings.Replacer) (string, error) {
+	if b.Loaded == nil {
+		return "", fmt.Errorf("the body in %s, was not loaded", b.describe())
+	}
+	return replacer.Replace(string(b.Loaded)), nil
+}
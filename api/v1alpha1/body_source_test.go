@@ -0,0 +1,91 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHttpRequest_BuildRequest_bodyFrom(t *testing.T) {
+	variables := VariableList{{Name: "user", From: FromTypeProvided, Value: "ann"}}
+	secret := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "payloads"}, Key: "login.json"}
+
+	tests := []struct {
+		TestName string
+		Source   BodySource
+		Expected string
+		Err      string
+	}{
+		{"secret", BodySource{SecretRef: secret, Loaded: []byte(`{"user":"{user}","password":"hunter2"}`)},
+			`{"user":"ann","password":"hunter2"}`, ""},
+		{"empty", BodySource{SecretRef: secret, Loaded: []byte{}}, "", ""},
+		{"not loaded", BodySource{SecretRef: secret}, "",
+			"the body in secret payloads, key login.json, was not loaded"},
+	}
+	for _, testdata := range tests {
+		source := testdata.Source
+		request := &HttpRequest{Method: http.MethodPost, Url: "https://example.com/login", AvailableVariables: variables,
+			BodyFrom: &source}
+		req, err := request.BuildRequest()
+		if testdata.Err != "" {
+			if err == nil || !strings.Contains(err.Error(), testdata.Err) {
+				t.Errorf("[%s] unexpected error %v", testdata.TestName, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[%s] unexpected error %s", testdata.TestName, err)
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		if string(body) != testdata.Expected {
+			t.Errorf("[%s] unexpected body %q", testdata.TestName, body)
+		}
+	}
+}
+
+func TestHttpRequest_BuildRequest_bodyBase64(t *testing.T) {
+	request := &HttpRequest{Method: http.MethodPost, Url: "https://example.com/images",
+		// A PNG signature, which is not valid UTF-8, followed by a placeholder that is left alone
+		BodyBase64: "iVBORw0KGgp7dXNlcn0=\n", CompressRequestBody: "gzip",
+		AvailableVariables: VariableList{{Name: "user", From: FromTypeProvided, Value: "ann"}}}
+	req, err := request.BuildRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := gzip.NewReader(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(reader)
+	if string(body) != "\x89PNG\r\n\x1a\n{user}" {
+		t.Errorf("unexpected body %q", body)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// The response was a challenge interstitial from a bot protection service rather than the application
+type botChallengeError struct {
+	vendor string
+	status int
+}
+
+func (e *botChallengeError) Error() string {
+	return fmt.Sprintf("request was challenged by %s bot protection (%d)", e.vendor, e.status)
+}
+
+// Markers in the bodies of challenge pages, for each vendor
+var botChallengeMarkers = []struct {
+	vendor  string
+	server  string
+	markers []string
+}{
+	{"Cloudflare", "cloudflare", []string{"/cdn-cgi/challenge-platform/", "cf-chl-", "<title>Just a moment...</title>",
+		"Attention Required! | Cloudflare"}},
+	{"Akamai", "akamaighost", []string{"/_sec/cp_challenge/", "bm-verify", "<TITLE>Access Denied</TITLE>"}},
+}
+
+// The vendor whose challenge page resp is, if it is one. Challenges are served with an error status, except
+// for Akamai's, which can come with a 200.
+func detectBotChallenge(resp *http.Response) string {
+	if resp.Header.Get("Cf-Mitigated") == "challenge" {
+		return "Cloudflare"
+	}
+	server := strings.ToLower(resp.Header.Get("Server"))
+	var body []byte
+	for _, signature := range botChallengeMarkers {
+		if !strings.Contains(server, signature.server) || resp.StatusCode < 400 && signature.vendor != "Akamai" {
+			continue
+		}
+		if body == nil {
+			body = readBodyAndReset(resp)
+		}
+		for _, marker := range signature.markers {
+			if bytes.Contains(body, []byte(marker)) {
+				return signature.vendor
+			}
+		}
+	}
+	return ""
+}
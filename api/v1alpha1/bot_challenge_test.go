@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDetectBotChallenge(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Status   int
+		Headers  map[string]string
+		Body     string
+		Expected string
+	}{
+		{"cf-mitigated", http.StatusForbidden, map[string]string{"Cf-Mitigated": "challenge"}, "", "Cloudflare"},
+		{"cloudflare-interstitial", http.StatusServiceUnavailable, map[string]string{"Server": "cloudflare"},
+			`<html><head><title>Just a moment...</title></head></html>`, "Cloudflare"},
+		{"cloudflare-origin-error", http.StatusBadGateway, map[string]string{"Server": "cloudflare"},
+			`<html><title>502 Bad Gateway</title></html>`, ""},
+		{"cloudflare-page-mentioning-challenge", http.StatusOK, map[string]string{"Server": "cloudflare"},
+			`<p>cf-chl- is a prefix</p>`, ""},
+		{"akamai-denied", http.StatusForbidden, map[string]string{"Server": "AkamaiGHost"},
+			`<HTML><HEAD><TITLE>Access Denied</TITLE></HEAD></HTML>`, "Akamai"},
+		{"akamai-bot-manager", http.StatusOK, map[string]string{"Server": "AkamaiGHost"},
+			`<script src="/_sec/cp_challenge/ak-challenge-3-3.js"></script>`, "Akamai"},
+		{"application", http.StatusForbidden, map[string]string{"Server": "nginx"}, `<title>Just a moment...</title>`, ""},
+	}
+
+	for _, testdata := range tests {
+		resp := &http.Response{StatusCode: testdata.Status, Header: http.Header{},
+			Body: ioutil.NopCloser(strings.NewReader(testdata.Body))}
+		for k, v := range testdata.Headers {
+			resp.Header.Set(k, v)
+		}
+		if out := detectBotChallenge(resp); out != testdata.Expected {
+			t.Errorf("[%s] unexpected result. Got: %q, expected: %q", testdata.TestName, out, testdata.Expected)
+		}
+	}
+}
+
+func TestBotChallengeCategory(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://test.com", nil)
+	resp := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{"Cf-Mitigated": {"challenge"}},
+		Body: ioutil.NopCloser(strings.NewReader(""))}
+
+	// Challenges fail the request even in observe-only mode, and are not assertion failures
+	observed := &HttpRequest{ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, ObserveOnly: true}
+	err := observed.verifyResponse(context.Background(), http.DefaultClient, req, resp, &RequestResult{})
+	if category := failureCategory(err); category != FailureCategoryBotChallenged {
+		t.Errorf("expected %s, got %q for %v", FailureCategoryBotChallenged, category, err)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// A certificate the target presented expires sooner than the request allows
+type certificateExpiryError struct {
+	cert      *x509.Certificate
+	remaining time.Duration
+	required  time.Duration
+}
+
+func (e *certificateExpiryError) Error() string {
+	if e.remaining <= 0 {
+		return fmt.Sprintf("certificate %s expired at %s", e.cert.Subject.CommonName, e.cert.NotAfter.UTC())
+	}
+	return fmt.Sprintf("certificate %s expires at %s, in %s, sooner than the required %s", e.cert.Subject.CommonName,
+		e.cert.NotAfter.UTC(), e.remaining.Round(time.Hour), e.required)
+}
+
+// Check that every certificate the target presented, the leaf and any intermediates, is valid for at least
+// minRemaining after now
+func checkCertificateExpiry(state *tls.ConnectionState, minRemaining time.Duration, now time.Time) error {
+	if state == nil {
+		return errors.New("the response was not served over TLS, so it has no certificate to check")
+	}
+	for _, cert := range state.PeerCertificates {
+		if remaining := cert.NotAfter.Sub(now); remaining < minRemaining || remaining <= 0 {
+			return &certificateExpiryError{cert: cert, remaining: remaining, required: minRemaining}
+		}
+	}
+	return nil
+}
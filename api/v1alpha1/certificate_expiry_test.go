@@ -0,0 +1,70 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+)
+
+func TestCheckCertificateExpiry(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	certificate := func(name string, validFor time.Duration) *x509.Certificate {
+		return &x509.Certificate{Subject: pkix.Name{CommonName: name}, NotAfter: now.Add(validFor)}
+	}
+
+	tests := []struct {
+		TestName         string
+		State            *tls.ConnectionState
+		MinRemaining     time.Duration
+		ExpectedError    bool
+		ExpectedCategory FailureCategory
+	}{
+		{"valid long enough", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+			certificate("shop.example.com", 90*day), certificate("Example CA", 900*day)}}, 30 * day, false, ""},
+		{"leaf expiring", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+			certificate("shop.example.com", 10*day), certificate("Example CA", 900*day)}}, 30 * day, true, FailureCategoryCertificateExpiring},
+		{"intermediate expiring", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+			certificate("shop.example.com", 90*day), certificate("Example CA", 10*day)}}, 30 * day, true, FailureCategoryCertificateExpiring},
+		{"expired with no minimum", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+			certificate("shop.example.com", -day)}}, 0, true, FailureCategoryCertificateExpiring},
+		{"plain http", nil, 30 * day, true, ""},
+	}
+
+	for _, testdata := range tests {
+		err := checkCertificateExpiry(testdata.State, testdata.MinRemaining, now)
+		if (err != nil) != testdata.ExpectedError {
+			t.Errorf("[%s] unexpected error: %v", testdata.TestName, err)
+		}
+		if category := failureCategory(err); category != testdata.ExpectedCategory {
+			t.Errorf("[%s] unexpected category %q", testdata.TestName, category)
+		}
+	}
+}
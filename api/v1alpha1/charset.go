@@ -0,0 +1,74 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"fmt"
+	"golang.org/x/text/encoding/htmlindex"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+// The charset the response body is in: the override if there is one, otherwise the Content-Type charset
+func (r *HttpRequest) responseCharset(resp *http.Response) string {
+	if r.ResponseCharset != "" {
+		return r.ResponseCharset
+	}
+	_, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
+
+// Replace the response body with its UTF-8 decoding, so extraction and assertions see text rather than bytes.
+// body is the body as read from the wire. Charsets the server names but nobody knows are left alone, an
+// unknown override is an error.
+func (r *HttpRequest) decodeBody(resp *http.Response, body []byte) error {
+	name := r.responseCharset(resp)
+	if name == "" {
+		return nil
+	}
+	encoding, err := htmlindex.Get(name)
+	if err != nil {
+		if r.ResponseCharset != "" {
+			return fmt.Errorf("unknown response charset %q", name)
+		}
+		return nil
+	}
+	if canonical, _ := htmlindex.Name(encoding); canonical == "utf-8" {
+		return nil
+	}
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		return fmt.Errorf("failed to decode response body from %s: %w", name, err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(decoded))
+	resp.ContentLength = int64(len(decoded))
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestHttpRequest_decodeBody(t *testing.T) {
+	tests := []struct {
+		TestName    string
+		Override    string
+		ContentType string
+		Body        []byte
+		ExpectErr   bool
+		Expected    string
+	}{
+		{
+			TestName:    "latin-1 from content type",
+			ContentType: "text/html; charset=ISO-8859-1",
+			Body:        []byte{'c', 'a', 'f', 0xe9},
+			Expected:    "café",
+		},
+		{
+			TestName:    "shift_jis override",
+			Override:    "Shift_JIS",
+			ContentType: "text/plain",
+			Body:        []byte{0x93, 0xfa, 0x96, 0x7b},
+			Expected:    "日本",
+		},
+		{
+			TestName:    "override wins over content type",
+			Override:    "windows-1252",
+			ContentType: "text/plain; charset=utf-8",
+			Body:        []byte{0x80},
+			Expected:    "€",
+		},
+		{
+			TestName:    "utf-8 is left alone",
+			ContentType: "application/json; charset=utf-8",
+			Body:        []byte(`{"name":"café"}`),
+			Expected:    `{"name":"café"}`,
+		},
+		{
+			TestName:    "unknown charset from server is left alone",
+			ContentType: "text/plain; charset=x-made-up",
+			Body:        []byte("plain"),
+			Expected:    "plain",
+		},
+		{
+			TestName:  "unknown override",
+			Override:  "x-made-up",
+			Body:      []byte("plain"),
+			ExpectErr: true,
+		},
+	}
+
+	for _, testdata := range tests {
+		r := &HttpRequest{ResponseCharset: testdata.Override}
+		resp := &http.Response{
+			Header: http.Header{"Content-Type": {testdata.ContentType}},
+			Body:   ioutil.NopCloser(bytes.NewReader(testdata.Body)),
+		}
+		err := r.decodeBody(resp, testdata.Body)
+		if err == nil && testdata.ExpectErr {
+			t.Errorf("[%s] expected error but got none", testdata.TestName)
+			continue
+		}
+		if err != nil {
+			if !testdata.ExpectErr {
+				t.Errorf("[%s] got unexpected err: %s", testdata.TestName, err)
+			}
+			continue
+		}
+		if body := string(readBodyAndReset(resp)); body != testdata.Expected {
+			t.Errorf("[%s] unexpected body. Got: %q, expected: %q", testdata.TestName, body, testdata.Expected)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestHttpMonitor_RetryCleanup_afterLaterRun(t *testing.T) {
+	var lock sync.Mutex
+	created := 0
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		if r.Method == http.MethodPost {
+			created++
+			_, _ = fmt.Fprintf(w, `{"id": "item-%d"}`, created)
+			return
+		}
+		deleted = append(deleted, r.URL.Path)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	httpclient.Initialize(0)
+
+	monitor := &HttpMonitor{Spec: HttpMonitorSpec{
+		Requests: []HttpRequest{{Name: "create", Method: http.MethodPost, Url: server.URL + "/items",
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)},
+			VariablesFromResponse: VariableList{{Name: "id", From: FromTypeBodyJson, JsonPath: "/id"}}}},
+		Cleanup: []HttpRequest{{Name: "delete", Method: http.MethodDelete, Url: server.URL + "/items/{id}",
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(204)}}},
+	}}
+	first := monitor.Execute()
+	if len(first.Cleanup) != 1 || first.Cleanup[0].Err == nil || first.Cleanup[0].Request == nil {
+		t.Fatalf("unexpected cleanup %+v", first.Cleanup)
+	}
+	// The second run creates another item before the first one's cleanup is retried
+	monitor.Execute()
+	retried := monitor.RetryCleanup(first.Cleanup[0].Request)
+	if retried.Err == nil {
+		t.Errorf("unexpected retry success")
+	}
+	expected := []string{"/items/item-1", "/items/item-2", "/items/item-1"}
+	if fmt.Sprint(deleted) != fmt.Sprint(expected) {
+		t.Errorf("unexpected deletes %v, expected %v", deleted, expected)
+	}
+}
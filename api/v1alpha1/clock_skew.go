@@ -0,0 +1,81 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// The target rejected a request while its clock was too far from the controller's, which signed and timestamped
+// APIs report like any other authentication failure
+type clockSkewError struct {
+	// How far the controller's clock is ahead of the target's. Negative when it is behind.
+	skew    time.Duration
+	allowed time.Duration
+	err     error
+}
+
+func (e *clockSkewError) Error() string {
+	direction, skew := "ahead of", e.skew
+	if skew < 0 {
+		direction, skew = "behind", -skew
+	}
+	return fmt.Sprintf("%s; the controller's clock is %s %s the target's, more than the allowed %s", e.err,
+		skew.Round(time.Second), direction, e.allowed)
+}
+
+func (e *clockSkewError) Unwrap() error {
+	return e.err
+}
+
+// How far the controller's clock, when the response arrived, is ahead of the target's Date header
+func clockSkew(resp *http.Response, received time.Time) (time.Duration, bool) {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, false
+	}
+	return received.Sub(date), true
+}
+
+// Classify a client error as caused by clock skew, when the target's clock is further off than allowed. Other
+// failures are returned as they are.
+func (r *HttpRequest) checkClockSkew(resp *http.Response, received time.Time, err error) error {
+	if r.AllowedClockSkew == nil || resp.StatusCode < 400 || resp.StatusCode >= 500 {
+		return err
+	}
+	skew, ok := clockSkew(resp, received)
+	if !ok {
+		return err
+	}
+	allowed := r.AllowedClockSkew.Duration
+	// Date only has whole seconds, so a target that is up to a second behind may still be on time
+	if skew > allowed+time.Second || -skew > allowed {
+		return &clockSkewError{skew: skew, allowed: allowed, err: err}
+	}
+	return err
+}
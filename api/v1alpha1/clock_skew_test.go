@@ -0,0 +1,75 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHttpRequest_checkClockSkew(t *testing.T) {
+	received := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	authErr := errors.New("assertion status_code failed")
+	tests := []struct {
+		TestName string
+		Allowed  *metav1.Duration
+		Status   int
+		Date     time.Time
+		Err      string
+	}{
+		{"controller ahead", &metav1.Duration{Duration: 5 * time.Minute}, http.StatusForbidden,
+			received.Add(-10 * time.Minute), "the controller's clock is 10m0s ahead of the target's, more than the allowed 5m0s"},
+		{"controller behind", &metav1.Duration{Duration: 5 * time.Minute}, http.StatusUnauthorized,
+			received.Add(6 * time.Minute), "the controller's clock is 6m0s behind the target's"},
+		{"within the allowance", &metav1.Duration{Duration: 5 * time.Minute}, http.StatusForbidden,
+			received.Add(-4 * time.Minute), ""},
+		{"truncated date", &metav1.Duration{}, http.StatusForbidden, received.Add(-time.Second), ""},
+		{"server error", &metav1.Duration{Duration: time.Minute}, http.StatusInternalServerError,
+			received.Add(-time.Hour), ""},
+		{"not set", nil, http.StatusForbidden, received.Add(-time.Hour), ""},
+	}
+	for _, testdata := range tests {
+		request := &HttpRequest{AllowedClockSkew: testdata.Allowed}
+		resp := &http.Response{StatusCode: testdata.Status,
+			Header: http.Header{"Date": {testdata.Date.Format(http.TimeFormat)}}}
+		err := request.checkClockSkew(resp, received, authErr)
+		category := failureCategory(err)
+		if testdata.Err == "" {
+			if err != authErr || category != "" {
+				t.Errorf("[%s] unexpected error %v", testdata.TestName, err)
+			}
+			continue
+		}
+		if !strings.Contains(err.Error(), testdata.Err) || category != FailureCategoryClockSkew ||
+			!errors.Is(err, authErr) {
+			t.Errorf("[%s] unexpected error %v (%q)", testdata.TestName, err, category)
+		}
+	}
+}
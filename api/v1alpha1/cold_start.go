@@ -0,0 +1,60 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/go-logr/logr"
+	"net/http"
+)
+
+// The request as it is sent to wake the target: with the cold start budget as its timeout, and without
+// extracting variables, which come from the measured request that follows
+func (r HttpRequest) wakeUpRequest() HttpRequest {
+	r.VariablesFromResponse = nil
+	if r.ColdStart.Budget != nil {
+		r.Timeout = r.ColdStart.Budget
+	}
+	return r
+}
+
+// Send the request once so a target that scaled to zero is running before the request is measured.
+// Mutating requests send the same idempotency key both times, so the target only applies them once.
+func (h *HttpMonitor) wakeUp(client *http.Client, r HttpRequest, logger logr.Logger) *RequestResult {
+	wakeUp := r.wakeUpRequest()
+	resp, result := wakeUp.timedSendRequest(client)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	result.Attempts = 1
+	HandleColdStartMetrics(h, r, result)
+	if result.Err != nil {
+		result.Err = fmt.Errorf("wake-up request failed: %w", result.Err)
+		return result
+	}
+	logger.V(2).Info("woke up target", "duration", result.Duration.String())
+	return result
+}
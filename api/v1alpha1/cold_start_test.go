@@ -0,0 +1,56 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestHttpRequest_wakeUpRequest(t *testing.T) {
+	r := HttpRequest{
+		Name:                  "ksvc",
+		Timeout:               &metav1.Duration{Duration: 2 * time.Second},
+		VariablesFromResponse: VariableList{{Name: "id"}},
+		ColdStart:             &ColdStart{Budget: &metav1.Duration{Duration: 30 * time.Second}},
+	}
+	wakeUp := r.wakeUpRequest()
+	if wakeUp.timeout() != 30*time.Second {
+		t.Errorf("expected the cold start budget as timeout, got %s", wakeUp.timeout())
+	}
+	if wakeUp.VariablesFromResponse != nil {
+		t.Errorf("expected no variables to be extracted from the wake-up request")
+	}
+	if r.timeout() != 2*time.Second || len(r.VariablesFromResponse) != 1 {
+		t.Errorf("expected the measured request to be left alone, got %+v", r)
+	}
+
+	r.ColdStart = &ColdStart{}
+	if wakeUp := r.wakeUpRequest(); wakeUp.timeout() != 2*time.Second {
+		t.Errorf("expected the request timeout without a budget, got %s", wakeUp.timeout())
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// The certificate extension holding SCTs embedded by the CA, from RFC 6962
+var oidEmbeddedSCTs = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+// The endpoint does not meet a compliance requirement
+type complianceError struct {
+	err error
+}
+
+func (e *complianceError) Error() string {
+	return e.err.Error()
+}
+
+type hstsPolicy struct {
+	maxAge            int64
+	includeSubDomains bool
+	preload           bool
+}
+
+func parseHsts(header string) (*hstsPolicy, error) {
+	if header == "" {
+		return nil, errors.New("no Strict-Transport-Security header")
+	}
+	policy := &hstsPolicy{maxAge: -1}
+	for _, directive := range strings.Split(header, ";") {
+		name, value := directive, ""
+		if i := strings.Index(directive, "="); i >= 0 {
+			name, value = directive[:i], strings.Trim(strings.TrimSpace(directive[i+1:]), `"`)
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "max-age":
+			maxAge, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid HSTS max-age %q", value)
+			}
+			policy.maxAge = maxAge
+		case "includesubdomains":
+			policy.includeSubDomains = true
+		case "preload":
+			policy.preload = true
+		}
+	}
+	if policy.maxAge < 0 {
+		return nil, errors.New("HSTS header has no max-age")
+	}
+	return policy, nil
+}
+
+func (c *HstsCheck) verify(resp *http.Response) error {
+	policy, err := parseHsts(resp.Header.Get("Strict-Transport-Security"))
+	if err != nil {
+		return err
+	}
+	minMaxAge := c.MinMaxAge
+	if c.Preload && minMaxAge < hstsPreloadMinMaxAge {
+		minMaxAge = hstsPreloadMinMaxAge
+	}
+	if policy.maxAge < minMaxAge {
+		return fmt.Errorf("HSTS max-age %d is less than %d", policy.maxAge, minMaxAge)
+	}
+	// The preload list requires includeSubDomains and preload
+	if (c.IncludeSubDomains || c.Preload) && !policy.includeSubDomains {
+		return errors.New("HSTS header does not include subdomains")
+	}
+	if c.Preload && !policy.preload {
+		return errors.New("HSTS header does not allow preloading")
+	}
+	return nil
+}
+
+// The preload list requires a max-age of at least a year
+const hstsPreloadMinMaxAge = 31536000
+
+// The log IDs of the SCTs embedded in a certificate
+func embeddedSCTLogIds(cert *x509.Certificate) ([][]byte, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidEmbeddedSCTs) {
+			continue
+		}
+		var list []byte
+		if _, err := asn1.Unmarshal(ext.Value, &list); err != nil {
+			return nil, err
+		}
+		return sctListLogIds(list)
+	}
+	return nil, nil
+}
+
+// Parse a TLS encoded SignedCertificateTimestampList: a 2 byte length, then SCTs each prefixed with a 2 byte
+// length. Each SCT starts with a version byte and the 32 byte log ID.
+func sctListLogIds(list []byte) ([][]byte, error) {
+	if len(list) < 2 {
+		return nil, errors.New("truncated SCT list")
+	}
+	length := int(list[0])<<8 | int(list[1])
+	list = list[2:]
+	if length != len(list) {
+		return nil, errors.New("invalid SCT list length")
+	}
+	var ids [][]byte
+	for len(list) > 0 {
+		if len(list) < 2 {
+			return nil, errors.New("truncated SCT")
+		}
+		sctLength := int(list[0])<<8 | int(list[1])
+		if sctLength > len(list)-2 {
+			return nil, errors.New("truncated SCT")
+		}
+		ids = append(ids, sctLogId(list[2:2+sctLength]))
+		list = list[2+sctLength:]
+	}
+	return ids, nil
+}
+
+func sctLogId(sct []byte) []byte {
+	if len(sct) < 33 {
+		return nil
+	}
+	return sct[1:33]
+}
+
+func (c *CertificateTransparencyCheck) verify(resp *http.Response) error {
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return errors.New("certificate transparency can only be checked for HTTPS requests")
+	}
+	ids, err := embeddedSCTLogIds(resp.TLS.PeerCertificates[0])
+	if err != nil {
+		return fmt.Errorf("could not parse embedded SCTs: %w", err)
+	}
+	for _, sct := range resp.TLS.SignedCertificateTimestamps {
+		ids = append(ids, sctLogId(sct))
+	}
+
+	allowed := make(map[string]bool, len(c.AllowedLogIds))
+	for _, id := range c.AllowedLogIds {
+		allowed[id] = true
+	}
+	count := 0
+	for _, id := range ids {
+		if id == nil {
+			continue
+		}
+		if len(allowed) == 0 || allowed[base64.StdEncoding.EncodeToString(id)] {
+			count++
+		}
+	}
+
+	minScts := c.MinScts
+	if minScts == 0 {
+		minScts = 2
+	}
+	if count < minScts {
+		return fmt.Errorf("certificate has %d SCTs from accepted logs, expected at least %d", count, minScts)
+	}
+	return nil
+}
+
+// Run the compliance checks on the response
+func (c *ComplianceCheck) verify(resp *http.Response) error {
+	if c.Hsts != nil {
+		if err := c.Hsts.verify(resp); err != nil {
+			return &complianceError{err}
+		}
+	}
+	if c.CertificateTransparency != nil {
+		if err := c.CertificateTransparency.verify(resp); err != nil {
+			return &complianceError{err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestHstsCheck_verify(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Check    HstsCheck
+		Header   string
+		Passes   bool
+	}{
+		{"long-enough", HstsCheck{MinMaxAge: 86400}, "max-age=31536000", true},
+		{"too-short", HstsCheck{MinMaxAge: 86400}, "max-age=300", false},
+		{"missing", HstsCheck{}, "", false},
+		{"preload-eligible", HstsCheck{Preload: true}, "max-age=63072000; includeSubDomains; preload", true},
+		{"preload-short-max-age", HstsCheck{Preload: true}, "max-age=86400; includeSubDomains; preload", false},
+		{"preload-without-subdomains", HstsCheck{Preload: true}, "max-age=63072000; preload", false},
+		{"quoted-max-age", HstsCheck{MinMaxAge: 60}, `max-age="600"`, true},
+	}
+
+	for _, testdata := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if testdata.Header != "" {
+			resp.Header.Set("Strict-Transport-Security", testdata.Header)
+		}
+		err := testdata.Check.verify(resp)
+		if (err == nil) != testdata.Passes {
+			t.Errorf("[%s] unexpected result. Got error: %v, expected to pass: %t", testdata.TestName, err, testdata.Passes)
+		}
+	}
+}
+
+func TestSctListLogIds(t *testing.T) {
+	logId := bytes.Repeat([]byte{0xab}, 32)
+	// version, log ID, then the rest of the SCT which is not parsed
+	sct := append(append([]byte{0}, logId...), 1, 2, 3)
+	entry := append([]byte{0, byte(len(sct))}, sct...)
+	list := append([]byte{0, byte(2 * len(entry))}, append(entry, entry...)...)
+
+	ids, err := sctListLogIds(list)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(ids) != 2 || !bytes.Equal(ids[0], logId) {
+		t.Errorf("unexpected log IDs: %x", ids)
+	}
+
+	if _, err := sctListLogIds(list[:len(list)-1]); err == nil {
+		t.Errorf("expected an error for a truncated list")
+	}
+}
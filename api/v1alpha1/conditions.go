@@ -0,0 +1,121 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type Severity string
+
+var (
+	SeverityCritical Severity = "critical"
+	SeverityWarning  Severity = "warning"
+	SeverityInfo     Severity = "info"
+)
+
+// Severities in increasing order of importance
+var severities = []Severity{SeverityInfo, SeverityWarning, SeverityCritical}
+
+func (s Severity) rank() int {
+	for i, severity := range severities {
+		if severity == s {
+			return i
+		}
+	}
+	// Unset severities are critical, so nothing is quietly downgraded
+	return len(severities) - 1
+}
+
+// Whether this severity is as important as other, or more
+func (s Severity) AtLeast(other Severity) bool {
+	return s.rank() >= other.rank()
+}
+
+// The severity to use, falling back to a default when an override is not set
+func (s Severity) Or(fallback Severity) Severity {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// All known severities, for callers that need to enumerate metric labels
+func Severities() []Severity {
+	return append([]Severity(nil), severities...)
+}
+
+const (
+	// True when the last run succeeded
+	ConditionHealthy = "Healthy"
+
+	// True when the spec linter found nothing to warn about
+	ConditionLintClean = "LintClean"
+)
+
+// The state of one aspect of a monitor, in the style of core Kubernetes conditions
+type MonitorCondition struct {
+	Type   string                 `json:"type"`
+	Status corev1.ConditionStatus `json:"status"`
+
+	// How important the problem is, when the condition reports one
+	Severity Severity `json:"severity,omitempty"`
+
+	// A CamelCase reason for the last transition
+	Reason string `json:"reason,omitempty"`
+
+	// Details about the last transition
+	Message string `json:"message,omitempty"`
+
+	LastTransitionTime metav1.Time `json:"last_transition_time"`
+}
+
+// Set a condition, replacing the existing one with the same type. The transition time only changes when the
+// status does.
+func SetCondition(conditions []MonitorCondition, condition MonitorCondition) []MonitorCondition {
+	for i := range conditions {
+		if conditions[i].Type != condition.Type {
+			continue
+		}
+		if conditions[i].Status == condition.Status {
+			condition.LastTransitionTime = conditions[i].LastTransitionTime
+		}
+		conditions[i] = condition
+		return conditions
+	}
+	return append(conditions, condition)
+}
+
+// Find a condition by type
+func FindCondition(conditions []MonitorCondition, conditionType string) *MonitorCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
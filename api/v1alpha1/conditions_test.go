@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestSeverity_AtLeast(t *testing.T) {
+	tests := []struct {
+		Severity Severity
+		Other    Severity
+		Expected bool
+	}{
+		{SeverityCritical, SeverityWarning, true},
+		{SeverityWarning, SeverityWarning, true},
+		{SeverityInfo, SeverityWarning, false},
+		{"", SeverityCritical, true},
+	}
+
+	for _, testdata := range tests {
+		if result := testdata.Severity.AtLeast(testdata.Other); result != testdata.Expected {
+			t.Errorf("%q.AtLeast(%q) = %t, expected %t", testdata.Severity, testdata.Other, result, testdata.Expected)
+		}
+	}
+}
+
+func TestSetCondition(t *testing.T) {
+	then := metav1.NewTime(time.Now().Add(-time.Hour))
+	now := metav1.Now()
+	conditions := []MonitorCondition{
+		{Type: ConditionHealthy, Status: corev1.ConditionFalse, LastTransitionTime: then},
+	}
+
+	conditions = SetCondition(conditions, MonitorCondition{
+		Type: ConditionHealthy, Status: corev1.ConditionFalse, Message: "still failing", LastTransitionTime: now,
+	})
+	if len(conditions) != 1 {
+		t.Fatalf("expected the condition to be replaced, got %d conditions", len(conditions))
+	}
+	if !conditions[0].LastTransitionTime.Equal(&then) || conditions[0].Message != "still failing" {
+		t.Errorf("same status should keep the transition time and update the message, got %+v", conditions[0])
+	}
+
+	conditions = SetCondition(conditions, MonitorCondition{
+		Type: ConditionHealthy, Status: corev1.ConditionTrue, LastTransitionTime: now,
+	})
+	if !conditions[0].LastTransitionTime.Equal(&now) {
+		t.Errorf("status change should update the transition time, got %s", conditions[0].LastTransitionTime)
+	}
+
+	conditions = SetCondition(conditions, MonitorCondition{Type: "Other", Status: corev1.ConditionTrue})
+	if len(conditions) != 2 || FindCondition(conditions, "Other") == nil {
+		t.Errorf("new condition types should be appended, got %+v", conditions)
+	}
+}
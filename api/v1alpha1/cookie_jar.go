@@ -0,0 +1,62 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"golang.org/x/net/publicsuffix"
+	"net/http"
+	"net/http/cookiejar"
+)
+
+// A copy of client that keeps cookies. The public suffix list stops a response from setting cookies for a whole
+// registry domain, like co.uk, as browsers do.
+func withCookieJar(client *http.Client) *http.Client {
+	// New only fails for invalid options
+	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	withJar := *client
+	withJar.Jar = jar
+	return &withJar
+}
+
+// Add the jar's cookies for the request's URL, for requests not sent by an http.Client
+func addCookies(jar http.CookieJar, req *http.Request) {
+	if jar == nil {
+		return
+	}
+	for _, cookie := range jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+}
+
+// Keep the cookies a response set, for requests not sent by an http.Client
+func storeCookies(jar http.CookieJar, req *http.Request, resp *http.Response) {
+	if jar == nil {
+		return
+	}
+	if cookies := resp.Cookies(); len(cookies) > 0 {
+		jar.SetCookies(req.URL, cookies)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHttpMonitor_Execute_cookieJar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3ss10n", Path: "/"})
+		case "/account":
+			if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "s3ss10n" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}
+	}))
+	defer server.Close()
+	httpclient.Initialize(0)
+
+	tests := []struct {
+		TestName  string
+		CookieJar bool
+		Failed    bool
+	}{
+		{"with a cookie jar", true, false},
+		{"without one", false, true},
+	}
+	for _, testdata := range tests {
+		ok := StatusCodeMatcher{intstr.FromInt(200)}
+		monitor := &HttpMonitor{Spec: HttpMonitorSpec{CookieJar: testdata.CookieJar, Requests: []HttpRequest{
+			{Name: "login", Method: http.MethodPost, Url: server.URL + "/login", ExpectedResponseCodes: ok},
+			{Name: "account", Method: http.MethodGet, Url: server.URL + "/account", ExpectedResponseCodes: ok},
+		}}}
+		for run := 0; run < 2; run++ {
+			if result := monitor.Execute(); result.Failed() != testdata.Failed {
+				t.Errorf("[%s] unexpected result of run %d: %v", testdata.TestName, run, result.FirstFailure())
+			}
+		}
+	}
+}
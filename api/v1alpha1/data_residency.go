@@ -0,0 +1,101 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Where the data a monitor captures from responses may be kept
+type DataResidency string
+
+var (
+	// In the cluster only: response snippets in diagnostic bundles, and captured headers in status
+	DataResidencyInCluster DataResidency = "InCluster"
+	// Nowhere: nothing from responses is kept once a request has been checked, for monitors that see personal
+	// data
+	DataResidencyNowhere DataResidency = "Nowhere"
+)
+
+// Whether response snippets, captured headers and diagnostic bundles may be stored for the monitor
+func (h *HttpMonitor) MayStoreCapturedData() bool {
+	return h.Spec.DataResidency != DataResidencyNowhere
+}
+
+// Drop what the request captured from its response, for monitors that may not store it
+func (h *HttpMonitor) restrictCapturedData(result *RequestResult) {
+	if h.MayStoreCapturedData() {
+		return
+	}
+	result.ResponseSnippet = ""
+	result.CapturedHeaders = nil
+	result.Err = withheldError(result.Err)
+	for i := range result.Violations {
+		result.Violations[i].Err = withheldError(result.Violations[i].Err)
+	}
+}
+
+// What kind of failure err was, without its message, which can quote the response
+func failureSummary(err error) string {
+	var netErr net.Error
+	if category := failureCategory(err); category != "" {
+		return "the request failed: " + string(category)
+	} else if errors.As(err, &netErr) && netErr.Timeout() {
+		return "the request timed out"
+	} else if netErr != nil {
+		return "the connection failed"
+	}
+	return "the request failed"
+}
+
+// An error without its message. What kind of failure it was is kept, and the original error is still there for
+// errors.As, so failures are classified the same way.
+func withheldError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &scrubbedError{message: failureSummary(err) + ", details are withheld by data_residency " + string(DataResidencyNowhere), err: err}
+}
+
+// Settings that would store captured data are rejected, rather than silently doing nothing
+func (h *HttpMonitor) checkDataResidency() error {
+	if h.MayStoreCapturedData() {
+		return nil
+	}
+	if h.Spec.Diagnostics != nil && h.Spec.Diagnostics.BundleAfterFailures > 0 {
+		return fmt.Errorf("data_residency %s does not allow diagnostic bundles, unset bundle_after_failures", h.Spec.DataResidency)
+	}
+	for _, requests := range [][]HttpRequest{h.Spec.Requests, h.Spec.Cleanup} {
+		for _, r := range requests {
+			if len(r.CaptureHeaders) > 0 {
+				return fmt.Errorf("data_residency %s does not allow request %q to capture_headers", h.Spec.DataResidency, r.Name)
+			}
+		}
+	}
+	return nil
+}
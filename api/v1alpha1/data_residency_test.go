@@ -0,0 +1,107 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHttpMonitor_Execute_dataResidency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Patient-Id", "12345")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"patient": "Jane Doe"}`))
+	}))
+	defer server.Close()
+	httpclient.Initialize(0)
+
+	tests := []struct {
+		TestName      string
+		DataResidency DataResidency
+		Check         bool
+		Stored        bool
+	}{
+		{"default", "", false, true},
+		{"in cluster", DataResidencyInCluster, false, true},
+		{"nowhere", DataResidencyNowhere, false, false},
+		{"nowhere check", DataResidencyNowhere, true, false},
+	}
+	for _, testdata := range tests {
+		request := HttpRequest{Name: "record", Method: http.MethodGet, Url: server.URL, CaptureHeaders: []string{"X-Patient-Id"},
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}}
+		monitor := &HttpMonitor{Spec: HttpMonitorSpec{DataResidency: testdata.DataResidency,
+			Requests: []HttpRequest{request}, Cleanup: []HttpRequest{request}}}
+		if testdata.Check {
+			check := &HttpCheck{Spec: HttpCheckSpec{DataResidency: testdata.DataResidency,
+				Requests: []HttpRequest{request}, Cleanup: []HttpRequest{request}}}
+			monitor = check.AsHttpMonitor()
+		}
+		run := monitor.Execute()
+		for _, result := range []*RequestResult{run.Requests[0], run.Cleanup[0]} {
+			if stored := result.ResponseSnippet != "" && result.CapturedHeaders["X-Patient-Id"] != ""; stored != testdata.Stored {
+				t.Errorf("[%s] unexpected captured data. Snippet: %q, headers: %v", testdata.TestName,
+					result.ResponseSnippet, result.CapturedHeaders)
+			}
+			if result.Err == nil || strings.Contains(result.Err.Error(), "withheld") == testdata.Stored {
+				t.Errorf("[%s] unexpected error %v", testdata.TestName, result.Err)
+			}
+		}
+		if monitor.MayStoreCapturedData() != testdata.Stored {
+			t.Errorf("[%s] unexpected MayStoreCapturedData", testdata.TestName)
+		}
+	}
+}
+
+func TestWithheldError(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Err      error
+		Expected string
+		Category FailureCategory
+	}{
+		{"quotes the body", fmt.Errorf(`body contains "Jane Doe"`), "the request failed", ""},
+		{"categorized", &egressIpError{err: fmt.Errorf("203.0.113.7 is not expected")},
+			"the request failed: " + string(FailureCategoryEgressIpChanged), FailureCategoryEgressIpChanged},
+		{"timeout", &net.DNSError{Err: "i/o timeout", Name: "patients.example.com", IsTimeout: true}, "the request timed out", ""},
+		{"connection", &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}, "the connection failed", ""},
+	}
+	for _, testdata := range tests {
+		err := withheldError(testdata.Err)
+		if expected := testdata.Expected + ", details are withheld by data_residency Nowhere"; err.Error() != expected {
+			t.Errorf("[%s] unexpected message. Got: %q, expected: %q", testdata.TestName, err, expected)
+		}
+		if category := failureCategory(err); category != testdata.Category {
+			t.Errorf("[%s] unexpected category %q", testdata.TestName, category)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/go-logr/logr"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Whether any request depends on another. Monitors like that run their requests as a graph, each one as soon as
+// what it depends on has succeeded, instead of one after the other.
+func (s *HttpMonitorSpec) hasDependencies() bool {
+	for _, r := range s.Requests {
+		if len(r.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Check that dependencies name other requests and do not form a cycle
+func (s *HttpMonitorSpec) validateDependencies() error {
+	index := make(map[string]int, len(s.Requests))
+	for i, r := range s.Requests {
+		index[r.Name] = i
+	}
+	for _, r := range s.Requests {
+		for _, name := range r.DependsOn {
+			if name == r.Name {
+				return fmt.Errorf("request %q depends on itself", r.Name)
+			}
+			if _, ok := index[name]; !ok {
+				return fmt.Errorf("request %q depends on %q, which is not a request", r.Name, name)
+			}
+		}
+	}
+
+	// Depth first, keeping the path so a cycle can be shown
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(s.Requests))
+	var path []string
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for path[start] != s.Requests[i].Name {
+				start++
+			}
+			return fmt.Errorf("requests depend on each other in a cycle: %s -> %s",
+				strings.Join(path[start:], " -> "), s.Requests[i].Name)
+		}
+		state[i] = visiting
+		path = append(path, s.Requests[i].Name)
+		for _, name := range s.Requests[i].DependsOn {
+			if err := visit(index[name]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+	for i := range s.Requests {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The longest a chain of dependent requests can take, given how long each request can take. Cycles are not
+// followed, they are rejected before a monitor runs.
+func (s *HttpMonitorSpec) criticalPath(duration func(HttpRequest) time.Duration) time.Duration {
+	index := make(map[string]int, len(s.Requests))
+	for i, r := range s.Requests {
+		index[r.Name] = i
+	}
+	finish := make([]time.Duration, len(s.Requests))
+	done := make([]bool, len(s.Requests))
+	var visit func(i int) time.Duration
+	visit = func(i int) time.Duration {
+		if done[i] {
+			return finish[i]
+		}
+		done[i] = true
+		var start time.Duration
+		for _, name := range s.Requests[i].DependsOn {
+			if j, ok := index[name]; ok {
+				if d := visit(j); d > start {
+					start = d
+				}
+			}
+		}
+		finish[i] = start + duration(s.Requests[i])
+		return finish[i]
+	}
+	var longest time.Duration
+	for i := range s.Requests {
+		if d := visit(i); d > longest {
+			longest = d
+		}
+	}
+	return longest
+}
+
+// Run the requests as a graph. Each request starts once the requests it depends on have succeeded, and sees
+// the variables they and their own dependencies produced. Requests that depend on one that failed or was
+// skipped do not run. Results are in the order of the spec, and so are the variables returned for cleanup.
+// A graph the webhook would have rejected fails every request without sending any, since it would never finish.
+func (h *HttpMonitor) executeGraph(client *http.Client, result *RunResult, provided VariableList, lineage *lineageTracker, logger logr.Logger) ([]string, VariableList) {
+	requests := h.Spec.Requests
+	if err := h.Spec.validateDependencies(); err != nil {
+		logger.Error(err, "not running requests")
+		for _, r := range requests {
+			result.Requests = append(result.Requests, &RequestResult{Name: r.Name, Err: fmt.Errorf("invalid depends_on: %w", err)})
+		}
+		return nil, provided
+	}
+	index := make(map[string]int, len(requests))
+	for i, r := range requests {
+		index[r.Name] = i
+	}
+	done := make([]chan struct{}, len(requests))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	// Written by each request before it closes its channel, and only read after
+	results := make([]*RequestResult, len(requests))
+	produced := make([]VariableList, len(requests))
+	// Variables from each request and everything it depends on, from the requests before them first
+	inherited := make([]VariableList, len(requests))
+
+	var lineageLock sync.Mutex
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer close(done[i])
+			httpRequest := requests[i]
+			entry := logger.WithValues("name", httpRequest.Name)
+
+			available := append(VariableList(nil), provided...)
+			var visible VariableList
+			for _, name := range httpRequest.DependsOn {
+				j := index[name]
+				<-done[j]
+				if results[j] == nil || results[j].Err != nil {
+					entry.Info("skipping request", "reason", fmt.Sprintf("depends on %q, which failed or was skipped", name))
+					return
+				}
+				visible = append(visible, inherited[j]...)
+			}
+			available = append(available, visible...)
+
+			h.prepareRequest(&httpRequest, available)
+			lineageLock.Lock()
+			lineage.consumed(&httpRequest)
+			lineageLock.Unlock()
+			results[i] = h.executeRequest(client, httpRequest, entry)
+			if results[i] != nil && results[i].Err == nil {
+				produced[i] = httpRequest.VariablesFromResponse
+				inherited[i] = append(visible, produced[i]...)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var succeeded []string
+	available := provided
+	for i, r := range requests {
+		if results[i] == nil {
+			continue
+		}
+		result.Requests = append(result.Requests, results[i])
+		if results[i].Err != nil {
+			continue
+		}
+		succeeded = append(succeeded, r.Name)
+		available = append(available, produced[i]...)
+		for _, variable := range produced[i] {
+			lineage.produced(variable.Name, r.Name)
+			result.Variables[variable.Name] = variable.Value
+		}
+	}
+	return succeeded, available
+}
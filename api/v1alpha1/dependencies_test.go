@@ -0,0 +1,152 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHttpMonitorSpec_validateDependencies(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Requests []HttpRequest
+		Expected string
+	}{
+		{"no dependencies", []HttpRequest{{Name: "a"}, {Name: "b"}}, ""},
+		{"diamond", []HttpRequest{{Name: "a"}, {Name: "b", DependsOn: []string{"a"}}, {Name: "c", DependsOn: []string{"a"}},
+			{Name: "d", DependsOn: []string{"b", "c"}}}, ""},
+		{"unknown request", []HttpRequest{{Name: "a", DependsOn: []string{"login"}}},
+			`request "a" depends on "login", which is not a request`},
+		{"itself", []HttpRequest{{Name: "a", DependsOn: []string{"a"}}}, `request "a" depends on itself`},
+		{"cycle", []HttpRequest{{Name: "a", DependsOn: []string{"c"}}, {Name: "b", DependsOn: []string{"a"}},
+			{Name: "c", DependsOn: []string{"b"}}}, "requests depend on each other in a cycle: a -> c -> b -> a"},
+	}
+
+	for _, testdata := range tests {
+		spec := HttpMonitorSpec{Requests: testdata.Requests}
+		out := ""
+		if err := spec.validateDependencies(); err != nil {
+			out = err.Error()
+		}
+		if out != testdata.Expected {
+			t.Errorf("[%s] unexpected result. Got: %q, expected: %q", testdata.TestName, out, testdata.Expected)
+		}
+	}
+}
+
+func TestHttpMonitor_executeGraph(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			_, _ = w.Write([]byte(`{"token": "abc"}`))
+		case "/slow":
+			time.Sleep(300 * time.Millisecond)
+		case "/orders":
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	httpclient.Initialize(0)
+	ok := StatusCodeMatcher{intstr.FromInt(200)}
+
+	monitor := &HttpMonitor{Spec: HttpMonitorSpec{Requests: []HttpRequest{
+		{Name: "login", Url: server.URL + "/login", ExpectedResponseCodes: ok,
+			VariablesFromResponse: VariableList{{Name: "token", From: FromTypeBodyJson, JsonPath: "/token"}}},
+		{Name: "slow", Url: server.URL + "/slow", ExpectedResponseCodes: ok},
+		{Name: "broken", Url: server.URL + "/broken", ExpectedResponseCodes: ok},
+		{Name: "orders", Url: server.URL + "/orders", ExpectedResponseCodes: ok, DependsOn: []string{"login"},
+			Headers: http.Header{"Authorization": []string{"Bearer {token}"}}},
+		{Name: "also slow", Url: server.URL + "/slow", ExpectedResponseCodes: ok, DependsOn: []string{"login"}},
+		{Name: "after broken", Url: server.URL + "/", ExpectedResponseCodes: ok, DependsOn: []string{"broken"}},
+	}}}
+	monitor.Name = "graph"
+	result := monitor.Execute()
+
+	var names, failed []string
+	for _, r := range result.Requests {
+		names = append(names, r.Name)
+		if r.Err != nil {
+			failed = append(failed, r.Name)
+		}
+	}
+	if expected := []string{"login", "slow", "broken", "orders", "also slow"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("unexpected requests. Got: %v, expected: %v", names, expected)
+	}
+	if expected := []string{"broken"}; !reflect.DeepEqual(failed, expected) {
+		t.Errorf("unexpected failed requests. Got: %v, expected: %v", failed, expected)
+	}
+	if result.Variables["token"] != "abc" {
+		t.Errorf("unexpected variables: %v", result.Variables)
+	}
+	// The slow requests ran at the same time
+	if result.Duration >= 550*time.Millisecond {
+		t.Errorf("unexpected duration: %s", result.Duration)
+	}
+}
+
+func TestHttpMonitor_executeGraph_invalid(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Requests []HttpRequest
+		Expected string
+	}{
+		{"unknown request", []HttpRequest{{Name: "a"}, {Name: "b", DependsOn: []string{"login"}}},
+			`invalid depends_on: request "b" depends on "login", which is not a request`},
+		{"itself", []HttpRequest{{Name: "a", DependsOn: []string{"a"}}}, `invalid depends_on: request "a" depends on itself`},
+		{"cycle", []HttpRequest{{Name: "a", DependsOn: []string{"b"}}, {Name: "b", DependsOn: []string{"a"}}},
+			"invalid depends_on: requests depend on each other in a cycle: a -> b -> a"},
+	}
+
+	for _, testdata := range tests {
+		monitor := &HttpMonitor{Spec: HttpMonitorSpec{Requests: testdata.Requests}}
+		finished := make(chan *RunResult)
+		go func() { finished <- monitor.Execute() }()
+		select {
+		case result := <-finished:
+			if len(result.Requests) != len(testdata.Requests) {
+				t.Errorf("[%s] unexpected results %v", testdata.TestName, result.Requests)
+				continue
+			}
+			for _, r := range result.Requests {
+				if r.Err == nil || r.Err.Error() != testdata.Expected {
+					t.Errorf("[%s] unexpected error for %s. Got: %v, expected: %q", testdata.TestName, r.Name, r.Err, testdata.Expected)
+				}
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("[%s] the run did not finish", testdata.TestName)
+		}
+	}
+}
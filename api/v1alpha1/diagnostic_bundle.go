@@ -0,0 +1,161 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"github.com/oregondesignservices/monitoring-controller/internal/netdiag"
+	"net/url"
+	"time"
+)
+
+type bundleCertificate struct {
+	Subject  string `json:"subject"`
+	Issuer   string `json:"issuer"`
+	NotAfter string `json:"not_after"`
+}
+
+type bundleTLS struct {
+	Version      string              `json:"version"`
+	CipherSuite  string              `json:"cipher_suite"`
+	ServerName   string              `json:"server_name,omitempty"`
+	Certificates []bundleCertificate `json:"certificates,omitempty"`
+}
+
+type bundleTimings struct {
+	DNS          string `json:"dns"`
+	Connect      string `json:"connect"`
+	TLSHandshake string `json:"tls_handshake"`
+	FirstByte    string `json:"first_byte"`
+}
+
+type bundleRequest struct {
+	Name              string        `json:"name"`
+	Url               string        `json:"url"`
+	StatusCode        int           `json:"status_code,omitempty"`
+	Error             string        `json:"error,omitempty"`
+	Duration          string        `json:"duration"`
+	Timings           bundleTimings `json:"timings"`
+	ResolvedAddresses []string      `json:"resolved_addresses,omitempty"`
+	TLS               *bundleTLS    `json:"tls,omitempty"`
+	ResponseSnippet   string        `json:"response_snippet,omitempty"`
+	Traceroute        string        `json:"traceroute,omitempty"`
+}
+
+var tlsVersionNames = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+func newBundleTLS(state *tls.ConnectionState) *bundleTLS {
+	if state == nil {
+		return nil
+	}
+	out := &bundleTLS{
+		Version:     tlsVersionNames[state.Version],
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+	}
+	for _, cert := range state.PeerCertificates {
+		out.Certificates = append(out.Certificates, bundleCertificate{
+			Subject:  cert.Subject.String(),
+			Issuer:   cert.Issuer.String(),
+			NotAfter: cert.NotAfter.UTC().Format(time.RFC3339),
+		})
+	}
+	return out
+}
+
+// Fill in the traceroute for requests that could not connect, if it was not already captured during the run
+func (h *HttpMonitor) completeHopReports(result *RunResult) {
+	for _, r := range append(result.Requests, result.Cleanup...) {
+		if r.Err == nil || r.HopReport != nil || !isConnectionError(r.Err) {
+			continue
+		}
+		target, err := url.Parse(r.Url)
+		if err != nil {
+			continue
+		}
+		maxHops := 0
+		if h.Spec.Diagnostics != nil {
+			maxHops = h.Spec.Diagnostics.MaxHops
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), tracerouteTimeout)
+		r.HopReport, _ = netdiag.Trace(ctx, target.Hostname(), netdiag.Options{MaxHops: maxHops})
+		cancel()
+	}
+}
+
+// Collect everything we know about a failed run into ConfigMap data, so on-call can see what happened
+// without reproducing the failure by hand.
+func (h *HttpMonitor) CollectDiagnosticBundle(result *RunResult) (map[string]string, error) {
+	h.completeHopReports(result)
+
+	var requests []bundleRequest
+	for _, r := range append(result.Requests, result.Cleanup...) {
+		entry := bundleRequest{
+			Name:       r.Name,
+			Url:        r.Url,
+			StatusCode: r.StatusCode,
+			Duration:   r.Duration.String(),
+			Timings: bundleTimings{
+				DNS:          r.Timings.DNS.String(),
+				Connect:      r.Timings.Connect.String(),
+				TLSHandshake: r.Timings.TLSHandshake.String(),
+				FirstByte:    r.Timings.FirstByte.String(),
+			},
+			ResolvedAddresses: r.Timings.ResolvedAddresses,
+			TLS:               newBundleTLS(r.TLS),
+			ResponseSnippet:   r.ResponseSnippet,
+			Traceroute:        r.HopReport.String(),
+		}
+		if r.Err != nil {
+			entry.Error = r.Err.Error()
+		}
+		requests = append(requests, entry)
+	}
+
+	out, err := yaml.Marshal(requests)
+	if err != nil {
+		return nil, err
+	}
+	lineage, err := yaml.Marshal(result.Lineage)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"monitor":       fmt.Sprintf("%s/%s", h.Namespace, h.Name),
+		"started":       result.Start.UTC().Format(time.RFC3339),
+		"duration":      result.Duration.String(),
+		"requests.yaml": string(out),
+		"lineage.yaml":  string(lineage),
+	}, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
+	"net"
+	"strings"
+)
+
+// Check the address the controller's traffic leaves the cluster from, as an echo service like
+// https://checkip.amazonaws.com reports it in the response. Partners that allowlist our addresses silently drop
+// traffic after a NAT gateway or egress route changes, so a changed address is worth failing on by itself.
+type EgressIpCheck struct {
+	// Addresses or CIDR ranges, like 203.0.113.10 or 198.51.100.0/28. The observed address must be in one of them.
+	// +kubebuilder:validation:MinItems=1
+	Expected []string `json:"expected"`
+
+	// Where the address is in a JSON response, like /ip for https://api.ipify.org?format=json. Without it the body
+	// is the address as plain text, or has an ip= line like https://www.cloudflare.com/cdn-cgi/trace.
+	JsonPath string `json:"json_path,omitempty"`
+}
+
+// The egress address is not an expected one
+type egressIpError struct {
+	err error
+}
+
+func (e *egressIpError) Error() string {
+	return e.err.Error()
+}
+
+func (c *EgressIpCheck) validate() error {
+	for _, expected := range c.Expected {
+		if _, _, err := net.ParseCIDR(expected); err != nil && net.ParseIP(expected) == nil {
+			return fmt.Errorf("invalid egress_ip: %q is neither an IP address nor a CIDR range", expected)
+		}
+	}
+	return nil
+}
+
+// The address the echo service reported
+func (c *EgressIpCheck) observed(body []byte) (net.IP, error) {
+	text := strings.TrimSpace(string(body))
+	if c.JsonPath != "" {
+		getter := jsoniter.Get(body, jsonPathKeys(splitJsonPath(c.JsonPath))...)
+		if err := getter.LastError(); err != nil {
+			return nil, fmt.Errorf("no egress IP at %s in the response: %w", c.JsonPath, err)
+		}
+		text = strings.TrimSpace(getter.ToString())
+	} else if strings.Contains(text, "\n") {
+		for _, line := range strings.Split(text, "\n") {
+			if strings.HasPrefix(line, "ip=") {
+				text = strings.TrimSpace(strings.TrimPrefix(line, "ip="))
+				break
+			}
+		}
+	}
+	ip := net.ParseIP(text)
+	if ip == nil {
+		if len(text) > 64 {
+			text = text[:64] + "..."
+		}
+		return nil, fmt.Errorf("the response is not an IP address: %q", text)
+	}
+	return ip, nil
+}
+
+func (c *EgressIpCheck) verify(body []byte) error {
+	ip, err := c.observed(body)
+	if err != nil {
+		return err
+	}
+	for _, expected := range c.Expected {
+		if _, network, err := net.ParseCIDR(expected); err == nil {
+			if network.Contains(ip) {
+				return nil
+			}
+		} else if ip.Equal(net.ParseIP(expected)) {
+			return nil
+		}
+	}
+	return &egressIpError{fmt.Errorf("egress IP is %s, expected %s", ip, strings.Join(c.Expected, " or "))}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEgressIpCheck_verify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/text":
+			_, _ = w.Write([]byte("203.0.113.10\n"))
+		case "/json":
+			_, _ = w.Write([]byte(`{"ip": "2001:db8::1"}`))
+		case "/trace":
+			_, _ = w.Write([]byte("fl=123\nh=www.cloudflare.com\nip=198.51.100.7\nts=1700000000\n"))
+		case "/html":
+			_, _ = w.Write([]byte("<html>blocked</html>"))
+		}
+	}))
+	defer server.Close()
+	httpclient.Initialize(0)
+
+	tests := []struct {
+		TestName         string
+		Path             string
+		Check            EgressIpCheck
+		ExpectedError    string
+		ExpectedCategory FailureCategory
+	}{
+		{"plain text", "/text", EgressIpCheck{Expected: []string{"203.0.113.10"}}, "", ""},
+		{"json", "/json", EgressIpCheck{Expected: []string{"2001:db8::/64"}, JsonPath: "/ip"}, "", ""},
+		{"trace", "/trace", EgressIpCheck{Expected: []string{"198.51.100.0/28"}}, "", ""},
+		{"changed", "/text", EgressIpCheck{Expected: []string{"203.0.113.11", "198.51.100.0/28"}},
+			"egress IP is 203.0.113.10, expected 203.0.113.11 or 198.51.100.0/28", FailureCategoryEgressIpChanged},
+		{"not an address", "/html", EgressIpCheck{Expected: []string{"203.0.113.10"}},
+			`the response is not an IP address: "<html>blocked</html>"`, ""},
+	}
+
+	for _, testdata := range tests {
+		check := testdata.Check
+		request := HttpRequest{Name: testdata.TestName, Method: http.MethodGet, Url: server.URL + testdata.Path,
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, EgressIp: &check}
+		_, result := request.timedSendRequest(httpclient.GetClient())
+		out := ""
+		if result.Err != nil {
+			out = result.Err.Error()
+		}
+		if out != testdata.ExpectedError {
+			t.Errorf("[%s] unexpected error %q, expected %q", testdata.TestName, out, testdata.ExpectedError)
+		}
+		if result.Category != testdata.ExpectedCategory {
+			t.Errorf("[%s] unexpected category %q, expected %q", testdata.TestName, result.Category, testdata.ExpectedCategory)
+		}
+	}
+}
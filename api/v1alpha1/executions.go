@@ -0,0 +1,177 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Which executions of a tick must pass for the tick to pass
+type ExecutionRule string
+
+var (
+	ExecutionRuleAll ExecutionRule = "all"
+	ExecutionRuleAny ExecutionRule = "any"
+	// More than half
+	ExecutionRuleQuorum ExecutionRule = "quorum"
+)
+
+// Runs a monitor more than once on each tick, for targets where a single execution is not enough to tell an
+// outage from a blip. Status, metrics and notifications follow the outcome the rule decides, so one tick never
+// reports conflicting states or pages more than once.
+type Executions struct {
+	// How many times to run the requests on each tick
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=10
+	Count int `json:"count"`
+
+	// How many executions run at the same time. Default is 3
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	MaxParallel int `json:"max_parallel,omitempty"`
+
+	// Which executions must pass for the tick to pass: all, any, or a quorum of more than half. Default is quorum
+	// +kubebuilder:validation:Enum=all;any;quorum
+	PassWhen ExecutionRule `json:"pass_when,omitempty"`
+}
+
+func (e *Executions) passes(passed int) bool {
+	switch e.PassWhen {
+	case ExecutionRuleAll:
+		return passed == e.Count
+	case ExecutionRuleAny:
+		return passed > 0
+	}
+	return passed*2 > e.Count
+}
+
+const defaultMaxParallelExecutions = 3
+
+func (e *Executions) maxParallel() int {
+	parallel := e.MaxParallel
+	if parallel <= 0 {
+		parallel = defaultMaxParallelExecutions
+	}
+	if parallel > e.Count {
+		parallel = e.Count
+	}
+	return parallel
+}
+
+// The last tick each monitor's runners have started, by monitor, so when two runners of the same monitor overlap,
+// while one replaces the other, only one of them runs a tick and reports it. One entry per monitor, so it only
+// grows with the number of monitors.
+var claimedExecutions = struct {
+	sync.Mutex
+	ticks map[string]int64
+}{ticks: make(map[string]int64)}
+
+// The name of the monitor's tick that was due at due. Ticks of runners on grids with different offsets get the
+// same name when they fall in the same period.
+func (h *HttpMonitor) ExecutionName(due time.Time) string {
+	return fmt.Sprintf("%s/%s@%d", h.Namespace, h.Name, h.executionTick(due))
+}
+
+func (h *HttpMonitor) executionTick(due time.Time) int64 {
+	var period time.Duration
+	if h.Spec.Period != nil {
+		period = h.Spec.Period.Duration
+	}
+	return due.Truncate(period).Unix()
+}
+
+// Whether no runner of the monitor has claimed the tick, or a later one, before
+func claimExecution(monitor string, tick int64) bool {
+	claimedExecutions.Lock()
+	defer claimedExecutions.Unlock()
+	if last, ok := claimedExecutions.ticks[monitor]; ok && tick <= last {
+		return false
+	}
+	claimedExecutions.ticks[monitor] = tick
+	return true
+}
+
+// Run the tick that was due at due as many times as the monitor's executions ask for, and return the result that
+// decides it. Returns nil when another runner has already claimed the tick. Executions run at the same time, up to
+// max_parallel, each on its own copy of the monitor.
+func (h *HttpMonitor) ExecuteTick(due time.Time) *RunResult {
+	name := h.ExecutionName(due)
+	if !claimExecution(h.Namespace+"/"+h.Name, h.executionTick(due)) {
+		return nil
+	}
+	executions := h.Spec.Executions
+	if executions == nil || executions.Count < 2 {
+		result := h.Execute()
+		result.Execution = name
+		return result
+	}
+	results := make([]*RunResult, executions.Count)
+	slots := make(chan bool, executions.maxParallel())
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		slots <- true
+		go func(i int) {
+			defer wg.Done()
+			results[i] = h.DeepCopy().Execute()
+			<-slots
+		}(i)
+	}
+	wg.Wait()
+	result := aggregateExecutions(executions, results)
+	result.Execution = name
+	return result
+}
+
+// The first execution that agrees with the decided outcome stands for the tick. The others are kept along, so
+// their traffic is counted and their failed cleanup retried.
+func aggregateExecutions(executions *Executions, results []*RunResult) *RunResult {
+	passed := 0
+	for _, result := range results {
+		if !result.Failed() {
+			passed++
+		}
+	}
+	pass := executions.passes(passed)
+
+	decided := 0
+	for i, result := range results {
+		if result.Failed() != pass {
+			decided = i
+			break
+		}
+	}
+	aggregate := *results[decided]
+	aggregate.ExecutionsPassed = passed
+	for i, result := range results {
+		if i != decided {
+			aggregate.OtherExecutions = append(aggregate.OtherExecutions, result)
+		}
+	}
+	return &aggregate
+}
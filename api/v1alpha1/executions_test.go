@@ -0,0 +1,170 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestAggregateExecutions(t *testing.T) {
+	passing := func() *RunResult {
+		return &RunResult{Requests: []*RequestResult{{Name: "a", Attempts: 1}}}
+	}
+	failing := func() *RunResult {
+		return &RunResult{Requests: []*RequestResult{{Name: "a", Attempts: 1, Err: errors.New("timeout")}}}
+	}
+
+	tests := []struct {
+		TestName       string
+		Rule           ExecutionRule
+		Results        []*RunResult
+		ExpectedState  MonitorState
+		ExpectedPassed int
+	}{
+		{"quorum passes", "", []*RunResult{failing(), passing(), passing()}, MonitorStateUp, 2},
+		{"quorum fails", ExecutionRuleQuorum, []*RunResult{passing(), failing(), failing()}, MonitorStateDown, 1},
+		{"half is no quorum", ExecutionRuleQuorum, []*RunResult{passing(), failing()}, MonitorStateDown, 1},
+		{"all fails", ExecutionRuleAll, []*RunResult{passing(), passing(), failing()}, MonitorStateDown, 2},
+		{"all passes", ExecutionRuleAll, []*RunResult{passing(), passing()}, MonitorStateUp, 2},
+		{"any passes", ExecutionRuleAny, []*RunResult{failing(), failing(), passing()}, MonitorStateUp, 1},
+		{"any fails", ExecutionRuleAny, []*RunResult{failing(), failing()}, MonitorStateDown, 0},
+	}
+
+	for _, testdata := range tests {
+		executions := &Executions{Count: len(testdata.Results), PassWhen: testdata.Rule}
+		result := aggregateExecutions(executions, testdata.Results)
+		if result.State() != testdata.ExpectedState {
+			t.Errorf("[%s] unexpected state. Got: %s, expected: %s", testdata.TestName, result.State(), testdata.ExpectedState)
+		}
+		if result.ExecutionsPassed != testdata.ExpectedPassed {
+			t.Errorf("[%s] unexpected executions passed. Got: %d, expected: %d", testdata.TestName,
+				result.ExecutionsPassed, testdata.ExpectedPassed)
+		}
+		if len(result.OtherExecutions) != len(testdata.Results)-1 {
+			t.Errorf("[%s] expected the other executions to be kept, got %d", testdata.TestName, len(result.OtherExecutions))
+		}
+		if traffic := result.Traffic(); traffic.Requests != int64(len(testdata.Results)) {
+			t.Errorf("[%s] expected the traffic of every execution, got %d requests", testdata.TestName, traffic.Requests)
+		}
+	}
+}
+
+func TestHttpMonitor_ExecuteTick(t *testing.T) {
+	var lock sync.Mutex
+	running, mostRunning, requests := 0, 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		running++
+		requests++
+		if running > mostRunning {
+			mostRunning = running
+		}
+		lock.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		lock.Lock()
+		running--
+		lock.Unlock()
+	}))
+	defer server.Close()
+	httpclient.Initialize(0)
+	claimedExecutions.ticks = make(map[string]int64)
+
+	tests := []struct {
+		TestName            string
+		MaxParallel         int
+		ExpectedMostRunning int
+	}{
+		{"one at a time", 1, 1},
+		{"bounded", 2, 2},
+		{"default", 0, 3},
+	}
+
+	for i, testdata := range tests {
+		mostRunning, requests = 0, 0
+		monitor := &HttpMonitor{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "executions"},
+			Spec: HttpMonitorSpec{
+				Period:     &metav1.Duration{Duration: time.Minute},
+				Executions: &Executions{Count: 4, MaxParallel: testdata.MaxParallel},
+				Requests: []HttpRequest{{Name: "a", Method: http.MethodGet, Url: server.URL,
+					ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}}},
+			},
+		}
+		due := time.Unix(int64(i)*3600, 0)
+		result := monitor.ExecuteTick(due)
+		if result == nil || result.Failed() || result.ExecutionsPassed != 4 {
+			t.Fatalf("[%s] unexpected result: %+v", testdata.TestName, result)
+		}
+		if requests != 4 || mostRunning != testdata.ExpectedMostRunning {
+			t.Errorf("[%s] unexpected concurrency. Got %d requests, up to %d at a time, expected up to %d",
+				testdata.TestName, requests, mostRunning, testdata.ExpectedMostRunning)
+		}
+
+		// Another runner of the same monitor, on a grid a few seconds off, sees the tick as already run
+		if other := monitor.DeepCopy().ExecuteTick(due.Add(5 * time.Second)); other != nil {
+			t.Errorf("[%s] unexpected second run of the tick %s", testdata.TestName, result.Execution)
+		}
+		if next := monitor.ExecuteTick(due.Add(time.Minute)); next == nil {
+			t.Errorf("[%s] unexpected skip of the next tick", testdata.TestName)
+		}
+	}
+}
+
+func TestClaimExecution(t *testing.T) {
+	claimedExecutions.ticks = make(map[string]int64)
+	claimExecution("default/claimed", 120)
+	// However many other monitors claim ticks, the monitor's own claim stays
+	for i := 0; i < 5000; i++ {
+		claimExecution(fmt.Sprintf("default/other-%d", i), 120)
+	}
+
+	tests := []struct {
+		TestName string
+		Monitor  string
+		Tick     int64
+		Expected bool
+	}{
+		{"same tick", "default/claimed", 120, false},
+		{"earlier tick", "default/claimed", 60, false},
+		{"next tick", "default/claimed", 180, true},
+		{"other monitor", "default/unclaimed", 120, true},
+	}
+	for _, testdata := range tests {
+		if claimed := claimExecution(testdata.Monitor, testdata.Tick); claimed != testdata.Expected {
+			t.Errorf("[%s] unexpected claim. Got: %t, expected: %t", testdata.TestName, claimed, testdata.Expected)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"crypto/tls"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"github.com/oregondesignservices/monitoring-controller/internal/proxyproto"
+	"net/http"
+)
+
+type ClientFingerprint string
+
+var (
+	ClientFingerprintChrome  ClientFingerprint = "chrome"
+	ClientFingerprintFirefox ClientFingerprint = "firefox"
+	ClientFingerprintSafari  ClientFingerprint = "safari"
+)
+
+// What a browser looks like to a server: the headers it sends, the order it sends them in over HTTP/1.1, and
+// the TLS parameters it offers
+type fingerprintPreset struct {
+	headers     map[string]string
+	headerOrder []string
+	tls         httpclient.Options
+}
+
+// Modern browsers offer TLS 1.2 and 1.3 with ECDHE suites, prefer AES-GCM and ChaCha20, and negotiate h2
+var browserTLS = httpclient.Options{
+	TLSMinVersion: tls.VersionTLS12,
+	TLSCipherSuites: []uint16{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+		tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	},
+	TLSCurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+	TLSNextProtos:       []string{"h2", "http/1.1"},
+}
+
+var fingerprintPresets = map[ClientFingerprint]fingerprintPreset{
+	ClientFingerprintChrome: {
+		headers: map[string]string{
+			"User-Agent":      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
+			"Accept-Language": "en-US,en;q=0.9",
+		},
+		headerOrder: []string{"Host", "Connection", "Content-Length", "Cache-Control", "Sec-Ch-Ua", "Sec-Ch-Ua-Mobile",
+			"Sec-Ch-Ua-Platform", "Upgrade-Insecure-Requests", "Origin", "Content-Type", "User-Agent", "Accept",
+			"Sec-Fetch-Site", "Sec-Fetch-Mode", "Sec-Fetch-User", "Sec-Fetch-Dest", "Referer", "Accept-Encoding",
+			"Accept-Language", "Cookie"},
+		tls: browserTLS,
+	},
+	ClientFingerprintFirefox: {
+		headers: map[string]string{
+			"User-Agent":      "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
+			"Accept-Language": "en-US,en;q=0.5",
+		},
+		headerOrder: []string{"Host", "User-Agent", "Accept", "Accept-Language", "Accept-Encoding", "Content-Type",
+			"Content-Length", "Origin", "Connection", "Referer", "Cookie", "Upgrade-Insecure-Requests", "Sec-Fetch-Dest",
+			"Sec-Fetch-Mode", "Sec-Fetch-Site", "Sec-Fetch-User"},
+		tls: browserTLS,
+	},
+	ClientFingerprintSafari: {
+		headers: map[string]string{
+			"User-Agent":      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+			"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			"Accept-Language": "en-US,en;q=0.9",
+		},
+		headerOrder: []string{"Host", "Content-Type", "Accept", "Sec-Fetch-Site", "Origin", "Cookie", "Sec-Fetch-Dest",
+			"Content-Length", "Accept-Language", "Sec-Fetch-Mode", "User-Agent", "Referer", "Accept-Encoding", "Connection"},
+		tls: browserTLS,
+	},
+}
+
+// Add the browser headers the request does not set itself
+func (r *HttpRequest) applyFingerprintHeaders(req *http.Request) {
+	preset, ok := fingerprintPresets[r.Fingerprint]
+	if !ok {
+		return
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	for key, value := range preset.headers {
+		if req.Header.Get(key) == "" {
+			req.Header.Set(key, value)
+		}
+	}
+}
+
+// The client to send the request with. Requests with connection settings of their own get a client for those
+// settings, everything else shares the default client.
+func (r *HttpRequest) httpClient(shared *http.Client) (*http.Client, error) {
+	preset, ok := fingerprintPresets[r.Fingerprint]
+	if !ok && r.ProxyProtocol == "" && r.Tls == nil && r.ProxyUrl == "" && r.HeaderOrder == "" {
+		return shared, nil
+	}
+	options := preset.tls
+	options.ProxyProtocol = proxyproto.Version(r.ProxyProtocol)
+	options.Proxy = r.ProxyUrl
+	options.HeaderOrder = fingerprintPresets[r.HeaderOrder].headerOrder
+	r.Tls.apply(&options)
+	client, err := httpclient.GetClientWithOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if shared.Jar != nil {
+		// The run's cookies go with the request whichever client sends it
+		withJar := *client
+		withJar.Jar = shared.Jar
+		return &withJar, nil
+	}
+	return client, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bufio"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Answers every connection with 200, sending the names of the request's headers, in order, to the channel
+func headerOrderServer(t *testing.T, names chan<- []string) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			reader := bufio.NewReader(conn)
+			var received []string
+			for {
+				line, err := reader.ReadString('\n')
+				line = strings.TrimRight(line, "\r\n")
+				if err != nil || line == "" {
+					break
+				}
+				if i := strings.IndexByte(line, ':'); i > 0 {
+					received = append(received, line[:i])
+				}
+			}
+			_, _ = conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 2\r\n\r\nok"))
+			conn.Close()
+			names <- received
+		}
+	}()
+	return listener
+}
+
+func TestHttpRequest_HeaderOrder(t *testing.T) {
+	names := make(chan []string, 1)
+	listener := headerOrderServer(t, names)
+	defer listener.Close()
+	httpclient.Initialize(0)
+
+	tests := []struct {
+		TestName    string
+		HeaderOrder ClientFingerprint
+		Expected    []string
+	}{
+		{"chrome", ClientFingerprintChrome,
+			[]string{"Host", "User-Agent", "Accept", "Referer", "Accept-Language", "Cookie", "X-Request-Source"}},
+		{"firefox", ClientFingerprintFirefox,
+			[]string{"Host", "User-Agent", "Accept", "Accept-Language", "Referer", "Cookie", "X-Request-Source"}},
+		{"safari", ClientFingerprintSafari,
+			[]string{"Host", "Accept", "Cookie", "Accept-Language", "User-Agent", "Referer", "X-Request-Source"}},
+	}
+	for _, testdata := range tests {
+		r := HttpRequest{Name: testdata.TestName, Method: http.MethodGet, Url: "http://" + listener.Addr().String(),
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, Fingerprint: ClientFingerprintChrome,
+			HeaderOrder: testdata.HeaderOrder, Headers: http.Header{"X-Request-Source": {"monitoring"},
+				"Referer": {"https://example.com/"}, "Cookie": {"session=abc"}}}
+		resp, result := r.timedSendRequest(httpclient.GetClient())
+		if result.Err != nil {
+			t.Errorf("[%s] unexpected error: %s", testdata.TestName, result.Err)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("[%s] unexpected status code %d", testdata.TestName, resp.StatusCode)
+		}
+		received := <-names
+		var ordered []string
+		for _, name := range received {
+			for _, expected := range testdata.Expected {
+				if name == expected {
+					ordered = append(ordered, name)
+				}
+			}
+		}
+		if !reflect.DeepEqual(ordered, testdata.Expected) {
+			t.Errorf("[%s] unexpected header order. Got: %v, expected: %v", testdata.TestName, received, testdata.Expected)
+		}
+	}
+}
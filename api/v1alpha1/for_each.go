@@ -0,0 +1,181 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-logr/logr"
+	jsoniter "github.com/json-iterator/go"
+	"net/http"
+	"time"
+)
+
+// How many items a for_each request sends when it does not set max_items
+const defaultForEachMaxItems = 50
+
+// Sends a request once per item of a list. Conditions apply to the whole loop, so they cannot refer to the item.
+type ForEach struct {
+	// A variable holding a JSON array, like one taken from a response with a json_path of /items
+	// +kubebuilder:validation:MinLength=1
+	Variable string `json:"variable"`
+
+	// Where in each item the value is, like /id for an array of objects. Without it, strings are used as they
+	// are and anything else as JSON.
+	JsonPath string `json:"json_path,omitempty"`
+
+	// The variable holding the current item. Defaults to item.
+	As string `json:"as,omitempty"`
+
+	// Only the first items of longer lists are sent, so a run stays within its period. Defaults to 50.
+	// +kubebuilder:validation:Minimum=1
+	MaxItems int `json:"max_items,omitempty"`
+}
+
+func (f *ForEach) as() string {
+	if f.As == "" {
+		return "item"
+	}
+	return f.As
+}
+
+func (f *ForEach) maxItems() int {
+	if f.MaxItems == 0 {
+		return defaultForEachMaxItems
+	}
+	return f.MaxItems
+}
+
+// The value of each item of the list variable
+func (f *ForEach) items(available VariableList) ([]string, error) {
+	var list *Variable
+	for _, variable := range available {
+		if variable.Name == f.Variable {
+			list = variable
+			break
+		}
+	}
+	if list == nil {
+		return nil, fmt.Errorf("for_each: no variable named %q is available", f.Variable)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(list.Value), &items); err != nil {
+		return nil, fmt.Errorf("for_each: variable %q is not a JSON array", f.Variable)
+	}
+	keys := jsonPathKeys(splitJsonPath(f.JsonPath))
+	values := make([]string, len(items))
+	for i, item := range items {
+		getter := jsoniter.Get(item, keys...)
+		if err := getter.LastError(); err != nil {
+			return nil, fmt.Errorf("for_each: item %d has no %s: %w", i, f.JsonPath, err)
+		}
+		values[i] = getter.ToString()
+	}
+	return values, nil
+}
+
+// Send the request for every item, with the item in its own variable. Items after a failed one are still sent,
+// so the result tells how many failed. It carries the details of the first item that failed, or of the last
+// item, and variables taken from responses hold the values of the last item.
+func (h *HttpMonitor) forEach(client *http.Client, httpRequest HttpRequest, entry logr.Logger) *RequestResult {
+	start := time.Now()
+	items, err := httpRequest.ForEach.items(httpRequest.AvailableVariables)
+	if err != nil {
+		return &RequestResult{Name: httpRequest.Name, Err: err}
+	}
+	if limit := httpRequest.ForEach.maxItems(); len(items) > limit {
+		entry.Info("sending only the first items of the list", "items", len(items), "max_items", limit)
+		items = items[:limit]
+	}
+	if len(items) == 0 {
+		entry.V(2).Info("list is empty, nothing to send", "variable", httpRequest.ForEach.Variable)
+		return &RequestResult{Name: httpRequest.Name, Duration: time.Since(start)}
+	}
+
+	var result, firstFailure *RequestResult
+	failed, failedItem := 0, ""
+	var attempts int
+	var bytesSent, bytesReceived int64
+	var responseTime time.Duration
+	var violations []Violation
+	for i, item := range items {
+		itemRequest := httpRequest
+		// The item goes first, since the first variable with a name wins
+		itemRequest.AvailableVariables = append(VariableList{{Name: httpRequest.ForEach.as(), From: FromTypeProvided,
+			Value: item}}, httpRequest.AvailableVariables...)
+		itemRequest.newIdempotencyKey()
+		result = h.sendWithRetries(client, itemRequest, entry.WithValues("item", i))
+		HandleViolationMetrics(h, itemRequest, result)
+
+		attempts += result.Attempts
+		bytesSent += result.BytesSent
+		bytesReceived += result.BytesReceived
+		responseTime += result.ResponseTime
+		violations = append(violations, result.Violations...)
+		if result.Err != nil {
+			failed++
+			if firstFailure == nil {
+				firstFailure, failedItem = result, item
+			}
+		}
+	}
+
+	if firstFailure != nil {
+		result = firstFailure
+		result.Err = fmt.Errorf("%d of %d items failed, first %q: %w", failed, len(items), failedItem, result.Err)
+	}
+	result.Duration = time.Since(start)
+	result.ResponseTime = responseTime
+	result.Attempts = attempts
+	result.BytesSent = bytesSent
+	result.BytesReceived = bytesReceived
+	result.Violations = violations
+	return result
+}
+
+// Loops only apply to plain requests. Cleanup runs through its own retries, and scripts, soaks and the checks
+// of other protocols are not sent per item.
+func (r *HttpRequest) validateForEach(kind string) error {
+	if r.ForEach == nil {
+		return nil
+	}
+	if kind != "request" {
+		return fmt.Errorf("%s %q has for_each, which is only supported for requests", kind, r.Name)
+	}
+	switch {
+	case r.Script != nil:
+		return fmt.Errorf("%s %q has for_each, which cannot be combined with script", kind, r.Name)
+	case r.Soak != nil:
+		return fmt.Errorf("%s %q has for_each, which cannot be combined with soak", kind, r.Name)
+	case r.StartTls != nil:
+		return fmt.Errorf("%s %q has for_each, which cannot be combined with starttls", kind, r.Name)
+	case r.Modbus != nil:
+		return fmt.Errorf("%s %q has for_each, which cannot be combined with modbus", kind, r.Name)
+	case r.OpcUa != nil:
+		return fmt.Errorf("%s %q has for_each, which cannot be combined with opcua", kind, r.Name)
+	}
+	return nil
+}
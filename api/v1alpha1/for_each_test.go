@@ -0,0 +1,133 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestForEach_items(t *testing.T) {
+	tests := []struct {
+		TestName string
+		ForEach  ForEach
+		Value    string
+		Expected []string
+		Error    string
+	}{
+		{"strings", ForEach{Variable: "ids"}, `["a", "b"]`, []string{"a", "b"}, ""},
+		{"numbers", ForEach{Variable: "ids"}, `[1, 2.5]`, []string{"1", "2.5"}, ""},
+		{"objects", ForEach{Variable: "ids"}, `[{"id": 1}]`, []string{`{"id": 1}`}, ""},
+		{"json path", ForEach{Variable: "ids", JsonPath: "/owner/name"}, `[{"owner": {"name": "ann"}}]`,
+			[]string{"ann"}, ""},
+		{"empty", ForEach{Variable: "ids"}, `[]`, []string{}, ""},
+		{"not an array", ForEach{Variable: "ids"}, `{"id": 1}`, nil, `for_each: variable "ids" is not a JSON array`},
+		{"missing json path", ForEach{Variable: "ids", JsonPath: "/id"}, `[{"id": 1}, {}]`, nil, "for_each: item 1 has no /id"},
+		{"unknown variable", ForEach{Variable: "other"}, `[]`, nil, `for_each: no variable named "other" is available`},
+	}
+
+	for _, testdata := range tests {
+		available := VariableList{{Name: "ids", From: FromTypeProvided, Value: testdata.Value}}
+		items, err := testdata.ForEach.items(available)
+		out := ""
+		if err != nil {
+			out = err.Error()
+		}
+		if !strings.HasPrefix(out, testdata.Error) || (testdata.Error == "") != (out == "") {
+			t.Errorf("[%s] unexpected error %q, expected %q", testdata.TestName, out, testdata.Error)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(items, testdata.Expected) {
+			t.Errorf("[%s] unexpected items %v, expected %v", testdata.TestName, items, testdata.Expected)
+		}
+	}
+}
+
+func TestHttpMonitor_forEach(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/lists/"):
+			_, _ = w.Write([]byte(map[string]string{
+				"/lists/good":    `{"items": [{"id": "a"}, {"id": "c"}]}`,
+				"/lists/broken":  `{"items": [{"id": "a"}, {"id": "b"}, {"id": "c"}]}`,
+				"/lists/long":    `{"items": [{"id": "a"}, {"id": "c"}, {"id": "b"}]}`,
+				"/lists/invalid": `{"items": "a"}`,
+			}[r.URL.Path]))
+		case strings.HasPrefix(r.URL.Path, "/items/"):
+			requested = append(requested, strings.TrimPrefix(r.URL.Path, "/items/"))
+			if r.URL.Path == "/items/b" {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}
+	}))
+	defer server.Close()
+	httpclient.Initialize(0)
+
+	tests := []struct {
+		TestName          string
+		List              string
+		MaxItems          int
+		ExpectedRequested []string
+		ExpectedError     string
+	}{
+		{"every item resolves", "good", 0, []string{"a", "c"}, ""},
+		{"failures do not stop the loop", "broken", 0, []string{"a", "b", "c"}, `1 of 3 items failed, first "b": `},
+		{"max items", "long", 2, []string{"a", "c"}, ""},
+		{"not a list", "invalid", 0, nil, `for_each: variable "items" is not a JSON array`},
+	}
+
+	for _, testdata := range tests {
+		requested = nil
+		monitor := &HttpMonitor{Spec: HttpMonitorSpec{Requests: []HttpRequest{
+			{Name: "list", Method: http.MethodGet, Url: server.URL + "/lists/" + testdata.List,
+				ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)},
+				VariablesFromResponse: VariableList{{Name: "items", From: FromTypeBodyJson, JsonPath: "/items"}}},
+			{Name: "item", Method: http.MethodGet, Url: server.URL + "/items/{id}",
+				ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)},
+				ForEach:               &ForEach{Variable: "items", JsonPath: "/id", As: "id", MaxItems: testdata.MaxItems}},
+		}}}
+		result := monitor.Execute()
+		if !reflect.DeepEqual(requested, testdata.ExpectedRequested) {
+			t.Errorf("[%s] unexpected items requested %v, expected %v", testdata.TestName, requested, testdata.ExpectedRequested)
+		}
+		out := ""
+		if failure := result.FirstFailure(); failure != nil {
+			out = failure.Err.Error()
+		}
+		if !strings.HasPrefix(out, testdata.ExpectedError) || (testdata.ExpectedError == "") != (out == "") {
+			t.Errorf("[%s] unexpected error %q, expected %q", testdata.TestName, out, testdata.ExpectedError)
+		}
+		if last := result.Requests[len(result.Requests)-1]; last.Attempts != len(testdata.ExpectedRequested) {
+			t.Errorf("[%s] unexpected attempts %d, expected %d", testdata.TestName, last.Attempts, len(testdata.ExpectedRequested))
+		}
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+type FormEncoding string
+
+var (
+	FormUrlEncoded FormEncoding = "urlencoded"
+	FormMultipart  FormEncoding = "multipart"
+)
+
+// Escapes names in a Content-Disposition header, like mime/multipart does for its own form fields
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// A body of form fields, sent instead of body with a Content-Type to match
+type FormBody struct {
+	// urlencoded for application/x-www-form-urlencoded, or multipart for multipart/form-data. Defaults to
+	// urlencoded.
+	// +kubebuilder:validation:Enum=urlencoded;multipart
+	Encoding FormEncoding `json:"encoding,omitempty"`
+
+	// Sent in order. Names may repeat.
+	// +kubebuilder:validation:MinItems=1
+	Fields []FormField `json:"fields"`
+}
+
+type FormField struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Variables are substituted in the value
+	Value string `json:"value,omitempty"`
+
+	// Send the content of a ConfigMap key instead of value, as it is. Binary files go in binaryData.
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"config_map_ref,omitempty"`
+
+	// The content of the ConfigMap key, loaded by the controller
+	Loaded []byte `json:"-"`
+
+	// Send the field as a file with this name. Only multipart forms have files.
+	FileName string `json:"file_name,omitempty"`
+
+	// The Content-Type of a file. Defaults to application/octet-stream.
+	ContentType string `json:"content_type,omitempty"`
+}
+
+func (f *FormBody) encoding() FormEncoding {
+	if f.Encoding == "" {
+		return FormUrlEncoded
+	}
+	return f.Encoding
+}
+
+func (f *FormBody) validate() error {
+	for _, field := range f.Fields {
+		if field.Value != "" && field.ConfigMapRef != nil {
+			return fmt.Errorf("invalid form: field %q sets both value and config_map_ref", field.Name)
+		}
+		if field.FileName != "" && f.encoding() != FormMultipart {
+			return fmt.Errorf("invalid form: field %q is a file, which only multipart forms can send", field.Name)
+		}
+	}
+	return nil
+}
+
+func (f *FormField) content(replacer *strings.Replacer) ([]byte, error) {
+	if f.ConfigMapRef == nil {
+		return []byte(replacer.Replace(f.Value)), nil
+	}
+	if f.Loaded == nil {
+		return nil, fmt.Errorf("form field %q: configmap %s, key %s, was not loaded", f.Name, f.ConfigMapRef.Name,
+			f.ConfigMapRef.Key)
+	}
+	return f.Loaded, nil
+}
+
+// Encode the fields with variables substituted, returning the body and its Content-Type
+func (f *FormBody) encode(replacer *strings.Replacer) ([]byte, string, error) {
+	switch f.encoding() {
+	case FormUrlEncoded:
+		// Not url.Values, which would sort the fields
+		pairs := make([]string, len(f.Fields))
+		for i, field := range f.Fields {
+			value, err := field.content(replacer)
+			if err != nil {
+				return nil, "", err
+			}
+			pairs[i] = url.QueryEscape(field.Name) + "=" + url.QueryEscape(string(value))
+		}
+		return []byte(strings.Join(pairs, "&")), "application/x-www-form-urlencoded", nil
+	case FormMultipart:
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for _, field := range f.Fields {
+			value, err := field.content(replacer)
+			if err != nil {
+				return nil, "", err
+			}
+			header := make(textproto.MIMEHeader)
+			disposition := fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(field.Name))
+			if field.FileName != "" {
+				disposition += fmt.Sprintf(`; filename="%s"`, quoteEscaper.Replace(replacer.Replace(field.FileName)))
+				contentType := field.ContentType
+				if contentType == "" {
+					contentType = "application/octet-stream"
+				}
+				header.Set("Content-Type", contentType)
+			}
+			header.Set("Content-Disposition", disposition)
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, err := part.Write(value); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), writer.FormDataContentType(), nil
+	}
+	return nil, "", errors.New("unknown form encoding " + string(f.Encoding))
+}
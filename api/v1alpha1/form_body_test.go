@@ -0,0 +1,97 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHttpRequest_BuildRequest_form(t *testing.T) {
+	variables := VariableList{{Name: "user", From: FromTypeProvided, Value: "ann&bob"}}
+	logo := corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "files"}, Key: "logo.png"}
+
+	request := &HttpRequest{Method: http.MethodPost, Url: "https://example.com/upload", AvailableVariables: variables,
+		Form: &FormBody{Fields: []FormField{{Name: "z", Value: "{user}"}, {Name: "a", Value: "1 2"}, {Name: "a", Value: "3"}}}}
+	req, err := request.BuildRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(req.Body)
+	if string(body) != "z=ann%26bob&a=1+2&a=3" {
+		t.Errorf("unexpected urlencoded body %q", body)
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "application/x-www-form-urlencoded" {
+		t.Errorf("unexpected Content-Type %q", contentType)
+	}
+
+	request.Form = &FormBody{Encoding: FormMultipart, Fields: []FormField{
+		{Name: "owner", Value: "{user}"},
+		{Name: "logo", FileName: "{user}.png", ContentType: "image/png", ConfigMapRef: &logo, Loaded: []byte{0x89, 'P', 'N', 'G'}},
+		{Name: "notes", FileName: "notes.txt", Value: "hello"},
+	}}
+	req, err = request.BuildRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("unexpected Content-Type %q", req.Header.Get("Content-Type"))
+	}
+	reader := multipart.NewReader(req.Body, params["boundary"])
+	tests := []struct {
+		TestName    string
+		FileName    string
+		ContentType string
+		Content     string
+	}{
+		{"owner", "", "", "ann&bob"},
+		{"logo", "ann&bob.png", "image/png", "\x89PNG"},
+		{"notes", "notes.txt", "application/octet-stream", "hello"},
+	}
+	for _, testdata := range tests {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("[%s] unexpected error %s", testdata.TestName, err)
+		}
+		content, _ := ioutil.ReadAll(part)
+		if part.FormName() != testdata.TestName || part.FileName() != testdata.FileName ||
+			part.Header.Get("Content-Type") != testdata.ContentType || string(content) != testdata.Content {
+			t.Errorf("[%s] unexpected part %s, file %q, type %q, content %q", testdata.TestName, part.FormName(),
+				part.FileName(), part.Header.Get("Content-Type"), content)
+		}
+	}
+
+	request.Form.Fields[1].Loaded = nil
+	if _, err := request.BuildRequest(); err == nil || !strings.Contains(err.Error(), "was not loaded") {
+		t.Errorf("unexpected error for a file that was not loaded: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// How many values of each captured header status keeps
+const headerHistoryLength = 10
+
+// A value a captured response header had, over consecutive runs
+type HeaderObservation struct {
+	// Name of the request
+	Request string `json:"request"`
+
+	Header string `json:"header"`
+
+	// Empty when the response did not have the header
+	Value string `json:"value,omitempty"`
+
+	FirstSeen metav1.Time `json:"first_seen"`
+	LastSeen  metav1.Time `json:"last_seen"`
+
+	// How many runs saw the value
+	Runs int `json:"runs"`
+}
+
+func (r *HttpRequest) captureHeaders(resp *http.Response) map[string]string {
+	if len(r.CaptureHeaders) == 0 {
+		return nil
+	}
+	captured := make(map[string]string, len(r.CaptureHeaders))
+	for _, name := range r.CaptureHeaders {
+		name = http.CanonicalHeaderKey(name)
+		captured[name] = strings.Join(resp.Header.Values(name), ", ")
+	}
+	return captured
+}
+
+// Add the headers captured in a run to the history. A value that is the same as the last one seen extends that
+// observation, and a different one starts a new observation. Only the most recent values of each header are kept.
+func RecordHeaders(history []HeaderObservation, result *RunResult) []HeaderObservation {
+	seen := metav1.NewTime(result.Start)
+	for _, r := range result.Requests {
+		// Sorted, so new observations are added in the same order on every run
+		names := make([]string, 0, len(r.CapturedHeaders))
+		for name := range r.CapturedHeaders {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			history = recordHeader(history, r.Name, name, r.CapturedHeaders[name], seen)
+		}
+	}
+	return history
+}
+
+func recordHeader(history []HeaderObservation, request, header, value string, seen metav1.Time) []HeaderObservation {
+	kept := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Request != request || history[i].Header != header {
+			continue
+		}
+		if kept == 0 && history[i].Value == value {
+			history[i].LastSeen = seen
+			history[i].Runs++
+			return history
+		}
+		kept++
+		if kept == headerHistoryLength {
+			// Making room for the new value
+			history = append(history[:i], history[i+1:]...)
+			break
+		}
+	}
+	return append(history, HeaderObservation{
+		Request:   request,
+		Header:    header,
+		Value:     value,
+		FirstSeen: seen,
+		LastSeen:  seen,
+		Runs:      1,
+	})
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHttpRequest_captureHeaders(t *testing.T) {
+	request := &HttpRequest{CaptureHeaders: []string{"x-app-version", "Via", "X-Missing"}}
+	resp := &http.Response{Header: http.Header{
+		"X-App-Version": {"1.4.2"},
+		"Via":           {"1.1 varnish", "1.1 cloudfront"},
+		"Server":        {"nginx"},
+	}}
+	expected := map[string]string{"X-App-Version": "1.4.2", "Via": "1.1 varnish, 1.1 cloudfront", "X-Missing": ""}
+	if captured := request.captureHeaders(resp); !reflect.DeepEqual(captured, expected) {
+		t.Errorf("unexpected captured headers %v", captured)
+	}
+	if captured := (&HttpRequest{}).captureHeaders(resp); captured != nil {
+		t.Errorf("unexpected captured headers without capture_headers %v", captured)
+	}
+}
+
+func TestRecordHeaders(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	run := func(minute int, version string) *RunResult {
+		return &RunResult{Start: start.Add(time.Duration(minute) * time.Minute), Requests: []*RequestResult{
+			{Name: "home", CapturedHeaders: map[string]string{"X-App-Version": version}},
+			{Name: "timed out"},
+		}}
+	}
+	summarize := func(history []HeaderObservation) []string {
+		var out []string
+		for _, o := range history {
+			out = append(out, fmt.Sprintf("%s %s=%s %s-%s x%d", o.Request, o.Header, o.Value,
+				o.FirstSeen.Format("15:04"), o.LastSeen.Format("15:04"), o.Runs))
+		}
+		return out
+	}
+
+	var history []HeaderObservation
+	for minute, version := range []string{"1.0", "1.0", "1.1", "", "1.1"} {
+		history = RecordHeaders(history, run(minute, version))
+	}
+	expected := []string{
+		"home X-App-Version=1.0 12:00-12:01 x2",
+		"home X-App-Version=1.1 12:02-12:02 x1",
+		"home X-App-Version= 12:03-12:03 x1",
+		"home X-App-Version=1.1 12:04-12:04 x1",
+	}
+	if out := summarize(history); !reflect.DeepEqual(out, expected) {
+		t.Errorf("unexpected history %q", out)
+	}
+
+	// Only the most recent values are kept
+	for minute := 5; minute < 20; minute++ {
+		history = RecordHeaders(history, run(minute, fmt.Sprintf("2.%d", minute)))
+	}
+	if len(history) != headerHistoryLength || history[0].Value != "2.10" || history[len(history)-1].Value != "2.19" {
+		t.Errorf("unexpected history after many changes %q", summarize(history))
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHmacHeader       = "X-Signature"
+	defaultHmacStringToSign = "{body}"
+)
+
+var hmacAlgorithms = map[string]func() hash.Hash{"sha1": sha1.New, "sha256": sha256.New, "sha512": sha512.New}
+
+// Sign the request with an HMAC and send the signature in a header, like webhook-style APIs expect
+type HmacSignature struct {
+	// Default is sha256
+	// +kubebuilder:validation:Enum=sha1;sha256;sha512
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// The Secret key holding the signing key
+	SecretRef corev1.SecretKeySelector `json:"secret_ref"`
+
+	// The header the signature is sent in. Default is X-Signature
+	Header string `json:"header,omitempty"`
+
+	// Put in front of the signature, like sha256=
+	Prefix string `json:"prefix,omitempty"`
+
+	// How the signature is written. Default is hex
+	// +kubebuilder:validation:Enum=hex;base64
+	Encoding string `json:"encoding,omitempty"`
+
+	// What is signed, with {method}, {path} including the query, {body} and {timestamp} replaced. Default is
+	// {body}
+	StringToSign string `json:"string_to_sign,omitempty"`
+
+	// Send the time of signing in this header, in seconds since the Unix epoch, for APIs that sign
+	// {timestamp} to refuse replays
+	TimestampHeader string `json:"timestamp_header,omitempty"`
+
+	// The signing key, loaded by the controller
+	Key    []byte `json:"-"`
+	Loaded bool   `json:"-"`
+}
+
+func (s *HmacSignature) validate() error {
+	if s.SecretRef.Name == "" || s.SecretRef.Key == "" {
+		return fmt.Errorf("invalid hmac_signature: it needs the name and key of a secret in secret_ref")
+	}
+	if strings.Contains(s.StringToSign, "{timestamp}") && s.TimestampHeader == "" {
+		return fmt.Errorf("invalid hmac_signature: string_to_sign has {timestamp}, but no timestamp_header sends it")
+	}
+	return nil
+}
+
+// Add the signature header, and the timestamp header if there is one. payload is the body as it is sent.
+func (s *HmacSignature) sign(req *http.Request, payload []byte, now time.Time) error {
+	if s == nil {
+		return nil
+	}
+	if !s.Loaded {
+		return fmt.Errorf("the hmac_signature key in secret %s, key %s, was not loaded", s.SecretRef.Name,
+			s.SecretRef.Key)
+	}
+	algorithm := s.Algorithm
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+	newHash, ok := hmacAlgorithms[algorithm]
+	if !ok {
+		return fmt.Errorf("unsupported hmac_signature algorithm %q", algorithm)
+	}
+	stringToSign := s.StringToSign
+	if stringToSign == "" {
+		stringToSign = defaultHmacStringToSign
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signed := strings.NewReplacer(
+		"{method}", req.Method,
+		"{path}", req.URL.RequestURI(),
+		"{body}", string(payload),
+		"{timestamp}", timestamp,
+	).Replace(stringToSign)
+
+	mac := hmac.New(newHash, s.Key)
+	mac.Write([]byte(signed))
+	sum := mac.Sum(nil)
+	signature := hex.EncodeToString(sum)
+	if s.Encoding == "base64" {
+		signature = base64.StdEncoding.EncodeToString(sum)
+	}
+
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	if s.TimestampHeader != "" {
+		req.Header.Set(s.TimestampHeader, timestamp)
+	}
+	header := s.Header
+	if header == "" {
+		header = defaultHmacHeader
+	}
+	req.Header.Set(header, s.Prefix+signature)
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHmacSignature_sign(t *testing.T) {
+	// The example from Slack's documentation on verifying requests
+	slackBody := "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&" +
+		"channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&" +
+		"response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&" +
+		"trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+	key := []byte("8f742231b10e8888abcd99yyyzzz85a5")
+	tests := []struct {
+		TestName  string
+		Signature HmacSignature
+		Body      string
+		Header    string
+		Expected  string
+		Err       string
+	}{
+		{"slack", HmacSignature{Header: "X-Slack-Signature", Prefix: "v0=", StringToSign: "v0:{timestamp}:{body}",
+			TimestampHeader: "X-Slack-Request-Timestamp", Key: key, Loaded: true}, slackBody, "X-Slack-Signature",
+			"v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503", ""},
+		{"defaults", HmacSignature{Key: []byte("key"), Loaded: true}, "The quick brown fox jumps over the lazy dog",
+			"X-Signature", "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", ""},
+		{"base64 sha1", HmacSignature{Algorithm: "sha1", Encoding: "base64", Key: []byte("key"), Loaded: true},
+			"The quick brown fox jumps over the lazy dog", "X-Signature", "3nybhbi3iqa8ino29wqQcBydtNk=", ""},
+		{"not loaded", HmacSignature{SecretRef: corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "webhook"}, Key: "key"}}, "", "", "",
+			"the hmac_signature key in secret webhook, key key, was not loaded"},
+	}
+	for _, testdata := range tests {
+		req, _ := http.NewRequest(http.MethodPost, "https://example.com/slack/commands", nil)
+		err := testdata.Signature.sign(req, []byte(testdata.Body), time.Unix(1531420618, 0))
+		if testdata.Err != "" {
+			if err == nil || !strings.Contains(err.Error(), testdata.Err) {
+				t.Errorf("[%s] unexpected error %v", testdata.TestName, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[%s] unexpected error %s", testdata.TestName, err)
+		}
+		if got := req.Header.Get(testdata.Header); got != testdata.Expected {
+			t.Errorf("[%s] unexpected signature %q, expected %q", testdata.TestName, got, testdata.Expected)
+		}
+		if header := testdata.Signature.TimestampHeader; header != "" && req.Header.Get(header) != "1531420618" {
+			t.Errorf("[%s] unexpected timestamp %q", testdata.TestName, req.Header.Get(header))
+		}
+	}
+}
+
+func TestHttpRequest_BuildRequest_hmacSignature(t *testing.T) {
+	request := HttpRequest{Method: http.MethodPost, Url: "https://example.com/hooks", Body: `{"id": "{id}"}`,
+		AvailableVariables: VariableList{{Name: "id", Value: "42"}},
+		HmacSignature:      &HmacSignature{StringToSign: "{method} {path}\n{body}", Key: []byte("key"), Loaded: true}}
+	req, err := request.BuildRequest()
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	// The rendered body is signed, with variables replaced
+	expected := &http.Request{Method: http.MethodPost, URL: req.URL, Header: make(http.Header)}
+	if err := request.HmacSignature.sign(expected, []byte(`{"id": "42"}`), time.Now()); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if got := req.Header.Get("X-Signature"); got != expected.Header.Get("X-Signature") {
+		t.Errorf("unexpected signature %q, expected %q", got, expected.Header.Get("X-Signature"))
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"github.com/oregondesignservices/monitoring-controller/internal/proxyproto"
+	"golang.org/x/net/http2"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// An error specific to HTTP/2, which plain request checks cannot tell apart from other failures
+type http2Error struct {
+	category FailureCategory
+	err      error
+}
+
+func (e *http2Error) Error() string {
+	return e.err.Error()
+}
+
+func (e *http2Error) Unwrap() error {
+	return e.err
+}
+
+// Sort HTTP/2 errors into the failure categories
+func classifyHttp2Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	var goAway http2.GoAwayError
+	var streamErr http2.StreamError
+	var connErr http2.ConnectionError
+	switch {
+	case errors.As(err, &goAway), strings.Contains(err.Error(), "GOAWAY"):
+		return &http2Error{FailureCategoryHttp2GoAway, err}
+	case errors.As(err, &streamErr):
+		return &http2Error{FailureCategoryHttp2StreamError, err}
+	case errors.As(err, &connErr):
+		return &http2Error{FailureCategoryHttp2ConnectionError, err}
+	}
+	return err
+}
+
+// Open a dedicated HTTP/2 connection to the target. HTTPS targets have to negotiate h2 with ALPN, plain HTTP
+// targets are expected to speak h2c with prior knowledge.
+func dialHttp2(ctx context.Context, req *http.Request, proxyProtocol proxyproto.Version, config *tls.Config) (net.Conn, error) {
+	host := req.URL.Host
+	if req.URL.Port() == "" {
+		if req.URL.Scheme == "https" {
+			host = net.JoinHostPort(req.URL.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(req.URL.Hostname(), "80")
+		}
+	}
+	dialer := &net.Dialer{}
+	dial := dialer.DialContext
+	if proxyProtocol != "" {
+		dial = proxyproto.DialContext(proxyProtocol, dial)
+	}
+	conn, err := dial(ctx, "tcp", host)
+	if err != nil || req.URL.Scheme != "https" {
+		return conn, err
+	}
+
+	if config.ServerName == "" {
+		config.ServerName = req.URL.Hostname()
+	}
+	config.NextProtos = []string{http2.NextProtoTLS}
+	tlsConn := tls.Client(conn, config)
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = tlsConn.SetDeadline(deadline)
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = tlsConn.SetDeadline(time.Time{})
+	if protocol := tlsConn.ConnectionState().NegotiatedProtocol; protocol != http2.NextProtoTLS {
+		tlsConn.Close()
+		return nil, &http2Error{FailureCategoryHttp2NotNegotiated, fmt.Errorf("server negotiated %q instead of h2", protocol)}
+	}
+	return tlsConn, nil
+}
+
+// Ping the connection, recording the round trip time
+func (r *HttpRequest) http2Ping(ctx context.Context, cc *http2.ClientConn, result *RequestResult) error {
+	for i := 0; i < r.Http2.Pings; i++ {
+		start := time.Now()
+		if err := cc.Ping(ctx); err != nil {
+			if classified := classifyHttp2Error(err); classified != err {
+				return classified
+			}
+			return &http2Error{FailureCategoryHttp2PingFailed, err}
+		}
+		result.Http2PingRTTs = append(result.Http2PingRTTs, time.Since(start))
+	}
+	return nil
+}
+
+// Send the request over its own HTTP/2 connection, pinging the connection before the request and after the
+// response. Redirects are not followed.
+func (r *HttpRequest) sendHttp2Request(ctx context.Context, req *http.Request, result *RequestResult) (*http.Response, error) {
+	var options httpclient.Options
+	r.Tls.apply(&options)
+	tlsConfig, err := options.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dialHttp2(ctx, req, proxyproto.Version(r.ProxyProtocol), tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	transport := &http2.Transport{AllowHTTP: true}
+	cc, err := transport.NewClientConn(conn)
+	if err != nil {
+		conn.Close()
+		return nil, classifyHttp2Error(err)
+	}
+	defer cc.Close()
+
+	if err := r.http2Ping(ctx, cc, result); err != nil {
+		return nil, err
+	}
+	resp, err := cc.RoundTrip(req)
+	if err != nil {
+		return nil, classifyHttp2Error(err)
+	}
+	result.TLS = resp.TLS
+
+	// Read the body while the connection is open, so stream errors show up here and variables can be parsed later
+	readBodyAndReset(resp)
+	if err := r.http2Ping(ctx, cc, result); err != nil {
+		return resp, err
+	}
+	if !cc.CanTakeNewRequest() {
+		return resp, &http2Error{FailureCategoryHttp2GoAway, errors.New("http2: server sent GOAWAY during the request")}
+	}
+	return resp, nil
+}
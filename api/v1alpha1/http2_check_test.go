@@ -0,0 +1,53 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/net/http2"
+	"testing"
+)
+
+func TestClassifyHttp2Error(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Err      error
+		Expected FailureCategory
+	}{
+		{"goaway", fmt.Errorf("Get: %w", http2.GoAwayError{ErrCode: http2.ErrCodeNo}), FailureCategoryHttp2GoAway},
+		{"graceful-goaway", errors.New("http2: Transport received Server's graceful shutdown GOAWAY"), FailureCategoryHttp2GoAway},
+		{"stream-error", http2.StreamError{StreamID: 1, Code: http2.ErrCodeProtocol}, FailureCategoryHttp2StreamError},
+		{"connection-error", http2.ConnectionError(http2.ErrCodeProtocol), FailureCategoryHttp2ConnectionError},
+		{"other", errors.New("connection refused"), ""},
+	}
+
+	for _, testdata := range tests {
+		if out := failureCategory(classifyHttp2Error(testdata.Err)); out != testdata.Expected {
+			t.Errorf("[%s] unexpected category. Got: %q, expected: %q", testdata.TestName, out, testdata.Expected)
+		}
+	}
+}
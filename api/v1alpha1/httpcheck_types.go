@@ -0,0 +1,173 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ForgeProvider string
+
+var (
+	ForgeProviderGithub ForgeProvider = "github"
+	ForgeProviderGitlab ForgeProvider = "gitlab"
+)
+
+// Where to report the result of a check, so it can gate merges and promotions
+type CommitStatusReport struct {
+	// +kubebuilder:validation:Enum=github;gitlab
+	Provider ForgeProvider `json:"provider"`
+
+	// The API URL. Defaults to https://api.github.com or https://gitlab.com/api/v4
+	ApiUrl string `json:"api_url,omitempty"`
+
+	// The repository, as owner/name for GitHub or the project path or ID for GitLab
+	Repository string `json:"repository"`
+
+	// The commit SHA to report on
+	Sha string `json:"sha"`
+
+	// The name of the status, shown next to the commit. Default is monitoring-controller/<check name>
+	Context string `json:"context,omitempty"`
+
+	// A link shown with the status, like a dashboard
+	TargetUrl string `json:"target_url,omitempty"`
+
+	// Report a GitHub deployment status for this deployment, instead of a commit status
+	DeploymentId int64 `json:"deployment_id,omitempty"`
+
+	// An API token allowed to set commit statuses
+	TokenSecretRef corev1.SecretKeySelector `json:"token_secret_ref"`
+}
+
+// HttpCheckSpec defines the desired state of HttpCheck
+type HttpCheckSpec struct {
+	// Variables available to all requests from the start
+	Environment map[string]string `json:"environment,omitempty"`
+
+	// +kubebuilder:validation:MinItems=1
+	Requests []HttpRequest `json:"requests"`
+
+	// Optional requests to be run after `requests`.
+	Cleanup []HttpRequest `json:"cleanup,omitempty"`
+
+	// Credentials to send with every request that has neither auth nor an Authorization header of its own
+	Auth *RequestAuth `json:"auth,omitempty"`
+
+	// A client certificate to present with every request that has none of its own, for targets that require
+	// mutual TLS
+	ClientCertificate *ClientCertificate `json:"client_certificate,omitempty"`
+
+	// Report the result as a commit status or deployment status
+	CommitStatus *CommitStatusReport `json:"commit_status,omitempty"`
+
+	// Mark every request, including cleanup, as synthetic traffic
+	SyntheticMarker *SyntheticMarker `json:"synthetic_marker,omitempty"`
+
+	// Keep the cookies responses set for the rest of the check, and send them with later requests
+	CookieJar bool `json:"cookie_jar,omitempty"`
+
+	// Generate variables like random-8 from this seed, taken from the random_seed in the status of a failed run,
+	// to send the same values again
+	// +kubebuilder:validation:Minimum=0
+	RandomSeed *int64 `json:"random_seed,omitempty"`
+
+	// Where data captured from responses may be stored. Nowhere replaces error messages, which can quote the
+	// response, with the kind of failure, in status and in the commit status. Default is InCluster
+	// +kubebuilder:validation:Enum=InCluster;Nowhere
+	DataResidency DataResidency `json:"data_residency,omitempty"`
+
+	// Patterns to redact from captured headers and error messages before they are stored in status or reported
+	// as the commit status
+	Scrubbing *ScrubbingRules `json:"scrubbing,omitempty"`
+}
+
+// HttpCheckStatus defines the observed state of HttpCheck
+type HttpCheckStatus struct {
+	StartedAt   *metav1.Time `json:"started_at,omitempty"`
+	CompletedAt *metav1.Time `json:"completed_at,omitempty"`
+
+	// Whether every request succeeded
+	Succeeded bool `json:"succeeded"`
+
+	// The request that failed, and why
+	FailedRequest string `json:"failed_request,omitempty"`
+	Error         string `json:"error,omitempty"`
+
+	// Set if the result could not be reported as a commit status
+	CommitStatusError string `json:"commit_status_error,omitempty"`
+
+	// The seed the generated variables were made from
+	RandomSeed int64 `json:"random_seed,omitempty"`
+}
+
+// HttpCheck runs its requests once, for example to verify a deployment
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Succeeded",type=boolean,JSONPath=`.status.succeeded`
+// +kubebuilder:printcolumn:name="Completed",type=string,format=date-time,JSONPath=`.status.completed_at`
+// +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.error`,priority=1
+type HttpCheck struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HttpCheckSpec   `json:"spec,omitempty"`
+	Status HttpCheckStatus `json:"status,omitempty"`
+}
+
+// HttpCheckList contains a list of HttpCheck
+// +kubebuilder:object:root=true
+type HttpCheckList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HttpCheck `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HttpCheck{}, &HttpCheckList{})
+}
+
+// Checks run their requests exactly like a single run of a monitor
+func (c *HttpCheck) AsHttpMonitor() *HttpMonitor {
+	return &HttpMonitor{
+		ObjectMeta: *c.ObjectMeta.DeepCopy(),
+		Spec: HttpMonitorSpec{
+			Environment: c.Spec.Environment,
+			Requests:    c.Spec.Requests,
+			Cleanup:     c.Spec.Cleanup,
+
+			Auth:              c.Spec.Auth,
+			ClientCertificate: c.Spec.ClientCertificate,
+			SyntheticMarker:   c.Spec.SyntheticMarker,
+			RandomSeed:        c.Spec.RandomSeed,
+			CookieJar:         c.Spec.CookieJar,
+			DataResidency:     c.Spec.DataResidency,
+			Scrubbing:         c.Spec.Scrubbing,
+		},
+	}
+}
@@ -29,6 +29,7 @@ The R&D leading to these results received funding from the:
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"net/http"
 	"net/url"
@@ -45,6 +46,9 @@ var (
 	FromTypeBodyRaw  FromType = "body_raw" //
 	FromTypeHeaders  FromType = "headers"  // extract the variable from Headers
 	FromTypeProvided FromType = "provided" // provided by the user
+
+	// A claim of the token found by the request's jwt check
+	FromTypeJwtClaim FromType = "jwt_claim"
 )
 
 type Variable struct {
@@ -52,7 +56,7 @@ type Variable struct {
 	Name string `json:"name"`
 
 	// Where to extract the variable from
-	// +kubebuilder:validation:Enum=body_yaml;body_json;body_raw;headers;provided
+	// +kubebuilder:validation:Enum=body_yaml;body_json;body_raw;headers;provided;jwt_claim
 	From FromType `json:"from"`
 
 	// The JSON path to the data.
@@ -64,21 +68,230 @@ type Variable struct {
 
 type VariableList []*Variable
 
+// Checks a JWT the response carries, like an access token from a login
+type JwtCheck struct {
+	// Where the token is in the response, like a variable. A "Bearer " prefix is ignored.
+	// +kubebuilder:validation:Enum=body_yaml;body_json;body_raw;headers
+	From FromType `json:"from"`
+
+	// The JSON path to the token
+	JsonPath string `json:"json_path,omitempty"`
+
+	// Verify the signature with the keys published at this URL. RSA and ECDSA keys are supported. Without it,
+	// the token is decoded but its signature is not checked.
+	JwksUrl string `json:"jwks_url,omitempty"`
+
+	// The iss claim the token must have
+	Issuer string `json:"issuer,omitempty"`
+
+	// A value the aud claim of the token must have
+	Audience string `json:"audience,omitempty"`
+
+	// Fail when the token expires sooner than this. Expired tokens, and tokens that are not valid yet, always fail.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	MinRemaining *metav1.Duration `json:"min_remaining,omitempty"`
+}
+
+// Marks requests as synthetic traffic, so targets and analytics can filter them out
+type SyntheticMarker struct {
+	// Headers set on every request, replacing headers of the same name. Default is X-Synthetic: monitoring-controller
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Query parameters added to every request, replacing parameters of the same name. For analytics that
+	// only see URLs.
+	QueryParams map[string]string `json:"query_params,omitempty"`
+
+	// Header set to the namespace and name of the monitor, so targets can tell monitors apart. Default is
+	// X-Synthetic-Monitor
+	MonitorHeader string `json:"monitor_header,omitempty"`
+
+	// Who to contact about the traffic, like an email address. Sent as the From header, as crawlers do.
+	Contact string `json:"contact,omitempty"`
+
+	// What the traffic is for, for the teams that find it in their logs. Not sent.
+	Description string `json:"description,omitempty"`
+}
+
+// Checks an OpenID Connect discovery document and the keys it points to
+type OidcDiscoveryCheck struct {
+	// The issuer the document must name. Default is the request URL without /.well-known/openid-configuration
+	Issuer string `json:"issuer,omitempty"`
+
+	// Fail when the JWKS has fewer signing keys than this. Set to 2 to hear about rotations that leave no
+	// overlap with the previous key. Default is 1
+	// +kubebuilder:validation:Minimum=1
+	MinSigningKeys int `json:"min_signing_keys,omitempty"`
+
+	// Fail when the certificate of a key (x5c) expires sooner than this
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	MinCertificateRemaining *metav1.Duration `json:"min_certificate_remaining,omitempty"`
+
+	// Check that the authorization, token and userinfo endpoints the document names respond without a server error
+	CheckEndpoints bool `json:"check_endpoints,omitempty"`
+}
+
+// Checks a SAML identity provider's metadata, and that a service provider's login redirects to it
+type SamlCheck struct {
+	// Where the identity provider publishes its metadata
+	MetadataUrl string `json:"metadata_url"`
+
+	// The entity ID the metadata must describe. Default is any
+	EntityId string `json:"entity_id,omitempty"`
+
+	// Fail when a signing certificate in the metadata expires sooner than this. Expired certificates always fail.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	MinCertificateRemaining *metav1.Duration `json:"min_certificate_remaining,omitempty"`
+}
+
+// Runs a k6 or Playwright script in a Job, for journeys that need a real client rather than single requests.
+// The script gets the rendered url as TARGET_URL and every available variable as VAR_<NAME>, like VAR_RANDOM_8.
+type ScriptStep struct {
+	// +kubebuilder:validation:Enum=k6;playwright
+	Engine ScriptEngine `json:"engine"`
+
+	// The ConfigMap key holding the script, in the monitor's namespace
+	ScriptRef corev1.ConfigMapKeySelector `json:"script_ref"`
+
+	// The image to run the script in. Default is the engine's official image
+	Image string `json:"image,omitempty"`
+
+	// The command that runs the script, which is mounted under /scripts. Default is `k6 run` or
+	// `npx playwright test` with the script's path
+	Command []string `json:"command,omitempty"`
+}
+
+// Sends and receives protobuf messages, for Connect, Twirp and gRPC-gateway APIs that speak binary protobuf
+type ProtobufCodec struct {
+	// ConfigMap key holding a FileDescriptorSet, as written by `protoc --include_imports --descriptor_set_out`.
+	// The key is read from binaryData, or from data if it is not there.
+	DescriptorSetRef corev1.ConfigMapKeySelector `json:"descriptor_set_ref"`
+
+	// Fully qualified name of the request message. The body is its JSON form, and is encoded after variables
+	// are substituted.
+	RequestMessage string `json:"request_message,omitempty"`
+
+	// Fully qualified name of the response message. Responses are decoded to JSON, so variables can be
+	// extracted with json_path. Responses with a JSON Content-Type, like errors, are left alone.
+	ResponseMessage string `json:"response_message,omitempty"`
+
+	// The contents of the descriptor set, loaded by the controller
+	DescriptorSet []byte `json:"-"`
+}
+
+// Wakes a target that scales to zero, like a Knative service, before the request is measured
+type ColdStart struct {
+	// How long the wake-up request may take. Default is the timeout of the request
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	Budget *metav1.Duration `json:"budget,omitempty"`
+}
+
+// Sends a request repeatedly during a run, for lightweight load validation of critical endpoints
+type Soak struct {
+	// Requests per second
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Rate int `json:"rate"`
+
+	// How long to keep sending requests
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	Duration metav1.Duration `json:"duration"`
+
+	// Fail the run if more than this percentage of requests fail. Default is 0
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MaxErrorPercent int `json:"max_error_percent,omitempty"`
+
+	// Fail the run if the 95th percentile response time is higher than this
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	MaxP95 *metav1.Duration `json:"max_p95,omitempty"`
+}
+
+// Requirements on the Strict-Transport-Security header
+type HstsCheck struct {
+	// The lowest acceptable max-age, in seconds
+	// +kubebuilder:validation:Minimum=0
+	MinMaxAge int64 `json:"min_max_age,omitempty"`
+
+	// Require includeSubDomains
+	IncludeSubDomains bool `json:"include_sub_domains,omitempty"`
+
+	// Require the policy to be eligible for the HSTS preload list: preload, includeSubDomains, and a max-age of
+	// at least a year
+	Preload bool `json:"preload,omitempty"`
+}
+
+// Requirements on the Signed Certificate Timestamps for the served certificate. SCTs embedded in the
+// certificate and sent in the TLS handshake count. Their signatures are not verified.
+type CertificateTransparencyCheck struct {
+	// The fewest SCTs from accepted logs. Default is 2
+	// +kubebuilder:validation:Minimum=1
+	MinScts int `json:"min_scts,omitempty"`
+
+	// Only count SCTs from these logs, as base64 log IDs. By default SCTs from any log count.
+	AllowedLogIds []string `json:"allowed_log_ids,omitempty"`
+}
+
+// Compliance requirements for endpoints under regulatory requirements
+type ComplianceCheck struct {
+	Hsts                    *HstsCheck                    `json:"hsts,omitempty"`
+	CertificateTransparency *CertificateTransparencyCheck `json:"certificate_transparency,omitempty"`
+}
+
+// Checks the health of the HTTP/2 connection itself, not just the response
+type Http2Check struct {
+	// How many PINGs to send before the request and again after the response. Default is none
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	Pings int `json:"pings,omitempty"`
+}
+
 type HttpRequest struct {
 	// Name of the HTTP request. Used for debugging and metrics
+	// +kubebuilder:validation:MinLength=1
 	Name string `json:"name"`
 
 	// A target service, to be used in metrics
 	TargetService string `json:"target_service"`
 
-	// The request timeout. Default is 5 seconds
-	Timeout string `json:"timeout,omitempty"`
+	// Who to contact when this request fails, and where to look. Each field overrides the monitor's.
+	Ownership `json:",inline"`
+
+	// Names of requests that must succeed before this one is sent. When any request has dependencies, requests
+	// run in parallel as soon as theirs have succeeded, and a failure only stops the requests that depend on
+	// it. A request sees the variables of the requests it depends on, directly or not. Without dependencies,
+	// requests run in order and the first failure stops the run
+	DependsOn []string `json:"depends_on,omitempty"`
+
+	// Only send the request when this condition holds, like '{feature_enabled} == "true"'. Otherwise it is
+	// skipped, along with its cleanup. Conditions compare {name} placeholders and values, quoted or not, with ==,
+	// !=, =~, !~, <, <=, > and >=, and combine comparisons with && and ||. A value on its own holds unless it is
+	// empty, false or 0
+	OnlyIf string `json:"only_if,omitempty"`
+
+	// Skip the request when this condition holds. Same syntax as only_if
+	SkipIf string `json:"skip_if,omitempty"`
 
-	// The HTTP method
+	// Send the request once for each item of a list variable, like an array taken from an earlier response
+	ForEach *ForEach `json:"for_each,omitempty"`
+
+	// The request timeout. Default is 5 seconds, or 2 minutes for scripts. Must be shorter than the monitor's
+	// period, which the webhook checks, since the CRD schema cannot compare it with another field
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// The HTTP method. Default is GET
 	// +kubebuilder:validation:Enum=HEAD;GET;POST;PUT;PATCH;DELETE;OPTIONS
-	Method string `json:"method"`
+	Method string `json:"method,omitempty"`
 
 	// HTTP(S) URL to make the request
+	// +kubebuilder:validation:MinLength=1
 	Url string `json:"url"`
 
 	// Any potential query parameters
@@ -87,17 +300,234 @@ type HttpRequest struct {
 	// The request body
 	Body string `json:"body,omitempty"`
 
+	// Read the body from a ConfigMap or a Secret instead. Variables are substituted in it like in body.
+	BodyFrom *BodySource `json:"body_from,omitempty"`
+
+	// A binary body, like an image or an encoded protobuf message, in standard base64. It is sent as it is,
+	// without substituting variables.
+	BodyBase64 string `json:"body_base64,omitempty"`
+
+	// Form fields to send as the body instead, url encoded or as multipart/form-data with files. Content-Type
+	// is set to match.
+	Form *FormBody `json:"form,omitempty"`
+
 	// Request headers
 	Headers http.Header `json:"headers,omitempty"`
 
+	// Credentials to send with the request, read from a Secret. Overrides the monitor's auth.
+	Auth *RequestAuth `json:"auth,omitempty"`
+
+	// How far the controller's clock may be from the target's, by its Date header, for APIs that reject signed or
+	// timestamped requests from clocks that are off. A client error from a target whose clock is further off fails
+	// with the ClockSkew category instead of looking like bad credentials.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	AllowedClockSkew *metav1.Duration `json:"allowed_clock_skew,omitempty"`
+
+	// Sign the request with an HMAC, sent in a header. The signature is made before auth, so an aws_sigv4
+	// signature covers it.
+	HmacSignature *HmacSignature `json:"hmac_signature,omitempty"`
+
+	// Decode and check a JWT in the response. Its claims can be extracted with jwt_claim variables.
+	Jwt *JwtCheck `json:"jwt,omitempty"`
+
+	// Treat the response as an OpenID Connect discovery document and check it, its JWKS and its endpoints.
+	// The url should be the discovery document, like https://issuer/.well-known/openid-configuration
+	OidcDiscovery *OidcDiscoveryCheck `json:"oidc_discovery,omitempty"`
+
+	// Treat the request as an SP-initiated SAML login. The url should be the service provider's login URL. The
+	// redirects it answers with must end at a single sign-on service in the identity provider's metadata, with a
+	// SAMLRequest. Forms for the HTTP-POST binding are submitted, and the login page must respond with 200.
+	Saml *SamlCheck `json:"saml,omitempty"`
+
+	// Run a script in a Job instead of sending a request. The step fails when the script does.
+	Script *ScriptStep `json:"script,omitempty"`
+
+	// Audit the page for accessibility in a Job instead of sending a request
+	Accessibility *AccessibilityAudit `json:"accessibility,omitempty"`
+
+	// Measure how the page loads in a Job instead of sending a request, and check it against budgets
+	Performance *PerformanceAudit `json:"performance,omitempty"`
+
+	// Encode the body as a protobuf message, and decode responses from one
+	Protobuf *ProtobufCodec `json:"protobuf,omitempty"`
+
+	// Compress the body after variables are substituted, and set Content-Encoding to match
+	// +kubebuilder:validation:Enum=gzip
+	CompressRequestBody string `json:"compress_request_body,omitempty"`
+
 	// Extract variables for later requests to utilize
-	VariablesFromResponse VariableList `json:"vars_from_response,omitempty"`
+	VariablesFromResponse VariableList `json:"vars_from_response,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+
+	// Response headers to keep a history of in status, like X-App-Version or Via, to see when the deployed
+	// version or serving path changed relative to a failure
+	CaptureHeaders []string `json:"capture_headers,omitempty"`
+
+	// How many times to send the request again when it fails in a way listed in retry_on, so a single transient
+	// blip does not fail the run
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=5
+	Retries int `json:"retries,omitempty"`
+
+	// The wait before the first retry, doubled before each one after. Default is 1s
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	RetryBackoff *metav1.Duration `json:"retry_backoff,omitempty"`
+
+	// Which failures to retry: network_error, timeout and 5xx. Default is all of them. Failed assertions on other
+	// responses are never retried
+	RetryOn []RetryCondition `json:"retry_on,omitempty"`
+
+	// Whether redirects are followed, how many, and where they must lead. By default, up to 10 are followed
+	Redirects *Redirects `json:"redirects,omitempty"`
+
+	// Expected response codes, like 200, classes like "2xx" or ranges like "200-204". Codes, classes and ranges
+	// starting with "!" are never expected, like "!503". By default, this will be anything seen as "ok"
+	ExpectedResponseCodes StatusCodeMatcher `json:"expected_response_codes,omitempty"`
+
+	// Fail the request when the response, body included, takes longer than this, even if it is otherwise fine
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	MaxDuration *metav1.Duration `json:"max_duration,omitempty"`
+
+	// Fail the request when the response body is larger than this many bytes. Reading stops there, so the body
+	// is never held in memory in full
+	// +kubebuilder:validation:Minimum=1
+	MaxResponseBytes *int64 `json:"max_response_bytes,omitempty"`
+
+	// When true, fail the request unless it reused a kept-alive connection from an earlier request. When false,
+	// fail it unless it opened a new one. Checks that load balancers and the target keep connections alive as
+	// intended
+	ExpectConnectionReused *bool `json:"expect_connection_reused,omitempty"`
+
+	// Checks on the response beyond its status code
+	ResponseAssertions *ResponseAssertions `json:"response_assertions,omitempty"`
+
+	// Ask for a language and check the response is served in it
+	Language *LanguageCheck `json:"language,omitempty"`
+
+	// For cleanup requests, the name of the request whose effects this one undoes. The cleanup request only runs
+	// if that request succeeded. Cleanup requests without it always run, after the linked ones.
+	CleanupFor string `json:"cleanup_for,omitempty"`
+
+	// Record assertions that do not hold as violations in status and metrics, without failing the run. Use this to
+	// trial new assertions against production before enforcing them. Requests that cannot be sent still fail.
+	ObserveOnly bool `json:"observe_only,omitempty"`
+
+	// Also check that the plain HTTP variant of this HTTPS URL, on the default port, is refused or redirects to
+	// HTTPS, so sensitive endpoints are never served over cleartext
+	RequireHttps bool `json:"require_https,omitempty"`
+
+	// Check HSTS and certificate transparency on the response
+	Compliance *ComplianceCheck `json:"compliance,omitempty"`
+
+	// Check that the address an echo service reports for the controller is an expected one
+	EgressIp *EgressIpCheck `json:"egress_ip,omitempty"`
+
+	// Emulate a common browser: its User-Agent, Accept and Accept-Language headers, unless the request sets
+	// them, and the TLS versions, cipher suites, curves and ALPN protocols it offers. See header_order for the
+	// order it sends headers in.
+	// +kubebuilder:validation:Enum=chrome;firefox;safari
+	Fingerprint ClientFingerprint `json:"fingerprint,omitempty"`
+
+	// Send the headers in the order this browser sends them, instead of sorted by name. The request is sent over
+	// HTTP/1.1, on a connection of its own.
+	// +kubebuilder:validation:Enum=chrome;firefox;safari
+	HeaderOrder ClientFingerprint `json:"header_order,omitempty"`
+
+	// Start each connection with a PROXY protocol header of this version, for backends behind load balancers
+	// that send one, which reject connections without it. The header names the controller as the client
+	ProxyProtocol ProxyProtocolVersion `json:"proxy_protocol,omitempty"`
 
-	// Expected response codes. By default, this will be anything seen as "ok"
-	ExpectedResponseCodes []int `json:"expected_response_codes,omitempty"`
+	// Send the request through this HTTP, HTTPS or SOCKS5 proxy, like http://egress-proxy.infra:3128, instead of
+	// connecting to the target directly
+	ProxyUrl string `json:"proxy_url,omitempty"`
+
+	// Verify the target's certificate with a private CA, under another server name, or not at all
+	Tls *TlsOptions `json:"tls,omitempty"`
+
+	// Fail when a certificate the HTTPS target presents expires sooner than this, like 720h to hear about it 30
+	// days ahead. Expired certificates always fail, even when tls.insecure_skip_verify lets the request through.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	MinCertificateRemaining *metav1.Duration `json:"min_certificate_remaining,omitempty"`
+
+	// Send the request over a dedicated HTTP/2 connection, and report GOAWAY frames, stream errors and failed
+	// PINGs as distinct failures. HTTPS targets must negotiate h2, plain HTTP targets must support h2c.
+	Http2 *Http2Check `json:"http2,omitempty"`
+
+	// Read a Server-Sent Events stream: wait for events instead of the end of the body, and check the data of the
+	// last one like a body
+	Sse *SseCheck `json:"sse,omitempty"`
+
+	// Upgrade to a WebSocket, send a message and check the reply like a body. The url may be ws, wss, http or
+	// https.
+	WebSocket *WebSocketCheck `json:"websocket,omitempty"`
+
+	// Upgrade a connection to an SMTP, IMAP, LDAP or Postgres server with STARTTLS instead of sending an HTTP
+	// request
+	StartTls *StartTlsCheck `json:"starttls,omitempty"`
+
+	// Read registers from a Modbus TCP device instead of sending an HTTP request
+	Modbus *ModbusCheck `json:"modbus,omitempty"`
+
+	// Open a connection to an OPC UA endpoint instead of sending an HTTP request
+	OpcUa *OpcUaCheck `json:"opcua,omitempty"`
+
+	// Send this request at a sustained rate for a while, and check the aggregate error rate and latency
+	// instead of a single response. Variables are not extracted from soaked requests.
+	Soak *Soak `json:"soak,omitempty"`
+
+	// Decode response bodies from this charset, like ISO-8859-1 or Shift_JIS, instead of the one in the
+	// Content-Type header. Bodies are decoded to UTF-8 before variables are extracted and assertions run.
+	ResponseCharset string `json:"response_charset,omitempty"`
+
+	// Send the request once to wake the target before measuring it. The wake-up has its own time budget, and
+	// its duration is exported as a cold start instead of counting towards the response time of the request.
+	ColdStart *ColdStart `json:"cold_start,omitempty"`
+
+	// The severity of this request failing, overriding the monitor severity
+	// +kubebuilder:validation:Enum=critical;warning;info
+	Severity Severity `json:"severity,omitempty"`
+
+	// The request changes state on the target. Mutating requests get an idempotency key header, generated once
+	// per run and reused when the request is retried, so retries are safe against APIs that support them.
+	Mutating bool `json:"mutating,omitempty"`
+
+	// The header the idempotency key is sent in. Default is Idempotency-Key
+	IdempotencyKeyHeader string `json:"idempotency_key_header,omitempty"`
 
 	// VariablesFromResponse available from previous requests
 	AvailableVariables VariableList `json:"-"`
+
+	// The idempotency key for this run of a mutating request
+	IdempotencyKey string `json:"-"`
+}
+
+// Diagnostics collected when requests fail
+type Diagnostics struct {
+	// Run a traceroute to the target when a request fails to connect or times out
+	Traceroute bool `json:"traceroute,omitempty"`
+
+	// The maximum number of hops to probe. Default is 30
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=64
+	MaxHops int `json:"max_hops,omitempty"`
+
+	// After this many consecutive failed runs, save a diagnostic bundle to a ConfigMap. Disabled by default
+	// +kubebuilder:validation:Minimum=1
+	BundleAfterFailures int `json:"bundle_after_failures,omitempty"`
+}
+
+// The workload that serves a monitor's requests
+type TargetWorkload struct {
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet
+	Kind string `json:"kind"`
+
+	Name string `json:"name"`
+
+	// Default is the namespace of the monitor
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // HttpMonitorSpec defines the desired state of HttpMonitor
@@ -105,16 +535,167 @@ type HttpMonitorSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
+	// Start from a spec kept as YAML in a ConfigMap key, shared by the monitors for each cluster or region
+	TemplateRef *corev1.ConfigMapKeySelector `json:"template_ref,omitempty"`
+
+	// Strategic merge patches, as YAML, applied in order to the template, like different hostnames or credentials.
+	// Requests, cleanup requests and their variables are merged by name. The fields set in this spec apply last.
+	Patches []string `json:"patches,omitempty"`
+
 	// Variables available to all requests from the start
 	Environment map[string]string `json:"environment,omitempty"`
 
-	Requests []HttpRequest `json:"requests"`
+	// Required unless the monitor has a template
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=name
+	Requests []HttpRequest `json:"requests,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
 
 	// Optional requests to be run after `requests`.
-	Cleanup []HttpRequest `json:"cleanup,omitempty"`
+	// +listType=map
+	// +listMapKey=name
+	Cleanup []HttpRequest `json:"cleanup,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+
+	// Credentials to send with every request that has neither auth nor an Authorization header of its own
+	Auth *RequestAuth `json:"auth,omitempty"`
+
+	// A client certificate to present with every request that has none of its own, for targets that require
+	// mutual TLS
+	ClientCertificate *ClientCertificate `json:"client_certificate,omitempty"`
+
+	// How many times to retry each failed cleanup request during a run. Retries of one cleanup request do not
+	// hold up the others. The wait between attempts doubles from a second up to the request's timeout, and retries
+	// that would run past the period are left to the next runs. Default is no retries
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=5
+	CleanupRetries int `json:"cleanup_retries,omitempty"`
+
+	// How frequently to execute the monitor requests. Required unless the monitor has a template
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	Period *metav1.Duration `json:"period,omitempty"`
+
+	// Stop scheduled runs, for monitors that only run as a stage of a Journey
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Run the requests more than once on each tick, and decide the outcome from all the executions
+	Executions *Executions `json:"executions,omitempty"`
+
+	// How important a failure of this monitor is. Default is critical
+	// +kubebuilder:validation:Enum=critical;warning;info
+	Severity Severity `json:"severity,omitempty"`
+
+	// How many recent runs the latency percentiles in status are computed over. Default is 100
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	LatencyWindow int `json:"latency_window,omitempty"`
+
+	// Extra diagnostics to collect when requests fail
+	Diagnostics *Diagnostics `json:"diagnostics,omitempty"`
+
+	// Where data captured from responses, like response snippets and headers, may be stored. InCluster keeps it in
+	// status and ConfigMaps, Nowhere does not keep it at all. Default is InCluster
+	// +kubebuilder:validation:Enum=InCluster;Nowhere
+	DataResidency DataResidency `json:"data_residency,omitempty"`
+
+	// Patterns to redact from response snippets, captured headers and error messages before they are stored or
+	// sent anywhere
+	Scrubbing *ScrubbingRules `json:"scrubbing,omitempty"`
+
+	// Raise the VersionSkew condition when a variable differs from the same one in another monitor for too long
+	VersionSkew *VersionSkewRule `json:"version_skew,omitempty"`
+
+	// Where to send notifications when the monitor starts failing or recovers
+	Notifications []NotificationSink `json:"notifications,omitempty"`
+
+	// Skip runs while this workload is scaled to zero or its namespace is terminating, so planned teardowns do not
+	// look like outages
+	Target *TargetWorkload `json:"target,omitempty"`
+
+	// Mark every request, including cleanup, as synthetic traffic
+	SyntheticMarker *SyntheticMarker `json:"synthetic_marker,omitempty"`
+
+	// Keep the cookies responses set for the rest of the run, and send them with later requests, like the
+	// session cookie of a login. Every run starts without cookies
+	CookieJar bool `json:"cookie_jar,omitempty"`
 
-	// How frequently to execute the monitor requests
-	Period *metav1.Duration `json:"period"`
+	// Who to contact when the monitor fails, and where to look
+	Ownership `json:",inline"`
+
+	// The seed of the generated variables, like random-8, to reproduce a run. Set from an HttpCheck's random_seed;
+	// otherwise every run picks a new one.
+	RandomSeed *int64 `json:"-"`
+}
+
+// Where a variable came from during a run and which requests used it. Values are never recorded.
+type VariableLineage struct {
+	Name string `json:"name"`
+
+	// The request that extracted the variable, or environment or generated. Empty if nothing produced it.
+	ProducedBy string `json:"produced_by,omitempty"`
+
+	// The requests that refer to the variable
+	ConsumedBy []string `json:"consumed_by,omitempty"`
+}
+
+// An assertion that did not hold for a request in observe-only mode
+type AssertionViolation struct {
+	// Name of the request
+	Request string `json:"request"`
+
+	// The assertion that did not hold, like expected_response_codes
+	Assertion string `json:"assertion"`
+
+	// How many runs violated it
+	Count int `json:"count"`
+
+	FirstSeen metav1.Time `json:"first_seen"`
+	LastSeen  metav1.Time `json:"last_seen"`
+
+	// Why the assertion did not hold in the most recent violation
+	Message string `json:"message"`
+}
+
+// Response time percentiles for a request over the most recent runs
+type RequestLatency struct {
+	// Name of the request
+	Name string `json:"name"`
+
+	// How many runs the percentiles are computed from
+	Samples int `json:"samples"`
+
+	P50 metav1.Duration `json:"p50"`
+	P95 metav1.Duration `json:"p95"`
+	P99 metav1.Duration `json:"p99"`
+}
+
+// A cleanup request that failed and is retried on later runs
+type PendingCleanup struct {
+	// Name of the cleanup request
+	Name string `json:"name"`
+
+	// The URL the request is sent to
+	Url string `json:"url,omitempty"`
+
+	// How many times the request was sent, across runs
+	Attempts int `json:"attempts"`
+
+	FirstFailure *metav1.Time `json:"first_failure,omitempty"`
+	NextAttempt  *metav1.Time `json:"next_attempt,omitempty"`
+
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Outbound traffic during one calendar month (UTC)
+type RequestUsage struct {
+	// The month counted, like 2020-06
+	Month string `json:"month"`
+
+	// Requests sent, including retries, cleanup and soaks
+	Requests int64 `json:"requests"`
+
+	// Bytes of request and response bodies
+	Bytes int64 `json:"bytes"`
 }
 
 // HttpMonitorStatus defines the observed state of HttpMonitor
@@ -124,12 +705,56 @@ type HttpMonitorStatus struct {
 
 	LastExecution *metav1.Time `json:"last_execution"`
 	LastFailure   *metav1.Time `json:"last_failure"`
+
+	// Up, Degraded when only some requests succeeded, or Down when none did
+	State MonitorState `json:"state,omitempty"`
+
+	// The current state of the monitor. The Healthy condition carries the severity of an ongoing failure.
+	Conditions []MonitorCondition `json:"conditions,omitempty"`
+
+	// Likely authoring mistakes found in the spec, like unused variables or timeouts longer than the period
+	LintWarnings []string `json:"lint_warnings,omitempty"`
+
+	// Which request produced each variable in the last run, and which requests consumed it
+	VariableLineage []VariableLineage `json:"variable_lineage,omitempty"`
+
+	// Assertions that did not hold for requests in observe-only mode
+	Violations []AssertionViolation `json:"violations,omitempty"`
+
+	// Response time percentiles for each request, over the last latency_window runs
+	Latency []RequestLatency `json:"latency,omitempty"`
+
+	// Cleanup requests that failed and have not succeeded on a retry yet. Pending retries are kept in memory, so
+	// they are abandoned if the controller restarts.
+	CleanupDebt []PendingCleanup `json:"cleanup_debt,omitempty"`
+
+	// The monitor's outbound traffic this month, counted against request budgets
+	Usage *RequestUsage `json:"usage,omitempty"`
+
+	// Name of the ConfigMap holding the most recent diagnostic bundle
+	DiagnosticBundle string `json:"diagnostic_bundle,omitempty"`
+
+	// The recent values of the response headers requests capture, oldest first
+	HeaderHistory []HeaderObservation `json:"header_history,omitempty"`
+
+	// The seed of the generated variables in the last run. An HttpCheck with the same requests and this
+	// random_seed sends the same values again.
+	RandomSeed int64 `json:"random_seed,omitempty"`
+
+	// The last measurements of each request with a performance audit
+	Performance []PagePerformance `json:"performance,omitempty"`
+
+	// The signed record of the last run, when the controller signs run records
+	LastRunRecord *SignedRunRecord `json:"last_run_record,omitempty"`
 }
 
 // HttpMonitor is the Schema for the httpmonitors API
 // +kubebuilder:object:root=true
 // +k8s:openapi-gen=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Severity",type=string,JSONPath=`.status.conditions[?(@.type=="Healthy")].severity`
+// +kubebuilder:printcolumn:name="Last Execution",type=string,format=date-time,JSONPath=`.status.last_execution`
 type HttpMonitor struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -25,12 +25,18 @@ The R&D leading to these results received funding from the:
 package v1alpha1
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"github.com/go-logr/logr"
 	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
-	"k8s.io/apimachinery/pkg/util/rand"
+	"github.com/oregondesignservices/monitoring-controller/internal/netdiag"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"strings"
@@ -77,67 +83,246 @@ func (r *HttpRequest) BuildRequest() (*http.Request, error) {
 	body := replacer.Replace(r.Body)
 	query := replaceQueryParams(r.QueryParams, replacer)
 	header := replaceHeader(r.Headers, replacer)
+	if r.BodyFrom != nil {
+		content, err := r.BodyFrom.content(replacer)
+		if err != nil {
+			return nil, err
+		}
+		body = content
+	}
+
+	payload := []byte(body)
+	var formContentType string
+	if r.Form != nil {
+		encoded, contentType, err := r.Form.encode(replacer)
+		if err != nil {
+			return nil, err
+		}
+		payload, formContentType = encoded, contentType
+	}
+	if r.BodyBase64 != "" {
+		decoded, err := base64.StdEncoding.DecodeString(r.BodyBase64)
+		if err != nil {
+			return nil, fmt.Errorf("body_base64 is not valid base64: %w", err)
+		}
+		payload = decoded
+	}
+	if r.Protobuf != nil && r.Protobuf.RequestMessage != "" {
+		encoded, err := r.Protobuf.encode(payload)
+		if err != nil {
+			return nil, err
+		}
+		payload = encoded
+	}
+	if r.CompressRequestBody != "" {
+		compressed, err := compressBody(r.CompressRequestBody, payload)
+		if err != nil {
+			return nil, err
+		}
+		payload = compressed
+	}
 
-	req, err := http.NewRequest(r.Method, finalUrl, strings.NewReader(body))
+	req, err := http.NewRequest(r.Method, finalUrl, bytes.NewReader(payload))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header = header
+	if req.Header == nil {
+		// A client with a cookie jar adds cookies to the header
+		req.Header = make(http.Header)
+	}
+	r.Language.apply(req)
+	if formContentType != "" {
+		// The boundary of a multipart body only matches its own Content-Type
+		req.Header.Set("Content-Type", formContentType)
+	}
+	if r.Protobuf != nil && r.Protobuf.RequestMessage != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", protobufContentType)
+	}
+	if r.CompressRequestBody != "" {
+		req.Header.Set("Content-Encoding", r.CompressRequestBody)
+	}
+	r.applyFingerprintHeaders(req)
+	if r.Mutating && r.IdempotencyKey != "" {
+		req.Header.Set(r.idempotencyKeyHeader(), r.IdempotencyKey)
+	}
 
 	req.URL.RawQuery = query.Encode()
+	if err := r.HmacSignature.sign(req, payload, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := r.Auth.apply(req); err != nil {
+		return nil, err
+	}
 	return req, nil
 }
 
-func containsInt(needle int, haystay []int) bool {
-	for _, val := range haystay {
-		if val == needle {
-			return true
-		}
+// Compress a rendered request body with the given Content-Encoding
+func compressBody(encoding string, body []byte) ([]byte, error) {
+	if encoding != "gzip" {
+		return nil, fmt.Errorf("unsupported request body compression %q", encoding)
+	}
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const defaultIdempotencyKeyHeader = "Idempotency-Key"
+
+func (r *HttpRequest) idempotencyKeyHeader() string {
+	if r.IdempotencyKeyHeader == "" {
+		return defaultIdempotencyKeyHeader
+	}
+	return r.IdempotencyKeyHeader
+}
+
+// Give a mutating request a new idempotency key for this run. Retries reuse it.
+func (r *HttpRequest) newIdempotencyKey() {
+	if r.Mutating {
+		r.IdempotencyKey = string(uuid.NewUUID())
+	}
+}
+
+// How long a request may take when it does not set a timeout
+const defaultRequestTimeout = 5 * time.Second
+
+func (r *HttpRequest) timeout() time.Duration {
+	if r.Timeout == nil && r.Script != nil {
+		return defaultScriptTimeout
 	}
-	return false
+	if r.Timeout == nil && (r.Accessibility != nil || r.Performance != nil) {
+		return defaultAuditTimeout
+	}
+	if r.Timeout == nil {
+		return defaultRequestTimeout
+	}
+	return r.Timeout.Duration
 }
 
 // Send the HTTP request and parse any variables
-func (r *HttpRequest) sendRequest(client *http.Client) (*http.Response, error) {
+func (r *HttpRequest) sendRequest(client *http.Client, result *RequestResult) (*http.Response, error) {
 	req, err := r.BuildRequest()
 	if err != nil {
 		return nil, err
 	}
-
-	timeoutDuration := 5 * time.Second
-
-	if r.Timeout != "" {
-		timeoutDuration, err = time.ParseDuration(r.Timeout)
-		if err != nil {
-			return nil, err
-		}
+	result.Url = req.URL.String()
+	if req.ContentLength > 0 {
+		result.BytesSent = req.ContentLength
+	}
+	if err := r.Tls.loaded(); err != nil {
+		return nil, err
+	}
+	if r.Sse != nil && req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "text/event-stream")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeoutDuration)
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout())
 	defer cancel()
+	start := time.Now()
+	ctx = httptrace.WithClientTrace(ctx, result.Timings.clientTrace(start))
 
-	resp, err := client.Do(req.WithContext(ctx))
-	if err != nil {
+	if client, err = r.httpClient(client); err != nil {
 		return nil, err
 	}
+	if r.Redirects != nil {
+		client = r.Redirects.client(client)
+	}
+	var resp *http.Response
+	if r.Http2 != nil {
+		// The connection is dialed by hand, so the client's cookie jar is too
+		addCookies(client.Jar, req)
+		resp, err = r.sendHttp2Request(ctx, req.WithContext(ctx), result)
+		if resp != nil {
+			storeCookies(client.Jar, req, resp)
+		}
+	} else if r.WebSocket != nil {
+		resp, err = r.exchangeWebSocket(ctx, client, req, result)
+	} else {
+		resp, err = client.Do(req.WithContext(ctx))
+		if resp != nil {
+			result.TLS = resp.TLS
+		}
+	}
+	received := time.Now()
+	if err != nil {
+		return resp, err
+	}
+	if resp == nil {
+		return nil, errors.New("got nil response object")
+	}
+	if r.Sse != nil && r.ExpectedResponseCodes.Matches(resp.StatusCode) {
+		if err := r.Sse.read(resp); err != nil {
+			return resp, err
+		}
+	}
+	limitErr := r.limitResponseBody(resp)
+	body := readBodyAndReset(resp)
+	result.ResponseTime = time.Since(start)
+	if r.WebSocket == nil {
+		// WebSocket checks count every reply, not only the last one that is the body
+		result.BytesReceived = int64(len(body))
+	}
+	if limitErr != nil {
+		return resp, limitErr
+	}
+	if err := r.decodeBody(resp, body); err != nil {
+		return resp, err
+	}
+	if err := r.decodeProtobufBody(resp); err != nil {
+		return resp, err
+	}
+	if err := r.verifyResponse(ctx, client, req, resp, result); err != nil {
+		return resp, r.checkClockSkew(resp, received, err)
+	}
 	return resp, r.handleResponse(resp)
 }
 
-func (r *HttpRequest) handleResponse(resp *http.Response) error {
-	if resp == nil {
-		return errors.New("got nil response object")
-	}
-	if !containsInt(resp.StatusCode, r.ExpectedResponseCodes) {
-		return fmt.Errorf("not an expected error code: %d is not in %x", resp.StatusCode, r.ExpectedResponseCodes)
+// How much of the response body to keep for diagnostics
+const responseSnippetLength = 1024
+
+// Send the request, recording how it went
+func (r *HttpRequest) timedSendRequest(client *http.Client) (*http.Response, *RequestResult) {
+	result := &RequestResult{Name: r.Name}
+	start := time.Now()
+	resp, err := r.sendRequest(client, result)
+	result.Duration = time.Since(start)
+	result.Err = err
+	result.Category = failureCategory(err)
+	if resp != nil {
+		result.StatusCode = resp.StatusCode
+		result.CapturedHeaders = r.captureHeaders(resp)
+		if err != nil {
+			body := readBodyAndReset(resp)
+			if len(body) > responseSnippetLength {
+				body = body[:responseSnippetLength]
+			}
+			result.ResponseSnippet = string(body)
+		}
 	}
+	return resp, result
+}
+
+// Parse variables from a response that passed its assertions
+func (r *HttpRequest) handleResponse(resp *http.Response) error {
 	// Nothing to parse
 	if len(r.VariablesFromResponse) == 0 {
 		return nil
 	}
 
 	for _, variable := range r.VariablesFromResponse {
-		err := variable.ParseFromResponse(resp)
+		var err error
+		if variable.From == FromTypeJwtClaim {
+			err = r.parseJwtClaim(variable, resp)
+		} else {
+			err = variable.ParseFromResponse(resp)
+		}
 		if err != nil {
 			return err
 		}
@@ -146,22 +331,187 @@ func (r *HttpRequest) handleResponse(resp *http.Response) error {
 	return nil
 }
 
-func (h *HttpMonitor) Execute() {
+// How long a traceroute may take in total, regardless of the number of hops
+const tracerouteTimeout = 30 * time.Second
+
+func (d *Diagnostics) wantsTraceroute(err error) bool {
+	return d != nil && d.Traceroute && isConnectionError(err)
+}
+
+// Run a bounded traceroute to the host the request was sent to
+func (h *HttpMonitor) traceroute(r HttpRequest, logger logr.Logger) *netdiag.Report {
+	req, err := r.BuildRequest()
+	if err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tracerouteTimeout)
+	defer cancel()
+
+	report, err := netdiag.Trace(ctx, req.URL.Hostname(), netdiag.Options{MaxHops: h.Spec.Diagnostics.MaxHops})
+	if err != nil {
+		logger.Error(err, "traceroute failed", "host", req.URL.Hostname())
+	}
+	if report != nil {
+		logger.Info("traceroute to target", "address", report.Address, "hops", report.String())
+	}
+	return report
+}
+
+// The first cleanup retry waits this long, doubling with each attempt
+const cleanupRetryBackoff = time.Second
+
+// How long to wait after the given attempt of a cleanup request. The wait doubles up to the request's timeout, so
+// retries with many attempts do not wait for minutes.
+func cleanupRetryWait(attempt int, timeout time.Duration) time.Duration {
+	wait := cleanupRetryBackoff << uint(attempt-1)
+	if wait <= 0 || wait > timeout {
+		return timeout
+	}
+	return wait
+}
+
+// Pick the cleanup requests to run after the given requests succeeded, in order. Cleanup undoes the succeeded
+// requests in reverse, then the cleanup requests that are not linked to a request run in the order they are
+// declared.
+func (h *HttpMonitor) cleanupPlan(succeeded []string) []HttpRequest {
+	var plan []HttpRequest
+	for i := len(succeeded) - 1; i >= 0; i-- {
+		for _, cleanup := range h.Spec.Cleanup {
+			if cleanup.CleanupFor == succeeded[i] {
+				plan = append(plan, cleanup)
+			}
+		}
+	}
+	for _, cleanup := range h.Spec.Cleanup {
+		if cleanup.CleanupFor == "" {
+			plan = append(plan, cleanup)
+		}
+	}
+	return plan
+}
+
+// Send a cleanup request, retrying it with backoff if it fails
+func (h *HttpMonitor) sendCleanupRequest(client *http.Client, httpRequest HttpRequest, logger logr.Logger) *RequestResult {
+	start := time.Now()
+	var bytesSent, bytesReceived int64
+	for attempt := 1; ; attempt++ {
+		resp, requestResult := httpRequest.timedSendRequest(client)
+		h.protectCapturedData(requestResult)
+		requestResult.Attempts = attempt
+		bytesSent += requestResult.BytesSent
+		bytesReceived += requestResult.BytesReceived
+		requestResult.BytesSent = bytesSent
+		requestResult.BytesReceived = bytesReceived
+		HandleMetrics(h, httpRequest, resp)
+		if requestResult.Err == nil {
+			return requestResult
+		}
+		if attempt > h.Spec.CleanupRetries {
+			logger.Error(requestResult.Err, "failed to complete cleanup request", "name", httpRequest.Name, "attempts", attempt)
+			return requestResult
+		}
+		backoff := cleanupRetryWait(attempt, httpRequest.timeout())
+		if h.Spec.Period != nil && time.Since(start)+backoff > h.Spec.Period.Duration {
+			// Left to the cleanup debt, which later runs retry
+			logger.Error(requestResult.Err, "giving up on cleanup request before the next run", "name", httpRequest.Name, "attempts", attempt)
+			return requestResult
+		}
+		logger.V(2).Info("retrying cleanup request", "error", requestResult.Err.Error(), "backoff", backoff.String())
+		time.Sleep(backoff)
+	}
+}
+
+// Send a cleanup request that failed during an earlier run again, with the variables it had then
+func (h *HttpMonitor) RetryCleanup(httpRequest *HttpRequest) *RequestResult {
+	resp, requestResult := httpRequest.timedSendRequest(httpclient.GetClient())
+	h.protectCapturedData(requestResult)
+	requestResult.Attempts = 1
+	requestResult.Request = httpRequest.DeepCopy()
+	HandleMetrics(h, *httpRequest, resp)
+	return requestResult
+}
+
+// Get a request ready to send with the variables available to it
+func (h *HttpMonitor) prepareRequest(httpRequest *HttpRequest, availableVariables VariableList) {
+	httpRequest.VariablesFromResponse.clearValues()
+	httpRequest.AvailableVariables = availableVariables
+	httpRequest.newIdempotencyKey()
+	if httpRequest.Auth == nil && !httpRequest.setsAuthorization() {
+		httpRequest.Auth = h.Spec.Auth
+	}
+	httpRequest.Tls = httpRequest.Tls.withClientCertificate(h.Spec.ClientCertificate)
+	h.Spec.SyntheticMarker.mark(httpRequest, h.Namespace+"/"+h.Name)
+}
+
+// Send a prepared request, or run its script or soak, recording failures. Returns nil when its conditions skip it.
+func (h *HttpMonitor) executeRequest(client *http.Client, httpRequest HttpRequest, entry logr.Logger) *RequestResult {
+	entry.V(2).Info("executing request")
+	skipReason, conditionErr := httpRequest.skipReason()
+	if conditionErr == nil && skipReason != "" {
+		entry.Info("skipping request", "reason", skipReason)
+		return nil
+	}
+
+	var requestResult *RequestResult
+	var wakeUp *RequestResult
+	if conditionErr == nil && httpRequest.ColdStart != nil {
+		wakeUp = h.wakeUp(client, httpRequest, entry)
+	}
+	if conditionErr != nil {
+		requestResult = &RequestResult{Name: httpRequest.Name, Err: conditionErr}
+	} else if wakeUp != nil && wakeUp.Err != nil {
+		requestResult = wakeUp
+	} else if httpRequest.Script != nil {
+		requestResult = h.runScript(httpRequest)
+	} else if httpRequest.Accessibility != nil {
+		requestResult, _ = h.runJob(httpRequest, h.accessibilityJob(&httpRequest))
+	} else if httpRequest.Performance != nil {
+		requestResult = h.runPerformanceAudit(httpRequest)
+		HandlePerformanceMetrics(h, httpRequest, requestResult)
+	} else if httpRequest.Soak != nil {
+		requestResult = h.soak(client, httpRequest, entry)
+	} else if httpRequest.StartTls != nil {
+		requestResult = httpRequest.checkStartTls()
+	} else if httpRequest.Modbus != nil {
+		requestResult = httpRequest.checkModbus()
+	} else if httpRequest.OpcUa != nil {
+		requestResult = httpRequest.checkOpcUa()
+	} else if httpRequest.ForEach != nil {
+		requestResult = h.forEach(client, httpRequest, entry)
+	} else {
+		requestResult = h.sendWithRetries(client, httpRequest, entry)
+		HandleViolationMetrics(h, httpRequest, requestResult)
+	}
+	if wakeUp != nil && wakeUp != requestResult {
+		requestResult.ColdStart = wakeUp.Duration
+		requestResult.Attempts += wakeUp.Attempts
+		requestResult.BytesSent += wakeUp.BytesSent
+		requestResult.BytesReceived += wakeUp.BytesReceived
+	}
+	h.protectCapturedData(requestResult)
+	requestResult.Ownership = httpRequest.Ownership.Or(h.Spec.Ownership)
+	if err := requestResult.Err; err != nil {
+		requestResult.Severity = httpRequest.Severity.Or(h.Spec.Severity.Or(SeverityCritical))
+		HandleFailureMetrics(h, httpRequest, requestResult)
+		entry.Error(err, "failed to complete request", "name", httpRequest.Name, "severity", requestResult.Severity,
+			"category", requestResult.Category)
+		if h.Spec.Diagnostics.wantsTraceroute(err) {
+			requestResult.HopReport = h.traceroute(httpRequest, entry)
+		}
+	}
+	return requestResult
+}
+
+func (h *HttpMonitor) Execute() *RunResult {
 	client := httpclient.GetClient()
+	if h.Spec.CookieJar {
+		client = withCookieJar(client)
+	}
+	result := &RunResult{Start: time.Now(), Variables: make(map[string]string), RandomSeed: h.randomSeed()}
 
 	// These variables are available for all requests to use
-	availableVariables := VariableList{
-		&Variable{
-			Name:  "random-8",
-			From:  FromTypeProvided,
-			Value: rand.String(8),
-		},
-		&Variable{
-			Name:  "random-16",
-			From:  FromTypeProvided,
-			Value: rand.String(16),
-		},
-	}
+	availableVariables := newGeneratedVariables(result.RandomSeed)
 	for key, val := range h.Spec.Environment {
 		availableVariables = append(availableVariables, &Variable{
 			Name:  key,
@@ -169,43 +519,74 @@ func (h *HttpMonitor) Execute() {
 			Value: val,
 		})
 	}
+	lineage := newLineageTracker()
+	for _, variable := range availableVariables {
+		if _, fromEnvironment := h.Spec.Environment[variable.Name]; fromEnvironment {
+			lineage.produced(variable.Name, lineageEnvironment)
+		} else {
+			lineage.produced(variable.Name, lineageGenerated)
+		}
+	}
 
-	logger := httpMonitorUtilsLogger.
+	logger := withLogVerbosity(httpMonitorUtilsLogger, raisedLogVerbosity(h.Namespace+"/"+h.Name, time.Now())).
 		WithName("httpmonitor").
 		WithName("runner").
 		WithValues("namespace", h.Namespace, "name", h.Name)
 
 	logger.Info("executing requests")
 
-	// run requests
-	for _, httpRequest := range h.Spec.Requests {
-		entry := logger.WithValues("name", httpRequest.Name)
-		entry.V(2).Info("executing request")
-		httpRequest.VariablesFromResponse.clearValues()
-		httpRequest.AvailableVariables = availableVariables
-
-		resp, err := httpRequest.sendRequest(client)
-		HandleMetrics(h, httpRequest, resp)
-		if err != nil {
-			entry.Error(err, "failed to complete request", "name", httpRequest.Name)
-			break
-		}
-		if len(httpRequest.VariablesFromResponse) > 0 {
-			availableVariables = append(availableVariables, httpRequest.VariablesFromResponse...)
+	// run requests, remembering which ones succeeded so only their cleanup runs
+	var succeeded []string
+	if h.Spec.hasDependencies() {
+		succeeded, availableVariables = h.executeGraph(client, result, availableVariables, lineage, logger)
+	} else {
+		for _, httpRequest := range h.Spec.Requests {
+			h.prepareRequest(&httpRequest, availableVariables)
+			lineage.consumed(&httpRequest)
+			requestResult := h.executeRequest(client, httpRequest, logger.WithValues("name", httpRequest.Name))
+			if requestResult == nil {
+				continue
+			}
+			result.Requests = append(result.Requests, requestResult)
+			if requestResult.Err != nil {
+				break
+			}
+			succeeded = append(succeeded, httpRequest.Name)
+			if len(httpRequest.VariablesFromResponse) > 0 {
+				availableVariables = append(availableVariables, httpRequest.VariablesFromResponse...)
+				for _, variable := range httpRequest.VariablesFromResponse {
+					lineage.produced(variable.Name, httpRequest.Name)
+					result.Variables[variable.Name] = variable.Value
+				}
+			}
 		}
 	}
 
 	// run cleanup
-	for _, httpRequest := range h.Spec.Cleanup {
+	for _, httpRequest := range h.cleanupPlan(succeeded) {
 		entry := logger.WithValues("name", httpRequest.Name)
 		entry.V(2).Info("executing cleanup request")
-		httpRequest.VariablesFromResponse.clearValues()
-		httpRequest.AvailableVariables = availableVariables
-
-		resp, err := httpRequest.sendRequest(client)
-		HandleMetrics(h, httpRequest, resp)
-		if err != nil {
-			entry.Error(err, "failed to complete cleanup request", "name", httpRequest.Name)
+		h.prepareRequest(&httpRequest, availableVariables)
+		lineage.consumed(&httpRequest)
+		// Cleanup requests that cannot tell whether they should run are not sent, since they would be retried later
+		if skipReason, err := httpRequest.skipReason(); err != nil {
+			entry.Error(err, "skipping cleanup request", "name", httpRequest.Name)
+			continue
+		} else if skipReason != "" {
+			entry.Info("skipping cleanup request", "reason", skipReason)
+			continue
 		}
+
+		requestResult := h.sendCleanupRequest(client, httpRequest, entry)
+		requestResult.Ownership = httpRequest.Ownership.Or(h.Spec.Ownership)
+		// A copy, since the variables are the run's own and the next run overwrites them
+		requestResult.Request = httpRequest.DeepCopy()
+		result.Cleanup = append(result.Cleanup, requestResult)
 	}
+
+	result.Duration = time.Since(result.Start)
+	result.Lineage = lineage.lineage()
+	logger.Info("run completed", append([]interface{}{"monitor", h.Namespace + "/" + h.Name},
+		result.summary(len(h.Spec.Requests))...)...)
+	return result
 }
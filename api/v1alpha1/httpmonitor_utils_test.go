@@ -25,8 +25,11 @@ The R&D leading to these results received funding from the:
 package v1alpha1
 
 import (
+	"compress/gzip"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -93,3 +96,109 @@ func TestHttpRequest_BuildRequest(t *testing.T) {
 		t.Errorf("unexpected url. Got: %s, wanted: %s", req.URL.String(), expectedUrl)
 	}
 }
+
+func TestHttpMonitor_cleanupPlan(t *testing.T) {
+	monitor := &HttpMonitor{
+		Spec: HttpMonitorSpec{
+			Cleanup: []HttpRequest{
+				{Name: "delete user", CleanupFor: "create user"},
+				{Name: "always"},
+				{Name: "delete post", CleanupFor: "create post"},
+			},
+		},
+	}
+
+	tests := []struct {
+		TestName  string
+		Succeeded []string
+		Expected  []string
+	}{
+		{"all-succeeded", []string{"create user", "create post"}, []string{"delete post", "delete user", "always"}},
+		{"partial-failure", []string{"create user"}, []string{"delete user", "always"}},
+		{"nothing-succeeded", nil, []string{"always"}},
+	}
+
+	for _, testdata := range tests {
+		var names []string
+		for _, r := range monitor.cleanupPlan(testdata.Succeeded) {
+			names = append(names, r.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(testdata.Expected, ",") {
+			t.Errorf("[%s] unexpected cleanup plan. Got: %v, expected: %v", testdata.TestName, names, testdata.Expected)
+		}
+	}
+}
+
+func TestHttpRequest_IdempotencyKey(t *testing.T) {
+	r := &HttpRequest{Method: http.MethodPost, Url: "http://test.com", Mutating: true}
+	r.newIdempotencyKey()
+
+	first, err := r.BuildRequest()
+	if err != nil {
+		t.Fatalf("got err while building request: %s", err)
+	}
+	retry, err := r.BuildRequest()
+	if err != nil {
+		t.Fatalf("got err while building request: %s", err)
+	}
+	key := first.Header.Get("Idempotency-Key")
+	if key == "" || retry.Header.Get("Idempotency-Key") != key {
+		t.Errorf("retries should reuse the idempotency key. Got: '%s' and '%s'", key, retry.Header.Get("Idempotency-Key"))
+	}
+
+	r.newIdempotencyKey()
+	next, _ := r.BuildRequest()
+	if next.Header.Get("Idempotency-Key") == key {
+		t.Errorf("each run should get a new idempotency key")
+	}
+
+	readOnly := &HttpRequest{Method: http.MethodGet, Url: "http://test.com"}
+	readOnly.newIdempotencyKey()
+	req, _ := readOnly.BuildRequest()
+	if req.Header.Get("Idempotency-Key") != "" {
+		t.Errorf("requests that are not mutating should not get an idempotency key")
+	}
+}
+
+func TestHttpRequest_Fingerprint(t *testing.T) {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	r := &HttpRequest{Method: http.MethodGet, Url: "https://test.com", Headers: header, Fingerprint: ClientFingerprintFirefox}
+
+	req, err := r.BuildRequest()
+	if err != nil {
+		t.Fatalf("got err while building request: %s", err)
+	}
+	if !strings.Contains(req.Header.Get("User-Agent"), "Firefox") {
+		t.Errorf("expected a Firefox User-Agent, got '%s'", req.Header.Get("User-Agent"))
+	}
+	if req.Header.Get("Accept") != "application/json" {
+		t.Errorf("headers set by the request should win over the preset, got '%s'", req.Header.Get("Accept"))
+	}
+}
+
+func TestHttpRequest_CompressRequestBody(t *testing.T) {
+	r := &HttpRequest{
+		Method:              http.MethodPost,
+		Url:                 "https://test.com/ingest",
+		Body:                `{"metric":"{name}"}`,
+		CompressRequestBody: "gzip",
+		AvailableVariables:  VariableList{{Name: "name", Value: "up"}},
+	}
+
+	req, err := r.BuildRequest()
+	if err != nil {
+		t.Fatalf("got err while building request: %s", err)
+	}
+	if req.Header.Get("Content-Encoding") != "gzip" {
+		t.Errorf("expected Content-Encoding gzip, got '%s'", req.Header.Get("Content-Encoding"))
+	}
+	reader, err := gzip.NewReader(req.Body)
+	if err != nil {
+		t.Fatalf("body is not gzipped: %s", err)
+	}
+	body, _ := ioutil.ReadAll(reader)
+	if string(body) != `{"metric":"up"}` {
+		t.Errorf("expected the rendered body, got '%s'", body)
+	}
+}
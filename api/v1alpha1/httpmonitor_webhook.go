@@ -0,0 +1,267 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (h *HttpMonitor) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(h).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-monitoring-raisingthefloor-org-v1alpha1-httpmonitor,mutating=false,failurePolicy=fail,groups=monitoring.raisingthefloor.org,resources=httpmonitors,versions=v1alpha1,name=vhttpmonitor.kb.io
+
+var _ webhook.Validator = &HttpMonitor{}
+
+// Reject monitors that break the invariants of the spec, or whose runs can take longer than their period.
+// Without the webhook these are only lint warnings.
+func (h *HttpMonitor) ValidateCreate() error {
+	if err := h.checkInvariants(); err != nil {
+		return err
+	}
+	return h.checkRunBudget()
+}
+
+func (h *HttpMonitor) ValidateUpdate(old runtime.Object) error {
+	if err := h.checkInvariants(); err != nil {
+		return err
+	}
+	return h.checkRunBudget()
+}
+
+// A monitor needs requests and a period to run. Monitors with a template_ref only have them once the template is
+// resolved, so the controller checks again before it starts a runner.
+func (h *HttpMonitor) CheckRunnable() error {
+	if len(h.Spec.Requests) == 0 {
+		return fmt.Errorf("at least one request is required")
+	}
+	if h.Spec.Period == nil {
+		return fmt.Errorf("a period is required")
+	}
+	return nil
+}
+
+// Whether a request can time out no sooner than the next run starts. Shared by the webhook and lint so both draw the
+// line at the same place: a timeout equal to the period is rejected.
+func (s *HttpMonitorSpec) timeoutExceedsPeriod(r HttpRequest) bool {
+	return r.Timeout != nil && s.Period != nil && r.Timeout.Duration >= s.Period.Duration
+}
+
+// Rules the CRD schema cannot express. At least one request and unique request and cleanup names are also in the
+// schema, through minItems and map list types. Comparing a timeout with the period would be a CEL rule, but those
+// need apiextensions.k8s.io/v1 CRDs, and these are generated as v1beta1.
+func (h *HttpMonitor) checkInvariants() error {
+	if h.Spec.TemplateRef == nil {
+		// Otherwise the template provides them, and they are checked once it is resolved
+		if err := h.CheckRunnable(); err != nil {
+			return err
+		}
+	} else if len(h.Spec.Patches) > 0 {
+		if err := h.checkPatches(); err != nil {
+			return err
+		}
+	}
+	if h.Spec.VersionSkew != nil && h.Spec.VersionSkew.Monitor == h.Name {
+		return fmt.Errorf("version_skew compares the monitor with itself")
+	}
+	if err := h.checkDataResidency(); err != nil {
+		return err
+	}
+	if h.Spec.Scrubbing != nil {
+		if err := h.Spec.Scrubbing.validate(); err != nil {
+			return fmt.Errorf("the monitor has an %s", err)
+		}
+	}
+	if h.Spec.Auth != nil {
+		if err := h.Spec.Auth.validate(); err != nil {
+			return fmt.Errorf("the monitor has an %s", err)
+		}
+	}
+	for _, group := range []struct {
+		kind     string
+		requests []HttpRequest
+	}{{"request", h.Spec.Requests}, {"cleanup request", h.Spec.Cleanup}} {
+		kind := group.kind
+		names := make(map[string]bool)
+		for _, r := range group.requests {
+			if names[r.Name] {
+				return fmt.Errorf("%s name %q is used more than once", kind, r.Name)
+			}
+			names[r.Name] = true
+			if h.Spec.timeoutExceedsPeriod(r) {
+				return fmt.Errorf("%s %q has a timeout of %s, not shorter than the period of %s", kind, r.Name,
+					r.Timeout.Duration, h.Spec.Period.Duration)
+			}
+			if err := r.validateConditions(); err != nil {
+				return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+			}
+			if err := r.ExpectedResponseCodes.validate(); err != nil {
+				return fmt.Errorf("%s %q has an invalid expected_response_codes entry: %s", kind, r.Name, err)
+			}
+			if r.ExpectConnectionReused != nil && r.Http2 != nil {
+				return fmt.Errorf("%s %q sets expect_connection_reused, but http2 requests always open a dedicated connection",
+					kind, r.Name)
+			}
+			if r.WebSocket != nil && r.Http2 != nil {
+				return fmt.Errorf("%s %q sets both websocket and http2", kind, r.Name)
+			}
+			if r.HeaderOrder != "" {
+				if r.Http2 != nil {
+					return fmt.Errorf("%s %q sets both header_order and http2", kind, r.Name)
+				}
+				if r.WebSocket != nil {
+					return fmt.Errorf("%s %q sets both header_order and websocket", kind, r.Name)
+				}
+				if r.ExpectConnectionReused != nil {
+					return fmt.Errorf("%s %q sets expect_connection_reused, but header_order requests always open a dedicated connection",
+						kind, r.Name)
+				}
+			}
+			if r.ProxyUrl != "" {
+				if r.Http2 != nil {
+					return fmt.Errorf("%s %q sets proxy_url, but http2 requests always connect to the target directly",
+						kind, r.Name)
+				}
+				if r.HeaderOrder != "" {
+					return fmt.Errorf("%s %q sets proxy_url, but header_order requests always connect to the target directly",
+						kind, r.Name)
+				}
+				if err := r.validateProxyUrl(); err != nil {
+					return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+				}
+			}
+			if r.Auth != nil {
+				if err := r.Auth.validate(); err != nil {
+					return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+				}
+				if r.setsAuthorization() {
+					return fmt.Errorf("%s %q sets both auth and an Authorization header", kind, r.Name)
+				}
+			}
+			if r.HmacSignature != nil {
+				if err := r.HmacSignature.validate(); err != nil {
+					return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+				}
+			}
+			if r.BodyFrom != nil {
+				if r.Body != "" {
+					return fmt.Errorf("%s %q sets both body and body_from", kind, r.Name)
+				}
+				if err := r.BodyFrom.validate(); err != nil {
+					return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+				}
+			}
+			if r.BodyBase64 != "" {
+				if r.Body != "" || r.BodyFrom != nil {
+					return fmt.Errorf("%s %q sets both body and body_base64", kind, r.Name)
+				}
+				if _, err := base64.StdEncoding.DecodeString(r.BodyBase64); err != nil {
+					return fmt.Errorf("%s %q has an invalid body_base64: %s", kind, r.Name, err)
+				}
+				if r.Protobuf != nil && r.Protobuf.RequestMessage != "" {
+					return fmt.Errorf("%s %q sets body_base64, which is sent as it is, so protobuf cannot encode it",
+						kind, r.Name)
+				}
+			}
+			if r.Form != nil {
+				if r.Body != "" || r.BodyFrom != nil || r.BodyBase64 != "" {
+					return fmt.Errorf("%s %q sets both body and form", kind, r.Name)
+				}
+				if err := r.Form.validate(); err != nil {
+					return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+				}
+			}
+			if r.EgressIp != nil {
+				if err := r.EgressIp.validate(); err != nil {
+					return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+				}
+			}
+			if err := r.validateForEach(kind); err != nil {
+				return err
+			}
+			if r.Redirects != nil {
+				if err := r.Redirects.validate(); err != nil {
+					return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+				}
+			}
+			if r.ResponseAssertions != nil {
+				if err := r.ResponseAssertions.validate(); err != nil {
+					return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+				}
+			}
+			if r.Accessibility != nil {
+				if r.Script != nil {
+					return fmt.Errorf("%s %q sets both script and accessibility", kind, r.Name)
+				}
+				if err := r.Accessibility.validate(); err != nil {
+					return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+				}
+			}
+			if r.Tls != nil {
+				if err := r.Tls.validate(); err != nil {
+					return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+				}
+			}
+			if r.Performance != nil {
+				if r.Script != nil {
+					return fmt.Errorf("%s %q sets both script and performance", kind, r.Name)
+				}
+				if r.Accessibility != nil {
+					return fmt.Errorf("%s %q sets both accessibility and performance", kind, r.Name)
+				}
+				if err := r.Performance.validate(); err != nil {
+					return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+				}
+			}
+			if r.Language != nil {
+				if r.setsHeader("Accept-Language") {
+					return fmt.Errorf("%s %q sets both language and an Accept-Language header", kind, r.Name)
+				}
+				if err := r.Language.validate(); err != nil {
+					return fmt.Errorf("%s %q has an %s", kind, r.Name, err)
+				}
+			}
+		}
+	}
+	if h.Spec.TemplateRef != nil {
+		// Requests may depend on requests the template has
+		return nil
+	}
+	// Names are unique by now, so dependencies can refer to them
+	return h.Spec.validateDependencies()
+}
+
+func (h *HttpMonitor) ValidateDelete() error {
+	return nil
+}
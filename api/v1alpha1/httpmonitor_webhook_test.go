@@ -0,0 +1,217 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestHttpMonitor_checkInvariants(t *testing.T) {
+	minute := &metav1.Duration{Duration: time.Minute}
+	yes := true
+	tests := []struct {
+		TestName string
+		Spec     HttpMonitorSpec
+		Expected string
+	}{
+		{"valid", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a", Timeout: &metav1.Duration{Duration: 10 * time.Second}}},
+			Cleanup: []HttpRequest{{Name: "a"}}}, ""},
+		{"no requests", HttpMonitorSpec{Period: minute}, "at least one request is required"},
+		{"duplicate cleanup", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a"}},
+			Cleanup: []HttpRequest{{Name: "delete"}, {Name: "delete"}}}, `cleanup request name "delete" is used more than once`},
+		{"duplicate request", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a"}, {Name: "a"}}},
+			`request name "a" is used more than once`},
+		{"timeout as long as period", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a", Timeout: minute}}},
+			`request "a" has a timeout of 1m0s, not shorter than the period of 1m0s`},
+		{"cleanup timeout as long as period", HttpMonitorSpec{Period: minute, Cleanup: []HttpRequest{{Name: "b", Timeout: minute}},
+			Requests: []HttpRequest{{Name: "a"}}}, `cleanup request "b" has a timeout of 1m0s, not shorter than the period of 1m0s`},
+		{"invalid body pattern", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			ResponseAssertions: &ResponseAssertions{BodyMatches: []string{"ok("}}}}},
+			"request \"a\" has an invalid body_matches pattern \"ok(\": error parsing regexp: missing closing ): `ok(`"},
+		{"invalid forbidden body pattern", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			ResponseAssertions: &ResponseAssertions{BodyNotMatches: []string{"[maintenance"}}}}},
+			"request \"a\" has an invalid body_not_matches pattern \"[maintenance\": error parsing regexp: missing closing ]: `[maintenance`"},
+		{"invalid redirect pattern", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			Redirects: &Redirects{FinalUrlMatches: "*/account"}}}},
+			"request \"a\" has an invalid final_url_matches pattern \"*/account\": error parsing regexp: missing argument to repetition operator: `*`"},
+		{"dependency cycle", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a", DependsOn: []string{"b"}},
+			{Name: "b", DependsOn: []string{"a"}}}}, "requests depend on each other in a cycle: a -> b -> a"},
+		{"invalid condition", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a", OnlyIf: "{plan} =="}}},
+			`request "a" has an invalid only_if condition: expected a value after ==`},
+		{"connection reuse over http2", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			ExpectConnectionReused: &yes, Http2: &Http2Check{}}}},
+			`request "a" sets expect_connection_reused, but http2 requests always open a dedicated connection`},
+		{"body and form", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a", Body: "x",
+			Form: &FormBody{Fields: []FormField{{Name: "b"}}}}}},
+			`request "a" sets both body and form`},
+		{"file in a urlencoded form", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			Form: &FormBody{Fields: []FormField{{Name: "b", FileName: "b.txt"}}}}}},
+			`request "a" has an invalid form: field "b" is a file, which only multipart forms can send`},
+		{"template without requests", HttpMonitorSpec{TemplateRef: &corev1.ConfigMapKeySelector{Key: "monitor.yaml"},
+			Patches: []string{"requests:\n  - name: a\n    url: https://example.com\n"}}, ""},
+		{"patch that is not an object", HttpMonitorSpec{TemplateRef: &corev1.ConfigMapKeySelector{Key: "monitor.yaml"},
+			Patches: []string{"- name: a"}},
+			`patch 1 is not a YAML object: error unmarshaling JSON: json: cannot unmarshal array into Go value of type map[string]interface {}`},
+		{"version skew with itself", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a"}},
+			VersionSkew: &VersionSkewRule{Monitor: "staging", Variable: "version"}},
+			`version_skew compares the monitor with itself`},
+		{"monitor auth without a secret", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a"}},
+			Auth: &RequestAuth{Basic: &BasicAuth{}}},
+			`the monitor has an invalid auth: basic needs the name of a secret in secret_ref`},
+		{"basic and bearer auth", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			Auth: &RequestAuth{Basic: &BasicAuth{SecretRef: corev1.LocalObjectReference{Name: "creds"}},
+				Bearer: &BearerAuth{SecretRef: corev1.SecretKeySelector{Key: "token"}}}}}},
+			`request "a" has an invalid auth: set exactly one of basic, bearer, oauth2 and aws_sigv4`},
+		{"oauth2 with a relative token url", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			Auth: &RequestAuth{OAuth2: &OAuth2ClientCredentials{TokenUrl: "/oauth/token",
+				SecretRef: corev1.LocalObjectReference{Name: "client"}}}}}},
+			`request "a" has an invalid auth: oauth2 token_url "/oauth/token" is not an http or https URL`},
+		{"aws_sigv4 without a service", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a"}},
+			Auth: &RequestAuth{AwsSigV4: &AwsSigV4Auth{Region: "us-east-1"}}},
+			`the monitor has an invalid auth: aws_sigv4 needs a region and a service`},
+		{"hmac_signature signing a timestamp it does not send", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{
+			Name: "a", HmacSignature: &HmacSignature{StringToSign: "{timestamp}.{body}",
+				SecretRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "hook"},
+					Key: "key"}}}}},
+			`request "a" has an invalid hmac_signature: string_to_sign has {timestamp}, but no timestamp_header sends it`},
+		{"accessibility tag with a comma", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			Accessibility: &AccessibilityAudit{Tags: []string{"wcag2a,wcag2aa"}}}}},
+			`request "a" has an invalid accessibility: tags and rules cannot be empty or have commas, "wcag2a,wcag2aa" does`},
+		{"tls with a ca_bundle it does not check", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			Tls: &TlsOptions{InsecureSkipVerify: true, CaBundle: &CaBundleSource{}}}}},
+			`request "a" has an invalid tls: insecure_skip_verify skips the checks ca_bundle is for, set only one`},
+		{"diagnostic bundles with data_residency Nowhere", HttpMonitorSpec{Period: minute, DataResidency: DataResidencyNowhere,
+			Diagnostics: &Diagnostics{BundleAfterFailures: 3}, Requests: []HttpRequest{{Name: "a"}}},
+			`data_residency Nowhere does not allow diagnostic bundles, unset bundle_after_failures`},
+		{"capture_headers with data_residency Nowhere", HttpMonitorSpec{Period: minute, DataResidency: DataResidencyNowhere,
+			Requests: []HttpRequest{{Name: "a", CaptureHeaders: []string{"X-App-Version"}}}},
+			`data_residency Nowhere does not allow request "a" to capture_headers`},
+		{"scrubbing pattern that does not compile", HttpMonitorSpec{Period: minute,
+			Scrubbing: &ScrubbingRules{Patterns: []string{"member-[0-9"}}, Requests: []HttpRequest{{Name: "a"}}},
+			"the monitor has an invalid scrubbing pattern \"member-[0-9\": error parsing regexp: missing closing ]: `[0-9`"},
+		{"proxy_url that is not a proxy", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			ProxyUrl: "egress-proxy.infra:3128"}}},
+			`request "a" has an invalid proxy_url: "egress-proxy.infra:3128" is not an http, https or socks5 URL`},
+		{"websocket and http2", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			WebSocket: &WebSocketCheck{}, Http2: &Http2Check{}}}},
+			`request "a" sets both websocket and http2`},
+		{"websocket through a proxy", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			WebSocket: &WebSocketCheck{}, ProxyUrl: "http://egress-proxy.infra:3128"}}}, ""},
+		{"header order and http2", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			HeaderOrder: ClientFingerprintChrome, Http2: &Http2Check{}}}},
+			`request "a" sets both header_order and http2`},
+		{"header order through a proxy", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			HeaderOrder: ClientFingerprintFirefox, ProxyUrl: "http://egress-proxy.infra:3128"}}},
+			`request "a" sets proxy_url, but header_order requests always connect to the target directly`},
+		{"performance and accessibility", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			Accessibility: &AccessibilityAudit{}, Performance: &PerformanceAudit{}}}},
+			`request "a" sets both accessibility and performance`},
+		{"language and an Accept-Language header", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			Headers:  map[string][]string{"accept-language": {"de"}},
+			Language: &LanguageCheck{AcceptLanguage: "fr"}}}},
+			`request "a" sets both language and an Accept-Language header`},
+		{"html_lang without content_language", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			Language: &LanguageCheck{AcceptLanguage: "fr", HtmlLang: true}}}},
+			`request "a" has an invalid language: html_lang is checked against content_language, which is not set`},
+		{"auth and an Authorization header", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			Headers: map[string][]string{"authorization": {"Bearer x"}},
+			Auth:    &RequestAuth{Basic: &BasicAuth{SecretRef: corev1.LocalObjectReference{Name: "creds"}}}}}},
+			`request "a" sets both auth and an Authorization header`},
+		{"body and body_from", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a", Body: "{}",
+			BodyFrom: &BodySource{SecretRef: &corev1.SecretKeySelector{Key: "body"}}}}},
+			`request "a" sets both body and body_from`},
+		{"body_from without a ref", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			BodyFrom: &BodySource{}}}},
+			`request "a" has an invalid body_from: set exactly one of config_map_ref and secret_ref`},
+		{"invalid body_base64", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			BodyBase64: "iVBO*w0K"}}},
+			`request "a" has an invalid body_base64: illegal base64 data at input byte 4`},
+		{"body_base64 and form", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			BodyBase64: "iVBORw0K", Form: &FormBody{Fields: []FormField{{Name: "b"}}}}}},
+			`request "a" sets both body and form`},
+		{"invalid egress range", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			EgressIp: &EgressIpCheck{Expected: []string{"203.0.113.0/33"}}}}},
+			`request "a" has an invalid egress_ip: "203.0.113.0/33" is neither an IP address nor a CIDR range`},
+		{"for_each in cleanup", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a"}},
+			Cleanup: []HttpRequest{{Name: "b", ForEach: &ForEach{Variable: "ids"}}}},
+			`cleanup request "b" has for_each, which is only supported for requests`},
+		{"for_each with a soak", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			ForEach: &ForEach{Variable: "ids"}, Soak: &Soak{Rate: 1}}}},
+			`request "a" has for_each, which cannot be combined with soak`},
+		{"invalid status code class", HttpMonitorSpec{Period: minute, Requests: []HttpRequest{{Name: "a",
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromString("2XX"), intstr.FromString("20x")}}}},
+			`request "a" has an invalid expected_response_codes entry: "20x" is not a status code, class like 2xx or range like 200-204`},
+	}
+
+	for _, testdata := range tests {
+		monitor := &HttpMonitor{ObjectMeta: metav1.ObjectMeta{Name: "staging"}, Spec: testdata.Spec}
+		out := ""
+		if err := monitor.checkInvariants(); err != nil {
+			out = err.Error()
+		}
+		if out != testdata.Expected {
+			t.Errorf("[%s] unexpected result. Got: %q, expected: %q", testdata.TestName, out, testdata.Expected)
+		}
+	}
+}
+
+func TestHttpMonitorSpec_timeoutExceedsPeriod(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Timeout  *metav1.Duration
+		Expected bool
+	}{
+		{"no timeout", nil, false},
+		{"shorter", &metav1.Duration{Duration: time.Minute - time.Millisecond}, false},
+		{"equal", &metav1.Duration{Duration: time.Minute}, true},
+		{"longer", &metav1.Duration{Duration: time.Minute + time.Millisecond}, true},
+	}
+
+	for _, testdata := range tests {
+		monitor := &HttpMonitor{Spec: HttpMonitorSpec{Period: &metav1.Duration{Duration: time.Minute},
+			Requests: []HttpRequest{{Name: "a", Url: "https://test.com", Timeout: testdata.Timeout}}}}
+		if out := monitor.Spec.timeoutExceedsPeriod(monitor.Spec.Requests[0]); out != testdata.Expected {
+			t.Errorf("[%s] unexpected result. Got: %v, expected: %v", testdata.TestName, out, testdata.Expected)
+		}
+		rejected := monitor.checkInvariants() != nil
+		warned := false
+		for _, warning := range monitor.Lint(nil) {
+			if strings.Contains(warning, "not shorter than the period") {
+				warned = true
+			}
+		}
+		if rejected != testdata.Expected || warned != testdata.Expected {
+			t.Errorf("[%s] unexpected disagreement. Rejected: %v, warned: %v, expected: %v", testdata.TestName,
+				rejected, warned, testdata.Expected)
+		}
+	}
+}
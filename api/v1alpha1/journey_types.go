@@ -0,0 +1,236 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+// One step of a journey, run as a single run of an HttpMonitor
+type JourneyStage struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// The HttpMonitor in the same namespace to run. Set `suspend` on it to only run it as part of the journey.
+	// +kubebuilder:validation:MinLength=1
+	Monitor string `json:"monitor"`
+}
+
+// JourneySpec defines the desired state of Journey
+type JourneySpec struct {
+	// Variables available to every stage, overriding the environment of the stage's monitor
+	Environment map[string]string `json:"environment,omitempty"`
+
+	// Run in order. Variables taken from responses in a stage are available to the stages after it. The
+	// journey stops at the first stage that fails.
+	// +kubebuilder:validation:MinItems=1
+	Stages []JourneyStage `json:"stages"`
+
+	// How frequently to run the journey
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	Period *metav1.Duration `json:"period"`
+}
+
+// The outcome of one stage in the last run
+type JourneyStageStatus struct {
+	Name  string       `json:"name"`
+	State MonitorState `json:"state"`
+
+	// +kubebuilder:validation:Type=string
+	Duration metav1.Duration `json:"duration"`
+}
+
+// JourneyStatus defines the observed state of Journey
+type JourneyStatus struct {
+	LastExecution *metav1.Time `json:"last_execution,omitempty"`
+	LastFailure   *metav1.Time `json:"last_failure,omitempty"`
+
+	// Up, Degraded when only some stages succeeded, or Down when the first one failed
+	State MonitorState `json:"state,omitempty"`
+
+	// The stage that failed the last run, and why
+	FailedStage string `json:"failed_stage,omitempty"`
+	Error       string `json:"error,omitempty"`
+
+	// The stages that ran in the last run, in order
+	Stages []JourneyStageStatus `json:"stages,omitempty"`
+
+	Conditions []MonitorCondition `json:"conditions,omitempty"`
+}
+
+// Journey runs HttpMonitors in order as the stages of one flow, passing variables between them
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Failed Stage",type=string,JSONPath=`.status.failed_stage`
+// +kubebuilder:printcolumn:name="Last Execution",type=string,format=date-time,JSONPath=`.status.last_execution`
+type Journey struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   JourneySpec   `json:"spec,omitempty"`
+	Status JourneyStatus `json:"status,omitempty"`
+}
+
+// JourneyList contains a list of Journey
+// +kubebuilder:object:root=true
+type JourneyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Journey `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Journey{}, &JourneyList{})
+}
+
+// The outcome of one stage of a journey run
+// +kubebuilder:object:generate=false
+type JourneyStageResult struct {
+	Name    string
+	Monitor string
+
+	// Set if the stage's monitor could not be read
+	Err error
+
+	// The run of the stage's monitor, if it ran
+	Result *RunResult
+}
+
+// Why the stage failed, or nil if it succeeded
+func (s *JourneyStageResult) Failure() error {
+	if s.Err != nil {
+		return s.Err
+	}
+	if failure := s.Result.FirstFailure(); failure != nil {
+		return fmt.Errorf("%s: %w", failure.Name, failure.Err)
+	}
+	return nil
+}
+
+func (s *JourneyStageResult) duration() time.Duration {
+	if s.Result == nil {
+		return 0
+	}
+	return s.Result.Duration
+}
+
+// The outcome of a single run of a Journey
+// +kubebuilder:object:generate=false
+type JourneyResult struct {
+	Start    time.Time
+	Duration time.Duration
+
+	// The stages that ran, up to and including the first one that failed
+	Stages []*JourneyStageResult
+}
+
+// The stage that failed the run, if any
+func (r *JourneyResult) FirstFailure() *JourneyStageResult {
+	for _, stage := range r.Stages {
+		if stage.Failure() != nil {
+			return stage
+		}
+	}
+	return nil
+}
+
+func (r *JourneyResult) Failed() bool {
+	return r.FirstFailure() != nil
+}
+
+// Up when every stage succeeded, Down when the first one failed and Degraded otherwise
+func (r *JourneyResult) State() MonitorState {
+	switch failure := r.FirstFailure(); {
+	case failure == nil:
+		return MonitorStateUp
+	case failure == r.Stages[0]:
+		return MonitorStateDown
+	}
+	return MonitorStateDegraded
+}
+
+// The stages of the run, for status
+func (r *JourneyResult) StageStatus() []JourneyStageStatus {
+	var stages []JourneyStageStatus
+	for _, stage := range r.Stages {
+		state := MonitorStateDown
+		if stage.Result != nil && stage.Err == nil {
+			state = stage.Result.State()
+		}
+		stages = append(stages, JourneyStageStatus{
+			Name:     stage.Name,
+			State:    state,
+			Duration: metav1.Duration{Duration: stage.duration()},
+		})
+	}
+	return stages
+}
+
+// Run the stages in order, reading each stage's monitor with resolve just before it runs. Each stage's cleanup
+// runs when that stage finishes.
+func (j *Journey) Execute(resolve func(name string) (*HttpMonitor, error)) *JourneyResult {
+	result := &JourneyResult{Start: time.Now()}
+	shared := make(map[string]string)
+	for k, v := range j.Spec.Environment {
+		shared[k] = v
+	}
+
+	for _, stage := range j.Spec.Stages {
+		stageResult := &JourneyStageResult{Name: stage.Name, Monitor: stage.Monitor}
+		result.Stages = append(result.Stages, stageResult)
+
+		monitor, err := resolve(stage.Monitor)
+		if err != nil {
+			stageResult.Err = fmt.Errorf("could not read monitor %q: %w", stage.Monitor, err)
+			break
+		}
+		monitor = monitor.DeepCopy()
+		environment := make(map[string]string)
+		for k, v := range monitor.Spec.Environment {
+			environment[k] = v
+		}
+		for k, v := range shared {
+			environment[k] = v
+		}
+		monitor.Spec.Environment = environment
+
+		stageResult.Result = monitor.Execute()
+		if stageResult.Failure() != nil {
+			break
+		}
+		for k, v := range stageResult.Result.Variables {
+			shared[k] = v
+		}
+	}
+
+	result.Duration = time.Since(result.Start)
+	return result
+}
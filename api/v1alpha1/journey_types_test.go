@@ -0,0 +1,112 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJourney_Execute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			_, _ = w.Write([]byte(`{"token": "abc"}`))
+		case "/orders/abc":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	// No client timeout, so other tests still see the same worst case run durations
+	httpclient.Initialize(0)
+
+	monitor := func(name, path string, variables ...*Variable) *HttpMonitor {
+		return &HttpMonitor{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: HttpMonitorSpec{
+				Environment: map[string]string{"host": "unused.example.com"},
+				Requests: []HttpRequest{{Name: name, Method: http.MethodGet, Url: "{host}" + path,
+					ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, VariablesFromResponse: variables}},
+			},
+		}
+	}
+	monitors := map[string]*HttpMonitor{
+		"login":   monitor("login", "/login", &Variable{Name: "token", From: FromTypeBodyJson, JsonPath: "/token"}),
+		"orders":  monitor("orders", "/orders/{token}"),
+		"missing": monitor("missing", "/missing"),
+	}
+	resolve := func(name string) (*HttpMonitor, error) {
+		if m, ok := monitors[name]; ok {
+			return m, nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	tests := []struct {
+		TestName      string
+		Stages        []string
+		ExpectedState MonitorState
+		ExpectedRan   int
+		FailedStage   string
+	}{
+		{"up", []string{"login", "orders"}, MonitorStateUp, 2, ""},
+		{"degraded", []string{"login", "missing", "orders"}, MonitorStateDegraded, 2, "missing"},
+		{"down", []string{"missing", "login"}, MonitorStateDown, 1, "missing"},
+		{"unknown monitor", []string{"login", "unknown"}, MonitorStateDegraded, 2, "unknown"},
+		{"variables do not flow backwards", []string{"orders", "login"}, MonitorStateDown, 1, "orders"},
+	}
+
+	for _, testdata := range tests {
+		journey := &Journey{Spec: JourneySpec{Environment: map[string]string{"host": server.URL}}}
+		for _, name := range testdata.Stages {
+			journey.Spec.Stages = append(journey.Spec.Stages, JourneyStage{Name: name, Monitor: name})
+		}
+		result := journey.Execute(resolve)
+		if state := result.State(); state != testdata.ExpectedState {
+			t.Errorf("[%s] unexpected state. Got: %s, expected: %s", testdata.TestName, state, testdata.ExpectedState)
+		}
+		if len(result.Stages) != testdata.ExpectedRan {
+			t.Errorf("[%s] unexpected number of stages run. Got: %d, expected: %d", testdata.TestName, len(result.Stages), testdata.ExpectedRan)
+		}
+		failedStage := ""
+		if failure := result.FirstFailure(); failure != nil {
+			failedStage = failure.Name
+		}
+		if failedStage != testdata.FailedStage {
+			t.Errorf("[%s] unexpected failed stage. Got: %q, expected: %q", testdata.TestName, failedStage, testdata.FailedStage)
+		}
+	}
+	if monitors["login"].Spec.Environment["host"] != "unused.example.com" {
+		t.Errorf("the stage monitor was modified: %v", monitors["login"].Spec.Environment)
+	}
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// A decoded JWT. The signature is only checked by verifySignature.
+type jwt struct {
+	header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	claims       map[string]interface{}
+	claimsJson   []byte
+	signingInput string
+	signature    []byte
+}
+
+func decodeJwt(token string) (*jwt, error) {
+	token = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(token), "Bearer "))
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("not a JWT: expected three dot separated parts")
+	}
+	t := &jwt{signingInput: parts[0] + "." + parts[1]}
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT header: %w", err)
+	}
+	if err := json.Unmarshal(header, &t.header); err != nil {
+		return nil, fmt.Errorf("invalid JWT header: %w", err)
+	}
+	if t.claimsJson, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+	if err := json.Unmarshal(t.claimsJson, &t.claims); err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+	if t.signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, fmt.Errorf("invalid JWT signature: %w", err)
+	}
+	return t, nil
+}
+
+// Find the token in the response
+func (j *JwtCheck) token(resp *http.Response) (*jwt, error) {
+	v := &Variable{Name: "jwt", From: j.From, JsonPath: j.JsonPath}
+	if err := v.ParseFromResponse(resp); err != nil {
+		return nil, fmt.Errorf("failed to find JWT in response: %w", err)
+	}
+	return decodeJwt(v.Value)
+}
+
+// Check the token's signature and claims
+func (j *JwtCheck) verify(ctx context.Context, client *http.Client, resp *http.Response) error {
+	t, err := j.token(resp)
+	if err != nil {
+		return err
+	}
+	if j.JwksUrl != "" {
+		if err := t.verifySignature(ctx, client, j.JwksUrl); err != nil {
+			return err
+		}
+	}
+	return j.verifyClaims(t, time.Now())
+}
+
+func (j *JwtCheck) verifyClaims(t *jwt, now time.Time) error {
+	if j.Issuer != "" && t.claims["iss"] != j.Issuer {
+		return fmt.Errorf("JWT issuer is %v, expected %s", t.claims["iss"], j.Issuer)
+	}
+	if j.Audience != "" && !hasAudience(t.claims["aud"], j.Audience) {
+		return fmt.Errorf("JWT audience %v does not include %s", t.claims["aud"], j.Audience)
+	}
+	if nbf, ok := t.claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("JWT is not valid until %s", time.Unix(int64(nbf), 0).UTC())
+	}
+	if exp, ok := t.claims["exp"].(float64); ok {
+		expires := time.Unix(int64(exp), 0)
+		if !now.Before(expires) {
+			return fmt.Errorf("JWT expired at %s", expires.UTC())
+		}
+		if j.MinRemaining != nil && expires.Sub(now) < j.MinRemaining.Duration {
+			return fmt.Errorf("JWT expires in %s, sooner than the required %s", expires.Sub(now).Round(time.Second),
+				j.MinRemaining.Duration)
+		}
+	}
+	return nil
+}
+
+// aud is either a single string or a list of them
+func hasAudience(aud interface{}, audience string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == audience
+	case []interface{}:
+		for _, a := range v {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// A key from a JSON Web Key Set
+type jwk struct {
+	Kty string   `json:"kty"`
+	Kid string   `json:"kid"`
+	Use string   `json:"use"`
+	Crv string   `json:"crv"`
+	N   string   `json:"n"`
+	E   string   `json:"e"`
+	X   string   `json:"x"`
+	Y   string   `json:"y"`
+	X5c []string `json:"x5c"`
+}
+
+func fetchJwks(ctx context.Context, client *http.Client, url string) ([]jwk, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: got status %d", resp.StatusCode)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+	return set.Keys, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+// The hash each supported algorithm signs with
+var jwtHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+// Check the signature against the key with the token's kid, or the only key if the token has no kid
+func (t *jwt) verifySignature(ctx context.Context, client *http.Client, jwksUrl string) error {
+	hash, ok := jwtHashes[t.header.Alg]
+	if !ok {
+		return fmt.Errorf("unsupported JWT algorithm %q", t.header.Alg)
+	}
+	keys, err := fetchJwks(ctx, client, jwksUrl)
+	if err != nil {
+		return err
+	}
+	var key *jwk
+	for i := range keys {
+		if keys[i].Kid == t.header.Kid || (t.header.Kid == "" && len(keys) == 1) {
+			key = &keys[i]
+			break
+		}
+	}
+	if key == nil {
+		return fmt.Errorf("no key with kid %q in JWKS", t.header.Kid)
+	}
+	publicKey, err := key.publicKey()
+	if err != nil {
+		return fmt.Errorf("invalid key %q in JWKS: %w", key.Kid, err)
+	}
+
+	hasher := hash.New()
+	hasher.Write([]byte(t.signingInput))
+	digest := hasher.Sum(nil)
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		if strings.HasPrefix(t.header.Alg, "PS") {
+			err = rsa.VerifyPSS(pub, hash, digest, t.signature, nil)
+		} else if strings.HasPrefix(t.header.Alg, "RS") {
+			err = rsa.VerifyPKCS1v15(pub, hash, digest, t.signature)
+		} else {
+			err = fmt.Errorf("%s does not use an RSA key", t.header.Alg)
+		}
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(t.header.Alg, "ES") || len(t.signature) != 2*size {
+			err = errors.New("malformed ECDSA signature")
+		} else if !ecdsa.Verify(pub, digest, new(big.Int).SetBytes(t.signature[:size]), new(big.Int).SetBytes(t.signature[size:])) {
+			err = errors.New("ECDSA verification failed")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("JWT signature is invalid: %w", err)
+	}
+	return nil
+}
+
+// Set a jwt_claim variable from the claims of the request's token. The path is into the claims, like /sub.
+func (r *HttpRequest) parseJwtClaim(v *Variable, resp *http.Response) error {
+	if r.Jwt == nil {
+		return fmt.Errorf("variable %s is a jwt_claim, but the request has no jwt check", v.Name)
+	}
+	t, err := r.Jwt.token(resp)
+	if err != nil {
+		return err
+	}
+	return v.parseFromJsonBytes(t.claimsJson)
+}
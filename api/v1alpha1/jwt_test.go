@@ -0,0 +1,172 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func signTestJwt(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := jwtHashes[alg].New()
+	digest.Write([]byte(input))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, k, jwtHashes[alg], digest.Sum(nil))
+	case *ecdsa.PrivateKey:
+		r, s, _ := ecdsa.Sign(rand.Reader, k, digest.Sum(nil))
+		signature = append(padTo(r.Bytes(), 32), padTo(s.Bytes(), 32)...)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func padTo(b []byte, size int) []byte {
+	return append(make([]byte, size-len(b)), b...)
+}
+
+func TestJwtCheck_verify(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+	jwks := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecKey.X.Bytes()), "y": encode(ecKey.Y.Bytes())},
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwks)
+	}))
+	defer server.Close()
+
+	valid := map[string]interface{}{
+		"iss": "https://auth.example.com",
+		"aud": []string{"api", "web"},
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	expired := map[string]interface{}{"iss": "https://auth.example.com", "exp": time.Now().Add(-time.Minute).Unix()}
+
+	tests := []struct {
+		TestName  string
+		Token     string
+		Check     JwtCheck
+		ExpectErr bool
+	}{
+		{
+			TestName: "RS256 with claims",
+			Token:    signTestJwt(t, "RS256", "rsa", rsaKey, valid),
+			Check: JwtCheck{JwksUrl: server.URL, Issuer: "https://auth.example.com", Audience: "api",
+				MinRemaining: &metav1.Duration{Duration: 30 * time.Minute}},
+		},
+		{
+			TestName: "ES256",
+			Token:    signTestJwt(t, "ES256", "ec", ecKey, valid),
+			Check:    JwtCheck{JwksUrl: server.URL},
+		},
+		{
+			TestName:  "signed by another key",
+			Token:     signTestJwt(t, "RS256", "rsa", otherKey, valid),
+			Check:     JwtCheck{JwksUrl: server.URL},
+			ExpectErr: true,
+		},
+		{
+			TestName: "decoded without verification",
+			Token:    signTestJwt(t, "RS256", "rsa", otherKey, valid),
+			Check:    JwtCheck{Issuer: "https://auth.example.com"},
+		},
+		{
+			TestName:  "wrong audience",
+			Token:     signTestJwt(t, "RS256", "rsa", rsaKey, valid),
+			Check:     JwtCheck{Audience: "billing"},
+			ExpectErr: true,
+		},
+		{
+			TestName:  "expires too soon",
+			Token:     signTestJwt(t, "RS256", "rsa", rsaKey, valid),
+			Check:     JwtCheck{MinRemaining: &metav1.Duration{Duration: 2 * time.Hour}},
+			ExpectErr: true,
+		},
+		{
+			TestName:  "expired",
+			Token:     signTestJwt(t, "RS256", "rsa", rsaKey, expired),
+			Check:     JwtCheck{},
+			ExpectErr: true,
+		},
+	}
+
+	for _, testdata := range tests {
+		testdata.Check.From = FromTypeBodyJson
+		testdata.Check.JsonPath = "/access_token"
+		body, _ := json.Marshal(map[string]string{"access_token": testdata.Token})
+		resp := &http.Response{Body: ioutil.NopCloser(bytes.NewReader(body))}
+		err := testdata.Check.verify(context.Background(), server.Client(), resp)
+		if err == nil && testdata.ExpectErr {
+			t.Errorf("[%s] expected error but got none", testdata.TestName)
+		}
+		if err != nil && !testdata.ExpectErr {
+			t.Errorf("[%s] got unexpected err: %s", testdata.TestName, err)
+		}
+	}
+}
+
+func TestHttpRequest_parseJwtClaim(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	token := signTestJwt(t, "RS256", "", key, map[string]interface{}{"sub": "user-1", "roles": []string{"admin"}})
+	r := &HttpRequest{Jwt: &JwtCheck{From: FromTypeHeaders, JsonPath: "/Authorization"}}
+	resp := &http.Response{Header: http.Header{"Authorization": {"Bearer " + token}}}
+
+	sub := &Variable{Name: "userid", From: FromTypeJwtClaim, JsonPath: "/sub"}
+	role := &Variable{Name: "role", From: FromTypeJwtClaim, JsonPath: "/roles/0"}
+	for _, v := range []*Variable{sub, role} {
+		if err := r.parseJwtClaim(v, resp); err != nil {
+			t.Fatalf("got err while parsing claim %s: %s", v.JsonPath, err)
+		}
+	}
+	if sub.Value != "user-1" || role.Value != "admin" {
+		t.Errorf("unexpected claims: sub=%q role=%q", sub.Value, role.Value)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// The lang attribute of the html element, which screen readers use to pick a voice
+var htmlLangPattern = regexp.MustCompile(`(?is)<html\b[^>]*?\blang\s*=\s*["']?([A-Za-z0-9-]+)`)
+
+// Ask for a language with Accept-Language and check the response is in it, for sites that route or translate by
+// the visitor's locale
+type LanguageCheck struct {
+	// Sent as the Accept-Language header, like fr-CA, fr;q=0.8
+	// +kubebuilder:validation:MinLength=1
+	AcceptLanguage string `json:"accept_language"`
+
+	// The language the Content-Language header must name, like fr. A more specific language, like fr-CA,
+	// matches too, and so does a header that lists it among others
+	ContentLanguage string `json:"content_language,omitempty"`
+
+	// Text that must appear in the body, like a translated heading, for pages whose header is right but whose
+	// content fell back to another language. The comparison is case-sensitive
+	Markers []string `json:"markers,omitempty"`
+
+	// Also check the lang attribute of the html element against content_language
+	HtmlLang bool `json:"html_lang,omitempty"`
+}
+
+func (l *LanguageCheck) validate() error {
+	if l.HtmlLang && l.ContentLanguage == "" {
+		return fmt.Errorf("invalid language: html_lang is checked against content_language, which is not set")
+	}
+	return nil
+}
+
+func (l *LanguageCheck) apply(req *http.Request) {
+	if l == nil {
+		return
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header.Set("Accept-Language", l.AcceptLanguage)
+}
+
+func (l *LanguageCheck) verify(header http.Header, body []byte) error {
+	if l.ContentLanguage != "" {
+		served := header.Get("Content-Language")
+		if served == "" {
+			return fmt.Errorf("no Content-Language header, expected %s", l.ContentLanguage)
+		}
+		matched := false
+		for _, tag := range strings.Split(served, ",") {
+			matched = matched || languageMatches(strings.TrimSpace(tag), l.ContentLanguage)
+		}
+		if !matched {
+			return fmt.Errorf("Content-Language is %q, expected %s", served, l.ContentLanguage)
+		}
+	}
+	for _, marker := range l.Markers {
+		if !bytes.Contains(body, []byte(marker)) {
+			return fmt.Errorf("body does not contain %q, expected for %s", marker, l.AcceptLanguage)
+		}
+	}
+	if l.HtmlLang {
+		match := htmlLangPattern.FindSubmatch(body)
+		if match == nil {
+			return fmt.Errorf("the html element has no lang attribute, expected %s", l.ContentLanguage)
+		}
+		if !languageMatches(string(match[1]), l.ContentLanguage) {
+			return fmt.Errorf("the html element has lang %q, expected %s", match[1], l.ContentLanguage)
+		}
+	}
+	return nil
+}
+
+// Whether a language tag is the expected language or a more specific form of it, like fr-CA for fr
+func languageMatches(tag, expected string) bool {
+	return strings.EqualFold(tag, expected) || len(tag) > len(expected) &&
+		strings.EqualFold(tag[:len(expected)], expected) && tag[len(expected)] == '-'
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLanguageCheck_verify(t *testing.T) {
+	frenchPage := `<!DOCTYPE html><html class="no-js" lang="fr-CA"><head><title>Accueil</title></head></html>`
+	tests := []struct {
+		TestName string
+		Check    LanguageCheck
+		Served   string
+		Body     string
+		Err      string
+	}{
+		{"exact", LanguageCheck{ContentLanguage: "fr"}, "fr", "", ""},
+		{"more specific", LanguageCheck{ContentLanguage: "fr"}, "fr-CA", "", ""},
+		{"one of several", LanguageCheck{ContentLanguage: "fr"}, "en, FR", "", ""},
+		{"prefix of another language", LanguageCheck{ContentLanguage: "fr"}, "fur", "",
+			`Content-Language is "fur", expected fr`},
+		{"fell back", LanguageCheck{ContentLanguage: "fr"}, "en", "", `Content-Language is "en", expected fr`},
+		{"no header", LanguageCheck{ContentLanguage: "fr"}, "", "", "no Content-Language header, expected fr"},
+		{"marker", LanguageCheck{AcceptLanguage: "fr", Markers: []string{"Accueil"}}, "", frenchPage, ""},
+		{"missing marker", LanguageCheck{AcceptLanguage: "fr", Markers: []string{"Bienvenue"}}, "", frenchPage,
+			`body does not contain "Bienvenue", expected for fr`},
+		{"html lang", LanguageCheck{ContentLanguage: "fr", HtmlLang: true}, "fr", frenchPage, ""},
+		{"wrong html lang", LanguageCheck{ContentLanguage: "fr", HtmlLang: true}, "fr", `<html lang=en>`,
+			`the html element has lang "en", expected fr`},
+		{"no html lang", LanguageCheck{ContentLanguage: "fr", HtmlLang: true}, "fr", `<html><body lang="fr">`,
+			"the html element has no lang attribute, expected fr"},
+	}
+	for _, testdata := range tests {
+		header := http.Header{}
+		if testdata.Served != "" {
+			header.Set("Content-Language", testdata.Served)
+		}
+		err := testdata.Check.verify(header, []byte(testdata.Body))
+		if testdata.Err == "" && err != nil || testdata.Err != "" && (err == nil || !strings.Contains(err.Error(), testdata.Err)) {
+			t.Errorf("[%s] unexpected error %v", testdata.TestName, err)
+		}
+	}
+}
+
+func TestHttpRequest_BuildRequest_language(t *testing.T) {
+	request := HttpRequest{Method: http.MethodGet, Url: "https://example.com/",
+		Language: &LanguageCheck{AcceptLanguage: "fr-CA, fr;q=0.8"}}
+	req, err := request.BuildRequest()
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if got := req.Header.Get("Accept-Language"); got != "fr-CA, fr;q=0.8" {
+		t.Errorf("unexpected Accept-Language %q", got)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"time"
+)
+
+const defaultLatencyWindow = 100
+
+// The response times of the most recent runs of each request
+// +kubebuilder:object:generate=false
+type LatencyWindow struct {
+	size    int
+	order   []string
+	samples map[string][]time.Duration
+}
+
+// A window over the last size runs. A size of zero uses the default.
+func NewLatencyWindow(size int) *LatencyWindow {
+	if size <= 0 {
+		size = defaultLatencyWindow
+	}
+	return &LatencyWindow{size: size, samples: make(map[string][]time.Duration)}
+}
+
+// Add the response times from a run. Requests that never got a response are not counted, so timeouts and
+// refused connections do not skew the percentiles. Soaked requests report their own percentiles.
+func (w *LatencyWindow) Add(result *RunResult) {
+	for _, r := range result.Requests {
+		if r.StatusCode == 0 || r.Soak != nil {
+			continue
+		}
+		samples, known := w.samples[r.Name]
+		if !known {
+			w.order = append(w.order, r.Name)
+		}
+		samples = append(samples, r.Duration)
+		if len(samples) > w.size {
+			samples = samples[len(samples)-w.size:]
+		}
+		w.samples[r.Name] = samples
+	}
+}
+
+// Take the samples of another window, keeping only what fits in this one
+func (w *LatencyWindow) Merge(other *LatencyWindow) {
+	for _, name := range other.order {
+		samples := append(other.samples[name], w.samples[name]...)
+		if len(samples) > w.size {
+			samples = samples[len(samples)-w.size:]
+		}
+		if _, known := w.samples[name]; !known {
+			w.order = append(w.order, name)
+		}
+		w.samples[name] = samples
+	}
+}
+
+// How many response times the window holds, across all requests
+func (w *LatencyWindow) Samples() int {
+	count := 0
+	for _, samples := range w.samples {
+		count += len(samples)
+	}
+	return count
+}
+
+// The percentiles for each request, in the order the requests were first seen
+func (w *LatencyWindow) Percentiles() []RequestLatency {
+	out := make([]RequestLatency, 0, len(w.order))
+	for _, name := range w.order {
+		sorted := append([]time.Duration(nil), w.samples[name]...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		out = append(out, RequestLatency{
+			Name:    name,
+			Samples: len(sorted),
+			P50:     metav1.Duration{Duration: percentile(sorted, 50)},
+			P95:     metav1.Duration{Duration: percentile(sorted, 95)},
+			P99:     metav1.Duration{Duration: percentile(sorted, 99)},
+		})
+	}
+	return out
+}
+
+// Nearest-rank percentile of sorted samples, rounded for display
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1].Round(time.Millisecond)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyWindow_Percentiles(t *testing.T) {
+	window := NewLatencyWindow(10)
+	// 20 runs, only the last 10 (11ms to 20ms) stay in the window
+	for i := 1; i <= 20; i++ {
+		window.Add(&RunResult{Requests: []*RequestResult{
+			{Name: "login", StatusCode: 200, Duration: time.Duration(i) * time.Millisecond},
+			{Name: "timeout", Duration: time.Second},
+		}})
+	}
+
+	latencies := window.Percentiles()
+	if len(latencies) != 1 {
+		t.Fatalf("requests without a response should not be tracked, got %+v", latencies)
+	}
+	latency := latencies[0]
+	tests := []struct {
+		TestName string
+		Got      time.Duration
+		Expected time.Duration
+	}{
+		{"p50", latency.P50.Duration, 15 * time.Millisecond},
+		{"p95", latency.P95.Duration, 20 * time.Millisecond},
+		{"p99", latency.P99.Duration, 20 * time.Millisecond},
+	}
+	for _, testdata := range tests {
+		if testdata.Got != testdata.Expected {
+			t.Errorf("[%s] unexpected percentile. Got: %s, expected: %s", testdata.TestName, testdata.Got, testdata.Expected)
+		}
+	}
+	if latency.Samples != 10 {
+		t.Errorf("unexpected number of samples. Got: %d, expected: 10", latency.Samples)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"regexp"
+	"sort"
+)
+
+const (
+	// Producers for variables that do not come from a request
+	lineageEnvironment = "environment"
+	lineageGenerated   = "generated"
+)
+
+// A {name} placeholder in a request
+var placeholderRegex = regexp.MustCompile(`\{([A-Za-z0-9_.\-]+)\}`)
+
+// Tracks which request produced each variable during a run, and which requests consumed it
+type lineageTracker struct {
+	entries map[string]*VariableLineage
+}
+
+func newLineageTracker() *lineageTracker {
+	return &lineageTracker{entries: make(map[string]*VariableLineage)}
+}
+
+func (l *lineageTracker) entry(name string) *VariableLineage {
+	if entry, ok := l.entries[name]; ok {
+		return entry
+	}
+	entry := &VariableLineage{Name: name}
+	l.entries[name] = entry
+	return entry
+}
+
+// Record that a variable became available. When several requests produce the same name, the first one wins,
+// matching how variables are replaced.
+func (l *lineageTracker) produced(name, by string) {
+	if entry := l.entry(name); entry.ProducedBy == "" {
+		entry.ProducedBy = by
+	}
+}
+
+// Record the variables a request refers to. Placeholders for variables that were never produced show up
+// without a producer, which usually explains a broken chain.
+func (l *lineageTracker) consumed(r *HttpRequest) {
+	for _, name := range r.placeholders() {
+		entry := l.entry(name)
+		entry.ConsumedBy = append(entry.ConsumedBy, r.Name)
+	}
+}
+
+// The lineage of every variable, sorted by name so status does not change between identical runs
+func (l *lineageTracker) lineage() []VariableLineage {
+	out := make([]VariableLineage, 0, len(l.entries))
+	for _, entry := range l.entries {
+		out = append(out, *entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// The names of the variables a request refers to, sorted
+func (r *HttpRequest) placeholders() []string {
+	texts := []string{r.Url, r.Body, r.OnlyIf, r.SkipIf}
+	for _, values := range r.Headers {
+		texts = append(texts, values...)
+	}
+	for _, values := range r.QueryParams {
+		texts = append(texts, values...)
+	}
+	if r.BodyFrom != nil {
+		texts = append(texts, string(r.BodyFrom.Loaded))
+	}
+	if r.Form != nil {
+		for _, field := range r.Form.Fields {
+			texts = append(texts, field.Value, field.FileName)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	if r.ForEach != nil {
+		// The item is set by the request itself, and the list is used like any placeholder
+		seen[r.ForEach.as()] = true
+		seen[r.ForEach.Variable] = true
+		names = append(names, r.ForEach.Variable)
+	}
+	for _, text := range texts {
+		for _, match := range placeholderRegex.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestLineageTracker(t *testing.T) {
+	tracker := newLineageTracker()
+	tracker.produced("USERNAME", lineageEnvironment)
+	tracker.consumed(&HttpRequest{
+		Name: "create user",
+		Url:  "https://test.com/users",
+		Body: `{"username": "{USERNAME}"}`,
+	})
+	tracker.produced("userid", "create user")
+	tracker.consumed(&HttpRequest{
+		Name:    "get user",
+		Url:     "https://test.com/users/{userid}",
+		Headers: http.Header{"X-Session": []string{"{session}"}},
+	})
+
+	expected := []VariableLineage{
+		{Name: "USERNAME", ProducedBy: lineageEnvironment, ConsumedBy: []string{"create user"}},
+		{Name: "session", ConsumedBy: []string{"get user"}},
+		{Name: "userid", ProducedBy: "create user", ConsumedBy: []string{"get user"}},
+	}
+	if out := tracker.lineage(); !reflect.DeepEqual(out, expected) {
+		t.Errorf("unexpected lineage.\nGot:      %+v\nExpected: %+v", out, expected)
+	}
+}
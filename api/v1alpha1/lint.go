@@ -0,0 +1,181 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"sort"
+	"strings"
+)
+
+// Variables every request can use without anything producing them
+var generatedVariables = []string{"random-8", "random-16"}
+
+// Find likely authoring mistakes in the spec: variables nothing uses, requests that refer to variables nothing
+// produces, requests that are never sent, timeouts or runs longer than the period, and cleanup linked to requests that
+// do not exist. globals are the names of the --set-var variables, which are available to every request.
+func (h *HttpMonitor) Lint(globals []string) []string {
+	var warnings []string
+
+	available := make(map[string]bool)
+	for _, name := range generatedVariables {
+		available[name] = true
+	}
+	for _, name := range globals {
+		available[name] = true
+	}
+	for name := range h.Spec.Environment {
+		available[name] = true
+	}
+
+	used := make(map[string]bool)
+	produced := make(map[string]string)
+	requestNames := make(map[string]bool)
+	neverSent := make(map[string]bool)
+	check := func(r HttpRequest, kind string) {
+		if reason := r.alwaysSkipped(); reason != "" {
+			warnings = append(warnings, fmt.Sprintf("%s %q is never sent, because its %s", kind, r.Name, reason))
+			if kind == "request" {
+				neverSent[r.Name] = true
+			}
+		}
+		for _, name := range r.placeholders() {
+			used[name] = true
+			if !available[name] {
+				warnings = append(warnings, fmt.Sprintf("%s %q refers to {%s}, which no earlier request produces", kind, r.Name, name))
+			}
+		}
+		if h.Spec.timeoutExceedsPeriod(r) {
+			warnings = append(warnings, fmt.Sprintf("%s %q has a timeout of %s, not shorter than the period of %s",
+				kind, r.Name, r.Timeout.Duration, h.Spec.Period.Duration))
+		}
+		if r.MaxDuration != nil && r.MaxDuration.Duration >= r.timeout() {
+			warnings = append(warnings, fmt.Sprintf("%s %q has a max_duration of %s, but times out after %s anyway",
+				kind, r.Name, r.MaxDuration.Duration, r.timeout()))
+		}
+		if r.Script != nil && len(r.VariablesFromResponse) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s %q runs a script, which has no response to take variables from", kind, r.Name))
+		}
+		if r.Accessibility != nil && len(r.VariablesFromResponse) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s %q runs an accessibility audit, which has no response to take variables from", kind, r.Name))
+		}
+		if r.Performance != nil && len(r.VariablesFromResponse) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s %q runs a performance audit, which has no response to take variables from", kind, r.Name))
+		}
+		if r.Tls != nil && r.Tls.InsecureSkipVerify && httpclient.StrictCrypto() {
+			warnings = append(warnings, fmt.Sprintf("%s %q has tls.insecure_skip_verify, which the controller's strict crypto mode ignores", kind, r.Name))
+		}
+		if r.MinCertificateRemaining != nil && strings.HasPrefix(strings.ToLower(r.Url), "http://") {
+			warnings = append(warnings, fmt.Sprintf("%s %q has a min_certificate_remaining, but its url is plain HTTP", kind, r.Name))
+		}
+	}
+
+	for _, r := range h.Spec.Requests {
+		if requestNames[r.Name] {
+			warnings = append(warnings, fmt.Sprintf("request name %q is used more than once", r.Name))
+		}
+		requestNames[r.Name] = true
+		check(r, "request")
+		for _, variable := range r.VariablesFromResponse {
+			available[variable.Name] = true
+			produced[variable.Name] = r.Name
+		}
+	}
+	// Requests that depend on a request that is never sent are skipped along with it
+	for changed := true; changed; {
+		changed = false
+		for _, r := range h.Spec.Requests {
+			if neverSent[r.Name] {
+				continue
+			}
+			for _, name := range r.DependsOn {
+				if neverSent[name] {
+					warnings = append(warnings, fmt.Sprintf("request %q is never sent, because it depends on %q, which is never sent",
+						r.Name, name))
+					neverSent[r.Name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	cleanupNames := make(map[string]bool)
+	for _, r := range h.Spec.Cleanup {
+		if cleanupNames[r.Name] {
+			warnings = append(warnings, fmt.Sprintf("cleanup request name %q is used more than once", r.Name))
+		}
+		cleanupNames[r.Name] = true
+		check(r, "cleanup request")
+		if r.Script != nil {
+			warnings = append(warnings, fmt.Sprintf("cleanup request %q runs a script, which is only supported for requests", r.Name))
+		}
+		if r.Accessibility != nil {
+			warnings = append(warnings, fmt.Sprintf("cleanup request %q runs an accessibility audit, which is only supported for requests", r.Name))
+		}
+		if r.Performance != nil {
+			warnings = append(warnings, fmt.Sprintf("cleanup request %q runs a performance audit, which is only supported for requests", r.Name))
+		}
+		if r.CleanupFor != "" && !requestNames[r.CleanupFor] {
+			warnings = append(warnings, fmt.Sprintf("cleanup request %q is for %q, which is not a request", r.Name, r.CleanupFor))
+		}
+		if neverSent[r.CleanupFor] {
+			warnings = append(warnings, fmt.Sprintf("cleanup request %q is for %q, which is never sent, so it never runs either",
+				r.Name, r.CleanupFor))
+		}
+	}
+
+	if rule := h.Spec.VersionSkew; rule != nil {
+		used[rule.Variable] = true
+		if _, ok := produced[rule.Variable]; !ok {
+			warnings = append(warnings, fmt.Sprintf("version_skew compares {%s}, which no request produces", rule.Variable))
+		}
+	}
+	for _, r := range h.Spec.Requests {
+		for _, variable := range r.VariablesFromResponse {
+			if !used[variable.Name] && produced[variable.Name] == r.Name {
+				warnings = append(warnings, fmt.Sprintf("variable %q from request %q is never used", variable.Name, r.Name))
+			}
+		}
+	}
+	if h.Spec.Period != nil {
+		if worst := h.WorstCaseRunDuration(sharedClientTimeout()); worst > h.Spec.Period.Duration {
+			warnings = append(warnings, fmt.Sprintf("a run can take up to %s, longer than the period of %s",
+				worst, h.Spec.Period.Duration))
+		}
+	}
+	var environment []string
+	for name := range h.Spec.Environment {
+		environment = append(environment, name)
+	}
+	sort.Strings(environment)
+	for _, name := range environment {
+		if !used[name] {
+			warnings = append(warnings, fmt.Sprintf("environment variable %q is never used", name))
+		}
+	}
+	return warnings
+}
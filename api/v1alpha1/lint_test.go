@@ -0,0 +1,122 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHttpMonitor_Lint(t *testing.T) {
+	monitor := &HttpMonitor{
+		Spec: HttpMonitorSpec{
+			Period:      &metav1.Duration{Duration: time.Minute},
+			Environment: map[string]string{"EMAIL": "test@example.com"},
+			Requests: []HttpRequest{
+				{
+					Name:                  "create user",
+					Url:                   "https://test.com/users?token={TOKEN}",
+					Timeout:               &metav1.Duration{Duration: 2 * time.Minute},
+					VariablesFromResponse: VariableList{{Name: "userid"}, {Name: "etag"}},
+				},
+				{Name: "get user", Url: "https://test.com/users/{userid}?session={session}"},
+			},
+			Cleanup: []HttpRequest{
+				{Name: "delete post", CleanupFor: "create post", Url: "http://test.com/posts/{random-8}",
+					MinCertificateRemaining: &metav1.Duration{Duration: 720 * time.Hour}},
+			},
+		},
+	}
+
+	expected := []string{
+		`request "create user" has a timeout of 2m0s, not shorter than the period of 1m0s`,
+		`request "get user" refers to {session}, which no earlier request produces`,
+		`cleanup request "delete post" has a min_certificate_remaining, but its url is plain HTTP`,
+		`cleanup request "delete post" is for "create post", which is not a request`,
+		`variable "etag" from request "create user" is never used`,
+		`a run can take up to 2m10s, longer than the period of 1m0s`,
+		`environment variable "EMAIL" is never used`,
+	}
+	if out := monitor.Lint([]string{"TOKEN"}); !reflect.DeepEqual(out, expected) {
+		t.Errorf("unexpected warnings.\nGot:      %q\nExpected: %q", out, expected)
+	}
+
+	// Comparing a variable with another monitor uses it
+	monitor.Spec.VersionSkew = &VersionSkewRule{Monitor: "production", Variable: "etag"}
+	for _, warning := range monitor.Lint([]string{"TOKEN"}) {
+		if warning == expected[4] {
+			t.Errorf("unexpected warning for a variable version_skew compares: %q", warning)
+		}
+	}
+	monitor.Spec.VersionSkew.Variable = "version"
+	if out := monitor.Lint([]string{"TOKEN"}); len(out) != len(expected)+1 ||
+		out[4] != "version_skew compares {version}, which no request produces" {
+		t.Errorf("unexpected warnings for a variable nothing produces: %q", out)
+	}
+}
+
+func TestHttpMonitor_Lint_neverSent(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Spec     HttpMonitorSpec
+		Expected []string
+	}{
+		{"conditions on variables", HttpMonitorSpec{
+			Environment: map[string]string{"feature": "on"},
+			Requests:    []HttpRequest{{Name: "a", OnlyIf: "{feature} == on"}},
+		}, nil},
+		{"constant only_if", HttpMonitorSpec{
+			Requests: []HttpRequest{{Name: "a", OnlyIf: "false"}, {Name: "b"}},
+		}, []string{`request "a" is never sent, because its only_if "false" does not hold`}},
+		{"constant skip_if", HttpMonitorSpec{
+			Environment: map[string]string{"feature": "on"},
+			Requests:    []HttpRequest{{Name: "a", OnlyIf: "{feature}", SkipIf: "1 < 2"}},
+		}, []string{`request "a" is never sent, because its skip_if "1 < 2" holds`}},
+		{"dependencies and cleanup", HttpMonitorSpec{
+			Requests: []HttpRequest{
+				{Name: "c", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"a"}},
+				{Name: "a", OnlyIf: "1 == 2"},
+				{Name: "d"},
+			},
+			Cleanup: []HttpRequest{{Name: "delete", CleanupFor: "c"}, {Name: "other", CleanupFor: "d"}},
+		}, []string{
+			`request "a" is never sent, because its only_if "1 == 2" does not hold`,
+			`request "b" is never sent, because it depends on "a", which is never sent`,
+			`request "c" is never sent, because it depends on "b", which is never sent`,
+			`cleanup request "delete" is for "c", which is never sent, so it never runs either`,
+		}},
+	}
+
+	for _, testdata := range tests {
+		monitor := &HttpMonitor{Spec: testdata.Spec}
+		if out := monitor.Lint(nil); !reflect.DeepEqual(out, testdata.Expected) {
+			t.Errorf("[%s] unexpected warnings.\nGot:      %q\nExpected: %q", testdata.TestName, out, testdata.Expected)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/go-logr/logr"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Raise the log verbosity of a single monitor's runs, like -v would for the whole controller. Both annotations
+// are needed, so verbose logging cannot be left on by accident.
+const (
+	LogVerbosityAnnotation      = "monitoring.raisingthefloor.org/log-verbosity"
+	LogVerbosityUntilAnnotation = "monitoring.raisingthefloor.org/log-verbosity-until"
+
+	// The furthest ahead log-verbosity-until may be
+	maxLogVerbosityWindow = 24 * time.Hour
+)
+
+type logVerbosity struct {
+	level int
+	until time.Time
+}
+
+var (
+	logVerbosities     = make(map[string]logVerbosity)
+	logVerbositiesLock sync.Mutex
+)
+
+// Read the verbosity annotations. A level of 0 with no error means they are not set.
+func LogVerbosityFrom(annotations map[string]string, now time.Time) (int, time.Time, error) {
+	value, found := annotations[LogVerbosityAnnotation]
+	if !found {
+		return 0, time.Time{}, nil
+	}
+	level, err := strconv.Atoi(value)
+	if err != nil || level < 0 {
+		return 0, time.Time{}, fmt.Errorf("%s must be a non-negative number, not %q", LogVerbosityAnnotation, value)
+	}
+	until, err := time.Parse(time.RFC3339, annotations[LogVerbosityUntilAnnotation])
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("%s must be an RFC 3339 time: %w", LogVerbosityUntilAnnotation, err)
+	}
+	if until.After(now.Add(maxLogVerbosityWindow)) {
+		return 0, time.Time{}, fmt.Errorf("%s may be at most %s away", LogVerbosityUntilAnnotation, maxLogVerbosityWindow)
+	}
+	return level, until, nil
+}
+
+// Log the runs of the monitor with the given namespace/name at the given verbosity until the given time.
+// A level of 0 goes back to the controller's verbosity. Returns whether anything changed.
+func SetLogVerbosity(key string, level int, until time.Time) bool {
+	logVerbositiesLock.Lock()
+	defer logVerbositiesLock.Unlock()
+	previous, found := logVerbosities[key]
+	if level <= 0 {
+		delete(logVerbosities, key)
+		return found
+	}
+	logVerbosities[key] = logVerbosity{level: level, until: until}
+	return !found || previous.level != level || !previous.until.Equal(until)
+}
+
+// The raised verbosity for a monitor, or 0 if it is not raised or the raise has expired
+func raisedLogVerbosity(key string, now time.Time) int {
+	logVerbositiesLock.Lock()
+	defer logVerbositiesLock.Unlock()
+	v, found := logVerbosities[key]
+	if !found || !now.Before(v.until) {
+		return 0
+	}
+	return v.level
+}
+
+// Logs V(level) messages at the default verbosity for levels up to verbosity, tagged with their level, and
+// leaves the rest to the underlying logger
+type verboseLogger struct {
+	logr.Logger
+	verbosity int
+}
+
+func withLogVerbosity(logger logr.Logger, verbosity int) logr.Logger {
+	if verbosity <= 0 {
+		return logger
+	}
+	return verboseLogger{Logger: logger, verbosity: verbosity}
+}
+
+func (l verboseLogger) V(level int) logr.InfoLogger {
+	if level <= l.verbosity {
+		return l.Logger.WithValues("v", level)
+	}
+	return l.Logger.V(level)
+}
+
+func (l verboseLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return verboseLogger{Logger: l.Logger.WithValues(keysAndValues...), verbosity: l.verbosity}
+}
+
+func (l verboseLogger) WithName(name string) logr.Logger {
+	return verboseLogger{Logger: l.Logger.WithName(name), verbosity: l.verbosity}
+}
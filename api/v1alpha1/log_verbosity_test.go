@@ -0,0 +1,102 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"github.com/go-logr/logr"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLogVerbosityFrom(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		TestName      string
+		Annotations   map[string]string
+		ExpectedLevel int
+		ExpectedErr   bool
+	}{
+		{"not set", map[string]string{}, 0, false},
+		{"set", map[string]string{LogVerbosityAnnotation: "4", LogVerbosityUntilAnnotation: "2020-06-01T14:00:00Z"}, 4, false},
+		{"no end", map[string]string{LogVerbosityAnnotation: "4"}, 0, true},
+		{"too far away", map[string]string{LogVerbosityAnnotation: "4", LogVerbosityUntilAnnotation: "2020-06-03T12:00:00Z"}, 0, true},
+		{"not a number", map[string]string{LogVerbosityAnnotation: "debug", LogVerbosityUntilAnnotation: "2020-06-01T14:00:00Z"}, 0, true},
+	}
+
+	for _, testdata := range tests {
+		level, _, err := LogVerbosityFrom(testdata.Annotations, now)
+		if (err != nil) != testdata.ExpectedErr || level != testdata.ExpectedLevel {
+			t.Errorf("[%s] unexpected verbosity. Got: %d %v, expected: %d, error: %t", testdata.TestName, level, err,
+				testdata.ExpectedLevel, testdata.ExpectedErr)
+		}
+	}
+
+	SetLogVerbosity("default/orders", 4, now.Add(time.Hour))
+	if out := raisedLogVerbosity("default/orders", now); out != 4 {
+		t.Errorf("unexpected verbosity before expiry: %d", out)
+	}
+	if out := raisedLogVerbosity("default/orders", now.Add(2*time.Hour)); out != 0 {
+		t.Errorf("unexpected verbosity after expiry: %d", out)
+	}
+	SetLogVerbosity("default/orders", 0, time.Time{})
+}
+
+// Records which messages would be written by a logger at the default verbosity
+type recordingLogger struct {
+	logr.Logger
+	level   int
+	written *[]string
+}
+
+func (l recordingLogger) Info(msg string, _ ...interface{}) {
+	if l.level == 0 {
+		*l.written = append(*l.written, msg)
+	}
+}
+
+func (l recordingLogger) V(level int) logr.InfoLogger {
+	return recordingLogger{level: level, written: l.written}
+}
+
+func (l recordingLogger) WithValues(...interface{}) logr.Logger { return l }
+func (l recordingLogger) WithName(string) logr.Logger           { return l }
+func (l recordingLogger) Error(error, string, ...interface{})   {}
+func (l recordingLogger) Enabled() bool                         { return l.level == 0 }
+
+func TestVerboseLogger(t *testing.T) {
+	var written []string
+	logger := withLogVerbosity(recordingLogger{written: &written}, 2).WithName("runner").WithValues("name", "orders")
+	logger.Info("info")
+	logger.V(1).Info("v1")
+	logger.V(2).Info("v2")
+	logger.V(3).Info("v3")
+
+	if expected := []string{"info", "v1", "v2"}; !reflect.DeepEqual(written, expected) {
+		t.Errorf("unexpected messages. Got: %v, expected: %v", written, expected)
+	}
+}
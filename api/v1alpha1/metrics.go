@@ -22,3 +22,55 @@ func HandleMetrics(m *HttpMonitor, req HttpRequest, resp *http.Response) {
 		req.Name,
 		stringStatus).Inc()
 }
+
+// Count failed requests by what went wrong
+func HandleFailureMetrics(m *HttpMonitor, req HttpRequest, result *RequestResult) {
+	category := string(result.Category)
+	if category == "" {
+		category = "Other"
+	}
+	metrics.CrdRequestFailureCounter.WithLabelValues(
+		"HttpMonitor/v1alpha1",
+		fmt.Sprintf("%s/%s", m.Namespace, m.Name),
+		req.Name,
+		category).Inc()
+}
+
+// Count the assertions that did not hold for a request in observe-only mode
+func HandleViolationMetrics(m *HttpMonitor, req HttpRequest, result *RequestResult) {
+	for _, violation := range result.Violations {
+		metrics.CrdAssertionViolationCounter.WithLabelValues(
+			"HttpMonitor/v1alpha1",
+			fmt.Sprintf("%s/%s", m.Namespace, m.Name),
+			req.Name,
+			violation.Assertion).Inc()
+	}
+}
+
+// Count a retry of a request, by the kind of failure that caused it
+func HandleRetryMetrics(m *HttpMonitor, req HttpRequest, condition RetryCondition) {
+	metrics.CrdRequestRetryCounter.WithLabelValues(
+		"HttpMonitor/v1alpha1",
+		fmt.Sprintf("%s/%s", m.Namespace, m.Name),
+		req.Name,
+		string(condition)).Inc()
+}
+
+// Record what a performance audit measured, even when the page was over budget
+func HandlePerformanceMetrics(m *HttpMonitor, req HttpRequest, result *RequestResult) {
+	if result.Performance == nil {
+		return
+	}
+	crd := fmt.Sprintf("%s/%s", m.Namespace, m.Name)
+	metrics.CrdPageTtfbGauge.WithLabelValues("HttpMonitor/v1alpha1", crd, req.Name).Set(result.Performance.Ttfb.Seconds())
+	metrics.CrdPageWeightGauge.WithLabelValues("HttpMonitor/v1alpha1", crd, req.Name).Set(float64(result.Performance.PageWeight))
+	metrics.CrdPageRequestsGauge.WithLabelValues("HttpMonitor/v1alpha1", crd, req.Name).Set(float64(result.Performance.Requests))
+}
+
+// Record how long a wake-up request took
+func HandleColdStartMetrics(m *HttpMonitor, req HttpRequest, result *RequestResult) {
+	metrics.CrdColdStartHistogram.WithLabelValues(
+		"HttpMonitor/v1alpha1",
+		fmt.Sprintf("%s/%s", m.Namespace, m.Name),
+		req.Name).Observe(result.Duration.Seconds())
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+)
+
+type ModbusRegisterType string
+
+var (
+	ModbusHoldingRegister ModbusRegisterType = "holding"
+	ModbusInputRegister   ModbusRegisterType = "input"
+)
+
+// Function codes for reading each register type
+var modbusReadFunctions = map[ModbusRegisterType]byte{
+	ModbusHoldingRegister: 0x03,
+	ModbusInputRegister:   0x04,
+}
+
+// What the exception codes a device answers with mean
+var modbusExceptions = map[byte]string{
+	0x01: "illegal function",
+	0x02: "illegal data address",
+	0x03: "illegal data value",
+	0x04: "server device failure",
+	0x05: "acknowledge",
+	0x06: "server device busy",
+	0x08: "memory parity error",
+	0x0a: "gateway path unavailable",
+	0x0b: "gateway target device failed to respond",
+}
+
+// Read registers from a Modbus TCP device instead of sending an HTTP request. The url names the device, like
+// modbus://plc.example.com:502. Fails when the device answers with an exception or a register is outside its
+// bounds.
+type ModbusCheck struct {
+	// The unit to address, for devices behind a gateway. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=255
+	UnitId int `json:"unit_id,omitempty"`
+
+	// Defaults to holding
+	// +kubebuilder:validation:Enum=holding;input
+	RegisterType ModbusRegisterType `json:"register_type,omitempty"`
+
+	// The first register, counting from 0
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Address int `json:"address"`
+
+	// How many registers to read. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=125
+	Count int `json:"count,omitempty"`
+
+	// Whether registers hold signed numbers
+	Signed bool `json:"signed,omitempty"`
+
+	// Bounds for every register read. Both are inclusive.
+	Min *int64 `json:"min,omitempty"`
+	Max *int64 `json:"max,omitempty"`
+}
+
+func (c *ModbusCheck) unitId() byte {
+	if c.UnitId == 0 {
+		return 1
+	}
+	return byte(c.UnitId)
+}
+
+func (c *ModbusCheck) registerType() ModbusRegisterType {
+	if c.RegisterType == "" {
+		return ModbusHoldingRegister
+	}
+	return c.RegisterType
+}
+
+func (c *ModbusCheck) count() int {
+	if c.Count == 0 {
+		return 1
+	}
+	return c.Count
+}
+
+// Run the check instead of sending an HTTP request
+func (r *HttpRequest) checkModbus() *RequestResult {
+	result := &RequestResult{Name: r.Name, Url: r.AvailableVariables.newReplacer().Replace(r.Url), Attempts: 1}
+	start := time.Now()
+	_, addr, err := hostAndAddress(result.Url, "502")
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout())
+		var registers []int64
+		if registers, err = r.Modbus.read(ctx, addr); err == nil {
+			err = r.Modbus.evaluate(registers)
+		}
+		cancel()
+	}
+	result.Err = err
+	result.Duration = time.Since(start)
+	result.ResponseTime = result.Duration
+	return result
+}
+
+// Read the registers, in order
+func (c *ModbusCheck) read(ctx context.Context, addr string) ([]int64, error) {
+	function, ok := modbusReadFunctions[c.registerType()]
+	if !ok {
+		return nil, fmt.Errorf("unknown register type %q", c.RegisterType)
+	}
+	conn, err := dialTcp(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// The MBAP header: transaction, protocol 0, the length of what follows and the unit
+	transaction := uint16(rand.Intn(1 << 16))
+	request := make([]byte, 12)
+	binary.BigEndian.PutUint16(request[0:], transaction)
+	binary.BigEndian.PutUint16(request[4:], 6)
+	request[6] = c.unitId()
+	request[7] = function
+	binary.BigEndian.PutUint16(request[8:], uint16(c.Address))
+	binary.BigEndian.PutUint16(request[10:], uint16(c.count()))
+	if _, err := conn.Write(request); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, fmt.Errorf("no answer from the device: %w", err)
+	}
+	if binary.BigEndian.Uint16(header[0:]) != transaction || binary.BigEndian.Uint16(header[2:]) != 0 {
+		return nil, errors.New("the answer is not a Modbus TCP response to the request")
+	}
+	length := int(binary.BigEndian.Uint16(header[4:]))
+	if length < 3 || length > 254 {
+		return nil, fmt.Errorf("the answer has an invalid length of %d", length)
+	}
+	pdu := make([]byte, length-1)
+	if _, err := io.ReadFull(conn, pdu); err != nil {
+		return nil, fmt.Errorf("the answer was cut short: %w", err)
+	}
+
+	if pdu[0] == function|0x80 {
+		code := pdu[1]
+		if meaning, ok := modbusExceptions[code]; ok {
+			return nil, fmt.Errorf("the device answered with exception %d (%s)", code, meaning)
+		}
+		return nil, fmt.Errorf("the device answered with exception %d", code)
+	}
+	if pdu[0] != function || len(pdu) < 2 || int(pdu[1]) != 2*c.count() || len(pdu) != 2+int(pdu[1]) {
+		return nil, errors.New("the answer does not hold the registers that were asked for")
+	}
+	registers := make([]int64, c.count())
+	for i := range registers {
+		value := binary.BigEndian.Uint16(pdu[2+2*i:])
+		if c.Signed {
+			registers[i] = int64(int16(value))
+		} else {
+			registers[i] = int64(value)
+		}
+	}
+	return registers, nil
+}
+
+// Check every register against the bounds
+func (c *ModbusCheck) evaluate(registers []int64) error {
+	for i, value := range registers {
+		address := c.Address + i
+		if c.Min != nil && value < *c.Min {
+			return fmt.Errorf("register %d is %d, less than the minimum of %d", address, value, *c.Min)
+		}
+		if c.Max != nil && value > *c.Max {
+			return fmt.Errorf("register %d is %d, more than the maximum of %d", address, value, *c.Max)
+		}
+	}
+	return nil
+}
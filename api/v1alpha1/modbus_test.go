@@ -0,0 +1,102 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+)
+
+// Answers reads of holding registers 100 and 101 with 42 and 0xfffe, and any other read with an exception
+func modbusServer(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				request := make([]byte, 12)
+				if _, err := io.ReadFull(conn, request); err != nil {
+					return
+				}
+				address, count := binary.BigEndian.Uint16(request[8:]), binary.BigEndian.Uint16(request[10:])
+				var pdu []byte
+				if request[7] == 0x03 && address >= 100 && int(address)+int(count) <= 102 {
+					pdu = []byte{0x03, byte(2 * count)}
+					for _, value := range []uint16{42, 0xfffe}[address-100 : address-100+count] {
+						pdu = append(pdu, byte(value>>8), byte(value))
+					}
+				} else {
+					pdu = []byte{request[7] | 0x80, 0x02}
+				}
+				response := append(append([]byte{}, request[:4]...), 0, byte(len(pdu)+1), request[6])
+				_, _ = conn.Write(append(response, pdu...))
+			}()
+		}
+	}()
+	return listener
+}
+
+func TestHttpRequest_checkModbus(t *testing.T) {
+	server := modbusServer(t)
+	defer server.Close()
+	low, high := int64(40), int64(100)
+
+	tests := []struct {
+		TestName string
+		Check    ModbusCheck
+		Expected string
+	}{
+		{"within bounds", ModbusCheck{Address: 100, Min: &low, Max: &high}, ""},
+		{"above the maximum", ModbusCheck{Address: 100, Count: 2, Max: &high},
+			"register 101 is 65534, more than the maximum of 100"},
+		{"signed", ModbusCheck{Address: 101, Signed: true, Min: &low}, "register 101 is -2, less than the minimum of 40"},
+		{"exception", ModbusCheck{Address: 7}, "the device answered with exception 2 (illegal data address)"},
+		{"input registers", ModbusCheck{Address: 100, RegisterType: ModbusInputRegister},
+			"the device answered with exception 2 (illegal data address)"},
+	}
+
+	for _, testdata := range tests {
+		check := testdata.Check
+		request := &HttpRequest{Name: testdata.TestName, Url: "modbus://" + server.Addr().String(), Modbus: &check}
+		result := request.checkModbus()
+		out := ""
+		if result.Err != nil {
+			out = result.Err.Error()
+		}
+		if out != testdata.Expected {
+			t.Errorf("[%s] unexpected error %q, expected %q", testdata.TestName, out, testdata.Expected)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The bundle format version. Bumped when a bundle can no longer be read by older controllers.
+const MonitorBundleVersion = "monitoring.raisingthefloor.org/bundle/v1"
+
+// Monitors exported from one cluster, to be imported into another
+// +kubebuilder:object:generate=false
+type MonitorBundle struct {
+	Version    string        `json:"version"`
+	ExportedAt metav1.Time   `json:"exported_at"`
+	Monitors   []HttpMonitor `json:"monitors"`
+}
+
+// Bundle the specs of monitors. Status and cluster assigned metadata are left out, so the bundle can be
+// applied anywhere.
+func NewMonitorBundle(monitors []HttpMonitor, now metav1.Time) *MonitorBundle {
+	bundle := &MonitorBundle{Version: MonitorBundleVersion, ExportedAt: now}
+	for _, monitor := range monitors {
+		bundle.Monitors = append(bundle.Monitors, HttpMonitor{
+			TypeMeta: metav1.TypeMeta{APIVersion: GroupVersion.String(), Kind: "HttpMonitor"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        monitor.Name,
+				Namespace:   monitor.Namespace,
+				Labels:      monitor.Labels,
+				Annotations: withoutLastApplied(monitor.Annotations),
+			},
+			Spec: *monitor.Spec.DeepCopy(),
+		})
+	}
+	sort.Slice(bundle.Monitors, func(i, j int) bool {
+		a, b := bundle.Monitors[i], bundle.Monitors[j]
+		return a.Namespace < b.Namespace || a.Namespace == b.Namespace && a.Name < b.Name
+	})
+	return bundle
+}
+
+func withoutLastApplied(annotations map[string]string) map[string]string {
+	const lastApplied = "kubectl.kubernetes.io/last-applied-configuration"
+	if _, found := annotations[lastApplied]; !found {
+		return annotations
+	}
+	out := make(map[string]string, len(annotations))
+	for key, value := range annotations {
+		if key != lastApplied {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// Read a bundle written as YAML or JSON
+func ParseMonitorBundle(data []byte) (*MonitorBundle, error) {
+	bundle := &MonitorBundle{}
+	if err := yaml.Unmarshal(data, bundle); err != nil {
+		return nil, fmt.Errorf("invalid monitor bundle: %w", err)
+	}
+	if bundle.Version != MonitorBundleVersion {
+		return nil, fmt.Errorf("unsupported monitor bundle version %q, expected %s", bundle.Version, MonitorBundleVersion)
+	}
+	return bundle, nil
+}
+
+// The monitors to create, moved to namespace if it is not empty, with overrides set in each environment
+func (b *MonitorBundle) MonitorsFor(namespace string, overrides map[string]string) []HttpMonitor {
+	monitors := make([]HttpMonitor, 0, len(b.Monitors))
+	for _, bundled := range b.Monitors {
+		monitor := *bundled.DeepCopy()
+		if namespace != "" {
+			monitor.Namespace = namespace
+		}
+		if len(overrides) > 0 && monitor.Spec.Environment == nil {
+			monitor.Spec.Environment = make(map[string]string, len(overrides))
+		}
+		for key, value := range overrides {
+			monitor.Spec.Environment[key] = value
+		}
+		monitors = append(monitors, monitor)
+	}
+	return monitors
+}
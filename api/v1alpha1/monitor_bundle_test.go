@@ -0,0 +1,89 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMonitorBundle_roundTrip(t *testing.T) {
+	monitors := []HttpMonitor{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "shop", ResourceVersion: "42", UID: "abc",
+				Labels:      map[string]string{"team": "shop"},
+				Annotations: map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}", "owner": "sre"}},
+			Spec: HttpMonitorSpec{
+				Environment: map[string]string{"host": "shop.staging.example.com", "user": "monitor"},
+				Requests:    []HttpRequest{{Name: "list", Method: "GET", Url: "https://{{host}}/orders"}},
+				Period:      &metav1.Duration{Duration: time.Minute},
+			},
+			Status: HttpMonitorStatus{LintWarnings: []string{"stale"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "cart", Namespace: "shop"},
+			Spec:       HttpMonitorSpec{Requests: []HttpRequest{{Name: "get", Method: "GET", Url: "https://{{host}}/cart"}}},
+		},
+	}
+
+	out, err := yaml.Marshal(NewMonitorBundle(monitors, metav1.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"resourceVersion", "uid", "last-applied-configuration", "lint_warnings"} {
+		if strings.Contains(string(out), leaked) {
+			t.Errorf("bundle should not contain %s:\n%s", leaked, out)
+		}
+	}
+
+	bundle, err := ParseMonitorBundle(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported := bundle.MonitorsFor("shop-prod", map[string]string{"host": "shop.example.com"})
+	if len(imported) != 2 || imported[0].Name != "cart" || imported[1].Name != "orders" {
+		t.Fatalf("unexpected monitors: %+v", imported)
+	}
+	for _, monitor := range imported {
+		if monitor.Namespace != "shop-prod" || monitor.Spec.Environment["host"] != "shop.example.com" {
+			t.Errorf("overrides not applied to %s: %s %v", monitor.Name, monitor.Namespace, monitor.Spec.Environment)
+		}
+	}
+	if imported[1].Spec.Environment["user"] != "monitor" || imported[1].Annotations["owner"] != "sre" {
+		t.Errorf("unexpected monitor: %+v", imported[1])
+	}
+	if bundle.Monitors[1].Spec.Environment["host"] != "shop.staging.example.com" {
+		t.Errorf("the bundle itself should not change")
+	}
+
+	if _, err := ParseMonitorBundle([]byte("version: v0\nmonitors: []")); err == nil {
+		t.Errorf("expected an unsupported version to be rejected")
+	}
+}
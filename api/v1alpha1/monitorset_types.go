@@ -0,0 +1,188 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Set on each generated monitor, so the set can find the monitors it manages
+const MonitorSetLabel = "monitoring.raisingthefloor.org/monitor-set"
+
+// One target of a set. Its variables are added to the template's environment, along with {target}, the
+// target's name.
+type MonitorTarget struct {
+	// Used in the generated monitor's name, <set>-<target>
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// The labels, annotations and spec of each generated monitor
+type MonitorTemplate struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	Spec HttpMonitorSpec `json:"spec"`
+}
+
+// MonitorSetSpec defines the desired state of MonitorSet
+type MonitorSetSpec struct {
+	Template MonitorTemplate `json:"template"`
+
+	// Targets listed in the set itself
+	Targets []MonitorTarget `json:"targets,omitempty"`
+
+	// A ConfigMap key holding a YAML list of targets, in the same format as `targets`
+	TargetsFrom *corev1.ConfigMapKeySelector `json:"targets_from,omitempty"`
+
+	// Services in the same namespace matching this selector are targets too. Each has the variables
+	// {service-name}, {service-host} (the cluster DNS name) and {service-port} (its first port).
+	ServiceSelector *metav1.LabelSelector `json:"service_selector,omitempty"`
+}
+
+// MonitorSetStatus defines the observed state of MonitorSet
+type MonitorSetStatus struct {
+	// How many targets the set has
+	Targets int `json:"targets"`
+
+	// Names of the monitors generated for the current targets
+	Monitors []string `json:"monitors,omitempty"`
+
+	// Set if the targets could not be read or a monitor could not be synced
+	Error string `json:"error,omitempty"`
+}
+
+// MonitorSet manages one HttpMonitor per target from a template
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Targets",type=integer,JSONPath=`.status.targets`
+// +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.error`
+type MonitorSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MonitorSetSpec   `json:"spec,omitempty"`
+	Status MonitorSetStatus `json:"status,omitempty"`
+}
+
+// MonitorSetList contains a list of MonitorSet
+// +kubebuilder:object:root=true
+type MonitorSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MonitorSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MonitorSet{}, &MonitorSetList{})
+}
+
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Parse the YAML list of targets kept in a ConfigMap
+func ParseMonitorTargets(data string) ([]MonitorTarget, error) {
+	var targets []MonitorTarget
+	if err := yaml.Unmarshal([]byte(data), &targets); err != nil {
+		return nil, fmt.Errorf("could not parse targets: %w", err)
+	}
+	return targets, nil
+}
+
+// Targets for Services, reached by their cluster DNS name
+func ServiceTargets(services []corev1.Service) []MonitorTarget {
+	var targets []MonitorTarget
+	for _, service := range services {
+		variables := map[string]string{
+			"service-name": service.Name,
+			"service-host": fmt.Sprintf("%s.%s.svc.cluster.local", service.Name, service.Namespace),
+		}
+		if len(service.Spec.Ports) > 0 {
+			variables["service-port"] = fmt.Sprint(service.Spec.Ports[0].Port)
+		}
+		targets = append(targets, MonitorTarget{Name: service.Name, Variables: variables})
+	}
+	return targets
+}
+
+// The name of the monitor generated for a target. Names are lowercased and anything that is not allowed in
+// a resource name is replaced with a dash.
+func (s *MonitorSet) MonitorName(target string) string {
+	name := invalidNameCharacters.ReplaceAllString(strings.ToLower(s.Name+"-"+target), "-")
+	if len(name) > 253 {
+		name = name[:253]
+	}
+	return strings.Trim(name, "-")
+}
+
+// The monitors the set should have for the given targets, sorted by name. Targets that map to the same name
+// are reported as an error, since only one of them could have a monitor.
+func (s *MonitorSet) MonitorsFor(targets []MonitorTarget) ([]HttpMonitor, error) {
+	seen := make(map[string]string)
+	var monitors []HttpMonitor
+	for _, target := range targets {
+		name := s.MonitorName(target.Name)
+		if other, exists := seen[name]; exists {
+			return nil, fmt.Errorf("targets %q and %q would both have the monitor %s", other, target.Name, name)
+		}
+		seen[name] = target.Name
+
+		monitor := HttpMonitor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   s.Namespace,
+				Labels:      map[string]string{},
+				Annotations: s.Spec.Template.Annotations,
+			},
+			Spec: *s.Spec.Template.Spec.DeepCopy(),
+		}
+		for k, v := range s.Spec.Template.Labels {
+			monitor.Labels[k] = v
+		}
+		monitor.Labels[MonitorSetLabel] = s.Name
+
+		environment := make(map[string]string)
+		for k, v := range monitor.Spec.Environment {
+			environment[k] = v
+		}
+		environment["target"] = target.Name
+		for k, v := range target.Variables {
+			environment[k] = v
+		}
+		monitor.Spec.Environment = environment
+		monitors = append(monitors, monitor)
+	}
+	sort.Slice(monitors, func(i, j int) bool { return monitors[i].Name < monitors[j].Name })
+	return monitors, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMonitorSet_MonitorsFor(t *testing.T) {
+	set := &MonitorSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "storefronts", Namespace: "shop"},
+		Spec: MonitorSetSpec{Template: MonitorTemplate{
+			Labels: map[string]string{"team": "shop"},
+			Spec: HttpMonitorSpec{
+				Period:      &metav1.Duration{Duration: time.Minute},
+				Environment: map[string]string{"path": "/alive", "host": "default.example.com"},
+				Requests:    []HttpRequest{{Name: "alive", Url: "https://{host}{path}"}},
+			},
+		}},
+	}
+	targets, err := ParseMonitorTargets(`
+- name: EU_West
+  variables:
+    host: eu.shop.example.com
+- name: us-east
+  variables:
+    host: us.shop.example.com
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	services := []corev1.Service{{
+		ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8080}}},
+	}}
+	targets = append(targets, ServiceTargets(services)...)
+
+	monitors, err := set.MonitorsFor(targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range monitors {
+		names = append(names, m.Name)
+	}
+	if expected := []string{"storefronts-checkout", "storefronts-eu-west", "storefronts-us-east"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("unexpected monitors. Got: %v, expected: %v", names, expected)
+	}
+
+	expectedEnvironment := map[string]string{"path": "/alive", "host": "eu.shop.example.com", "target": "EU_West"}
+	if out := monitors[1].Spec.Environment; !reflect.DeepEqual(out, expectedEnvironment) {
+		t.Errorf("unexpected target environment. Got: %v, expected: %v", out, expectedEnvironment)
+	}
+	service := monitors[0].Spec.Environment
+	if service["service-host"] != "checkout.shop.svc.cluster.local" || service["service-port"] != "8080" {
+		t.Errorf("unexpected service environment: %v", service)
+	}
+	if monitors[0].Labels[MonitorSetLabel] != "storefronts" || monitors[0].Labels["team"] != "shop" {
+		t.Errorf("unexpected labels: %v", monitors[0].Labels)
+	}
+	if len(set.Spec.Template.Labels) != 1 || set.Spec.Template.Spec.Environment["host"] != "default.example.com" {
+		t.Errorf("the template was modified: %v", set.Spec.Template)
+	}
+
+	if _, err := set.MonitorsFor([]MonitorTarget{{Name: "eu-west"}, {Name: "EU West"}}); err == nil {
+		t.Errorf("expected targets with the same monitor name to fail")
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"time"
+)
+
+// MonitorSilenceSpec defines the desired state of MonitorSilence
+type MonitorSilenceSpec struct {
+	// Monitors in the same namespace whose labels match this selector are silenced.
+	// An empty selector matches every monitor in the namespace.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Why the monitors are silenced, for whoever reads this later
+	Reason string `json:"reason"`
+
+	// When the silence stops applying
+	ExpiresAt metav1.Time `json:"expires_at"`
+}
+
+// MonitorSilenceStatus defines the observed state of MonitorSilence
+type MonitorSilenceStatus struct {
+	// Whether the silence has expired
+	Expired bool `json:"expired"`
+
+	// Names of the monitors currently matched by the selector
+	MatchedMonitors []string `json:"matched_monitors,omitempty"`
+}
+
+// MonitorSilence suppresses notifications for matching monitors until it expires
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.spec.reason`
+// +kubebuilder:printcolumn:name="Expires",type=string,format=date-time,JSONPath=`.spec.expires_at`
+// +kubebuilder:printcolumn:name="Expired",type=boolean,JSONPath=`.status.expired`
+type MonitorSilence struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MonitorSilenceSpec   `json:"spec,omitempty"`
+	Status MonitorSilenceStatus `json:"status,omitempty"`
+}
+
+// MonitorSilenceList contains a list of MonitorSilence
+// +kubebuilder:object:root=true
+type MonitorSilenceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MonitorSilence `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MonitorSilence{}, &MonitorSilenceList{})
+}
+
+func (s *MonitorSilence) IsExpired(now time.Time) bool {
+	return !now.Before(s.Spec.ExpiresAt.Time)
+}
+
+// Whether this silence applies to the given monitor. Expiry is not taken into account.
+func (s *MonitorSilence) Matches(m *HttpMonitor) (bool, error) {
+	if s.Namespace != m.Namespace {
+		return false, nil
+	}
+	return selectorMatches(s.Spec.Selector, m)
+}
+
+// Whether the monitor's labels match a selector. A nil selector matches every monitor.
+func selectorMatches(labelSelector *metav1.LabelSelector, m *HttpMonitor) (bool, error) {
+	if labelSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(m.Labels)), nil
+}
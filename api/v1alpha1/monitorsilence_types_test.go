@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestMonitorSilence_Matches(t *testing.T) {
+	monitor := &HttpMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "monitor",
+			Labels:    map[string]string{"service": "web"},
+		},
+	}
+
+	tests := []struct {
+		TestName  string
+		Namespace string
+		Selector  *metav1.LabelSelector
+		Expected  bool
+	}{
+		{"no-selector", "ns", nil, true},
+		{"matching-labels", "ns", &metav1.LabelSelector{MatchLabels: map[string]string{"service": "web"}}, true},
+		{"other-labels", "ns", &metav1.LabelSelector{MatchLabels: map[string]string{"service": "api"}}, false},
+		{"other-namespace", "other", nil, false},
+	}
+
+	for _, testdata := range tests {
+		silence := &MonitorSilence{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testdata.Namespace, Name: "silence"},
+			Spec:       MonitorSilenceSpec{Selector: testdata.Selector},
+		}
+		matches, err := silence.Matches(monitor)
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %s", testdata.TestName, err)
+			continue
+		}
+		if matches != testdata.Expected {
+			t.Errorf("[%s] unexpected match. Got: %t, expected: %t", testdata.TestName, matches, testdata.Expected)
+		}
+	}
+}
+
+func TestMonitorSilence_IsExpired(t *testing.T) {
+	now := time.Now()
+	silence := &MonitorSilence{
+		Spec: MonitorSilenceSpec{ExpiresAt: metav1.NewTime(now.Add(time.Hour))},
+	}
+	if silence.IsExpired(now) {
+		t.Errorf("silence expiring in an hour should not be expired")
+	}
+	if !silence.IsExpired(now.Add(2 * time.Hour)) {
+		t.Errorf("silence should be expired after its expiry time")
+	}
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+)
+
+// A generic HTTP endpoint that receives notification payloads
+type WebhookSink struct {
+	// The URL to send the payload to
+	Url string `json:"url"`
+
+	// The HTTP method. Default is POST
+	// +kubebuilder:validation:Enum=POST;PUT;PATCH
+	Method string `json:"method,omitempty"`
+
+	// Request headers, like Content-Type
+	Headers http.Header `json:"headers,omitempty"`
+}
+
+// Creates an Opsgenie alert when the monitor fails, and closes it when the monitor recovers
+type OpsgenieSink struct {
+	// The Opsgenie API key
+	ApiKeySecretRef corev1.SecretKeySelector `json:"api_key_secret_ref"`
+
+	// The Opsgenie API URL. Default is https://api.opsgenie.com, use https://api.eu.opsgenie.com for EU accounts
+	ApiUrl string `json:"api_url,omitempty"`
+
+	// The alert priority. Default is P3
+	// +kubebuilder:validation:Enum=P1;P2;P3;P4;P5
+	Priority string `json:"priority,omitempty"`
+
+	// Tags added to the alert
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Sends an email through an SMTP server
+type EmailSink struct {
+	// The SMTP server, as host:port
+	Server string `json:"server"`
+
+	// The username to authenticate with. Authentication is skipped if empty.
+	Username string `json:"username,omitempty"`
+
+	// The password to authenticate with
+	PasswordSecretRef *corev1.SecretKeySelector `json:"password_secret_ref,omitempty"`
+
+	// The sender address
+	From string `json:"from"`
+
+	// The recipient addresses
+	// +kubebuilder:validation:MinItems=1
+	To []string `json:"to"`
+
+	// A Go template for the subject, with the same data as the sink template
+	Subject string `json:"subject,omitempty"`
+}
+
+// Posts Grafana annotations, so outages show up on dashboards. A failure adds an annotation at the time of
+// the failure, and recovery adds a region annotation spanning the whole outage.
+type GrafanaSink struct {
+	// The Grafana URL, like https://grafana.example.com
+	Url string `json:"url"`
+
+	// A Grafana API token with permission to create annotations
+	ApiTokenSecretRef corev1.SecretKeySelector `json:"api_token_secret_ref"`
+
+	// Only show the annotations on this dashboard. By default they are organization wide.
+	DashboardUid string `json:"dashboard_uid,omitempty"`
+
+	// Only show the annotations on this panel of the dashboard
+	PanelId int `json:"panel_id,omitempty"`
+
+	// Tags added to the annotations, useful for filtering annotation queries
+	Tags []string `json:"tags,omitempty"`
+}
+
+type StatusPageProvider string
+
+var (
+	StatusPageProviderStatuspage StatusPageProvider = "statuspage"
+	StatusPageProviderInstatus   StatusPageProvider = "instatus"
+)
+
+// Keeps a status page component in sync with the monitor
+type StatusPageSink struct {
+	// Which status page service hosts the page
+	// +kubebuilder:validation:Enum=statuspage;instatus
+	Provider StatusPageProvider `json:"provider"`
+
+	// The status page the component belongs to
+	PageId string `json:"page_id"`
+
+	// The component that reflects this monitor
+	ComponentId string `json:"component_id"`
+
+	// The API key for the provider
+	ApiKeySecretRef corev1.SecretKeySelector `json:"api_key_secret_ref"`
+
+	// The component status while the monitor is failing. Default is major_outage
+	// +kubebuilder:validation:Enum=degraded_performance;partial_outage;major_outage
+	FailingStatus string `json:"failing_status,omitempty"`
+}
+
+type TicketProvider string
+
+var (
+	TicketProviderJira       TicketProvider = "jira"
+	TicketProviderServiceNow TicketProvider = "servicenow"
+)
+
+// Opens a ticket when the monitor fails, comments on it while the outage continues, and resolves it on recovery.
+// Combine with escalate_after to only open tickets for sustained outages.
+type TicketSink struct {
+	// Which ticketing system to use
+	// +kubebuilder:validation:Enum=jira;servicenow
+	Provider TicketProvider `json:"provider"`
+
+	// The instance URL, like https://example.atlassian.net or https://example.service-now.com
+	Url string `json:"url"`
+
+	// The user to authenticate as
+	Username string `json:"username"`
+
+	// The password, or API token for Jira Cloud
+	PasswordSecretRef corev1.SecretKeySelector `json:"password_secret_ref"`
+
+	// The Jira project key. Required for Jira.
+	Project string `json:"project,omitempty"`
+
+	// The Jira issue type. Default is Bug
+	IssueType string `json:"issue_type,omitempty"`
+
+	// The Jira transition that resolves the issue. Without it, Jira issues are only commented on at recovery.
+	ResolveTransition string `json:"resolve_transition,omitempty"`
+
+	// A Go template for the ticket summary, with the same data as the sink template
+	Summary string `json:"summary,omitempty"`
+
+	// Extra fields set when the ticket is opened, as Go templates. Values that render to a JSON object or array
+	// are sent as JSON, for fields like Jira's priority: {"name": "High"}
+	Fields map[string]string `json:"fields,omitempty"`
+
+	// Extra fields set when the ticket is resolved, like Jira's resolution or ServiceNow's close_code
+	ResolveFields map[string]string `json:"resolve_fields,omitempty"`
+}
+
+// A destination for notifications about a monitor failing or recovering
+type NotificationSink struct {
+	// Name of the sink. Used for debugging and metrics
+	Name string `json:"name"`
+
+	// Send notifications to an HTTP webhook
+	Webhook *WebhookSink `json:"webhook,omitempty"`
+
+	// Open and close Opsgenie alerts. The template is used for the alert description.
+	Opsgenie *OpsgenieSink `json:"opsgenie,omitempty"`
+
+	// Send an email. The template is used for the body.
+	Email *EmailSink `json:"email,omitempty"`
+
+	// Post Grafana annotations. The template is used for the annotation text.
+	Grafana *GrafanaSink `json:"grafana,omitempty"`
+
+	// Update a status page component. Templates are not used.
+	StatusPage *StatusPageSink `json:"status_page,omitempty"`
+
+	// Open and resolve Jira or ServiceNow tickets. The template is used for the ticket description and comments.
+	Ticket *TicketSink `json:"ticket,omitempty"`
+
+	// A Go template for the notification payload. The template has access to the monitor (.Monitor, including
+	// .Monitor.Spec and .Monitor.Status), the state change (.State), the last run (.Run), and the failure
+	// details (.Error, .FailedRequest, .Severity, .Category). By default a JSON document describing the state change is sent.
+	Template string `json:"template,omitempty"`
+
+	// Only notify this sink about failures at least this severe. By default it hears about every failure.
+	// +kubebuilder:validation:Enum=critical;warning;info
+	MinSeverity Severity `json:"min_severity,omitempty"`
+
+	// Only notify this sink once the monitor has been failing for this long. By default the sink is notified
+	// as soon as the monitor fails. Use this to escalate prolonged outages to another channel.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	EscalateAfter *metav1.Duration `json:"escalate_after,omitempty"`
+
+	// Repeat the failure notification this often while the monitor is still failing. By default the sink is
+	// only notified once per outage.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	RepeatInterval *metav1.Duration `json:"repeat_interval,omitempty"`
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	corev1 "k8s.io/api/core/v1"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultClientIdKey     = "client_id"
+	defaultClientSecretKey = "client_secret"
+)
+
+// The OAuth2 client credentials flow. A token is requested from the token URL, sent with every request, and
+// requested again shortly before it expires.
+type OAuth2ClientCredentials struct {
+	// +kubebuilder:validation:MinLength=1
+	TokenUrl string `json:"token_url"`
+
+	// A Secret with the client ID and secret
+	SecretRef corev1.LocalObjectReference `json:"secret_ref"`
+
+	// The keys of the client ID and secret in the Secret. Defaults are client_id and client_secret
+	ClientIdKey     string `json:"client_id_key,omitempty"`
+	ClientSecretKey string `json:"client_secret_key,omitempty"`
+
+	Scopes []string `json:"scopes,omitempty"`
+
+	// Other parameters of the token request, like audience
+	Params map[string]string `json:"params,omitempty"`
+
+	// The client credentials, loaded by the controller
+	ClientId     string `json:"-"`
+	ClientSecret string `json:"-"`
+	Loaded       bool   `json:"-"`
+}
+
+// Where the controller reads the client ID
+func (o *OAuth2ClientCredentials) ClientIdSecretKey() *corev1.SecretKeySelector {
+	return secretKey(o.SecretRef, o.ClientIdKey, defaultClientIdKey)
+}
+
+// Where the controller reads the client secret
+func (o *OAuth2ClientCredentials) ClientSecretSecretKey() *corev1.SecretKeySelector {
+	return secretKey(o.SecretRef, o.ClientSecretKey, defaultClientSecretKey)
+}
+
+func (o *OAuth2ClientCredentials) validate() error {
+	if o.SecretRef.Name == "" {
+		return fmt.Errorf("invalid auth: oauth2 needs the name of a secret in secret_ref")
+	}
+	if u, err := url.Parse(o.TokenUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid auth: oauth2 token_url %q is not an http or https URL", o.TokenUrl)
+	}
+	return nil
+}
+
+func (o *OAuth2ClientCredentials) config() *clientcredentials.Config {
+	params := make(url.Values, len(o.Params))
+	for key, value := range o.Params {
+		params.Set(key, value)
+	}
+	return &clientcredentials.Config{
+		ClientID:       o.ClientId,
+		ClientSecret:   o.ClientSecret,
+		TokenURL:       o.TokenUrl,
+		Scopes:         o.Scopes,
+		EndpointParams: params,
+	}
+}
+
+// Identifies the token a configuration gets, so requests with the same one share it. The secret is hashed, so a
+// rotated one gets a new token.
+func (o *OAuth2ClientCredentials) cacheKey() string {
+	params := make([]string, 0, len(o.Params))
+	for key, value := range o.Params {
+		params = append(params, key+"="+value)
+	}
+	sort.Strings(params)
+	secret := sha256.Sum256([]byte(o.ClientSecret))
+	return strings.Join([]string{o.TokenUrl, o.ClientId, hex.EncodeToString(secret[:]), strings.Join(o.Scopes, " "),
+		strings.Join(params, "&")}, "\n")
+}
+
+// Token sources by cache key. Each one keeps its token until shortly before it expires. Sources whose token has
+// expired are dropped, since getting a token from a new source costs the same as refreshing one, and so are the
+// sources of monitors that are removed.
+var oauth2Tokens = struct {
+	lock    sync.Mutex
+	sources map[string]*oauth2TokenSource
+}{sources: make(map[string]*oauth2TokenSource)}
+
+type oauth2TokenSource struct {
+	source oauth2.TokenSource
+	// When the last token from the source expires. Zero before the first token, and for tokens that do not expire.
+	expiry time.Time
+}
+
+func (o *OAuth2ClientCredentials) token(ctx context.Context) (*oauth2.Token, error) {
+	if !o.Loaded {
+		return nil, fmt.Errorf("the client credentials in secret %s were not loaded", o.SecretRef.Name)
+	}
+	key := o.cacheKey()
+	now := time.Now()
+	oauth2Tokens.lock.Lock()
+	for other, cached := range oauth2Tokens.sources {
+		if other != key && !cached.expiry.IsZero() && now.After(cached.expiry) {
+			delete(oauth2Tokens.sources, other)
+		}
+	}
+	cached, ok := oauth2Tokens.sources[key]
+	if !ok {
+		// Refreshes outlive the request that made the source, so they do not use its context
+		cached = &oauth2TokenSource{source: o.config().TokenSource(
+			context.WithValue(context.Background(), oauth2.HTTPClient, httpclient.GetClient()))}
+		oauth2Tokens.sources[key] = cached
+	}
+	oauth2Tokens.lock.Unlock()
+
+	token, err := cached.source.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get an OAuth2 token from %s: %w", o.TokenUrl, err)
+	}
+	oauth2Tokens.lock.Lock()
+	cached.expiry = token.Expiry
+	oauth2Tokens.lock.Unlock()
+	return token, nil
+}
+
+// The cache keys of the OAuth2 tokens the monitor and its requests get
+func (h *HttpMonitor) oauth2CacheKeys() []string {
+	var keys []string
+	for _, auth := range h.auths() {
+		if auth.OAuth2 != nil && auth.OAuth2.Loaded {
+			keys = append(keys, auth.OAuth2.cacheKey())
+		}
+	}
+	return keys
+}
+
+func (h *HttpMonitor) auths() []*RequestAuth {
+	auths := []*RequestAuth{h.Spec.Auth}
+	for _, requests := range [][]HttpRequest{h.Spec.Requests, h.Spec.Cleanup} {
+		for i := range requests {
+			auths = append(auths, requests[i].Auth)
+		}
+	}
+	var set []*RequestAuth
+	for _, auth := range auths {
+		if auth != nil {
+			set = append(set, auth)
+		}
+	}
+	return set
+}
+
+// Drop the cached OAuth2 tokens of a monitor that is removed or replaced, except the ones the others still get
+func (h *HttpMonitor) ForgetOAuth2Tokens(others []*HttpMonitor) {
+	inUse := make(map[string]bool)
+	for _, other := range others {
+		for _, key := range other.oauth2CacheKeys() {
+			inUse[key] = true
+		}
+	}
+	oauth2Tokens.lock.Lock()
+	defer oauth2Tokens.lock.Unlock()
+	for _, key := range h.oauth2CacheKeys() {
+		if !inUse[key] {
+			delete(oauth2Tokens.sources, key)
+		}
+	}
+}
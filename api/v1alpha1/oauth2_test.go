@@ -0,0 +1,167 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOAuth2ClientCredentials_token(t *testing.T) {
+	var exchanges int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&exchanges, 1)
+		clientId, clientSecret, _ := r.BasicAuth()
+		if r.FormValue("grant_type") != "client_credentials" || clientSecret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": clientId + ":" + r.FormValue("scope") + ":" + r.FormValue("audience"),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer server.Close()
+
+	secret := corev1.LocalObjectReference{Name: "client"}
+	tests := []struct {
+		TestName  string
+		Auth      OAuth2ClientCredentials
+		Header    string
+		Exchanges int32
+		Err       string
+	}{
+		{"exchange", OAuth2ClientCredentials{ClientId: "monitor", ClientSecret: "s3cret", Scopes: []string{"read", "write"},
+			Params: map[string]string{"audience": "api"}}, "Bearer monitor:read write:api", 1, ""},
+		{"cached", OAuth2ClientCredentials{ClientId: "monitor", ClientSecret: "s3cret", Scopes: []string{"read", "write"},
+			Params: map[string]string{"audience": "api"}}, "Bearer monitor:read write:api", 0, ""},
+		{"other scopes", OAuth2ClientCredentials{ClientId: "monitor", ClientSecret: "s3cret", Scopes: []string{"read"}},
+			"Bearer monitor:read:", 1, ""},
+		{"wrong secret", OAuth2ClientCredentials{ClientId: "monitor", ClientSecret: "wrong"}, "", 1,
+			"failed to get an OAuth2 token from " + server.URL},
+		{"not loaded", OAuth2ClientCredentials{SecretRef: secret}, "", 0,
+			"the client credentials in secret client were not loaded"},
+	}
+	for _, testdata := range tests {
+		auth := testdata.Auth
+		auth.TokenUrl, auth.Loaded = server.URL, auth.SecretRef.Name == ""
+		request := HttpRequest{Method: http.MethodGet, Url: "https://example.com/api", Auth: &RequestAuth{OAuth2: &auth}}
+		before := atomic.LoadInt32(&exchanges)
+		req, err := request.BuildRequest()
+		if sent := atomic.LoadInt32(&exchanges) - before; sent != testdata.Exchanges {
+			t.Errorf("[%s] unexpected token requests %d", testdata.TestName, sent)
+		}
+		if testdata.Err != "" {
+			if err == nil || !strings.Contains(err.Error(), testdata.Err) {
+				t.Errorf("[%s] unexpected error %v", testdata.TestName, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[%s] unexpected error %s", testdata.TestName, err)
+		}
+		if header := req.Header.Get("Authorization"); header != testdata.Header {
+			t.Errorf("[%s] unexpected Authorization header %q", testdata.TestName, header)
+		}
+	}
+}
+
+func TestHttpMonitor_ForgetOAuth2Tokens(t *testing.T) {
+	auth := func(clientId string) *RequestAuth {
+		return &RequestAuth{OAuth2: &OAuth2ClientCredentials{TokenUrl: "https://auth.example.com/token",
+			ClientId: clientId, ClientSecret: "s3cret", Loaded: true}}
+	}
+	monitor := &HttpMonitor{Spec: HttpMonitorSpec{Auth: auth("monitor"),
+		Requests: []HttpRequest{{Name: "api", Auth: auth("request")}},
+		Cleanup:  []HttpRequest{{Name: "logout", Auth: auth("cleanup")}}}}
+	tests := []struct {
+		TestName string
+		Others   []*HttpMonitor
+		Kept     []string
+	}{
+		{"no others", nil, nil},
+		{"shared", []*HttpMonitor{{Spec: HttpMonitorSpec{Requests: []HttpRequest{{Name: "other", Auth: auth("request")}}}}},
+			[]string{"request"}},
+		{"other clients", []*HttpMonitor{{Spec: HttpMonitorSpec{Auth: auth("other")}}}, nil},
+	}
+	for _, testdata := range tests {
+		oauth2Tokens.sources = make(map[string]*oauth2TokenSource)
+		for _, clientId := range []string{"monitor", "request", "cleanup"} {
+			oauth2Tokens.sources[auth(clientId).OAuth2.cacheKey()] = &oauth2TokenSource{}
+		}
+		monitor.ForgetOAuth2Tokens(testdata.Others)
+		if len(oauth2Tokens.sources) != len(testdata.Kept) {
+			t.Errorf("[%s] unexpected token sources. Got: %d, expected: %d", testdata.TestName,
+				len(oauth2Tokens.sources), len(testdata.Kept))
+		}
+		for _, clientId := range testdata.Kept {
+			if _, ok := oauth2Tokens.sources[auth(clientId).OAuth2.cacheKey()]; !ok {
+				t.Errorf("[%s] expected the token source of %s to be kept", testdata.TestName, clientId)
+			}
+		}
+	}
+}
+
+func TestOAuth2ClientCredentials_token_expired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "token_type": "Bearer",
+			"expires_in": 3600})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		TestName string
+		Expiry   time.Time
+		Kept     bool
+	}{
+		{"expired", time.Now().Add(-time.Minute), false},
+		{"valid", time.Now().Add(time.Minute), true},
+		{"no expiry", time.Time{}, true},
+	}
+	for _, testdata := range tests {
+		oauth2Tokens.sources = map[string]*oauth2TokenSource{"other": {expiry: testdata.Expiry}}
+		auth := OAuth2ClientCredentials{TokenUrl: server.URL, ClientId: "monitor", ClientSecret: "s3cret", Loaded: true}
+		if _, err := auth.token(context.Background()); err != nil {
+			t.Fatalf("[%s] got unexpected err: %s", testdata.TestName, err)
+		}
+		if _, kept := oauth2Tokens.sources["other"]; kept != testdata.Kept {
+			t.Errorf("[%s] unexpected kept token source. Got: %t, expected: %t", testdata.TestName, kept, testdata.Kept)
+		}
+		if cached := oauth2Tokens.sources[auth.cacheKey()]; cached == nil || cached.expiry.IsZero() {
+			t.Errorf("[%s] expected the new token source to keep its expiry", testdata.TestName)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+// The parts of a discovery document that are checked
+type oidcDiscoveryDocument struct {
+	Issuer                           string   `json:"issuer"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+	TokenEndpoint                    string   `json:"token_endpoint"`
+	UserinfoEndpoint                 string   `json:"userinfo_endpoint"`
+	JwksUri                          string   `json:"jwks_uri"`
+	ResponseTypesSupported           []string `json:"response_types_supported"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	IdTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// The fields OpenID Connect Discovery 1.0 requires
+func (d *oidcDiscoveryDocument) missingFields() []string {
+	var missing []string
+	for _, field := range []struct {
+		name    string
+		present bool
+	}{
+		{"issuer", d.Issuer != ""},
+		{"authorization_endpoint", d.AuthorizationEndpoint != ""},
+		{"jwks_uri", d.JwksUri != ""},
+		{"response_types_supported", len(d.ResponseTypesSupported) > 0},
+		{"subject_types_supported", len(d.SubjectTypesSupported) > 0},
+		{"id_token_signing_alg_values_supported", len(d.IdTokenSigningAlgValuesSupported) > 0},
+	} {
+		if !field.present {
+			missing = append(missing, field.name)
+		}
+	}
+	return missing
+}
+
+func (o *OidcDiscoveryCheck) expectedIssuer(documentUrl *url.URL) string {
+	if o.Issuer != "" {
+		return o.Issuer
+	}
+	issuer := *documentUrl
+	issuer.Path = strings.TrimSuffix(issuer.Path, oidcDiscoveryPath)
+	issuer.RawQuery = ""
+	return issuer.String()
+}
+
+// Check the discovery document, the keys it publishes and, optionally, its endpoints
+func (o *OidcDiscoveryCheck) verify(ctx context.Context, client *http.Client, documentUrl *url.URL, body []byte) error {
+	document := &oidcDiscoveryDocument{}
+	if err := json.Unmarshal(body, document); err != nil {
+		return fmt.Errorf("invalid discovery document: %w", err)
+	}
+	if missing := document.missingFields(); len(missing) > 0 {
+		return fmt.Errorf("discovery document is missing %s", strings.Join(missing, ", "))
+	}
+	if expected := o.expectedIssuer(documentUrl); document.Issuer != expected {
+		return fmt.Errorf("discovery document names issuer %s, expected %s", document.Issuer, expected)
+	}
+
+	keys, err := fetchJwks(ctx, client, document.JwksUri)
+	if err != nil {
+		return err
+	}
+	if err := o.verifyKeys(keys, time.Now()); err != nil {
+		return err
+	}
+
+	if o.CheckEndpoints {
+		for _, endpoint := range []string{document.AuthorizationEndpoint, document.TokenEndpoint, document.UserinfoEndpoint} {
+			if endpoint == "" {
+				continue
+			}
+			if err := checkEndpointResponds(ctx, client, endpoint); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Check that there are enough usable signing keys, and that their certificates are not about to expire
+func (o *OidcDiscoveryCheck) verifyKeys(keys []jwk, now time.Time) error {
+	signing := 0
+	for _, key := range keys {
+		if key.Kid == "" && len(keys) > 1 {
+			return fmt.Errorf("JWKS has %d keys, but not all of them have a kid", len(keys))
+		}
+		if _, err := key.publicKey(); err != nil {
+			return fmt.Errorf("invalid key %q in JWKS: %w", key.Kid, err)
+		}
+		if key.Use == "" || key.Use == "sig" {
+			signing++
+		}
+		if len(key.X5c) > 0 && o.MinCertificateRemaining != nil {
+			der, err := base64.StdEncoding.DecodeString(key.X5c[0])
+			if err != nil {
+				return fmt.Errorf("invalid certificate for key %q: %w", key.Kid, err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return fmt.Errorf("invalid certificate for key %q: %w", key.Kid, err)
+			}
+			if remaining := cert.NotAfter.Sub(now); remaining < o.MinCertificateRemaining.Duration {
+				return fmt.Errorf("certificate for key %q expires at %s, sooner than the required %s", key.Kid,
+					cert.NotAfter.UTC(), o.MinCertificateRemaining.Duration)
+			}
+		}
+	}
+	minKeys := o.MinSigningKeys
+	if minKeys < 1 {
+		minKeys = 1
+	}
+	if signing < minKeys {
+		return fmt.Errorf("JWKS has %d signing keys, expected at least %d", signing, minKeys)
+	}
+	return nil
+}
+
+// Endpoints are checked with a plain GET. Most answer it with a client error, which still shows they are up.
+func checkEndpointResponds(ctx context.Context, client *http.Client, endpoint string) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("endpoint %s is unreachable: %w", endpoint, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("endpoint %s responded with %d", endpoint, resp.StatusCode)
+	}
+	return nil
+}
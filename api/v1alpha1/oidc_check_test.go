@@ -0,0 +1,136 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOidcDiscoveryCheck_verify(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "signing"},
+		NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(72 * time.Hour)}
+	cert, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	rsaJwk := func(kid, use string) map[string]interface{} {
+		return map[string]interface{}{"kty": "RSA", "kid": kid, "use": use, "n": encode(key.N.Bytes()),
+			"e": encode(big.NewInt(int64(key.E)).Bytes()), "x5c": []string{base64.StdEncoding.EncodeToString(cert)}}
+	}
+
+	var server *httptest.Server
+	keys := []map[string]interface{}{}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/jwks":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+		case "/token":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case "/authorize":
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	document := func(changes map[string]interface{}) []byte {
+		d := map[string]interface{}{
+			"issuer":                                server.URL,
+			"authorization_endpoint":                server.URL + "/authorize",
+			"token_endpoint":                        server.URL + "/token",
+			"jwks_uri":                              server.URL + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		}
+		for k, v := range changes {
+			if v == nil {
+				delete(d, k)
+			} else {
+				d[k] = v
+			}
+		}
+		body, _ := json.Marshal(d)
+		return body
+	}
+	documentUrl, _ := url.Parse(server.URL + oidcDiscoveryPath)
+
+	tests := []struct {
+		TestName    string
+		Check       OidcDiscoveryCheck
+		Keys        []map[string]interface{}
+		Body        []byte
+		ExpectedErr string
+	}{
+		{"valid", OidcDiscoveryCheck{}, []map[string]interface{}{rsaJwk("a", "sig")}, document(nil), ""},
+		{"not json", OidcDiscoveryCheck{}, nil, []byte("<html>"), "invalid discovery document"},
+		{"missing fields", OidcDiscoveryCheck{}, nil, document(map[string]interface{}{"jwks_uri": nil, "subject_types_supported": nil}),
+			"missing jwks_uri, subject_types_supported"},
+		{"wrong issuer", OidcDiscoveryCheck{}, nil, document(map[string]interface{}{"issuer": "https://other"}), "expected " + server.URL},
+		{"explicit issuer", OidcDiscoveryCheck{Issuer: "https://other"}, []map[string]interface{}{rsaJwk("a", "")},
+			document(map[string]interface{}{"issuer": "https://other"}), ""},
+		{"no rotation overlap", OidcDiscoveryCheck{MinSigningKeys: 2}, []map[string]interface{}{rsaJwk("a", "sig"), rsaJwk("b", "enc")},
+			document(nil), "1 signing keys, expected at least 2"},
+		{"rotation overlap", OidcDiscoveryCheck{MinSigningKeys: 2}, []map[string]interface{}{rsaJwk("a", "sig"), rsaJwk("b", "sig")},
+			document(nil), ""},
+		{"missing kid", OidcDiscoveryCheck{}, []map[string]interface{}{rsaJwk("", "sig"), rsaJwk("b", "sig")}, document(nil),
+			"not all of them have a kid"},
+		{"invalid key", OidcDiscoveryCheck{}, []map[string]interface{}{{"kty": "oct", "kid": "a"}}, document(nil), "invalid key \"a\""},
+		{"certificate expiring", OidcDiscoveryCheck{MinCertificateRemaining: &metav1.Duration{Duration: 7 * 24 * time.Hour}},
+			[]map[string]interface{}{rsaJwk("a", "sig")}, document(nil), "certificate for key \"a\" expires"},
+		{"endpoint down", OidcDiscoveryCheck{CheckEndpoints: true}, []map[string]interface{}{rsaJwk("a", "sig")}, document(nil),
+			"/authorize responded with 502"},
+		{"endpoints up", OidcDiscoveryCheck{CheckEndpoints: true}, []map[string]interface{}{rsaJwk("a", "sig")},
+			document(map[string]interface{}{"authorization_endpoint": server.URL + "/token"}), ""},
+	}
+
+	for _, testdata := range tests {
+		keys = testdata.Keys
+		err := testdata.Check.verify(context.Background(), server.Client(), documentUrl, testdata.Body)
+		if testdata.ExpectedErr == "" {
+			if err != nil {
+				t.Errorf("[%s] got unexpected err: %s", testdata.TestName, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), testdata.ExpectedErr) {
+			t.Errorf("[%s] unexpected error. Got: %v, expected it to contain: %q", testdata.TestName, err, testdata.ExpectedErr)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// The smallest buffers the specification allows an endpoint to offer
+const opcUaMinBufferSize = 8192
+
+// What we offer in the Hello message
+const opcUaBufferSize = 65536
+
+// Messages an endpoint sends back that are larger than this are not read
+const opcUaMaxAnswerSize = 1 << 16
+
+// The meaning of the status codes endpoints commonly reject a Hello with
+var opcUaStatusCodes = map[uint32]string{
+	0x807d0000: "BadTcpServerTooBusy",
+	0x807e0000: "BadTcpMessageTypeInvalid",
+	0x80800000: "BadTcpMessageTooLarge",
+	0x80810000: "BadTcpNotEnoughResources",
+	0x80820000: "BadTcpInternalError",
+	0x80830000: "BadTcpEndpointUrlInvalid",
+	0x80be0000: "BadProtocolVersionUnsupported",
+}
+
+// Open an OPC UA connection with the Hello/Acknowledge handshake instead of sending an HTTP request. The url is
+// the endpoint url, like opc.tcp://plc.example.com:4840/UA/Server. Fails when the endpoint rejects the url or
+// answers with something other than an acknowledgement.
+type OpcUaCheck struct {
+	// The protocol version to offer. Endpoints reject versions newer than their own.
+	// +kubebuilder:validation:Minimum=0
+	ProtocolVersion uint32 `json:"protocol_version,omitempty"`
+}
+
+// Run the check instead of sending an HTTP request
+func (r *HttpRequest) checkOpcUa() *RequestResult {
+	result := &RequestResult{Name: r.Name, Url: r.AvailableVariables.newReplacer().Replace(r.Url), Attempts: 1}
+	start := time.Now()
+	_, addr, err := hostAndAddress(result.Url, "4840")
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout())
+		err = r.OpcUa.handshake(ctx, addr, result.Url)
+		cancel()
+	}
+	result.Err = err
+	result.Duration = time.Since(start)
+	result.ResponseTime = result.Duration
+	return result
+}
+
+func (c *OpcUaCheck) handshake(ctx context.Context, addr, endpointUrl string) error {
+	conn, err := dialTcp(ctx, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	body := make([]byte, 20)
+	binary.LittleEndian.PutUint32(body[0:], c.ProtocolVersion)
+	binary.LittleEndian.PutUint32(body[4:], opcUaBufferSize)
+	binary.LittleEndian.PutUint32(body[8:], opcUaBufferSize)
+	body = append(body, opcUaString(endpointUrl)...)
+	if _, err := conn.Write(opcUaMessage("HEL", body)); err != nil {
+		return err
+	}
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf("no answer to Hello: %w", err)
+	}
+	size := binary.LittleEndian.Uint32(header[4:])
+	if size < 8 || size > opcUaMaxAnswerSize {
+		return fmt.Errorf("the answer has an invalid size of %d bytes", size)
+	}
+	answer := make([]byte, size-8)
+	if _, err := io.ReadFull(conn, answer); err != nil {
+		return fmt.Errorf("the answer was cut short: %w", err)
+	}
+
+	switch string(header[:4]) {
+	case "ACKF":
+		if len(answer) < 20 {
+			return errors.New("the acknowledgement is too short")
+		}
+		receive, send := binary.LittleEndian.Uint32(answer[4:]), binary.LittleEndian.Uint32(answer[8:])
+		if receive < opcUaMinBufferSize || send < opcUaMinBufferSize {
+			return fmt.Errorf("the endpoint offered buffers of %d and %d bytes, less than the %d the specification requires",
+				receive, send, opcUaMinBufferSize)
+		}
+		return nil
+	case "ERRF":
+		if len(answer) < 4 {
+			return errors.New("the endpoint rejected the Hello")
+		}
+		code := binary.LittleEndian.Uint32(answer)
+		name, ok := opcUaStatusCodes[code]
+		if !ok {
+			name = fmt.Sprintf("0x%08x", code)
+		}
+		if reason := opcUaReadString(answer[4:]); reason != "" {
+			return fmt.Errorf("the endpoint rejected the Hello with %s: %s", name, reason)
+		}
+		return fmt.Errorf("the endpoint rejected the Hello with %s", name)
+	}
+	return fmt.Errorf("unexpected %q message instead of an acknowledgement", string(header[:3]))
+}
+
+// A final chunk of a message of the given type
+func opcUaMessage(messageType string, body []byte) []byte {
+	message := make([]byte, 8, 8+len(body))
+	copy(message, messageType+"F")
+	binary.LittleEndian.PutUint32(message[4:], uint32(8+len(body)))
+	return append(message, body...)
+}
+
+func opcUaString(s string) []byte {
+	out := make([]byte, 4, 4+len(s))
+	binary.LittleEndian.PutUint32(out, uint32(len(s)))
+	return append(out, s...)
+}
+
+// Decode a string, which is empty when it is null or does not fit
+func opcUaReadString(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+	length := int32(binary.LittleEndian.Uint32(data))
+	if length <= 0 || int(length) > len(data)-4 {
+		return ""
+	}
+	return string(data[4 : 4+length])
+}
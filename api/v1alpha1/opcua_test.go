@@ -0,0 +1,100 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// Acknowledges a Hello for opc.tcp://<address>/UA/Server with the given buffer size, and rejects other urls
+func opcUaServer(t *testing.T, bufferSize uint32) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				header := make([]byte, 8)
+				if _, err := io.ReadFull(conn, header); err != nil || string(header[:4]) != "HELF" {
+					return
+				}
+				body := make([]byte, binary.LittleEndian.Uint32(header[4:])-8)
+				if _, err := io.ReadFull(conn, body); err != nil {
+					return
+				}
+				if opcUaReadString(body[20:]) != "opc.tcp://"+listener.Addr().String()+"/UA/Server" {
+					answer := make([]byte, 4)
+					binary.LittleEndian.PutUint32(answer, 0x80830000)
+					_, _ = conn.Write(opcUaMessage("ERR", append(answer, opcUaString("unknown endpoint")...)))
+					return
+				}
+				answer := make([]byte, 20)
+				binary.LittleEndian.PutUint32(answer[4:], bufferSize)
+				binary.LittleEndian.PutUint32(answer[8:], bufferSize)
+				_, _ = conn.Write(opcUaMessage("ACK", answer))
+			}()
+		}
+	}()
+	return listener
+}
+
+func TestHttpRequest_checkOpcUa(t *testing.T) {
+	tests := []struct {
+		TestName   string
+		BufferSize uint32
+		Path       string
+		Expected   string
+	}{
+		{"acknowledged", 65536, "/UA/Server", ""},
+		{"unknown endpoint", 65536, "/other", "the endpoint rejected the Hello with BadTcpEndpointUrlInvalid: unknown endpoint"},
+		{"small buffers", 1024, "/UA/Server", "the endpoint offered buffers of 1024 and 1024 bytes, less than the 8192 the specification requires"},
+	}
+
+	for _, testdata := range tests {
+		server := opcUaServer(t, testdata.BufferSize)
+		request := &HttpRequest{Name: testdata.TestName, Url: "opc.tcp://" + server.Addr().String() + testdata.Path,
+			OpcUa: &OpcUaCheck{}}
+		result := request.checkOpcUa()
+		out := ""
+		if result.Err != nil {
+			out = result.Err.Error()
+		}
+		if !strings.HasPrefix(out, testdata.Expected) || (testdata.Expected == "") != (out == "") {
+			t.Errorf("[%s] unexpected error %q, expected %q", testdata.TestName, out, testdata.Expected)
+		}
+		server.Close()
+	}
+}
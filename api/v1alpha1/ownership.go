@@ -0,0 +1,69 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import "strings"
+
+// Included in status conditions and notifications, so whoever is paged knows where to start
+type Ownership struct {
+	// The team or person responsible, like a team name or an on-call alias
+	Owner string `json:"owner,omitempty"`
+
+	// +kubebuilder:validation:Pattern=`^https?://`
+	RunbookUrl string `json:"runbook_url,omitempty"`
+
+	// +kubebuilder:validation:Pattern=`^https?://`
+	DashboardUrl string `json:"dashboard_url,omitempty"`
+}
+
+// The ownership of a request, falling back to the monitor's for each field the request does not set
+func (o Ownership) Or(monitor Ownership) Ownership {
+	if o.Owner == "" {
+		o.Owner = monitor.Owner
+	}
+	if o.RunbookUrl == "" {
+		o.RunbookUrl = monitor.RunbookUrl
+	}
+	if o.DashboardUrl == "" {
+		o.DashboardUrl = monitor.DashboardUrl
+	}
+	return o
+}
+
+// A short description for messages, like "owner: payments, runbook: https://...". Empty if nothing is set.
+func (o Ownership) String() string {
+	var pieces []string
+	for _, field := range []struct{ name, value string }{
+		{"owner", o.Owner},
+		{"runbook", o.RunbookUrl},
+		{"dashboard", o.DashboardUrl},
+	} {
+		if field.value != "" {
+			pieces = append(pieces, field.name+": "+field.value)
+		}
+	}
+	return strings.Join(pieces, ", ")
+}
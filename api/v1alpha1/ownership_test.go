@@ -0,0 +1,52 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import "testing"
+
+func TestOwnership_Or(t *testing.T) {
+	monitor := Ownership{Owner: "web-team", RunbookUrl: "https://wiki.example.com/web"}
+
+	tests := []struct {
+		TestName        string
+		Request         Ownership
+		ExpectedMessage string
+	}{
+		{"from monitor", Ownership{}, "owner: web-team, runbook: https://wiki.example.com/web"},
+		{"override owner", Ownership{Owner: "docs-team"}, "owner: docs-team, runbook: https://wiki.example.com/web"},
+		{"add dashboard", Ownership{DashboardUrl: "https://grafana.example.com/d/docs"},
+			"owner: web-team, runbook: https://wiki.example.com/web, dashboard: https://grafana.example.com/d/docs"},
+	}
+
+	for _, testdata := range tests {
+		if out := testdata.Request.Or(monitor).String(); out != testdata.ExpectedMessage {
+			t.Errorf("[%s] unexpected ownership. Got: %q, expected: %q", testdata.TestName, out, testdata.ExpectedMessage)
+		}
+	}
+	if out := (Ownership{}).String(); out != "" {
+		t.Errorf("unexpected description of empty ownership: %q", out)
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Loads the page in a headless Chromium until the network is idle, counting every response the page received,
+// third parties included, and writes the measurements to the termination message as JSON
+const performanceAuditScript = `
+const fs = require('fs');
+const { chromium } = require('playwright');
+(async () => {
+  const browser = await chromium.launch();
+  const page = await browser.newPage();
+  const sizes = [];
+  page.on('requestfinished', request => sizes.push(request.sizes().catch(() => null)));
+  await page.goto(process.env.TARGET_URL, { waitUntil: 'networkidle' });
+  const ttfb = await page.evaluate(() => performance.getEntriesByType('navigation')[0].responseStart);
+  let weight = 0, requests = 0;
+  for (const size of await Promise.all(sizes)) {
+    requests++;
+    if (size) weight += size.responseHeadersSize + size.responseBodySize;
+  }
+  await browser.close();
+  const measurements = JSON.stringify({ ttfb_ms: ttfb, page_weight_bytes: weight, requests: requests });
+  console.log(measurements);
+  fs.writeFileSync('/dev/termination-log', measurements);
+})().catch(err => { console.log(String(err)); process.exit(2); });
+`
+
+// Images with playwright already installed skip the install
+const performanceAuditCommand = `cd /tmp && (node -e "require.resolve('playwright')" 2>/dev/null ||
+  npm install --no-save --silent playwright@1.38.0) && node -e "$AUDIT_SCRIPT"`
+
+// Load the page at the request's URL in a headless browser run by a Job, and measure its time to first byte,
+// how many bytes it transferred and how many requests it made. The step fails when a measurement is over its
+// budget. Budgets that are not set are only measured.
+type PerformanceAudit struct {
+	// The longest the page's HTML may take to start arriving
+	MaxTtfb *metav1.Duration `json:"max_ttfb,omitempty"`
+
+	// The most bytes the page may transfer until the network is idle, headers included
+	// +kubebuilder:validation:Minimum=1
+	MaxPageWeightBytes *int64 `json:"max_page_weight_bytes,omitempty"`
+
+	// The most requests the page may make until the network is idle, the page itself included
+	// +kubebuilder:validation:Minimum=1
+	MaxRequests *int32 `json:"max_requests,omitempty"`
+
+	// The image to run the audit in. It needs Node.js and Chromium, and the playwright package, which is
+	// installed at the start of the audit if the image does not have it. Default is the official Playwright image
+	Image string `json:"image,omitempty"`
+}
+
+// What a performance audit measured
+// +kubebuilder:object:generate=false
+type PageMeasurements struct {
+	Ttfb       time.Duration
+	PageWeight int64
+	Requests   int32
+}
+
+// The last measurements of a page with a performance audit
+type PagePerformance struct {
+	// Name of the request
+	Request string `json:"request"`
+
+	Ttfb            metav1.Duration `json:"ttfb"`
+	PageWeightBytes int64           `json:"page_weight_bytes"`
+	Requests        int32           `json:"requests"`
+
+	MeasuredAt metav1.Time `json:"measured_at"`
+}
+
+// A page that loaded, but over one or more of its budgets
+type performanceBudgetError struct {
+	exceeded []string
+}
+
+func (e *performanceBudgetError) Error() string {
+	return "over the performance budget: " + strings.Join(e.exceeded, ", ")
+}
+
+func (p *PerformanceAudit) validate() error {
+	if p.MaxTtfb != nil && p.MaxTtfb.Duration <= 0 {
+		return fmt.Errorf("invalid performance: max_ttfb must be positive, not %s", p.MaxTtfb.Duration)
+	}
+	return nil
+}
+
+func (p *PerformanceAudit) image() string {
+	if p.Image == "" {
+		return defaultAuditImage
+	}
+	return p.Image
+}
+
+// Compare the measurements with the budgets
+func (p *PerformanceAudit) check(m *PageMeasurements) error {
+	var exceeded []string
+	if p.MaxTtfb != nil && m.Ttfb > p.MaxTtfb.Duration {
+		exceeded = append(exceeded, fmt.Sprintf("time to first byte of %s is more than %s", m.Ttfb, p.MaxTtfb.Duration))
+	}
+	if p.MaxPageWeightBytes != nil && m.PageWeight > *p.MaxPageWeightBytes {
+		exceeded = append(exceeded, fmt.Sprintf("page weight of %d bytes is more than %d", m.PageWeight, *p.MaxPageWeightBytes))
+	}
+	if p.MaxRequests != nil && m.Requests > *p.MaxRequests {
+		exceeded = append(exceeded, fmt.Sprintf("%d requests are more than %d", m.Requests, *p.MaxRequests))
+	}
+	if len(exceeded) > 0 {
+		return &performanceBudgetError{exceeded: exceeded}
+	}
+	return nil
+}
+
+// Parse the measurements the audit wrote to its termination message
+func parsePageMeasurements(message string) (*PageMeasurements, error) {
+	var reported struct {
+		TtfbMs          *float64 `json:"ttfb_ms"`
+		PageWeightBytes *int64   `json:"page_weight_bytes"`
+		Requests        *int32   `json:"requests"`
+	}
+	if err := json.Unmarshal([]byte(message), &reported); err != nil {
+		return nil, fmt.Errorf("the performance audit did not report its measurements: %w", err)
+	}
+	if reported.TtfbMs == nil || reported.PageWeightBytes == nil || reported.Requests == nil {
+		return nil, fmt.Errorf("the performance audit did not report every measurement: %s", message)
+	}
+	return &PageMeasurements{
+		Ttfb:       time.Duration(*reported.TtfbMs * float64(time.Millisecond)),
+		PageWeight: *reported.PageWeightBytes,
+		Requests:   *reported.Requests,
+	}, nil
+}
+
+// The Job that measures the page
+func (h *HttpMonitor) performanceJob(r *HttpRequest) *batchv1.Job {
+	container := corev1.Container{
+		Name:    "perf",
+		Image:   r.Performance.image(),
+		Command: []string{"sh", "-c", performanceAuditCommand},
+		Env:     []corev1.EnvVar{{Name: "AUDIT_SCRIPT", Value: performanceAuditScript}},
+		// The measurements are written to the termination message, and errors are at the end of the log
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+	return h.stepJob(r, "perf", container, nil)
+}
+
+// Measure the page and check it against the budgets. The measurements are kept when the page is over budget.
+func (h *HttpMonitor) runPerformanceAudit(r HttpRequest) *RequestResult {
+	result, message := h.runJob(r, h.performanceJob(&r))
+	if result.Err != nil {
+		return result
+	}
+	result.Performance, result.Err = parsePageMeasurements(message)
+	if result.Err == nil {
+		result.Err = r.Performance.check(result.Performance)
+	}
+	result.Category = failureCategory(result.Err)
+	return result
+}
+
+// Replace the measurements of the pages audited in a run. Pages that were not audited, like ones that were
+// skipped, keep their last measurements, and requests that no longer have an audit are dropped.
+func (h *HttpMonitor) RecordPerformance(previous []PagePerformance, result *RunResult) []PagePerformance {
+	measured := metav1.NewTime(result.Start)
+	latest := make(map[string]PagePerformance)
+	for _, p := range previous {
+		latest[p.Request] = p
+	}
+	for _, r := range result.Requests {
+		if r.Performance != nil {
+			latest[r.Name] = PagePerformance{
+				Request:         r.Name,
+				Ttfb:            metav1.Duration{Duration: r.Performance.Ttfb},
+				PageWeightBytes: r.Performance.PageWeight,
+				Requests:        r.Performance.Requests,
+				MeasuredAt:      measured,
+			}
+		}
+	}
+	var recorded []PagePerformance
+	for _, r := range h.Spec.Requests {
+		if p, ok := latest[r.Name]; ok && r.Performance != nil {
+			recorded = append(recorded, p)
+		}
+	}
+	return recorded
+}
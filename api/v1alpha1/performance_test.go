@@ -0,0 +1,122 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParsePageMeasurements(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		Message       string
+		Expected      *PageMeasurements
+		ExpectedError bool
+	}{
+		{"all measurements", `{"ttfb_ms":182.5,"page_weight_bytes":1048576,"requests":42}`,
+			&PageMeasurements{Ttfb: 182500 * time.Microsecond, PageWeight: 1048576, Requests: 42}, false},
+		{"missing measurement", `{"ttfb_ms":182.5,"requests":42}`, nil, true},
+		{"log instead of JSON", `page.goto: net::ERR_NAME_NOT_RESOLVED`, nil, true},
+	}
+
+	for _, testdata := range tests {
+		measurements, err := parsePageMeasurements(testdata.Message)
+		if (err != nil) != testdata.ExpectedError {
+			t.Errorf("[%s] unexpected error: %v", testdata.TestName, err)
+		}
+		if !reflect.DeepEqual(measurements, testdata.Expected) {
+			t.Errorf("[%s] unexpected measurements. Got: %+v, expected: %+v", testdata.TestName, measurements, testdata.Expected)
+		}
+	}
+}
+
+func TestPerformanceAudit_check(t *testing.T) {
+	weight, requests := int64(500000), int32(30)
+	audit := PerformanceAudit{MaxTtfb: &metav1.Duration{Duration: 300 * time.Millisecond},
+		MaxPageWeightBytes: &weight, MaxRequests: &requests}
+
+	tests := []struct {
+		TestName      string
+		Audit         PerformanceAudit
+		Measurements  PageMeasurements
+		ExpectedError string
+	}{
+		{"within budget", audit, PageMeasurements{Ttfb: 120 * time.Millisecond, PageWeight: 400000, Requests: 30}, ""},
+		{"over two budgets", audit, PageMeasurements{Ttfb: 450 * time.Millisecond, PageWeight: 400000, Requests: 31},
+			"over the performance budget: time to first byte of 450ms is more than 300ms, 31 requests are more than 30"},
+		{"no budgets", PerformanceAudit{}, PageMeasurements{Ttfb: time.Minute, PageWeight: 1 << 30, Requests: 1000}, ""},
+	}
+
+	for _, testdata := range tests {
+		err := testdata.Audit.check(&testdata.Measurements)
+		if testdata.ExpectedError == "" {
+			if err != nil {
+				t.Errorf("[%s] unexpected error: %v", testdata.TestName, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != testdata.ExpectedError {
+			t.Errorf("[%s] unexpected error. Got: %v, expected: %s", testdata.TestName, err, testdata.ExpectedError)
+		}
+		var budgetErr *performanceBudgetError
+		if !errors.As(err, &budgetErr) || failureCategory(err) != FailureCategoryOverPerformanceBudget {
+			t.Errorf("[%s] unexpected category %s", testdata.TestName, failureCategory(err))
+		}
+	}
+}
+
+func TestHttpMonitor_RecordPerformance(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC))
+	now := time.Date(2020, 6, 1, 13, 0, 0, 0, time.UTC)
+	h := &HttpMonitor{Spec: HttpMonitorSpec{Requests: []HttpRequest{
+		{Name: "home", Performance: &PerformanceAudit{}},
+		{Name: "pricing", Performance: &PerformanceAudit{}},
+		{Name: "api"},
+	}}}
+	previous := []PagePerformance{
+		{Request: "pricing", Requests: 20, MeasuredAt: earlier},
+		{Request: "removed", Requests: 10, MeasuredAt: earlier},
+	}
+	result := &RunResult{Start: now, Requests: []*RequestResult{
+		{Name: "home", Performance: &PageMeasurements{Ttfb: time.Second, PageWeight: 2048, Requests: 12}},
+		{Name: "api"},
+	}}
+
+	recorded := h.RecordPerformance(previous, result)
+	expected := []PagePerformance{
+		{Request: "home", Ttfb: metav1.Duration{Duration: time.Second}, PageWeightBytes: 2048, Requests: 12,
+			MeasuredAt: metav1.NewTime(now)},
+		{Request: "pricing", Requests: 20, MeasuredAt: earlier},
+	}
+	if !reflect.DeepEqual(recorded, expected) {
+		t.Errorf("unexpected performance. Got: %+v, expected: %+v", recorded, expected)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/protojson"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+// What protobuf request bodies are sent as, unless the request sets its own Content-Type
+const protobufContentType = "application/x-protobuf"
+
+func (p *ProtobufCodec) registry() (*protojson.Registry, error) {
+	if len(p.DescriptorSet) == 0 {
+		return nil, fmt.Errorf("descriptor set %s/%s is not loaded", p.DescriptorSetRef.Name, p.DescriptorSetRef.Key)
+	}
+	return protojson.NewRegistry(p.DescriptorSet)
+}
+
+// Encode the JSON form of the request message
+func (p *ProtobufCodec) encode(body []byte) ([]byte, error) {
+	registry, err := p.registry()
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := registry.Marshal(p.RequestMessage, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", p.RequestMessage, err)
+	}
+	return encoded, nil
+}
+
+// Replace a protobuf response body with its JSON form
+func (r *HttpRequest) decodeProtobufBody(resp *http.Response) error {
+	if r.Protobuf == nil || r.Protobuf.ResponseMessage == "" {
+		return nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "application/json" {
+		return nil
+	}
+	registry, err := r.Protobuf.registry()
+	if err != nil {
+		return err
+	}
+	decoded, err := registry.Unmarshal(r.Protobuf.ResponseMessage, readBodyAndReset(resp))
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", r.Protobuf.ResponseMessage, err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(decoded))
+	resp.ContentLength = int64(len(decoded))
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func testDescriptorSet(t *testing.T) []byte {
+	field := func(name, jsonName string, number int32, typ descriptor.FieldDescriptorProto_Type, typeName string, repeated bool) *descriptor.FieldDescriptorProto {
+		label := descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+		if repeated {
+			label = descriptor.FieldDescriptorProto_LABEL_REPEATED
+		}
+		f := &descriptor.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(jsonName),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	set := &descriptor.FileDescriptorSet{File: []*descriptor.FileDescriptorProto{{
+		Name:    proto.String("shop/v1/order.proto"),
+		Package: proto.String("shop.v1"),
+		EnumType: []*descriptor.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptor.EnumValueDescriptorProto{
+				{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+				{Name: proto.String("PAID"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptor.DescriptorProto{{
+			Name: proto.String("Order"),
+			Field: []*descriptor.FieldDescriptorProto{
+				field("id", "id", 1, descriptor.FieldDescriptorProto_TYPE_STRING, "", false),
+				field("total_cents", "totalCents", 2, descriptor.FieldDescriptorProto_TYPE_INT64, "", false),
+				field("items", "items", 3, descriptor.FieldDescriptorProto_TYPE_STRING, "", true),
+				field("status", "status", 4, descriptor.FieldDescriptorProto_TYPE_ENUM, ".shop.v1.Status", false),
+				field("counts", "counts", 5, descriptor.FieldDescriptorProto_TYPE_MESSAGE, ".shop.v1.Order.CountsEntry", true),
+				field("customer", "customer", 6, descriptor.FieldDescriptorProto_TYPE_MESSAGE, ".shop.v1.Order.Customer", false),
+			},
+			NestedType: []*descriptor.DescriptorProto{
+				{
+					Name: proto.String("CountsEntry"),
+					Field: []*descriptor.FieldDescriptorProto{
+						field("key", "key", 1, descriptor.FieldDescriptorProto_TYPE_STRING, "", false),
+						field("value", "value", 2, descriptor.FieldDescriptorProto_TYPE_INT32, "", false),
+					},
+					Options: &descriptor.MessageOptions{MapEntry: proto.Bool(true)},
+				},
+				{
+					Name: proto.String("Customer"),
+					Field: []*descriptor.FieldDescriptorProto{
+						field("balance", "balance", 1, descriptor.FieldDescriptorProto_TYPE_SINT32, "", false),
+					},
+				},
+			},
+		}},
+	}}}
+	encoded, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+func TestHttpRequest_Protobuf(t *testing.T) {
+	codec := &ProtobufCodec{
+		RequestMessage:  "shop.v1.Order",
+		ResponseMessage: "shop.v1.Order",
+		DescriptorSet:   testDescriptorSet(t),
+	}
+	r := &HttpRequest{
+		Method:             http.MethodPost,
+		Url:                "https://test.com/shop.v1.OrderService/Create",
+		Body:               `{"id":"{order}","total_cents":"1250","items":["a","b"],"status":"PAID","counts":{"a":2},"customer":{"balance":-3}}`,
+		Protobuf:           codec,
+		AvailableVariables: VariableList{{Name: "order", Value: "o-1"}},
+	}
+
+	req, err := r.BuildRequest()
+	if err != nil {
+		t.Fatalf("got err while building request: %s", err)
+	}
+	if req.Header.Get("Content-Type") != protobufContentType {
+		t.Errorf("expected Content-Type %s, got '%s'", protobufContentType, req.Header.Get("Content-Type"))
+	}
+	encoded, _ := ioutil.ReadAll(req.Body)
+
+	// Send the request body back as the response, and check it decodes to what was sent
+	resp := &http.Response{
+		Header: http.Header{"Content-Type": {protobufContentType}},
+		Body:   ioutil.NopCloser(bytes.NewReader(encoded)),
+	}
+	if err := r.decodeProtobufBody(resp); err != nil {
+		t.Fatalf("got err while decoding response: %s", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(readBodyAndReset(resp), &decoded); err != nil {
+		t.Fatalf("decoded body is not JSON: %s", err)
+	}
+	expected := map[string]interface{}{
+		"id":         "o-1",
+		"totalCents": "1250",
+		"items":      []interface{}{"a", "b"},
+		"status":     "PAID",
+		"counts":     map[string]interface{}{"a": 2.0},
+		"customer":   map[string]interface{}{"balance": -3.0},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("unexpected decoded response.\nGot:      %v\nExpected: %v", decoded, expected)
+	}
+
+	jsonResp := &http.Response{
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   ioutil.NopCloser(bytes.NewReader([]byte(`{"code":"not_found"}`))),
+	}
+	if err := r.decodeProtobufBody(jsonResp); err != nil {
+		t.Errorf("JSON responses should be left alone, got err: %s", err)
+	}
+
+	codec.DescriptorSet = nil
+	if _, err := r.BuildRequest(); err == nil {
+		t.Errorf("expected an error when the descriptor set is not loaded")
+	}
+}
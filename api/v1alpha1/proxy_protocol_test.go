@@ -0,0 +1,116 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"io"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Accepts connections that start with a PROXY header, sending back the header it got as the response body
+func proxyProtocolServer(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				var header []byte
+				if start, _ := reader.Peek(6); string(start) == "PROXY " {
+					line, _ := reader.ReadString('\n')
+					header = []byte(strings.TrimSpace(line))
+				} else {
+					fixed := make([]byte, 16)
+					if _, err := io.ReadFull(reader, fixed); err != nil {
+						return
+					}
+					rest := make([]byte, int(fixed[14])<<8|int(fixed[15]))
+					if _, err := io.ReadFull(reader, rest); err != nil {
+						return
+					}
+					header = append(fixed, rest...)
+				}
+				if _, err := http.ReadRequest(reader); err != nil {
+					return
+				}
+				resp := http.Response{StatusCode: http.StatusOK, ProtoMajor: 1, ProtoMinor: 1,
+					ContentLength: int64(len(header)), Body: ioutil.NopCloser(bytes.NewReader(header)), Close: true}
+				_ = resp.Write(conn)
+			}()
+		}
+	}()
+	return listener
+}
+
+func TestHttpRequest_proxyProtocol(t *testing.T) {
+	listener := proxyProtocolServer(t)
+	defer listener.Close()
+	httpclient.Initialize(0)
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	tests := []struct {
+		TestName       string
+		Version        ProxyProtocolVersion
+		ExpectedPrefix []byte
+	}{
+		{"v1", ProxyProtocolV1, []byte("PROXY TCP4 127.0.0.1 127.0.0.1 ")},
+		{"v2", ProxyProtocolV2, []byte("\r\n\r\n\x00\r\nQUIT\n\x21\x11\x00\x0c\x7f\x00\x00\x01\x7f\x00\x00\x01")},
+	}
+
+	for _, testdata := range tests {
+		r := HttpRequest{Name: testdata.TestName, Method: http.MethodGet, Url: "http://" + listener.Addr().String(),
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, ProxyProtocol: testdata.Version}
+		resp, result := r.timedSendRequest(httpclient.GetClient())
+		if result.Err != nil {
+			t.Errorf("[%s] unexpected error: %s", testdata.TestName, result.Err)
+			continue
+		}
+		header := readBodyAndReset(resp)
+		if !bytes.HasPrefix(header, testdata.ExpectedPrefix) {
+			t.Errorf("[%s] unexpected header. Got: %q, expected it to start with: %q", testdata.TestName, header,
+				testdata.ExpectedPrefix)
+		}
+		// The destination port is the server's
+		if testdata.Version == ProxyProtocolV2 && int(header[len(header)-2])<<8|int(header[len(header)-1]) != port {
+			t.Errorf("[%s] unexpected destination port in header %q", testdata.TestName, header)
+		}
+	}
+}
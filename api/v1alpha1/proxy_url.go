@@ -0,0 +1,41 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// The proxy schemes the HTTP client can send requests through
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks5": true}
+
+func (r *HttpRequest) validateProxyUrl() error {
+	if u, err := url.Parse(r.ProxyUrl); err != nil || !proxySchemes[u.Scheme] || u.Host == "" {
+		return fmt.Errorf("invalid proxy_url: %q is not an http, https or socks5 URL", r.ProxyUrl)
+	}
+	return nil
+}
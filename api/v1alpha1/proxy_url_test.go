@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHttpRequest_proxyUrl(t *testing.T) {
+	// Answers for any host, like a forward proxy would after fetching the page
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host != "orders.internal.invalid" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer proxy.Close()
+	httpclient.Initialize(0)
+
+	tests := []struct {
+		TestName      string
+		ProxyUrl      string
+		ExpectedError bool
+	}{
+		{"through the proxy", proxy.URL, false},
+		{"direct", "", true},
+		{"unusable proxy", "http://%zz", true},
+	}
+
+	for _, testdata := range tests {
+		r := HttpRequest{Name: testdata.TestName, Method: http.MethodGet, Url: "http://orders.internal.invalid/healthz",
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, ProxyUrl: testdata.ProxyUrl}
+		_, result := r.timedSendRequest(httpclient.GetClient())
+		if (result.Err != nil) != testdata.ExpectedError {
+			t.Errorf("[%s] unexpected error: %v", testdata.TestName, result.Err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// The characters of generated values, the same ones k8s.io/apimachinery/pkg/util/rand uses, so values never spell
+// words or have characters that are easy to confuse
+const randomAlphabet = "bcdfghjklmnpqrstvwxz2456789"
+
+// Picks the seed of each run. Seeds stay below 2^53, so they survive tools that read JSON numbers as doubles.
+var seeds = struct {
+	lock   sync.Mutex
+	source *rand.Rand
+}{source: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+func (h *HttpMonitor) randomSeed() int64 {
+	if h.Spec.RandomSeed != nil {
+		return *h.Spec.RandomSeed
+	}
+	seeds.lock.Lock()
+	defer seeds.lock.Unlock()
+	return seeds.source.Int63n(1 << 53)
+}
+
+// The variables available to every request from the start of a run. The same seed makes the same values.
+func newGeneratedVariables(seed int64) VariableList {
+	r := rand.New(rand.NewSource(seed))
+	return VariableList{
+		&Variable{
+			Name:  "random-8",
+			From:  FromTypeProvided,
+			Value: randomString(r, 8),
+		},
+		&Variable{
+			Name:  "random-16",
+			From:  FromTypeProvided,
+			Value: randomString(r, 16),
+		},
+	}
+}
+
+func randomString(r *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = randomAlphabet[r.Intn(len(randomAlphabet))]
+	}
+	return string(b)
+}
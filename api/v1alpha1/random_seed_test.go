@@ -0,0 +1,68 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHttpMonitor_randomSeed(t *testing.T) {
+	seed := int64(1234)
+	tests := []struct {
+		TestName string
+		Seed     *int64
+	}{
+		{"override", &seed},
+		{"picked", nil},
+	}
+	for _, testdata := range tests {
+		monitor := &HttpMonitor{Spec: HttpMonitorSpec{RandomSeed: testdata.Seed}}
+		got := monitor.randomSeed()
+		if testdata.Seed != nil && got != *testdata.Seed {
+			t.Errorf("[%s] unexpected seed %d", testdata.TestName, got)
+		}
+		if got < 0 || got >= 1<<53 {
+			t.Errorf("[%s] seed %d out of range", testdata.TestName, got)
+		}
+	}
+}
+
+func TestNewGeneratedVariables(t *testing.T) {
+	first, again, other := newGeneratedVariables(42), newGeneratedVariables(42), newGeneratedVariables(43)
+	if !reflect.DeepEqual(first, again) {
+		t.Errorf("unexpected variables %v for the same seed, expected %v", again, first)
+	}
+	if reflect.DeepEqual(first, other) {
+		t.Errorf("unexpected variables %v for another seed", other)
+	}
+	for _, variable := range first {
+		length := map[string]int{"random-8": 8, "random-16": 16}[variable.Name]
+		if len(variable.Value) != length {
+			t.Errorf("[%s] unexpected value %q", variable.Name, variable.Value)
+		}
+	}
+}
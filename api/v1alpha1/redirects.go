@@ -0,0 +1,112 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// Go's http client stops after as many
+const defaultMaxRedirects = 10
+
+// How a request follows redirects, and where they must lead
+type Redirects struct {
+	// Whether to follow redirects. When false, the redirect itself is the response, so expected_response_codes
+	// should expect it, like [302]. Default is true
+	Follow *bool `json:"follow,omitempty"`
+
+	// The most redirects to follow before the request fails. Default is 10
+	// +kubebuilder:validation:Minimum=0
+	MaxHops *int `json:"max_hops,omitempty"`
+
+	// A regular expression, in Go syntax, the URL of the final response must match after following redirects
+	FinalUrlMatches string `json:"final_url_matches,omitempty"`
+
+	// A regular expression, in Go syntax, the Location header of the response must match. Mostly useful when
+	// redirects are not followed
+	LocationMatches string `json:"location_matches,omitempty"`
+}
+
+func (r *Redirects) follow() bool {
+	return r.Follow == nil || *r.Follow
+}
+
+func (r *Redirects) maxHops() int {
+	if r.MaxHops == nil {
+		return defaultMaxRedirects
+	}
+	return *r.MaxHops
+}
+
+// Report the first pattern that does not compile
+func (r *Redirects) validate() error {
+	if _, err := regexp.Compile(r.FinalUrlMatches); err != nil {
+		return fmt.Errorf("invalid final_url_matches pattern %q: %w", r.FinalUrlMatches, err)
+	}
+	if _, err := regexp.Compile(r.LocationMatches); err != nil {
+		return fmt.Errorf("invalid location_matches pattern %q: %w", r.LocationMatches, err)
+	}
+	return nil
+}
+
+// A client that shares the connections of c, with this redirect policy
+func (r *Redirects) client(c *http.Client) *http.Client {
+	withPolicy := *c
+	withPolicy.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !r.follow() {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > r.maxHops() {
+			return fmt.Errorf("stopped after %d redirects", r.maxHops())
+		}
+		return nil
+	}
+	return &withPolicy
+}
+
+func (r *Redirects) verify(resp *http.Response) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	if r.FinalUrlMatches != "" {
+		final := resp.Request.URL.String()
+		if !regexp.MustCompile(r.FinalUrlMatches).MatchString(final) {
+			return fmt.Errorf("ended at %s, which does not match %q", final, r.FinalUrlMatches)
+		}
+	}
+	if r.LocationMatches != "" {
+		location := resp.Header.Get("Location")
+		if location == "" {
+			return fmt.Errorf("response has no Location header, expected one matching %q", r.LocationMatches)
+		}
+		if !regexp.MustCompile(r.LocationMatches).MatchString(location) {
+			return fmt.Errorf("Location is %s, which does not match %q", location, r.LocationMatches)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirects_verify(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/sso?client=shop", http.StatusFound)
+	})
+	mux.HandleFunc("/sso", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/account", http.StatusFound)
+	})
+	mux.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	// No client timeout, so other tests still see the same worst case run durations
+	httpclient.Initialize(0)
+	follow := func(b bool) *bool { return &b }
+	hops := func(n int) *int { return &n }
+
+	tests := []struct {
+		TestName    string
+		Codes       StatusCodeMatcher
+		Redirects   Redirects
+		ExpectedErr string
+	}{
+		{"followed", StatusCodeMatcher{intstr.FromInt(200)}, Redirects{FinalUrlMatches: "/account$"}, ""},
+		{"wrong final url", StatusCodeMatcher{intstr.FromInt(200)}, Redirects{FinalUrlMatches: "/sso"},
+			`ended at ` + server.URL + `/account, which does not match "/sso"`},
+		{"too many hops", StatusCodeMatcher{intstr.FromInt(200)}, Redirects{MaxHops: hops(1)},
+			`Get "/account": stopped after 1 redirects`},
+		{"not followed", StatusCodeMatcher{intstr.FromInt(302)},
+			Redirects{Follow: follow(false), LocationMatches: `^/sso\?client=`}, ""},
+		{"wrong location", StatusCodeMatcher{intstr.FromInt(302)},
+			Redirects{Follow: follow(false), LocationMatches: "^https://"},
+			`Location is /sso?client=shop, which does not match "^https://"`},
+	}
+
+	for _, testdata := range tests {
+		r := HttpRequest{Name: testdata.TestName, Method: http.MethodGet, Url: server.URL + "/login",
+			ExpectedResponseCodes: testdata.Codes, Redirects: &testdata.Redirects}
+		_, result := r.timedSendRequest(httpclient.GetClient())
+		out := ""
+		if result.Err != nil {
+			out = result.Err.Error()
+		}
+		if out != testdata.ExpectedErr {
+			t.Errorf("[%s] unexpected error. Got: %q, expected: %q", testdata.TestName, out, testdata.ExpectedErr)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A parsed only_if or skip_if condition. It holds when any of its groups does, and a group holds when all of its
+// comparisons do, so && binds tighter than ||.
+type requestCondition struct {
+	groups [][]comparison
+}
+
+// Two operands and how they compare, or a single operand whose truthiness is the result
+type comparison struct {
+	left, right string
+	op          string
+}
+
+// Longer operators come first, so <= is not read as <
+var conditionOperators = []string{"==", "!=", "=~", "!~", "<=", ">=", "<", ">", "&&", "||"}
+
+func parseCondition(text string) (*requestCondition, error) {
+	tokens, err := tokenizeCondition(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("condition is empty")
+	}
+	c := &requestCondition{groups: [][]comparison{nil}}
+	for i := 0; i < len(tokens); {
+		if tokens[i].operator {
+			return nil, fmt.Errorf("expected a value, got %s", tokens[i].text)
+		}
+		cmp := comparison{left: tokens[i].text}
+		i++
+		if i < len(tokens) && tokens[i].operator && tokens[i].text != "&&" && tokens[i].text != "||" {
+			cmp.op = tokens[i].text
+			if i+1 == len(tokens) || tokens[i+1].operator {
+				return nil, fmt.Errorf("expected a value after %s", cmp.op)
+			}
+			cmp.right = tokens[i+1].text
+			i += 2
+		}
+		last := len(c.groups) - 1
+		c.groups[last] = append(c.groups[last], cmp)
+		if i == len(tokens) {
+			break
+		}
+		switch tokens[i].text {
+		case "&&":
+		case "||":
+			c.groups = append(c.groups, nil)
+		default:
+			return nil, fmt.Errorf("expected && or || before %s", tokens[i].text)
+		}
+		i++
+		if i == len(tokens) {
+			return nil, fmt.Errorf("expected a value after %s", tokens[i-1].text)
+		}
+	}
+	return c, nil
+}
+
+type conditionToken struct {
+	text     string
+	operator bool
+}
+
+// Split a condition into values and operators. Values are quoted with " or ', or run up to the next space or
+// operator.
+func tokenizeCondition(text string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	for rest := strings.TrimSpace(text); rest != ""; rest = strings.TrimSpace(rest) {
+		if quote := rest[0]; quote == '"' || quote == '\'' {
+			end := strings.IndexByte(rest[1:], quote)
+			if end < 0 {
+				return nil, fmt.Errorf("%s is missing its closing quote", rest)
+			}
+			tokens = append(tokens, conditionToken{text: rest[1 : end+1]})
+			rest = rest[end+2:]
+			continue
+		}
+		if op := operatorPrefix(rest); op != "" {
+			tokens = append(tokens, conditionToken{text: op, operator: true})
+			rest = rest[len(op):]
+			continue
+		}
+		end := strings.IndexFunc(rest, func(r rune) bool { return r == ' ' || strings.ContainsRune("=!<>&|", r) })
+		if end < 0 {
+			end = len(rest)
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("unexpected %q", rest[:1])
+		}
+		tokens = append(tokens, conditionToken{text: rest[:end]})
+		rest = rest[end:]
+	}
+	return tokens, nil
+}
+
+func operatorPrefix(text string) string {
+	for _, op := range conditionOperators {
+		if strings.HasPrefix(text, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// Whether the condition holds, with placeholders replaced by the values of variables. Placeholders for
+// variables that are not available are an error, so a typo does not silently change which requests run.
+func (c *requestCondition) holds(variables VariableList) (bool, error) {
+	values := make(map[string]string, len(variables))
+	// The first variable with a name wins, like when placeholders are replaced elsewhere
+	for i := len(variables) - 1; i >= 0; i-- {
+		values[variables[i].Name] = variables[i].Value
+	}
+	var missing error
+	resolve := func(text string) string {
+		return placeholderRegex.ReplaceAllStringFunc(text, func(placeholder string) string {
+			name := placeholder[1 : len(placeholder)-1]
+			value, ok := values[name]
+			if !ok && missing == nil {
+				missing = fmt.Errorf("no variable named %q is available", name)
+			}
+			return value
+		})
+	}
+
+	for _, group := range c.groups {
+		groupHolds := true
+		for _, cmp := range group {
+			holds, err := cmp.holds(resolve(cmp.left), resolve(cmp.right))
+			if missing != nil {
+				return false, missing
+			}
+			if err != nil {
+				return false, err
+			}
+			if !holds {
+				groupHolds = false
+				break
+			}
+		}
+		if groupHolds {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c comparison) holds(left, right string) (bool, error) {
+	switch c.op {
+	case "":
+		return left != "" && left != "false" && left != "0", nil
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	case "=~", "!~":
+		re, err := regexp.Compile(right)
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %q: %s", right, err)
+		}
+		return re.MatchString(left) == (c.op == "=~"), nil
+	}
+	l, lErr := strconv.ParseFloat(left, 64)
+	r, rErr := strconv.ParseFloat(right, 64)
+	if lErr != nil || rErr != nil {
+		return false, fmt.Errorf("%s needs numbers, got %q and %q", c.op, left, right)
+	}
+	switch c.op {
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	}
+	return l >= r, nil
+}
+
+// Why the request is skipped, given the variables available to it, or empty if it runs
+func (r *HttpRequest) skipReason() (string, error) {
+	for _, check := range []struct {
+		field, text string
+		runsWhen    bool
+	}{{"only_if", r.OnlyIf, true}, {"skip_if", r.SkipIf, false}} {
+		if check.text == "" {
+			continue
+		}
+		c, err := parseCondition(check.text)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s: %s", check.field, err)
+		}
+		holds, err := c.holds(r.AvailableVariables)
+		if err != nil {
+			return "", fmt.Errorf("cannot evaluate %s: %s", check.field, err)
+		}
+		if holds != check.runsWhen {
+			if check.runsWhen {
+				return fmt.Sprintf("only_if %q does not hold", check.text), nil
+			}
+			return fmt.Sprintf("skip_if %q holds", check.text), nil
+		}
+	}
+	return "", nil
+}
+
+// Why the request is skipped on every run, or empty if it can be sent. Only conditions that refer to no variables
+// are evaluated, since they hold or not regardless of what earlier requests produce.
+func (r HttpRequest) alwaysSkipped() string {
+	if placeholderRegex.MatchString(r.OnlyIf) {
+		r.OnlyIf = ""
+	}
+	if placeholderRegex.MatchString(r.SkipIf) {
+		r.SkipIf = ""
+	}
+	r.AvailableVariables = nil
+	reason, err := r.skipReason()
+	if err != nil {
+		return ""
+	}
+	return reason
+}
+
+// Check that the conditions parse, so mistakes are rejected before the monitor runs
+func (r *HttpRequest) validateConditions() error {
+	for _, check := range []struct{ field, text string }{{"only_if", r.OnlyIf}, {"skip_if", r.SkipIf}} {
+		if check.text == "" {
+			continue
+		}
+		if _, err := parseCondition(check.text); err != nil {
+			return fmt.Errorf("invalid %s condition: %s", check.field, err)
+		}
+	}
+	return nil
+}
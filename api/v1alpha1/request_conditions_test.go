@@ -0,0 +1,80 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+)
+
+func TestHttpRequest_skipReason(t *testing.T) {
+	variables := VariableList{
+		{Name: "feature_enabled", Value: "true"},
+		{Name: "plan", Value: "enterprise"},
+		{Name: "count", Value: "12"},
+		{Name: "empty", Value: ""},
+	}
+	tests := []struct {
+		TestName    string
+		OnlyIf      string
+		SkipIf      string
+		Expected    string
+		ExpectedErr string
+	}{
+		{"no conditions", "", "", "", ""},
+		{"only if equal", `{feature_enabled} == "true"`, "", "", ""},
+		{"only if not equal", `'{feature_enabled}' == 'false'`, "", `only_if "'{feature_enabled}' == 'false'" does not hold`, ""},
+		{"skip if holds", "", "{plan} != free", `skip_if "{plan} != free" holds`, ""},
+		{"skip if does not hold", "", "{plan} == free", "", ""},
+		{"numbers", "{count} > 9 && {count} <= 12", "", "", ""},
+		{"numbers compared as numbers", "{count} >= 9.5", "", "", ""},
+		{"or", "{plan} == free || {count} > 100", "", `only_if "{plan} == free || {count} > 100" does not hold`, ""},
+		{"and binds tighter", "{plan} == free && {count} > 100 || {feature_enabled}", "", "", ""},
+		{"pattern", "{plan} =~ ^enter", "{plan} !~ prise$", "", ""},
+		{"truthy", "{empty}", "", `only_if "{empty}" does not hold`, ""},
+		{"placeholder inside a value", `"{plan}-{count}" == enterprise-12`, "", "", ""},
+		{"unknown variable", "{missing} == 1", "", "", `cannot evaluate only_if: no variable named "missing" is available`},
+		{"not a number", "{plan} > 1", "", "", `cannot evaluate only_if: > needs numbers, got "enterprise" and "1"`},
+		{"unterminated quote", "", `{plan} == "free`, "", `invalid skip_if: "free is missing its closing quote`},
+		{"missing value", "{plan} ==", "", "", "invalid only_if: expected a value after =="},
+		{"missing operator", "{plan} free", "", "", "invalid only_if: expected && or || before free"},
+	}
+
+	for _, testdata := range tests {
+		r := HttpRequest{Name: testdata.TestName, OnlyIf: testdata.OnlyIf, SkipIf: testdata.SkipIf,
+			AvailableVariables: variables}
+		out, err := r.skipReason()
+		errText := ""
+		if err != nil {
+			errText = err.Error()
+		}
+		if out != testdata.Expected {
+			t.Errorf("[%s] unexpected reason. Got: %q, expected: %q", testdata.TestName, out, testdata.Expected)
+		}
+		if errText != testdata.ExpectedErr {
+			t.Errorf("[%s] unexpected error. Got: %q, expected: %q", testdata.TestName, errText, testdata.ExpectedErr)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+// RequestBudgetSpec defines the desired state of RequestBudget
+type RequestBudgetSpec struct {
+	// Monitors in the same namespace whose labels match this selector count against the budget.
+	// An empty selector matches every monitor in the namespace.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// How many requests matching monitors may send each calendar month (UTC), including retries, cleanup and
+	// soaks. Unlimited when not set
+	// +kubebuilder:validation:Minimum=1
+	MaxRequests int64 `json:"max_requests,omitempty"`
+
+	// How many bytes of request and response bodies matching monitors may transfer each calendar month (UTC).
+	// Unlimited when not set
+	// +kubebuilder:validation:Minimum=1
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+}
+
+// RequestBudgetStatus defines the observed state of RequestBudget
+type RequestBudgetStatus struct {
+	// What matching monitors have used this month
+	Usage RequestUsage `json:"usage"`
+
+	// Whether matching monitors are skipping runs until the month is over
+	Exceeded bool `json:"exceeded"`
+
+	// Names of the monitors currently matched by the selector
+	MatchedMonitors []string `json:"matched_monitors,omitempty"`
+}
+
+// RequestBudget limits how many requests and bytes matching monitors may send each month
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Month",type=string,JSONPath=`.status.usage.month`
+// +kubebuilder:printcolumn:name="Requests",type=integer,JSONPath=`.status.usage.requests`
+// +kubebuilder:printcolumn:name="Max Requests",type=integer,JSONPath=`.spec.max_requests`
+// +kubebuilder:printcolumn:name="Exceeded",type=boolean,JSONPath=`.status.exceeded`
+type RequestBudget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RequestBudgetSpec   `json:"spec,omitempty"`
+	Status RequestBudgetStatus `json:"status,omitempty"`
+}
+
+// RequestBudgetList contains a list of RequestBudget
+// +kubebuilder:object:root=true
+type RequestBudgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RequestBudget `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RequestBudget{}, &RequestBudgetList{})
+}
+
+// Whether this budget applies to the given monitor
+func (b *RequestBudget) Matches(m *HttpMonitor) (bool, error) {
+	if b.Namespace != m.Namespace {
+		return false, nil
+	}
+	return selectorMatches(b.Spec.Selector, m)
+}
+
+// What the matching monitors have used during the given month. Usage recorded for earlier months is ignored.
+func (b *RequestBudget) UsageOf(monitors []HttpMonitor, month string) (RequestUsage, error) {
+	usage := RequestUsage{Month: month}
+	for i := range monitors {
+		matches, err := b.Matches(&monitors[i])
+		if err != nil {
+			return usage, err
+		}
+		monitorUsage := monitors[i].Status.Usage
+		if !matches || monitorUsage == nil || monitorUsage.Month != month {
+			continue
+		}
+		usage.Requests += monitorUsage.Requests
+		usage.Bytes += monitorUsage.Bytes
+	}
+	return usage, nil
+}
+
+// Whether usage has reached either limit of the budget
+func (b *RequestBudget) ExceededBy(usage RequestUsage) bool {
+	if b.Spec.MaxRequests > 0 && usage.Requests >= b.Spec.MaxRequests {
+		return true
+	}
+	return b.Spec.MaxBytes > 0 && usage.Bytes >= b.Spec.MaxBytes
+}
+
+// The calendar month usage is counted in, like 2020-06
+func UsageMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// Add traffic to the usage for a month, starting over when the month changes
+func (u *RequestUsage) Add(month string, requests, bytes int64) *RequestUsage {
+	next := RequestUsage{Month: month}
+	if u != nil && u.Month == month {
+		next = *u
+	}
+	next.Requests += requests
+	next.Bytes += bytes
+	return &next
+}
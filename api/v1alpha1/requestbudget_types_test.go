@@ -0,0 +1,89 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+)
+
+func TestRequestUsage_Add(t *testing.T) {
+	var usage *RequestUsage
+	usage = usage.Add("2020-06", 3, 100)
+	usage = usage.Add("2020-06", 2, 50)
+	if expected := (RequestUsage{Month: "2020-06", Requests: 5, Bytes: 150}); *usage != expected {
+		t.Errorf("expected %+v, got %+v", expected, *usage)
+	}
+	if next := usage.Add("2020-07", 1, 10); *next != (RequestUsage{Month: "2020-07", Requests: 1, Bytes: 10}) {
+		t.Errorf("expected usage to start over in a new month, got %+v", *next)
+	}
+}
+
+func TestRequestBudget_UsageOf(t *testing.T) {
+	monitor := func(name string, labels map[string]string, usage *RequestUsage) HttpMonitor {
+		return HttpMonitor{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: name, Labels: labels},
+			Status:     HttpMonitorStatus{Usage: usage},
+		}
+	}
+	monitors := []HttpMonitor{
+		monitor("paid-api", map[string]string{"vendor": "acme"}, &RequestUsage{Month: "2020-06", Requests: 90, Bytes: 1000}),
+		monitor("paid-api-old", map[string]string{"vendor": "acme"}, &RequestUsage{Month: "2020-05", Requests: 500}),
+		monitor("paid-api-new", map[string]string{"vendor": "acme"}, nil),
+		monitor("free-api", nil, &RequestUsage{Month: "2020-06", Requests: 1000}),
+	}
+	budget := &RequestBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "acme"},
+		Spec: RequestBudgetSpec{
+			Selector:    &metav1.LabelSelector{MatchLabels: map[string]string{"vendor": "acme"}},
+			MaxRequests: 100,
+		},
+	}
+
+	usage, err := budget.UsageOf(monitors, "2020-06")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (RequestUsage{Month: "2020-06", Requests: 90, Bytes: 1000}); !reflect.DeepEqual(usage, expected) {
+		t.Errorf("expected %+v, got %+v", expected, usage)
+	}
+	if budget.ExceededBy(usage) {
+		t.Errorf("expected 90 requests to be within a budget of 100")
+	}
+	usage.Requests = 100
+	if !budget.ExceededBy(usage) {
+		t.Errorf("expected 100 requests to use up a budget of 100")
+	}
+
+	budget.Spec = RequestBudgetSpec{MaxBytes: 1000}
+	if !budget.ExceededBy(RequestUsage{Bytes: 1000}) {
+		t.Errorf("expected 1000 bytes to use up a budget of 1000")
+	}
+	if (&RequestBudget{}).ExceededBy(RequestUsage{Requests: 1 << 40}) {
+		t.Errorf("expected a budget without limits to never be exceeded")
+	}
+}
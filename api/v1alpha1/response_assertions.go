@@ -0,0 +1,294 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/ghodss/yaml"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/oregondesignservices/monitoring-controller/internal/jsonschema"
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// A check on one response header. Every condition that is set must hold.
+type HeaderAssertion struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Whether the header must be present, or absent when false
+	Present *bool `json:"present,omitempty"`
+
+	// The exact value. Headers sent more than once are compared with their values joined by ", "
+	Equals *string `json:"equals,omitempty"`
+
+	// A regular expression, in Go syntax, the value must match
+	Matches string `json:"matches,omitempty"`
+}
+
+// A check on one value in a JSON response body. Every condition that is set must hold.
+type JsonAssertion struct {
+	// The path to the value, like vars_from_response: /status or /items/0/id
+	// +kubebuilder:validation:MinLength=1
+	JsonPath string `json:"json_path"`
+
+	// The value, as text. Numbers and booleans are compared as they are written, like 3 or true
+	Equals *string `json:"equals,omitempty"`
+
+	// A regular expression, in Go syntax, the value as text must match
+	Matches string `json:"matches,omitempty"`
+
+	// The least number of elements of an array or object, or characters of a string
+	// +kubebuilder:validation:Minimum=0
+	MinLength *int `json:"min_length,omitempty"`
+}
+
+// A JSON Schema the body must validate against, to catch changes to an API's contract that status codes
+// never show. Set either inline or config_map_ref. The supported keywords are listed in the README.
+type JsonSchema struct {
+	// The schema, as JSON or YAML
+	Inline string `json:"inline,omitempty"`
+
+	// ConfigMap key holding the schema, for schemas shared between monitors or generated from an API's spec.
+	// The key is read from binaryData, or from data if it is not there.
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"config_map_ref,omitempty"`
+
+	// The contents of the ConfigMap key, loaded by the controller
+	Loaded []byte `json:"-"`
+}
+
+// Checks on the content of a response, for endpoints that answer 200 with an error page
+type ResponseAssertions struct {
+	// Regular expressions, in Go syntax, that must all match somewhere in the body
+	BodyMatches []string `json:"body_matches,omitempty"`
+
+	// Text that must not appear anywhere in the body, like "stack trace". The comparison is case-sensitive
+	BodyNotContains []string `json:"body_not_contains,omitempty"`
+
+	// Regular expressions, in Go syntax, that must not match anywhere in the body, like (?i)maintenance
+	BodyNotMatches []string `json:"body_not_matches,omitempty"`
+
+	// Checks on values in a JSON body
+	Json []JsonAssertion `json:"json,omitempty"`
+
+	// Checks on response headers, like Cache-Control or CORS headers
+	Headers []HeaderAssertion `json:"headers,omitempty"`
+
+	// A schema the whole body must validate against
+	JsonSchema *JsonSchema `json:"json_schema,omitempty"`
+}
+
+// Report the first pattern that does not compile
+func (a *ResponseAssertions) validate() error {
+	for _, expr := range a.BodyMatches {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid body_matches pattern %q: %w", expr, err)
+		}
+	}
+	for _, expr := range a.BodyNotMatches {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid body_not_matches pattern %q: %w", expr, err)
+		}
+	}
+	for _, j := range a.Json {
+		if _, err := regexp.Compile(j.Matches); err != nil {
+			return fmt.Errorf("invalid pattern %q for %s: %w", j.Matches, j.JsonPath, err)
+		}
+	}
+	for _, h := range a.Headers {
+		if _, err := regexp.Compile(h.Matches); err != nil {
+			return fmt.Errorf("invalid pattern %q for header %s: %w", h.Matches, h.Name, err)
+		}
+	}
+	if a.JsonSchema != nil {
+		if err := a.JsonSchema.validate(); err != nil {
+			return fmt.Errorf("invalid json_schema: %w", err)
+		}
+	}
+	return nil
+}
+
+func (a *ResponseAssertions) verify(header http.Header, body []byte) error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+	for _, expr := range a.BodyMatches {
+		if !regexp.MustCompile(expr).Match(body) {
+			return fmt.Errorf("body does not match %q", expr)
+		}
+	}
+	for _, text := range a.BodyNotContains {
+		if bytes.Contains(body, []byte(text)) {
+			return fmt.Errorf("body contains %q", text)
+		}
+	}
+	for _, expr := range a.BodyNotMatches {
+		if regexp.MustCompile(expr).Match(body) {
+			return fmt.Errorf("body matches %q", expr)
+		}
+	}
+	for _, j := range a.Json {
+		if err := j.verify(body); err != nil {
+			return err
+		}
+	}
+	for _, h := range a.Headers {
+		if err := h.verify(header); err != nil {
+			return err
+		}
+	}
+	if a.JsonSchema != nil {
+		if err := a.JsonSchema.verify(body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check what can be checked without the ConfigMap, which the webhook cannot read
+func (s *JsonSchema) validate() error {
+	if (s.Inline == "") == (s.ConfigMapRef == nil) {
+		return errors.New("set one of inline or config_map_ref")
+	}
+	if s.Inline != "" {
+		_, err := s.compile()
+		return err
+	}
+	return nil
+}
+
+// Compiled schemas by the SHA-256 of their source, so a schema is compiled once rather than for every response.
+// Schemas that no longer match any monitor's source stay until the cache is full, and then it starts over.
+var compiledSchemas = struct {
+	lock    sync.Mutex
+	schemas map[[sha256.Size]byte]*jsonschema.Schema
+}{schemas: make(map[[sha256.Size]byte]*jsonschema.Schema)}
+
+const maxCompiledSchemas = 256
+
+func (s *JsonSchema) compile() (*jsonschema.Schema, error) {
+	source := []byte(s.Inline)
+	if s.ConfigMapRef != nil {
+		if s.Loaded == nil {
+			return nil, fmt.Errorf("the schema in configmap %s, key %s, was not loaded", s.ConfigMapRef.Name, s.ConfigMapRef.Key)
+		}
+		source = s.Loaded
+	}
+	key := sha256.Sum256(source)
+	compiledSchemas.lock.Lock()
+	schema, ok := compiledSchemas.schemas[key]
+	compiledSchemas.lock.Unlock()
+	if ok {
+		return schema, nil
+	}
+
+	// YAML is a superset of JSON, so this accepts both
+	data, err := yaml.YAMLToJSON(source)
+	if err != nil {
+		return nil, err
+	}
+	if schema, err = jsonschema.Compile(data); err != nil {
+		return nil, err
+	}
+	compiledSchemas.lock.Lock()
+	if len(compiledSchemas.schemas) >= maxCompiledSchemas {
+		compiledSchemas.schemas = make(map[[sha256.Size]byte]*jsonschema.Schema)
+	}
+	compiledSchemas.schemas[key] = schema
+	compiledSchemas.lock.Unlock()
+	return schema, nil
+}
+
+func (s *JsonSchema) verify(body []byte) error {
+	schema, err := s.compile()
+	if err != nil {
+		return fmt.Errorf("invalid json_schema: %w", err)
+	}
+	if err := schema.Validate(body); err != nil {
+		return fmt.Errorf("body does not match the JSON schema: %w", err)
+	}
+	return nil
+}
+
+func (h *HeaderAssertion) verify(header http.Header) error {
+	values, present := header[http.CanonicalHeaderKey(h.Name)]
+	if h.Present != nil && present != *h.Present {
+		if present {
+			return fmt.Errorf("header %s is present, expected it to be absent", h.Name)
+		}
+		return fmt.Errorf("header %s is missing", h.Name)
+	}
+	if h.Equals == nil && h.Matches == "" {
+		return nil
+	}
+	if !present {
+		return fmt.Errorf("header %s is missing", h.Name)
+	}
+	value := strings.Join(values, ", ")
+	if h.Equals != nil && value != *h.Equals {
+		return fmt.Errorf("header %s is %q, expected %q", h.Name, value, *h.Equals)
+	}
+	if h.Matches != "" && !regexp.MustCompile(h.Matches).MatchString(value) {
+		return fmt.Errorf("header %s is %q, which does not match %q", h.Name, value, h.Matches)
+	}
+	return nil
+}
+
+func (j *JsonAssertion) verify(body []byte) error {
+	value := jsoniter.Get(body, jsonPathKeys(splitJsonPath(j.JsonPath))...)
+	if value.LastError() != nil || value.ValueType() == jsoniter.InvalidValue {
+		return fmt.Errorf("%s is missing from the response", j.JsonPath)
+	}
+	text := value.ToString()
+	if j.Equals != nil && text != *j.Equals {
+		return fmt.Errorf("%s is %q, expected %q", j.JsonPath, text, *j.Equals)
+	}
+	if j.Matches != "" && !regexp.MustCompile(j.Matches).MatchString(text) {
+		return fmt.Errorf("%s is %q, which does not match %q", j.JsonPath, text, j.Matches)
+	}
+	if j.MinLength != nil {
+		var length int
+		switch value.ValueType() {
+		case jsoniter.ArrayValue, jsoniter.ObjectValue:
+			length = value.Size()
+		case jsoniter.StringValue:
+			length = utf8.RuneCountInString(text)
+		default:
+			return fmt.Errorf("%s is %s, which has no length", j.JsonPath, text)
+		}
+		if length < *j.MinLength {
+			return fmt.Errorf("%s has a length of %d, expected at least %d", j.JsonPath, length, *j.MinLength)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"testing"
+)
+
+func TestResponseAssertions_verify(t *testing.T) {
+	body := []byte(`<html><title>Orders</title><body><ul><li>order 1042</li></ul></body></html>`)
+
+	tests := []struct {
+		TestName    string
+		Assertions  ResponseAssertions
+		ExpectedErr string
+	}{
+		{"none", ResponseAssertions{}, ""},
+		{"matches", ResponseAssertions{BodyMatches: []string{"<title>Orders</title>", `order \d+`}}, ""},
+		{"does not match", ResponseAssertions{BodyMatches: []string{"Orders", "(?i)no orders yet"}},
+			`body does not match "(?i)no orders yet"`},
+		{"does not contain", ResponseAssertions{BodyNotContains: []string{"stack trace", "order 1043"}}, ""},
+		{"contains", ResponseAssertions{BodyNotContains: []string{"stack trace", "order 1042"}}, `body contains "order 1042"`},
+		{"does not match forbidden", ResponseAssertions{BodyNotMatches: []string{"(?i)maintenance"}}, ""},
+		{"matches forbidden", ResponseAssertions{BodyNotMatches: []string{"(?i)maintenance", `(?i)<title>orders`}},
+			`body matches "(?i)<title>orders"`},
+	}
+
+	for _, testdata := range tests {
+		err := testdata.Assertions.verify(nil, body)
+		out := ""
+		if err != nil {
+			out = err.Error()
+		}
+		if out != testdata.ExpectedErr {
+			t.Errorf("[%s] unexpected error. Got: %q, expected: %q", testdata.TestName, out, testdata.ExpectedErr)
+		}
+	}
+}
+
+func TestJsonAssertion_verify(t *testing.T) {
+	body := []byte(`{"status": "healthy", "version": "2.4.1", "replicas": 3, "ready": true, "items": [{"id": 7}], "checks": {}}`)
+	str := func(s string) *string { return &s }
+	length := func(n int) *int { return &n }
+
+	tests := []struct {
+		TestName    string
+		Assertion   JsonAssertion
+		ExpectedErr string
+	}{
+		{"equals", JsonAssertion{JsonPath: "/status", Equals: str("healthy")}, ""},
+		{"equals number", JsonAssertion{JsonPath: "/replicas", Equals: str("3")}, ""},
+		{"equals bool", JsonAssertion{JsonPath: "/ready", Equals: str("true")}, ""},
+		{"not equal", JsonAssertion{JsonPath: "/status", Equals: str("degraded")}, `/status is "healthy", expected "degraded"`},
+		{"matches", JsonAssertion{JsonPath: "/version", Matches: `^2\.`}, ""},
+		{"does not match", JsonAssertion{JsonPath: "/version", Matches: `^3\.`}, `/version is "2.4.1", which does not match "^3\\."`},
+		{"array length", JsonAssertion{JsonPath: "/items", MinLength: length(1)}, ""},
+		{"empty object", JsonAssertion{JsonPath: "/checks", MinLength: length(1)}, "/checks has a length of 0, expected at least 1"},
+		{"nested", JsonAssertion{JsonPath: "/items/0/id", Equals: str("7")}, ""},
+		{"missing", JsonAssertion{JsonPath: "/items/1/id", Equals: str("7")}, "/items/1/id is missing from the response"},
+		{"no length", JsonAssertion{JsonPath: "/replicas", MinLength: length(1)}, "/replicas is 3, which has no length"},
+	}
+
+	for _, testdata := range tests {
+		err := testdata.Assertion.verify(body)
+		out := ""
+		if err != nil {
+			out = err.Error()
+		}
+		if out != testdata.ExpectedErr {
+			t.Errorf("[%s] unexpected error. Got: %q, expected: %q", testdata.TestName, out, testdata.ExpectedErr)
+		}
+	}
+}
+
+func TestHeaderAssertion_verify(t *testing.T) {
+	header := http.Header{
+		"Cache-Control":                {"no-store"},
+		"X-Request-Id":                 {"5f1c2a"},
+		"Access-Control-Allow-Methods": {"GET", "POST"},
+	}
+	str := func(s string) *string { return &s }
+	yes, no := true, false
+
+	tests := []struct {
+		TestName    string
+		Assertion   HeaderAssertion
+		ExpectedErr string
+	}{
+		{"equals", HeaderAssertion{Name: "cache-control", Equals: str("no-store")}, ""},
+		{"not equal", HeaderAssertion{Name: "Cache-Control", Equals: str("public")}, `header Cache-Control is "no-store", expected "public"`},
+		{"repeated", HeaderAssertion{Name: "Access-Control-Allow-Methods", Equals: str("GET, POST")}, ""},
+		{"matches", HeaderAssertion{Name: "X-Request-Id", Matches: "^[0-9a-f]+$"}, ""},
+		{"does not match", HeaderAssertion{Name: "X-Request-Id", Matches: "^[0-9]+$"},
+			`header X-Request-Id is "5f1c2a", which does not match "^[0-9]+$"`},
+		{"present", HeaderAssertion{Name: "X-Request-Id", Present: &yes}, ""},
+		{"missing", HeaderAssertion{Name: "Strict-Transport-Security", Present: &yes}, "header Strict-Transport-Security is missing"},
+		{"absent", HeaderAssertion{Name: "Server", Present: &no}, ""},
+		{"not absent", HeaderAssertion{Name: "X-Request-Id", Present: &no}, "header X-Request-Id is present, expected it to be absent"},
+		{"missing value", HeaderAssertion{Name: "Vary", Equals: str("Origin")}, "header Vary is missing"},
+	}
+
+	for _, testdata := range tests {
+		err := testdata.Assertion.verify(header)
+		out := ""
+		if err != nil {
+			out = err.Error()
+		}
+		if out != testdata.ExpectedErr {
+			t.Errorf("[%s] unexpected error. Got: %q, expected: %q", testdata.TestName, out, testdata.ExpectedErr)
+		}
+	}
+}
+
+func TestJsonSchema_verify(t *testing.T) {
+	schema := `
+type: object
+required: [status, items]
+additionalProperties: false
+properties:
+  status:
+    enum: [healthy, degraded]
+  version:
+    type: string
+    pattern: '^\d+\.\d+\.\d+$'
+  items:
+    type: array
+    minItems: 1
+    items:
+      $ref: '#/definitions/item'
+definitions:
+  item:
+    type: object
+    required: [id]
+    properties:
+      id:
+        type: integer
+        minimum: 1
+      tags:
+        type: array
+        items: {type: string}
+`
+
+	tests := []struct {
+		TestName    string
+		Body        string
+		ExpectedErr string
+	}{
+		{"valid", `{"status": "healthy", "version": "2.4.1", "items": [{"id": 7, "tags": ["a"]}]}`, ""},
+		{"wrong type", `{"status": "healthy", "items": [{"id": "7"}]}`,
+			"body does not match the JSON schema: /items/0/id: expected integer, got string"},
+		{"missing property", `{"items": [{"id": 7}]}`,
+			`body does not match the JSON schema: /: missing required property "status"`},
+		{"extra property", `{"status": "healthy", "items": [{"id": 7}], "debug": true}`,
+			`body does not match the JSON schema: /: property "debug" is not allowed`},
+		{"not in enum", `{"status": "down", "items": [{"id": 7}]}`,
+			`body does not match the JSON schema: /status: "down" is not one of the allowed values`},
+		{"several problems", `{"status": "healthy", "version": "2.4", "items": [{"id": 0}, {"tags": [1]}, {}]}`,
+			`body does not match the JSON schema: /items/0/id: 0 is less than the minimum of 1; ` +
+				`/items/1: missing required property "id"; /items/1/tags/0: expected string, got integer; and 2 more`},
+		{"not json", `<html>`, "body does not match the JSON schema: not valid JSON: invalid character '<' looking for beginning of value"},
+	}
+
+	for _, testdata := range tests {
+		assertions := ResponseAssertions{JsonSchema: &JsonSchema{Inline: schema}}
+		err := assertions.verify(nil, []byte(testdata.Body))
+		out := ""
+		if err != nil {
+			out = err.Error()
+		}
+		if out != testdata.ExpectedErr {
+			t.Errorf("[%s] unexpected error. Got: %q, expected: %q", testdata.TestName, out, testdata.ExpectedErr)
+		}
+	}
+}
+
+func TestJsonSchema_validate(t *testing.T) {
+	tests := []struct {
+		TestName    string
+		Schema      JsonSchema
+		ExpectedErr string
+	}{
+		{"inline", JsonSchema{Inline: `{"type": "object"}`}, ""},
+		{"configmap", JsonSchema{ConfigMapRef: &corev1.ConfigMapKeySelector{Key: "orders.json"}}, ""},
+		{"neither", JsonSchema{}, "set one of inline or config_map_ref"},
+		{"unknown type", JsonSchema{Inline: `{"type": "int"}`}, `#/type: unknown type "int"`},
+		{"bad pattern", JsonSchema{Inline: `{"properties": {"id": {"pattern": "("}}}`},
+			"#/properties/id/pattern: invalid pattern \"(\": error parsing regexp: missing closing ): `(`"},
+		{"remote ref", JsonSchema{Inline: `{"$ref": "https://example.com/order.json"}`},
+			`$ref "https://example.com/order.json": only references within the schema, like #/definitions/item, are supported`},
+		{"dangling ref", JsonSchema{Inline: `{"$ref": "#/definitions/order"}`},
+			`$ref "#/definitions/order" does not point to anything in the schema`},
+		{"ref to itself", JsonSchema{Inline: `{"$ref": "#"}`},
+			`$ref "#" refers back to itself without applying to a value inside it`},
+		{"ref cycle", JsonSchema{Inline: `{"definitions": {"a": {"allOf": [{"$ref": "#/definitions/b"}]}, "b": {"$ref": "#/definitions/a"}}, "$ref": "#/definitions/a"}`},
+			`$ref "#/definitions/b" refers back to itself without applying to a value inside it`},
+		{"recursive ref", JsonSchema{Inline: `{"properties": {"children": {"type": "array", "items": {"$ref": "#"}}}}`}, ""},
+	}
+
+	for _, testdata := range tests {
+		err := testdata.Schema.validate()
+		out := ""
+		if err != nil {
+			out = err.Error()
+		}
+		if out != testdata.ExpectedErr {
+			t.Errorf("[%s] unexpected error. Got: %q, expected: %q", testdata.TestName, out, testdata.ExpectedErr)
+		}
+	}
+}
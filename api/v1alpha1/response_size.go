@@ -0,0 +1,62 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// Read at most max_response_bytes of the body, so an endpoint that streams an endless body cannot exhaust the
+// controller's memory. What was read is left in the body, for assertions and diagnostics.
+func (r *HttpRequest) limitResponseBody(resp *http.Response) error {
+	if r.MaxResponseBytes == nil {
+		return nil
+	}
+	max := *r.MaxResponseBytes
+	if resp.ContentLength > max {
+		_ = resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(nil))
+		return fmt.Errorf("response body of %d bytes is larger than the maximum of %d", resp.ContentLength, max)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, max+1))
+	_ = resp.Body.Close()
+	exceeded := int64(len(body)) > max
+	if exceeded {
+		body = body[:max]
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if exceeded {
+		return fmt.Errorf("response body is larger than the maximum of %d bytes", max)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHttpRequest_limitResponseBody(t *testing.T) {
+	body := strings.Repeat("x", 2048)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/streamed" {
+			// Flushing before the body is written sends it chunked, without a Content-Length
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	// No client timeout, so other tests still see the same worst case run durations
+	httpclient.Initialize(0)
+	size := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		TestName              string
+		Path                  string
+		MaxResponseBytes      *int64
+		ExpectedErr           string
+		ExpectedBytesReceived int64
+	}{
+		{"no maximum", "/", nil, "", 2048},
+		{"under the maximum", "/", size(2048), "", 2048},
+		{"content length over the maximum", "/", size(1024),
+			"response body of 2048 bytes is larger than the maximum of 1024", 0},
+		{"streamed over the maximum", "/streamed", size(1024),
+			"response body is larger than the maximum of 1024 bytes", 1024},
+	}
+
+	for _, testdata := range tests {
+		r := HttpRequest{Name: testdata.TestName, Method: http.MethodGet, Url: server.URL + testdata.Path,
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, MaxResponseBytes: testdata.MaxResponseBytes}
+		_, result := r.timedSendRequest(httpclient.GetClient())
+		out := ""
+		if result.Err != nil {
+			out = result.Err.Error()
+		}
+		if out != testdata.ExpectedErr {
+			t.Errorf("[%s] unexpected error. Got: %q, expected: %q", testdata.TestName, out, testdata.ExpectedErr)
+		}
+		if result.BytesReceived != testdata.ExpectedBytesReceived {
+			t.Errorf("[%s] unexpected bytes received. Got: %d, expected: %d", testdata.TestName, result.BytesReceived,
+				testdata.ExpectedBytesReceived)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"github.com/go-logr/logr"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// A kind of failure worth sending a request again for
+// +kubebuilder:validation:Enum=network_error;timeout;"5xx"
+type RetryCondition string
+
+var (
+	// The connection could not be made or broke before the response arrived
+	RetryOnNetworkError RetryCondition = "network_error"
+	// The request or a phase of it took longer than its timeout
+	RetryOnTimeout RetryCondition = "timeout"
+	// The server answered with a 5xx status
+	RetryOn5xx RetryCondition = "5xx"
+)
+
+// The first retry waits this long, unless the request says otherwise, doubling with each attempt
+const defaultRetryBackoff = time.Second
+
+func (r *HttpRequest) retryBackoff() time.Duration {
+	if r.RetryBackoff == nil {
+		return defaultRetryBackoff
+	}
+	return r.RetryBackoff.Duration
+}
+
+// Whether a failed attempt is one the request retries
+func (r *HttpRequest) retries(result *RequestResult) bool {
+	conditions := r.RetryOn
+	if len(conditions) == 0 {
+		conditions = []RetryCondition{RetryOnNetworkError, RetryOnTimeout, RetryOn5xx}
+	}
+	for _, condition := range conditions {
+		if retryCondition(result) == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// Which retry condition a failed attempt meets, if any. Failed assertions on other responses are never retried.
+func retryCondition(result *RequestResult) RetryCondition {
+	err := result.Err
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return RetryOnTimeout
+	}
+	if result.StatusCode >= 500 {
+		return RetryOn5xx
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return RetryOnNetworkError
+	}
+	return ""
+}
+
+// Send a request, and send it again with backoff while it fails in a way it retries. The result is the last
+// attempt's, with the traffic of every attempt.
+func (h *HttpMonitor) sendWithRetries(client *http.Client, httpRequest HttpRequest, logger logr.Logger) *RequestResult {
+	backoff := httpRequest.retryBackoff()
+	var bytesSent, bytesReceived int64
+	for attempt := 1; ; attempt++ {
+		resp, requestResult := httpRequest.timedSendRequest(client)
+		h.protectCapturedData(requestResult)
+		requestResult.Attempts = attempt
+		bytesSent += requestResult.BytesSent
+		bytesReceived += requestResult.BytesReceived
+		requestResult.BytesSent = bytesSent
+		requestResult.BytesReceived = bytesReceived
+		HandleMetrics(h, httpRequest, resp)
+		if requestResult.Err == nil || attempt > httpRequest.Retries || !httpRequest.retries(requestResult) {
+			return requestResult
+		}
+		HandleRetryMetrics(h, httpRequest, retryCondition(requestResult))
+		logger.Info("retrying request", "attempt", attempt, "error", requestResult.Err.Error(),
+			"backoff", backoff.String())
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHttpMonitor_sendWithRetries(t *testing.T) {
+	// /flaky fails with a 503 twice before it succeeds
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if r.URL.Path == "/flaky" && n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	httpclient.Initialize(0)
+	monitor := &HttpMonitor{}
+	monitor.Name = "retries"
+
+	tests := []struct {
+		TestName         string
+		Path             string
+		Retries          int
+		RetryOn          []RetryCondition
+		ExpectedAttempts int
+		ExpectedErr      bool
+	}{
+		{"no retries", "/flaky", 0, nil, 1, true},
+		{"retried until it succeeds", "/flaky", 2, nil, 3, false},
+		{"out of retries", "/flaky", 1, nil, 2, true},
+		{"5xx not retried", "/flaky", 2, []RetryCondition{RetryOnNetworkError}, 1, true},
+		{"unexpected status not retried", "/missing", 2, nil, 1, true},
+	}
+
+	for _, testdata := range tests {
+		atomic.StoreInt32(&calls, 0)
+		r := HttpRequest{Name: testdata.TestName, Method: http.MethodGet, Url: server.URL + testdata.Path,
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, Retries: testdata.Retries,
+			RetryBackoff: &metav1.Duration{Duration: time.Millisecond}, RetryOn: testdata.RetryOn}
+		var written []string
+		result := monitor.sendWithRetries(httpclient.GetClient(), r, recordingLogger{written: &written})
+		if result.Attempts != testdata.ExpectedAttempts {
+			t.Errorf("[%s] unexpected attempts. Got: %d, expected: %d", testdata.TestName, result.Attempts,
+				testdata.ExpectedAttempts)
+		}
+		if (result.Err != nil) != testdata.ExpectedErr {
+			t.Errorf("[%s] unexpected error. Got: %v, expected an error: %t", testdata.TestName, result.Err,
+				testdata.ExpectedErr)
+		}
+		// Every retry is logged
+		if len(written) != testdata.ExpectedAttempts-1 {
+			t.Errorf("[%s] unexpected log messages: %v", testdata.TestName, written)
+		}
+	}
+}
+
+func TestRetryCondition(t *testing.T) {
+	errTest := errors.New("test")
+	tests := []struct {
+		TestName string
+		Result   *RequestResult
+		Expected RetryCondition
+	}{
+		{"5xx", &RequestResult{StatusCode: 502, Err: errTest}, RetryOn5xx},
+		{"4xx", &RequestResult{StatusCode: 404, Err: errTest}, ""},
+		{"timeout", &RequestResult{Err: context.DeadlineExceeded}, RetryOnTimeout},
+		{"connection refused", &RequestResult{Err: &net.OpError{Op: "dial", Err: errTest}}, RetryOnNetworkError},
+		{"dropped connection", &RequestResult{Err: io.ErrUnexpectedEOF}, RetryOnNetworkError},
+	}
+
+	for _, testdata := range tests {
+		if got := retryCondition(testdata.Result); got != testdata.Expected {
+			t.Errorf("[%s] unexpected condition. Got: %q, expected: %q", testdata.TestName, got, testdata.Expected)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"time"
+)
+
+// The longest a single run could take: every request and cleanup attempt uses its full timeout, wake-ups use
+// their cold start budget, soaks run for their whole duration, cleanup retries wait out their backoff, and the
+// failed request is traced. Requests that run in parallel only count along the longest chain of dependencies.
+// Request timeouts are capped by clientTimeout when it is set, like the shared client does.
+func (h *HttpMonitor) WorstCaseRunDuration(clientTimeout time.Duration) time.Duration {
+	timeout := func(r HttpRequest) time.Duration {
+		d := r.timeout()
+		if clientTimeout > 0 && clientTimeout < d {
+			d = clientTimeout
+		}
+		return d
+	}
+
+	request := func(r HttpRequest) time.Duration {
+		d := timeout(r)
+		total := d
+		if r.ColdStart != nil {
+			total += timeout(r.wakeUpRequest())
+		}
+		if r.Soak != nil {
+			// The last requests of a soak can still be in flight when its duration ends
+			total += r.Soak.Duration.Duration
+		}
+		if r.RequireHttps {
+			// The cleartext variant is sent as well
+			total += d
+		}
+		backoff := r.retryBackoff()
+		for i := 0; i < r.Retries; i++ {
+			total += backoff + d
+			backoff *= 2
+		}
+		if r.ForEach != nil {
+			total *= time.Duration(r.ForEach.maxItems())
+		}
+		return total
+	}
+
+	var total time.Duration
+	if h.Spec.hasDependencies() {
+		// Requests that do not depend on each other run at the same time
+		total = h.Spec.criticalPath(request)
+	} else {
+		for _, r := range h.Spec.Requests {
+			total += request(r)
+		}
+	}
+
+	attempts := h.Spec.CleanupRetries + 1
+	for _, r := range h.Spec.Cleanup {
+		total += time.Duration(attempts) * timeout(r)
+		for i := 1; i < attempts; i++ {
+			total += cleanupRetryWait(i, timeout(r))
+		}
+	}
+
+	if h.Spec.Diagnostics != nil && h.Spec.Diagnostics.Traceroute {
+		// Only the request that failed the run is traced
+		total += tracerouteTimeout
+	}
+	if e := h.Spec.Executions; e != nil && e.Count > 1 {
+		// Executions run in rounds of up to max_parallel at a time
+		parallel := e.maxParallel()
+		total *= time.Duration((e.Count + parallel - 1) / parallel)
+	}
+	return total
+}
+
+// The timeout of the shared client, or zero before it is initialized
+func sharedClientTimeout() time.Duration {
+	if client := httpclient.GetClient(); client != nil {
+		return client.Timeout
+	}
+	return 0
+}
+
+// A run that takes longer than the period delays the next one, so the monitor runs less often than it says
+func (h *HttpMonitor) checkRunBudget() error {
+	if h.Spec.Period == nil {
+		return nil
+	}
+	if worst := h.WorstCaseRunDuration(sharedClientTimeout()); worst > h.Spec.Period.Duration {
+		return fmt.Errorf("a run can take up to %s, longer than the period of %s", worst, h.Spec.Period.Duration)
+	}
+	return nil
+}
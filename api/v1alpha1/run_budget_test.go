@@ -0,0 +1,116 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestHttpMonitor_WorstCaseRunDuration(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		Spec          HttpMonitorSpec
+		ClientTimeout time.Duration
+		Expected      time.Duration
+	}{
+		{
+			TestName: "default timeouts",
+			Spec:     HttpMonitorSpec{Requests: []HttpRequest{{Name: "a"}, {Name: "b"}}},
+			Expected: 10 * time.Second,
+		},
+		{
+			TestName:      "capped by the client timeout",
+			Spec:          HttpMonitorSpec{Requests: []HttpRequest{{Name: "a", Timeout: &metav1.Duration{Duration: time.Minute}}}},
+			ClientTimeout: 29 * time.Second,
+			Expected:      29 * time.Second,
+		},
+		{
+			TestName: "cleanup retries with backoff",
+			Spec: HttpMonitorSpec{
+				Requests:       []HttpRequest{{Name: "a", Timeout: &metav1.Duration{Duration: 2 * time.Second}}},
+				Cleanup:        []HttpRequest{{Name: "b", Timeout: &metav1.Duration{Duration: 2 * time.Second}}},
+				CleanupRetries: 2,
+			},
+			// 2s + 3 attempts of 2s + 1s and 2s of backoff
+			Expected: 11 * time.Second,
+		},
+		{
+			TestName: "cleanup backoff capped by the timeout",
+			Spec: HttpMonitorSpec{
+				Requests:       []HttpRequest{{Name: "a", Timeout: &metav1.Duration{Duration: 2 * time.Second}}},
+				Cleanup:        []HttpRequest{{Name: "b", Timeout: &metav1.Duration{Duration: 2 * time.Second}}},
+				CleanupRetries: 5,
+			},
+			// 2s + 6 attempts of 2s + 1s and four 2s of backoff
+			Expected: 23 * time.Second,
+		},
+		{
+			TestName: "soak, require_https and traceroute",
+			Spec: HttpMonitorSpec{
+				Requests: []HttpRequest{
+					{Name: "a", Soak: &Soak{Rate: 1, Duration: metav1.Duration{Duration: time.Minute}}},
+					{Name: "b", RequireHttps: true},
+				},
+				Diagnostics: &Diagnostics{Traceroute: true},
+			},
+			Expected: time.Minute + 5*time.Second + 10*time.Second + tracerouteTimeout,
+		},
+		{
+			TestName: "parallel requests count along the longest chain",
+			Spec: HttpMonitorSpec{Requests: []HttpRequest{
+				{Name: "a"},
+				{Name: "b", DependsOn: []string{"a"}},
+				{Name: "c", DependsOn: []string{"a"}, Timeout: &metav1.Duration{Duration: 20 * time.Second}},
+				{Name: "d"},
+			}},
+			// a, then c
+			Expected: 25 * time.Second,
+		},
+		{
+			TestName: "cold start budget",
+			Spec: HttpMonitorSpec{Requests: []HttpRequest{
+				{Name: "a", ColdStart: &ColdStart{Budget: &metav1.Duration{Duration: 20 * time.Second}}},
+			}},
+			Expected: 25 * time.Second,
+		},
+		{
+			TestName: "executions in rounds of max_parallel",
+			Spec: HttpMonitorSpec{Requests: []HttpRequest{{Name: "a"}},
+				Executions: &Executions{Count: 5, MaxParallel: 2}},
+			// 3 rounds of 5s
+			Expected: 15 * time.Second,
+		},
+	}
+
+	for _, testdata := range tests {
+		monitor := &HttpMonitor{Spec: testdata.Spec}
+		if out := monitor.WorstCaseRunDuration(testdata.ClientTimeout); out != testdata.Expected {
+			t.Errorf("[%s] unexpected duration. Got: %s, expected: %s", testdata.TestName, out, testdata.Expected)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// What a run record says about a run. It leaves out response data, error messages included, so records can be kept
+// wherever uptime evidence is needed, whatever the monitor's data residency.
+// +kubebuilder:object:generate=false
+type RunRecord struct {
+	Monitor string `json:"monitor"`
+	Uid     string `json:"uid"`
+	// The generation of the spec the run used
+	Generation int64 `json:"generation"`
+	// Counts the records of the monitor from 1, so a record missing from an archive shows
+	Sequence uint64 `json:"sequence"`
+	// Hex of the SHA-256 of the record before this one, exactly as it was signed. Empty for the first record.
+	Previous   string             `json:"previous,omitempty"`
+	Start      time.Time          `json:"start"`
+	DurationMs int64              `json:"duration_ms"`
+	State      MonitorState       `json:"state"`
+	Requests   []RunRecordRequest `json:"requests"`
+	// The run's cleanup requests, and cleanup left over from earlier runs that was retried
+	Cleanup []RunRecordRequest `json:"cleanup,omitempty"`
+}
+
+// +kubebuilder:object:generate=false
+type RunRecordRequest struct {
+	Name       string `json:"name"`
+	StatusCode int    `json:"status_code,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	// What kind of failure it was, like "the request timed out". Error messages can quote the response, so they
+	// are left out.
+	Error    string          `json:"error,omitempty"`
+	Category FailureCategory `json:"category,omitempty"`
+}
+
+// A run record and the controller's signature of it
+type SignedRunRecord struct {
+	// The record as JSON, exactly as it was signed
+	Record string `json:"record"`
+
+	// Base64 of the Ed25519 signature of record
+	Signature string `json:"signature"`
+
+	// Which controller key signed the record: the start of the SHA-256 of its public key
+	KeyId string `json:"key_id"`
+}
+
+// The record of a run of the monitor
+func (h *HttpMonitor) RunRecord(result *RunResult) RunRecord {
+	record := RunRecord{
+		Monitor:    h.Namespace + "/" + h.Name,
+		Uid:        string(h.UID),
+		Generation: h.Generation,
+		Sequence:   1,
+		Start:      result.Start.UTC(),
+		DurationMs: result.Duration.Milliseconds(),
+		State:      result.State(),
+		Requests:   runRecordRequests(result.Requests),
+	}
+	if cleanup := append(append([]*RequestResult(nil), result.Cleanup...), result.RetriedCleanup...); len(cleanup) > 0 {
+		record.Cleanup = runRecordRequests(cleanup)
+	}
+	return record
+}
+
+func runRecordRequests(results []*RequestResult) []RunRecordRequest {
+	entries := make([]RunRecordRequest, 0, len(results))
+	for _, request := range results {
+		entry := RunRecordRequest{
+			Name:       request.Name,
+			StatusCode: request.StatusCode,
+			DurationMs: request.Duration.Milliseconds(),
+			Category:   request.Category,
+		}
+		if request.Err != nil {
+			entry.Error = failureSummary(request.Err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Chain the record to the one signed before it, so records that are missing, reordered or replaced in an archive
+// break the chain. The record stays the first of a new chain when previous cannot be read.
+func (r *RunRecord) Follow(previous *SignedRunRecord) error {
+	if previous == nil {
+		return nil
+	}
+	var last RunRecord
+	if err := json.Unmarshal([]byte(previous.Record), &last); err != nil {
+		return fmt.Errorf("invalid previous run record: %w", err)
+	}
+	sum := sha256.Sum256([]byte(previous.Record))
+	r.Sequence = last.Sequence + 1
+	r.Previous = hex.EncodeToString(sum[:])
+	return nil
+}
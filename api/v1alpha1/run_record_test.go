@@ -0,0 +1,116 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHttpMonitor_RunRecord(t *testing.T) {
+	monitor := &HttpMonitor{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout", UID: "1234", Generation: 3}}
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.FixedZone("PDT", -7*3600))
+
+	tests := []struct {
+		TestName        string
+		Result          *RunResult
+		ExpectedState   MonitorState
+		ExpectedError   string
+		ExpectedCleanup []RunRecordRequest
+	}{
+		{"passed", &RunResult{Start: start, Requests: []*RequestResult{
+			{Name: "cart", StatusCode: 200, ResponseSnippet: "{\"items\": []}"},
+		}}, MonitorStateUp, "", nil},
+		{"failed", &RunResult{Start: start, Requests: []*RequestResult{
+			{Name: "cart", StatusCode: 503, Err: errors.New("unexpected status code 503")},
+		}}, MonitorStateDown, "the request failed", nil},
+		{"error quoting the response", &RunResult{Start: start, Requests: []*RequestResult{
+			{Name: "cart", StatusCode: 200, Err: errors.New(`body "token=secret" does not match "items"`)},
+		}}, MonitorStateDown, "the request failed", nil},
+		{"cleanup", &RunResult{Start: start, Requests: []*RequestResult{{Name: "cart", StatusCode: 200}},
+			Cleanup:        []*RequestResult{{Name: "empty cart", StatusCode: 204}},
+			RetriedCleanup: []*RequestResult{{Name: "delete user", Err: timeoutError{}}},
+		}, MonitorStateUp, "", []RunRecordRequest{
+			{Name: "empty cart", StatusCode: 204},
+			{Name: "delete user", Error: "the request timed out"},
+		}},
+	}
+
+	for _, testdata := range tests {
+		record := monitor.RunRecord(testdata.Result)
+		if record.Monitor != "shop/checkout" || record.Uid != "1234" || record.Generation != 3 {
+			t.Errorf("[%s] unexpected monitor: %+v", testdata.TestName, record)
+		}
+		if !record.Start.Equal(start) || record.Start.Location() != time.UTC {
+			t.Errorf("[%s] unexpected start %s", testdata.TestName, record.Start)
+		}
+		if record.State != testdata.ExpectedState {
+			t.Errorf("[%s] unexpected state %s", testdata.TestName, record.State)
+		}
+		if len(record.Requests) != 1 || record.Requests[0].Error != testdata.ExpectedError {
+			t.Errorf("[%s] unexpected requests: %+v", testdata.TestName, record.Requests)
+		}
+		if !reflect.DeepEqual(record.Cleanup, testdata.ExpectedCleanup) {
+			t.Errorf("[%s] unexpected cleanup: %+v", testdata.TestName, record.Cleanup)
+		}
+		if record.Sequence != 1 || record.Previous != "" {
+			t.Errorf("[%s] unexpected chain: %d %q", testdata.TestName, record.Sequence, record.Previous)
+		}
+	}
+}
+
+func TestRunRecord_Follow(t *testing.T) {
+	first := `{"monitor":"shop/checkout","sequence":41}`
+	tests := []struct {
+		TestName         string
+		Previous         *SignedRunRecord
+		ExpectedSequence uint64
+		ExpectedPrevious string
+		ExpectedErr      bool
+	}{
+		{"first", nil, 1, "", false},
+		{"chained", &SignedRunRecord{Record: first}, 42,
+			"1e9c951eff6f2c537944263f3f4cbbaa4b0ee212a5a1bf04727a84ffc272884f", false},
+		{"from before sequences", &SignedRunRecord{Record: `{"monitor":"shop/checkout"}`}, 1,
+			"f5f85305faaf58c1b2d23be36cdf8253a3e1b2a65da87bc26a8438b91a7822da", false},
+		{"unreadable", &SignedRunRecord{Record: "not json"}, 1, "", true},
+	}
+
+	for _, testdata := range tests {
+		record := (&HttpMonitor{}).RunRecord(&RunResult{})
+		err := record.Follow(testdata.Previous)
+		if (err != nil) != testdata.ExpectedErr {
+			t.Errorf("[%s] unexpected error: %v", testdata.TestName, err)
+		}
+		if record.Sequence != testdata.ExpectedSequence || record.Previous != testdata.ExpectedPrevious {
+			t.Errorf("[%s] unexpected chain. Got: %d %q, expected: %d %q", testdata.TestName, record.Sequence,
+				record.Previous, testdata.ExpectedSequence, testdata.ExpectedPrevious)
+		}
+	}
+}
@@ -0,0 +1,412 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"crypto/tls"
+	"errors"
+	"github.com/oregondesignservices/monitoring-controller/internal/netdiag"
+	"net"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// How long each phase of a request took. Phases that did not happen, like TLS for plain HTTP, are zero.
+// +kubebuilder:object:generate=false
+type PhaseTimings struct {
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	FirstByte    time.Duration
+
+	// Whether the request was sent on a connection kept alive from an earlier one, and how long it had been idle
+	ConnReused bool
+	ConnIdle   time.Duration
+
+	// The addresses the target host resolved to
+	ResolvedAddresses []string
+
+	// Dials may happen in parallel, so trace callbacks need a lock
+	lock sync.Mutex
+}
+
+func (p *PhaseTimings) clientTrace(start time.Time) *httptrace.ClientTrace {
+	var dnsStart, connectStart, tlsStart time.Time
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			dnsStart = time.Now()
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			p.DNS = time.Since(dnsStart)
+			for _, addr := range info.Addrs {
+				p.ResolvedAddresses = append(p.ResolvedAddresses, addr.String())
+			}
+		},
+		ConnectStart: func(string, string) {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			connectStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			p.Connect = time.Since(connectStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			p.ConnReused, p.ConnIdle = info.Reused, info.IdleTime
+		},
+		TLSHandshakeStart: func() {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			p.TLSHandshake = time.Since(tlsStart)
+		},
+		GotFirstResponseByte: func() {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			p.FirstByte = time.Since(start)
+		},
+	}
+}
+
+// Kinds of failures that are worth telling apart, in status, metrics and notifications
+type FailureCategory string
+
+var (
+	FailureCategoryHttp2GoAway          FailureCategory = "Http2GoAway"
+	FailureCategoryHttp2StreamError     FailureCategory = "Http2StreamError"
+	FailureCategoryHttp2ConnectionError FailureCategory = "Http2ConnectionError"
+	FailureCategoryHttp2PingFailed      FailureCategory = "Http2PingFailed"
+	FailureCategoryHttp2NotNegotiated   FailureCategory = "Http2NotNegotiated"
+	FailureCategoryCleartextServed      FailureCategory = "CleartextServed"
+	FailureCategoryNotCompliant         FailureCategory = "NotCompliant"
+
+	// Bot protection in front of the target answered with a challenge page instead of the application
+	FailureCategoryBotChallenged FailureCategory = "BotChallenged"
+
+	// A server did not offer STARTTLS, or did not require it when it should
+	FailureCategoryStartTlsDowngrade FailureCategory = "StartTlsDowngrade"
+
+	// Traffic left the cluster from an address partners do not expect
+	FailureCategoryEgressIpChanged FailureCategory = "EgressIpChanged"
+
+	// The target rejected the request while its clock was further from the controller's than allowed
+	FailureCategoryClockSkew FailureCategory = "ClockSkew"
+
+	// A page loaded, but was slower, heavier or made more requests than its budgets allow
+	FailureCategoryOverPerformanceBudget FailureCategory = "OverPerformanceBudget"
+
+	// A certificate the target presented expires sooner than min_certificate_remaining, or already has
+	FailureCategoryCertificateExpiring FailureCategory = "CertificateExpiring"
+)
+
+// The category of a request error, if it is one worth telling apart
+func failureCategory(err error) FailureCategory {
+	var h2Err *http2Error
+	if errors.As(err, &h2Err) {
+		return h2Err.category
+	}
+	var cleartextErr *cleartextError
+	if errors.As(err, &cleartextErr) {
+		return FailureCategoryCleartextServed
+	}
+	var complianceErr *complianceError
+	if errors.As(err, &complianceErr) {
+		return FailureCategoryNotCompliant
+	}
+	var challengeErr *botChallengeError
+	if errors.As(err, &challengeErr) {
+		return FailureCategoryBotChallenged
+	}
+	var downgradeErr *startTlsDowngradeError
+	if errors.As(err, &downgradeErr) {
+		return FailureCategoryStartTlsDowngrade
+	}
+	var egressErr *egressIpError
+	if errors.As(err, &egressErr) {
+		return FailureCategoryEgressIpChanged
+	}
+	var skewErr *clockSkewError
+	if errors.As(err, &skewErr) {
+		return FailureCategoryClockSkew
+	}
+	var budgetErr *performanceBudgetError
+	if errors.As(err, &budgetErr) {
+		return FailureCategoryOverPerformanceBudget
+	}
+	var expiryErr *certificateExpiryError
+	if errors.As(err, &expiryErr) {
+		return FailureCategoryCertificateExpiring
+	}
+	return ""
+}
+
+// The outcome of a single request made during a run
+// +kubebuilder:object:generate=false
+type RequestResult struct {
+	Name       string
+	Url        string
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+
+	// How long the response took to arrive, body included. Duration also counts checks like assertions.
+	ResponseTime time.Duration
+
+	// How important the failure is. Only set when Err is.
+	Severity Severity
+
+	// What kind of failure it was, when that is known
+	Category FailureCategory
+
+	// Who owns the request, with the monitor's ownership filled in
+	Ownership Ownership
+
+	// How many times the request was sent, including retries
+	Attempts int
+
+	// Bytes of request and response bodies, across all attempts
+	BytesSent     int64
+	BytesReceived int64
+
+	// Assertions that did not hold, for requests in observe-only mode
+	Violations []Violation
+
+	// How long the wake-up request took, for requests with a cold start
+	ColdStart time.Duration
+
+	// The aggregate results, for soaked requests
+	Soak *SoakResult
+
+	// What the audit measured, for requests with a performance audit
+	Performance *PageMeasurements
+
+	// The request as it was sent, with the variables it had available, so it can be sent again later
+	Request *HttpRequest
+
+	Timings PhaseTimings
+	TLS     *tls.ConnectionState
+
+	// Round trip times of the HTTP/2 pings sent on the connection
+	Http2PingRTTs []time.Duration
+
+	// The values of the headers listed in capture_headers, empty for headers the response did not have
+	CapturedHeaders map[string]string
+
+	// The start of the response body, captured when the request failed
+	ResponseSnippet string
+
+	// The network path to the target, captured when the request could not connect
+	HopReport *netdiag.Report
+}
+
+type MonitorState string
+
+var (
+	// Every request succeeded
+	MonitorStateUp MonitorState = "Up"
+	// Some requests succeeded before one failed
+	MonitorStateDegraded MonitorState = "Degraded"
+	// No request succeeded
+	MonitorStateDown MonitorState = "Down"
+	// The run was skipped because the target is scaled to zero or being torn down
+	MonitorStateTargetAbsent MonitorState = "TargetAbsent"
+	// The run was skipped because a request budget for the month is used up
+	MonitorStateBudgetExceeded MonitorState = "BudgetExceeded"
+	// The run was skipped because the controller is a standby, which sends no requests until failover
+	MonitorStateStandby MonitorState = "Standby"
+)
+
+// All known states, for callers that need to enumerate metric labels
+func MonitorStates() []MonitorState {
+	return []MonitorState{MonitorStateUp, MonitorStateDegraded, MonitorStateDown, MonitorStateTargetAbsent,
+		MonitorStateBudgetExceeded, MonitorStateStandby}
+}
+
+// The outcome of a single execution of an HttpMonitor
+// +kubebuilder:object:generate=false
+type RunResult struct {
+	Start    time.Time
+	Duration time.Duration
+	Requests []*RequestResult
+	Cleanup  []*RequestResult
+
+	// Cleanup requests from earlier runs that were sent again after this one
+	RetriedCleanup []*RequestResult
+
+	// Which request produced each variable, and which requests consumed it
+	Lineage []VariableLineage
+
+	// The values of the variables taken from responses, by name
+	Variables map[string]string
+
+	// The seed the generated variables were made from
+	RandomSeed int64
+
+	// For monitors with executions, the ones that did not decide the outcome of the tick, and how many of all
+	// the executions passed
+	OtherExecutions  []*RunResult
+	ExecutionsPassed int
+
+	// The name of the tick, which runners claim before running it
+	Execution string
+}
+
+// What a run sent and received
+// +kubebuilder:object:generate=false
+type Traffic struct {
+	Requests      int64
+	BytesSent     int64
+	BytesReceived int64
+}
+
+// Everything the run sent, including retries, cleanup, soaks and the other executions of the tick
+func (r *RunResult) Traffic() Traffic {
+	var traffic Traffic
+	for _, results := range [][]*RequestResult{r.Requests, r.Cleanup, r.RetriedCleanup} {
+		for _, result := range results {
+			traffic.Requests += int64(result.Attempts)
+			traffic.BytesSent += result.BytesSent
+			traffic.BytesReceived += result.BytesReceived
+		}
+	}
+	for _, other := range r.OtherExecutions {
+		otherTraffic := other.Traffic()
+		traffic.Requests += otherTraffic.Requests
+		traffic.BytesSent += otherTraffic.BytesSent
+		traffic.BytesReceived += otherTraffic.BytesReceived
+	}
+	return traffic
+}
+
+// Key/value pairs describing the run, for the summary logged at the end of each run. Every key is always
+// present, so log queries can rely on them. planned is how many requests the monitor has.
+func (r *RunResult) summary(planned int) []interface{} {
+	passed, failed := 0, 0
+	for _, result := range r.Requests {
+		if result.Err == nil {
+			passed++
+		} else {
+			failed++
+		}
+	}
+	cleanupFailed := 0
+	for _, result := range r.Cleanup {
+		if result.Err != nil {
+			cleanupFailed++
+		}
+	}
+	failedRequest, category := "", FailureCategory("")
+	if failure := r.FirstFailure(); failure != nil {
+		failedRequest, category = failure.Name, failure.Category
+	}
+	traffic := r.Traffic()
+	return []interface{}{
+		"state", r.State(),
+		"duration_ms", r.Duration.Milliseconds(),
+		"steps_passed", passed,
+		"steps_failed", failed,
+		"steps_skipped", planned - passed - failed,
+		"cleanup_failed", cleanupFailed,
+		"requests_sent", traffic.Requests,
+		"bytes_sent", traffic.BytesSent,
+		"bytes_received", traffic.BytesReceived,
+		"failed_request", failedRequest,
+		"category", category,
+		"random_seed", r.RandomSeed,
+	}
+}
+
+// A run fails when any of its requests fail. Cleanup failures are logged but do not fail the run.
+func (r *RunResult) Failed() bool {
+	return r.FirstFailure() != nil
+}
+
+// The request that failed the run, if any
+func (r *RunResult) FirstFailure() *RequestResult {
+	for _, result := range r.Requests {
+		if result.Err != nil {
+			return result
+		}
+	}
+	return nil
+}
+
+// The request that failed the run and the kind of failure, without the error message, for places that only show
+// a line, like a commit status
+func (r *RunResult) FailureSummary() string {
+	failure := r.FirstFailure()
+	if failure == nil {
+		return ""
+	}
+	return failure.Name + ": " + failureSummary(failure.Err)
+}
+
+// Distinguish partial outages from complete ones. Cleanup requests do not count.
+func (r *RunResult) State() MonitorState {
+	succeeded := 0
+	for _, result := range r.Requests {
+		if result.Err == nil {
+			succeeded++
+		}
+	}
+	switch {
+	case succeeded == len(r.Requests):
+		return MonitorStateUp
+	case succeeded > 0:
+		return MonitorStateDegraded
+	}
+	return MonitorStateDown
+}
+
+// The severity of the failure that failed the run, or empty if the run succeeded
+func (r *RunResult) Severity() Severity {
+	if failure := r.FirstFailure(); failure != nil {
+		return failure.Severity
+	}
+	return ""
+}
+
+// Connection errors mean we never got a response from the target, as opposed to getting an unexpected one
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
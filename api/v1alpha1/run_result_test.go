@@ -0,0 +1,153 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Err      error
+		Expected bool
+	}{
+		{
+			"dial-refused",
+			&url.Error{Op: "Get", URL: "http://test.com", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+			true,
+		},
+		{
+			"timeout",
+			&url.Error{Op: "Get", URL: "http://test.com", Err: timeoutError{}},
+			true,
+		},
+		{
+			"read-reset",
+			&url.Error{Op: "Get", URL: "http://test.com", Err: &net.OpError{Op: "read", Err: errors.New("connection reset")}},
+			false,
+		},
+		{
+			"unexpected-status",
+			errors.New("not an expected error code"),
+			false,
+		},
+	}
+
+	for _, testdata := range tests {
+		if out := isConnectionError(testdata.Err); out != testdata.Expected {
+			t.Errorf("[%s] unexpected result. Got: %t, expected: %t", testdata.TestName, out, testdata.Expected)
+		}
+	}
+}
+
+func TestRunResult_State(t *testing.T) {
+	failed := errors.New("failed")
+	tests := []struct {
+		TestName string
+		Requests []*RequestResult
+		Expected MonitorState
+	}{
+		{"all-succeeded", []*RequestResult{{}, {}}, MonitorStateUp},
+		{"later-request-failed", []*RequestResult{{}, {Err: failed}}, MonitorStateDegraded},
+		{"first-request-failed", []*RequestResult{{Err: failed}}, MonitorStateDown},
+		{"all-failed", []*RequestResult{{Err: failed}, {Err: failed}}, MonitorStateDown},
+	}
+
+	for _, testdata := range tests {
+		result := &RunResult{Requests: testdata.Requests, Cleanup: []*RequestResult{{Err: failed}}}
+		if out := result.State(); out != testdata.Expected {
+			t.Errorf("[%s] unexpected state. Got: %s, expected: %s", testdata.TestName, out, testdata.Expected)
+		}
+	}
+}
+
+func TestRunResult_FailureSummary(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Requests []*RequestResult
+		Expected string
+	}{
+		{"succeeded", []*RequestResult{{Name: "login"}}, ""},
+		{"failed", []*RequestResult{{Name: "login"}, {Name: "profile", Err: errors.New("body has jane@example.com")}},
+			"profile: the request failed"},
+		{"timed out", []*RequestResult{{Name: "login", Err: &url.Error{Op: "Get", URL: "https://example.com", Err: timeoutError{}}}},
+			"login: the request timed out"},
+	}
+
+	for _, testdata := range tests {
+		result := &RunResult{Requests: testdata.Requests}
+		if out := result.FailureSummary(); out != testdata.Expected {
+			t.Errorf("[%s] unexpected summary. Got: %s, expected: %s", testdata.TestName, out, testdata.Expected)
+		}
+	}
+}
+
+func TestRunResult_summary(t *testing.T) {
+	result := &RunResult{
+		Duration: 1500 * time.Millisecond,
+		Requests: []*RequestResult{
+			{Name: "login", Attempts: 1, BytesSent: 100, BytesReceived: 2000},
+			{Name: "order", Attempts: 2, BytesSent: 300, Err: errors.New("boom"), Category: FailureCategoryBotChallenged},
+		},
+		Cleanup:    []*RequestResult{{Name: "logout", Attempts: 1, Err: errors.New("gone")}},
+		RandomSeed: 42,
+	}
+	summary := result.summary(3)
+
+	fields := make(map[string]interface{})
+	for i := 0; i < len(summary); i += 2 {
+		fields[summary[i].(string)] = summary[i+1]
+	}
+	expected := map[string]interface{}{
+		"state":          MonitorStateDegraded,
+		"duration_ms":    int64(1500),
+		"steps_passed":   1,
+		"steps_failed":   1,
+		"steps_skipped":  1,
+		"cleanup_failed": 1,
+		"requests_sent":  int64(4),
+		"bytes_sent":     int64(400),
+		"bytes_received": int64(2000),
+		"failed_request": "order",
+		"category":       FailureCategoryBotChallenged,
+		"random_seed":    int64(42),
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("unexpected summary.\nGot:      %v\nExpected: %v", fields, expected)
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	samlBindingRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	samlBindingPost     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+)
+
+// The parts of SAML 2.0 metadata that are checked. Namespaces are ignored.
+type samlEntityDescriptor struct {
+	XMLName    xml.Name `xml:"EntityDescriptor"`
+	EntityId   string   `xml:"entityID,attr"`
+	ValidUntil string   `xml:"validUntil,attr"`
+	Idp        *struct {
+		KeyDescriptors []struct {
+			Use          string   `xml:"use,attr"`
+			Certificates []string `xml:"KeyInfo>X509Data>X509Certificate"`
+		} `xml:"KeyDescriptor"`
+		SingleSignOnServices []struct {
+			Binding  string `xml:"Binding,attr"`
+			Location string `xml:"Location,attr"`
+		} `xml:"SingleSignOnService"`
+	} `xml:"IDPSSODescriptor"`
+}
+
+// Fetch the metadata and check it, then follow the login to the identity provider
+func (s *SamlCheck) verify(ctx context.Context, client *http.Client, resp *http.Response) error {
+	metadata, err := s.fetchMetadata(ctx, client)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyMetadata(metadata, time.Now()); err != nil {
+		return err
+	}
+	return s.verifyLogin(ctx, client, metadata, resp)
+}
+
+func (s *SamlCheck) fetchMetadata(ctx context.Context, client *http.Client) (*samlEntityDescriptor, error) {
+	req, err := http.NewRequest(http.MethodGet, s.MetadataUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch SAML metadata: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch SAML metadata: %s responded with %d", s.MetadataUrl, resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch SAML metadata: %w", err)
+	}
+	metadata := &samlEntityDescriptor{}
+	if err := xml.Unmarshal(body, metadata); err != nil {
+		return nil, fmt.Errorf("invalid SAML metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+func (s *SamlCheck) verifyMetadata(metadata *samlEntityDescriptor, now time.Time) error {
+	if s.EntityId != "" && metadata.EntityId != s.EntityId {
+		return fmt.Errorf("SAML metadata describes %s, expected %s", metadata.EntityId, s.EntityId)
+	}
+	if metadata.ValidUntil != "" {
+		validUntil, err := time.Parse(time.RFC3339, metadata.ValidUntil)
+		if err != nil {
+			return fmt.Errorf("invalid validUntil in SAML metadata: %w", err)
+		}
+		if now.After(validUntil) {
+			return fmt.Errorf("SAML metadata expired at %s", validUntil.UTC())
+		}
+	}
+	if metadata.Idp == nil {
+		return fmt.Errorf("SAML metadata for %s has no IDPSSODescriptor", metadata.EntityId)
+	}
+	if len(metadata.Idp.SingleSignOnServices) == 0 {
+		return fmt.Errorf("SAML metadata for %s has no SingleSignOnService", metadata.EntityId)
+	}
+
+	var minRemaining time.Duration
+	if s.MinCertificateRemaining != nil {
+		minRemaining = s.MinCertificateRemaining.Duration
+	}
+	signing := 0
+	for _, key := range metadata.Idp.KeyDescriptors {
+		if key.Use != "" && key.Use != "signing" {
+			continue
+		}
+		for _, encoded := range key.Certificates {
+			signing++
+			// Certificates in metadata are usually wrapped over several lines
+			der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+			if err != nil {
+				return fmt.Errorf("invalid signing certificate in SAML metadata: %w", err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return fmt.Errorf("invalid signing certificate in SAML metadata: %w", err)
+			}
+			if remaining := cert.NotAfter.Sub(now); remaining < minRemaining || remaining <= 0 {
+				return fmt.Errorf("SAML signing certificate %s expires at %s", cert.Subject.CommonName, cert.NotAfter.UTC())
+			}
+		}
+	}
+	if signing == 0 {
+		return fmt.Errorf("SAML metadata for %s has no signing certificate", metadata.EntityId)
+	}
+	return nil
+}
+
+// Whether u is at the single sign-on service for a binding. Query strings are not compared, the redirect
+// binding adds its own.
+func (m *samlEntityDescriptor) ssoServiceFor(u *url.URL, binding string) bool {
+	for _, service := range m.Idp.SingleSignOnServices {
+		location, err := url.Parse(service.Location)
+		if err != nil || service.Binding != binding {
+			continue
+		}
+		if strings.EqualFold(location.Host, u.Host) && location.Path == u.Path {
+			return true
+		}
+	}
+	return false
+}
+
+// The client follows redirects on its own, so resp is where the login ended up: the identity provider for the
+// redirect binding, or a page of the service provider with a form to post to it.
+func (s *SamlCheck) verifyLogin(ctx context.Context, client *http.Client, metadata *samlEntityDescriptor, resp *http.Response) error {
+	final := resp.Request.URL
+	if metadata.ssoServiceFor(final, samlBindingRedirect) {
+		if final.Query().Get("SAMLRequest") == "" {
+			return fmt.Errorf("login redirected to %s without a SAMLRequest", final.Host+final.Path)
+		}
+		return nil
+	}
+
+	action, fields := samlPostForm(readBodyAndReset(resp))
+	if action == "" {
+		return fmt.Errorf("login ended at %s, which is not a single sign-on service of %s", final.Host+final.Path,
+			metadata.EntityId)
+	}
+	target, err := final.Parse(action)
+	if err != nil {
+		return fmt.Errorf("invalid SAML form action %q: %w", action, err)
+	}
+	if !metadata.ssoServiceFor(target, samlBindingPost) {
+		return fmt.Errorf("login posts to %s, which is not a single sign-on service of %s", target.Host+target.Path,
+			metadata.EntityId)
+	}
+	req, err := http.NewRequest(http.MethodPost, target.String(), strings.NewReader(fields.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	loginPage, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("identity provider login page is unreachable: %w", err)
+	}
+	loginPage.Body.Close()
+	if loginPage.StatusCode != http.StatusOK {
+		return fmt.Errorf("identity provider login page responded with %d", loginPage.StatusCode)
+	}
+	return nil
+}
+
+// Find the form that carries a SAMLRequest, and the fields it would post
+func samlPostForm(body []byte) (string, url.Values) {
+	tokens := html.NewTokenizer(bytes.NewReader(body))
+	var action string
+	var fields url.Values
+	for {
+		switch tokens.Next() {
+		case html.ErrorToken:
+			return "", nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokens.Token()
+			attrs := map[string]string{}
+			for _, attr := range token.Attr {
+				attrs[attr.Key] = attr.Val
+			}
+			switch token.Data {
+			case "form":
+				action, fields = attrs["action"], url.Values{}
+			case "input":
+				if fields != nil && attrs["name"] != "" {
+					fields.Set(attrs["name"], attrs["value"])
+				}
+			}
+		case html.EndTagToken:
+			if token := tokens.Token(); token.Data == "form" && fields.Get("SAMLRequest") != "" {
+				return action, fields
+			}
+		}
+	}
+}
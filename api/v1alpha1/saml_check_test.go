@@ -0,0 +1,127 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSamlCheck_verify(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "idp-signing"},
+		NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(72 * time.Hour)}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	cert := base64.StdEncoding.EncodeToString(der)
+	// Wrap the certificate the way metadata usually does
+	wrapped := cert[:64] + "\n        " + cert[64:]
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metadata":
+			fmt.Fprintf(w, `<?xml version="1.0"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" xmlns:ds="http://www.w3.org/2000/09/xmldsig#" entityID="https://idp.example.com">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo><ds:X509Data><ds:X509Certificate>%s</ds:X509Certificate></ds:X509Data></ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="%s" Location="%s/sso/redirect"/>
+    <md:SingleSignOnService Binding="%s" Location="%s/sso/post"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`, wrapped, samlBindingRedirect, server.URL, samlBindingPost, server.URL)
+		case "/sp/redirect":
+			http.Redirect(w, r, "/sso/redirect?SAMLRequest=abc&RelayState=xyz", http.StatusFound)
+		case "/sp/no-request":
+			http.Redirect(w, r, "/sso/redirect", http.StatusFound)
+		case "/sp/elsewhere":
+			http.Redirect(w, r, "/error", http.StatusFound)
+		case "/sp/post":
+			fmt.Fprintf(w, `<html><body onload="document.forms[0].submit()">
+<form method="post" action="%s/sso/post"><input type="hidden" name="SAMLRequest" value="abc"/>
+<input type="hidden" name="RelayState" value="xyz"/></form></body></html>`, server.URL)
+		case "/sp/post-broken":
+			fmt.Fprint(w, `<form method="post" action="/sso/post?broken=1"><input type="hidden" name="SAMLRequest" value="abc"></form>`)
+		case "/sso/post":
+			_ = r.ParseForm()
+			if r.Method != http.MethodPost || r.PostForm.Get("SAMLRequest") != "abc" || r.URL.Query().Get("broken") != "" {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		TestName    string
+		Check       SamlCheck
+		Login       string
+		ExpectedErr string
+	}{
+		{"redirect binding", SamlCheck{EntityId: "https://idp.example.com"}, "/sp/redirect", ""},
+		{"post binding", SamlCheck{}, "/sp/post", ""},
+		{"login page fails", SamlCheck{}, "/sp/post-broken", "login page responded with 500"},
+		{"no SAMLRequest", SamlCheck{}, "/sp/no-request", "without a SAMLRequest"},
+		{"not at the idp", SamlCheck{}, "/sp/elsewhere", "not a single sign-on service"},
+		{"wrong entity", SamlCheck{EntityId: "https://other.example.com"}, "/sp/redirect", "expected https://other.example.com"},
+		{"certificate expiring", SamlCheck{MinCertificateRemaining: &metav1.Duration{Duration: 30 * 24 * time.Hour}},
+			"/sp/redirect", "certificate idp-signing expires"},
+		{"no metadata", SamlCheck{MetadataUrl: server.URL + "/missing"}, "/sp/redirect", "invalid SAML metadata"},
+	}
+
+	for _, testdata := range tests {
+		if testdata.Check.MetadataUrl == "" {
+			testdata.Check.MetadataUrl = server.URL + "/metadata"
+		}
+		resp, err := server.Client().Get(server.URL + testdata.Login)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = testdata.Check.verify(context.Background(), server.Client(), resp)
+		_ = resp.Body.Close()
+		if testdata.ExpectedErr == "" {
+			if err != nil {
+				t.Errorf("[%s] got unexpected err: %s", testdata.TestName, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), testdata.ExpectedErr) {
+			t.Errorf("[%s] unexpected error. Got: %v, expected it to contain: %q", testdata.TestName, err, testdata.ExpectedErr)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// The plain HTTP variant of an HTTPS URL answered with something other than a redirect to HTTPS
+type cleartextError struct {
+	err error
+}
+
+func (e *cleartextError) Error() string {
+	return e.err.Error()
+}
+
+// Whether the plain HTTP response is one we accept: a redirect to HTTPS
+func redirectsToHttps(resp *http.Response) bool {
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		return false
+	}
+	location, err := resp.Location()
+	return err == nil && location.Scheme == "https"
+}
+
+// Make sure the target never serves the URL over cleartext. The plain HTTP variant, on the default port, must
+// either be refused or redirect to HTTPS.
+func checkCleartextRefused(ctx context.Context, client *http.Client, target *url.URL) error {
+	if target.Scheme != "https" {
+		return &cleartextError{fmt.Errorf("require_https is set but %s is not an HTTPS URL", target.Host)}
+	}
+	plain := *target
+	plain.Scheme = "http"
+	plain.Host = target.Hostname()
+	if strings.Contains(plain.Host, ":") {
+		plain.Host = "[" + plain.Host + "]"
+	}
+
+	req, err := http.NewRequest(http.MethodGet, plain.String(), nil)
+	if err != nil {
+		return err
+	}
+	noRedirects := &http.Client{
+		Transport: client.Transport,
+		Timeout:   client.Timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := noRedirects.Do(req.WithContext(ctx))
+	if err != nil {
+		// Nothing listening, a firewall dropping the connection, or anything else that is not an HTTP response
+		// means the URL is not served over cleartext
+		return nil
+	}
+	defer resp.Body.Close()
+	if redirectsToHttps(resp) {
+		return nil
+	}
+	return &cleartextError{fmt.Errorf("%s answered over plain HTTP with %d instead of refusing or redirecting to HTTPS",
+		plain.Host, resp.StatusCode)}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRedirectsToHttps(t *testing.T) {
+	tests := []struct {
+		TestName string
+		Status   int
+		Location string
+		Expected bool
+	}{
+		{"redirect-to-https", http.StatusMovedPermanently, "https://test.com/login", true},
+		{"redirect-to-http", http.StatusFound, "http://test.com/login", false},
+		{"relative-redirect", http.StatusFound, "/login", false},
+		{"served", http.StatusOK, "", false},
+	}
+
+	for _, testdata := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "http://test.com/login", nil)
+		resp := &http.Response{StatusCode: testdata.Status, Header: http.Header{}, Request: req}
+		if testdata.Location != "" {
+			resp.Header.Set("Location", testdata.Location)
+		}
+		if out := redirectsToHttps(resp); out != testdata.Expected {
+			t.Errorf("[%s] unexpected result. Got: %t, expected: %t", testdata.TestName, out, testdata.Expected)
+		}
+	}
+}
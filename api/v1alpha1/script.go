@@ -0,0 +1,184 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ScriptEngine string
+
+var (
+	ScriptEngineK6         ScriptEngine = "k6"
+	ScriptEnginePlaywright ScriptEngine = "playwright"
+)
+
+const (
+	defaultScriptTimeout = 2 * time.Minute
+	scriptMountPath      = "/scripts"
+
+	// Finished Jobs are deleted by the runner. This is for the ones it could not delete.
+	scriptJobTtlSeconds = int32(10 * 60)
+
+	ScriptMonitorLabel = "monitoring.raisingthefloor.org/monitor"
+)
+
+var defaultScriptImages = map[ScriptEngine]string{
+	ScriptEngineK6:         "grafana/k6:0.45.0",
+	ScriptEnginePlaywright: "mcr.microsoft.com/playwright:v1.38.0-jammy",
+}
+
+// Creates script Jobs and waits for them to finish. The api package has no cluster client, so the controller
+// sets one with SetScriptRunner. Jobs that succeed return their termination message, for steps that report
+// measurements in it.
+// +kubebuilder:object:generate=false
+type ScriptRunner interface {
+	RunScript(ctx context.Context, job *batchv1.Job) (string, error)
+}
+
+var scriptRunner ScriptRunner
+
+func SetScriptRunner(runner ScriptRunner) {
+	scriptRunner = runner
+}
+
+var invalidEnvCharacters = regexp.MustCompile(`[^A-Z0-9_]`)
+
+func (s *ScriptStep) image() string {
+	if s.Image != "" {
+		return s.Image
+	}
+	return defaultScriptImages[s.Engine]
+}
+
+func (s *ScriptStep) command() []string {
+	if len(s.Command) > 0 {
+		return s.Command
+	}
+	script := path.Join(scriptMountPath, s.ScriptRef.Key)
+	if s.Engine == ScriptEnginePlaywright {
+		return []string{"npx", "playwright", "test", script}
+	}
+	return []string{"k6", "run", script}
+}
+
+// The Job that runs a script step
+func (h *HttpMonitor) scriptJob(r *HttpRequest) *batchv1.Job {
+	container := corev1.Container{
+		Name:    string(r.Script.Engine),
+		Image:   r.Script.image(),
+		Command: r.Script.command(),
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      "scripts",
+			MountPath: scriptMountPath,
+			ReadOnly:  true,
+		}},
+	}
+	volumes := []corev1.Volume{{
+		Name: "scripts",
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: r.Script.ScriptRef.LocalObjectReference,
+		}},
+	}}
+	return h.stepJob(r, "script", container, volumes)
+}
+
+// A Job that runs a step in container, with the step's URL and variables in its environment. It never retries,
+// a failed attempt fails the step.
+func (h *HttpMonitor) stepJob(r *HttpRequest, kind string, container corev1.Container, volumes []corev1.Volume) *batchv1.Job {
+	replacer := r.AvailableVariables.newReplacer()
+	env := []corev1.EnvVar{{Name: "TARGET_URL", Value: replacer.Replace(r.Url)}}
+	var variables []corev1.EnvVar
+	for _, variable := range r.AvailableVariables {
+		name := "VAR_" + invalidEnvCharacters.ReplaceAllString(strings.ToUpper(variable.Name), "_")
+		variables = append(variables, corev1.EnvVar{Name: name, Value: variable.Value})
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i].Name < variables[j].Name })
+	env = append(env, variables...)
+	container.Env = append(env, container.Env...)
+
+	noRetries := int32(0)
+	ttl := scriptJobTtlSeconds
+	deadline := int64(r.timeout().Seconds())
+	prefix := strings.ToLower(h.Name)
+	if len(prefix) > 40 {
+		prefix = prefix[:40]
+	}
+	labels := map[string]string{ScriptMonitorLabel: h.Name}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: strings.TrimRight(prefix, "-.") + "-" + kind + "-",
+			Namespace:    h.Namespace,
+			Labels:       labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &noRetries,
+			ActiveDeadlineSeconds:   &deadline,
+			TTLSecondsAfterFinished: &ttl,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers:    []corev1.Container{container},
+					Volumes:       volumes,
+				},
+			},
+		},
+	}
+}
+
+// Run a script step to completion
+func (h *HttpMonitor) runScript(r HttpRequest) *RequestResult {
+	result, _ := h.runJob(r, h.scriptJob(&r))
+	return result
+}
+
+// Run the Job of a step to completion. The step fails when the Job does. Returns the Job's termination message
+// when it succeeds.
+func (h *HttpMonitor) runJob(r HttpRequest, job *batchv1.Job) (*RequestResult, string) {
+	result := &RequestResult{Name: r.Name, Url: r.AvailableVariables.newReplacer().Replace(r.Url), Attempts: 1}
+	start := time.Now()
+	var message string
+	if scriptRunner == nil {
+		result.Err = errors.New("steps that run in a Job are not available without a cluster")
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout())
+		message, result.Err = scriptRunner.RunScript(ctx, job)
+		cancel()
+	}
+	result.Duration = time.Since(start)
+	return result, message
+}
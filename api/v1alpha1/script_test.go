@@ -0,0 +1,86 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHttpMonitor_scriptJob(t *testing.T) {
+	scriptRef := corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "checkout-scripts"}, Key: "checkout.js"}
+	tests := []struct {
+		TestName        string
+		Script          ScriptStep
+		Timeout         *metav1.Duration
+		ExpectedImage   string
+		ExpectedCommand []string
+		ExpectedTimeout int64
+	}{
+		{"k6 defaults", ScriptStep{Engine: ScriptEngineK6, ScriptRef: scriptRef}, nil,
+			defaultScriptImages[ScriptEngineK6], []string{"k6", "run", "/scripts/checkout.js"}, 120},
+		{"playwright defaults", ScriptStep{Engine: ScriptEnginePlaywright, ScriptRef: scriptRef}, &metav1.Duration{Duration: 5 * time.Minute},
+			defaultScriptImages[ScriptEnginePlaywright], []string{"npx", "playwright", "test", "/scripts/checkout.js"}, 300},
+		{"overridden", ScriptStep{Engine: ScriptEngineK6, ScriptRef: scriptRef, Image: "registry.example.com/k6:custom",
+			Command: []string{"k6", "run", "--quiet", "/scripts/checkout.js"}}, nil,
+			"registry.example.com/k6:custom", []string{"k6", "run", "--quiet", "/scripts/checkout.js"}, 120},
+	}
+
+	for _, testdata := range tests {
+		h := &HttpMonitor{ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"}}
+		script := testdata.Script
+		r := &HttpRequest{Name: "checkout", Url: "https://{host}/cart", Timeout: testdata.Timeout, Script: &script,
+			AvailableVariables: VariableList{{Name: "host", Value: "shop.example.com"}, {Name: "session-id", Value: "abc"}}}
+		job := h.scriptJob(r)
+
+		if job.Namespace != "shop" || job.GenerateName != "checkout-script-" {
+			t.Errorf("[%s] unexpected job metadata: %s %s", testdata.TestName, job.Namespace, job.GenerateName)
+		}
+		if *job.Spec.BackoffLimit != 0 || *job.Spec.ActiveDeadlineSeconds != testdata.ExpectedTimeout {
+			t.Errorf("[%s] unexpected job limits: %d %d", testdata.TestName, *job.Spec.BackoffLimit, *job.Spec.ActiveDeadlineSeconds)
+		}
+		pod := job.Spec.Template.Spec
+		if pod.Volumes[0].ConfigMap.Name != "checkout-scripts" {
+			t.Errorf("[%s] unexpected config map: %s", testdata.TestName, pod.Volumes[0].ConfigMap.Name)
+		}
+		container := pod.Containers[0]
+		if container.Image != testdata.ExpectedImage {
+			t.Errorf("[%s] unexpected image. Got: %s, expected: %s", testdata.TestName, container.Image, testdata.ExpectedImage)
+		}
+		if !reflect.DeepEqual(container.Command, testdata.ExpectedCommand) {
+			t.Errorf("[%s] unexpected command. Got: %v, expected: %v", testdata.TestName, container.Command, testdata.ExpectedCommand)
+		}
+		expectedEnv := []corev1.EnvVar{{Name: "TARGET_URL", Value: "https://shop.example.com/cart"},
+			{Name: "VAR_HOST", Value: "shop.example.com"}, {Name: "VAR_SESSION_ID", Value: "abc"}}
+		if !reflect.DeepEqual(container.Env, expectedEnv) {
+			t.Errorf("[%s] unexpected env. Got: %v, expected: %v", testdata.TestName, container.Env, expectedEnv)
+		}
+	}
+}
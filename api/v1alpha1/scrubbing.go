@@ -0,0 +1,182 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+const defaultScrubReplacement = "[REDACTED]"
+
+// Kinds of personal data with a built-in pattern
+// +kubebuilder:validation:Enum=email;ssn
+type PiiPattern string
+
+var (
+	PiiPatternEmail PiiPattern = "email"
+	PiiPatternSsn   PiiPattern = "ssn"
+)
+
+var piiPatterns = map[PiiPattern]*regexp.Regexp{
+	PiiPatternEmail: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	PiiPatternSsn:   regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+}
+
+// Patterns to redact from what requests capture from responses, before it is stored in status or diagnostic
+// bundles, logged, or sent in notifications. This covers response snippets, captured headers, the urls requests
+// were sent to and error messages, which can quote the response.
+type ScrubbingRules struct {
+	// Built-in patterns to redact
+	Builtin []PiiPattern `json:"builtin,omitempty"`
+
+	// Regular expressions whose matches are redacted, in Go syntax
+	Patterns []string `json:"patterns,omitempty"`
+
+	// What each match is replaced with. Default is [REDACTED]
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// An error whose message was scrubbed. The original error is still there for errors.As, so failures are
+// classified the same way.
+type scrubbedError struct {
+	message string
+	err     error
+}
+
+func (e *scrubbedError) Error() string {
+	return e.message
+}
+
+func (e *scrubbedError) Unwrap() error {
+	return e.err
+}
+
+func (s *ScrubbingRules) validate() error {
+	for _, expr := range s.Patterns {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid scrubbing pattern %q: %s", expr, err)
+		}
+	}
+	return nil
+}
+
+// Scrubbers by the rules they apply, so patterns are compiled once rather than for every request. Rules that no
+// monitor has any more stay until the cache is full, and then it starts over.
+var scrubbers = struct {
+	lock     sync.Mutex
+	scrubber map[string]func(string) string
+}{scrubber: make(map[string]func(string) string)}
+
+const maxScrubbers = 256
+
+func (s *ScrubbingRules) cacheKey() string {
+	return fmt.Sprintf("%q %q %q", s.Builtin, s.Patterns, s.Replacement)
+}
+
+// A function that redacts the matches of every pattern. If a custom pattern does not compile, which the webhook
+// would have rejected, everything is redacted rather than risk storing what it was meant to catch.
+func (s *ScrubbingRules) scrubber() func(string) string {
+	key := s.cacheKey()
+	scrubbers.lock.Lock()
+	defer scrubbers.lock.Unlock()
+	if scrub, ok := scrubbers.scrubber[key]; ok {
+		return scrub
+	}
+	if len(scrubbers.scrubber) >= maxScrubbers {
+		scrubbers.scrubber = make(map[string]func(string) string)
+	}
+	scrub := s.compile()
+	scrubbers.scrubber[key] = scrub
+	return scrub
+}
+
+func (s *ScrubbingRules) compile() func(string) string {
+	replacement := s.Replacement
+	if replacement == "" {
+		replacement = defaultScrubReplacement
+	}
+	var patterns []*regexp.Regexp
+	for _, name := range s.Builtin {
+		if re, ok := piiPatterns[name]; ok {
+			patterns = append(patterns, re)
+		}
+	}
+	for _, expr := range s.Patterns {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return func(text string) string {
+				if text == "" {
+					return text
+				}
+				return replacement
+			}
+		}
+		patterns = append(patterns, re)
+	}
+	return func(text string) string {
+		for _, re := range patterns {
+			text = re.ReplaceAllLiteralString(text, replacement)
+		}
+		return text
+	}
+}
+
+func scrubError(err error, scrub func(string) string) error {
+	if err == nil {
+		return nil
+	}
+	if message := scrub(err.Error()); message != err.Error() {
+		return &scrubbedError{message: message, err: err}
+	}
+	return err
+}
+
+// Redact what the request captured from its response, for monitors with scrubbing rules
+func (h *HttpMonitor) scrubCapturedData(result *RequestResult) {
+	if h.Spec.Scrubbing == nil {
+		return
+	}
+	scrub := h.Spec.Scrubbing.scrubber()
+	// Urls can hold variables taken from earlier responses, like an email in a query
+	result.Url = scrub(result.Url)
+	result.ResponseSnippet = scrub(result.ResponseSnippet)
+	for name, value := range result.CapturedHeaders {
+		result.CapturedHeaders[name] = scrub(value)
+	}
+	result.Err = scrubError(result.Err, scrub)
+	for i := range result.Violations {
+		result.Violations[i].Err = scrubError(result.Violations[i].Err, scrub)
+	}
+}
+
+// Apply the monitor's data residency and scrubbing rules to a result, as soon as it is made, so nothing logs or
+// stores what they remove
+func (h *HttpMonitor) protectCapturedData(result *RequestResult) {
+	h.restrictCapturedData(result)
+	h.scrubCapturedData(result)
+}
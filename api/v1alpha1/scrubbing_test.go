@@ -0,0 +1,127 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestScrubbingRules_scrubber(t *testing.T) {
+	text := `{"email": "jane.doe@example.com", "ssn": "078-05-1120", "member": "M-20394"}`
+	tests := []struct {
+		TestName string
+		Rules    ScrubbingRules
+		Expected string
+	}{
+		{"builtin", ScrubbingRules{Builtin: []PiiPattern{PiiPatternEmail, PiiPatternSsn}},
+			`{"email": "[REDACTED]", "ssn": "[REDACTED]", "member": "M-20394"}`},
+		{"custom with a replacement", ScrubbingRules{Patterns: []string{`M-\d+`}, Replacement: "***"},
+			`{"email": "jane.doe@example.com", "ssn": "078-05-1120", "member": "***"}`},
+		{"invalid custom pattern", ScrubbingRules{Builtin: []PiiPattern{PiiPatternEmail}, Patterns: []string{`M-(\d+`}},
+			`[REDACTED]`},
+	}
+
+	for _, testdata := range tests {
+		if scrubbed := testdata.Rules.scrubber()(text); scrubbed != testdata.Expected {
+			t.Errorf("[%s] unexpected scrubbed text. Got: %s, expected: %s", testdata.TestName, scrubbed, testdata.Expected)
+		}
+	}
+}
+
+func TestHttpMonitor_scrubCapturedData(t *testing.T) {
+	h := &HttpMonitor{Spec: HttpMonitorSpec{Scrubbing: &ScrubbingRules{Builtin: []PiiPattern{PiiPatternEmail}}}}
+	opErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	result := &RequestResult{
+		Url:             "https://api.example.com/users?email=jane@example.com",
+		ResponseSnippet: `{"owner": "jane@example.com"}`,
+		CapturedHeaders: map[string]string{"X-User": "jane@example.com", "Via": "1.1 edge"},
+		Err:             fmt.Errorf("GET https://api.example.com/users?email=jane@example.com: %w", opErr),
+		Violations:      []Violation{{Assertion: "body", Err: errors.New("body has jane@example.com")}},
+	}
+	h.scrubCapturedData(result)
+
+	if result.Url != "https://api.example.com/users?email=[REDACTED]" {
+		t.Errorf("unexpected url %s", result.Url)
+	}
+	if result.ResponseSnippet != `{"owner": "[REDACTED]"}` {
+		t.Errorf("unexpected snippet %s", result.ResponseSnippet)
+	}
+	if result.CapturedHeaders["X-User"] != "[REDACTED]" || result.CapturedHeaders["Via"] != "1.1 edge" {
+		t.Errorf("unexpected headers %v", result.CapturedHeaders)
+	}
+	if expected := "GET https://api.example.com/users?email=[REDACTED]: dial tcp: connection refused"; result.Err.Error() != expected {
+		t.Errorf("unexpected error. Got: %s, expected: %s", result.Err, expected)
+	}
+	if !isConnectionError(result.Err) {
+		t.Errorf("the scrubbed error is no longer a connection error")
+	}
+	if result.Violations[0].Err.Error() != "body has [REDACTED]" {
+		t.Errorf("unexpected violation %s", result.Violations[0].Err)
+	}
+}
+
+func TestHttpCheck_AsHttpMonitor_scrubbing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"owner": "jane@example.com"}`))
+	}))
+	defer server.Close()
+	httpclient.Initialize(0)
+	// The failed assertion quotes the owner
+	owner := "john@example.com"
+
+	tests := []struct {
+		TestName  string
+		Scrubbing *ScrubbingRules
+		Expected  string
+	}{
+		{"no rules", nil, "jane@example.com"},
+		{"email", &ScrubbingRules{Builtin: []PiiPattern{PiiPatternEmail}}, "[REDACTED]"},
+	}
+	for _, testdata := range tests {
+		check := &HttpCheck{Spec: HttpCheckSpec{Scrubbing: testdata.Scrubbing,
+			Requests: []HttpRequest{{Name: "users", Method: http.MethodGet, Url: server.URL,
+				ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)},
+				ResponseAssertions:    &ResponseAssertions{Json: []JsonAssertion{{JsonPath: "/owner", Equals: &owner}}}}}}}
+		failure := check.AsHttpMonitor().Execute().FirstFailure()
+		if failure == nil {
+			t.Errorf("[%s] expected error but got none", testdata.TestName)
+			continue
+		}
+		if message := failure.Err.Error(); !strings.Contains(message, testdata.Expected) ||
+			(testdata.Scrubbing != nil && strings.Contains(message, "jane@example.com")) {
+			t.Errorf("[%s] unexpected error. Got: %s, expected it to contain: %s", testdata.TestName, message,
+				testdata.Expected)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/snmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net"
+	"strconv"
+	"time"
+)
+
+// How long to wait for the device to answer when the monitor has no timeout
+const defaultSnmpTimeout = 5 * time.Second
+
+// Credentials for SNMPv3 with the user-based security model. Requests are neither authenticated nor encrypted
+// without an auth protocol.
+type SnmpV3Credentials struct {
+	// +kubebuilder:validation:MinLength=1
+	Username string `json:"username"`
+
+	// +kubebuilder:validation:Enum=md5;sha;sha256
+	AuthProtocol string `json:"auth_protocol,omitempty"`
+
+	// Required with an auth protocol. Passwords have at least 8 characters.
+	AuthPasswordSecretRef *corev1.SecretKeySelector `json:"auth_password_secret_ref,omitempty"`
+
+	// Encrypt requests and responses with AES-128. Needs an auth protocol.
+	// +kubebuilder:validation:Enum=aes
+	PrivProtocol string `json:"priv_protocol,omitempty"`
+
+	// Required with a priv protocol
+	PrivPasswordSecretRef *corev1.SecretKeySelector `json:"priv_password_secret_ref,omitempty"`
+}
+
+// A value to read, and what it must be for the device to be healthy
+type SnmpOid struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Dotted, like 1.3.6.1.2.1.1.3.0 for sysUpTime
+	// +kubebuilder:validation:Pattern=`^\.?[0-2](\.[0-9]+)+$`
+	Oid string `json:"oid"`
+
+	// Bounds for numeric values, like counters and gauges. Both are inclusive.
+	Min *int64 `json:"min,omitempty"`
+	Max *int64 `json:"max,omitempty"`
+
+	// The exact value, as text, like up or 1
+	Equals *string `json:"equals,omitempty"`
+}
+
+// SnmpMonitorSpec defines the desired state of SnmpMonitor
+type SnmpMonitorSpec struct {
+	// The device, as host or host:port. The port defaults to 161.
+	// +kubebuilder:validation:MinLength=1
+	Target string `json:"target"`
+
+	// The community for SNMPv2c, which is public when there is no secret. Ignored when v3 is set.
+	CommunitySecretRef *corev1.SecretKeySelector `json:"community_secret_ref,omitempty"`
+
+	// Use SNMPv3 instead of v2c
+	V3 *SnmpV3Credentials `json:"v3,omitempty"`
+
+	// Read in a single GET request. Every value must be present and within its thresholds.
+	// +kubebuilder:validation:MinItems=1
+	Oids []SnmpOid `json:"oids"`
+
+	// How frequently to read the values
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	Period *metav1.Duration `json:"period"`
+
+	// How long to wait for the device to answer. Defaults to 5s.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// One value read in the last run
+type SnmpValueStatus struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+
+	// Why the value is not healthy
+	Error string `json:"error,omitempty"`
+}
+
+// SnmpMonitorStatus defines the observed state of SnmpMonitor
+type SnmpMonitorStatus struct {
+	LastExecution *metav1.Time `json:"last_execution,omitempty"`
+	LastFailure   *metav1.Time `json:"last_failure,omitempty"`
+
+	// Up, Degraded when only some values are healthy, or Down when none are or the device did not answer
+	State MonitorState `json:"state,omitempty"`
+	Error string       `json:"error,omitempty"`
+
+	Values []SnmpValueStatus `json:"values,omitempty"`
+
+	Conditions []MonitorCondition `json:"conditions,omitempty"`
+}
+
+// SnmpMonitor reads values from a device with SNMP GET and checks them against thresholds
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.target`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Last Execution",type=string,format=date-time,JSONPath=`.status.last_execution`
+type SnmpMonitor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SnmpMonitorSpec   `json:"spec,omitempty"`
+	Status SnmpMonitorStatus `json:"status,omitempty"`
+}
+
+// SnmpMonitorList contains a list of SnmpMonitor
+// +kubebuilder:object:root=true
+type SnmpMonitorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SnmpMonitor `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SnmpMonitor{}, &SnmpMonitorList{})
+}
+
+// The secret values an SnmpMonitor refers to, read before each run
+// +kubebuilder:object:generate=false
+type SnmpSecrets struct {
+	Community    string
+	AuthPassword string
+	PrivPassword string
+}
+
+// One value of a run
+// +kubebuilder:object:generate=false
+type SnmpValueResult struct {
+	Name  string
+	Value string
+
+	// Set for numeric values
+	Number *float64
+
+	// Set when the value is missing or outside its thresholds
+	Err error
+}
+
+// The outcome of a single run of an SnmpMonitor
+// +kubebuilder:object:generate=false
+type SnmpResult struct {
+	Start    time.Time
+	Duration time.Duration
+
+	// Set when the device could not be read at all
+	Err error
+
+	Values []*SnmpValueResult
+}
+
+// Why the run failed, or nil if it succeeded
+func (r *SnmpResult) Failure() error {
+	if r.Err != nil {
+		return r.Err
+	}
+	for _, value := range r.Values {
+		if value.Err != nil {
+			return fmt.Errorf("%s: %w", value.Name, value.Err)
+		}
+	}
+	return nil
+}
+
+func (r *SnmpResult) Failed() bool {
+	return r.Failure() != nil
+}
+
+// Up when every value is healthy, Down when none are and Degraded otherwise
+func (r *SnmpResult) State() MonitorState {
+	if r.Err != nil {
+		return MonitorStateDown
+	}
+	healthy := 0
+	for _, value := range r.Values {
+		if value.Err == nil {
+			healthy++
+		}
+	}
+	switch {
+	case healthy == len(r.Values):
+		return MonitorStateUp
+	case healthy > 0:
+		return MonitorStateDegraded
+	}
+	return MonitorStateDown
+}
+
+// The values of the run, for status
+func (r *SnmpResult) ValueStatus() []SnmpValueStatus {
+	var values []SnmpValueStatus
+	for _, value := range r.Values {
+		status := SnmpValueStatus{Name: value.Name, Value: value.Value}
+		if value.Err != nil {
+			status.Error = value.Err.Error()
+		}
+		values = append(values, status)
+	}
+	return values
+}
+
+// The client for the monitor's device and credentials
+func (m *SnmpMonitor) client(secrets SnmpSecrets) *snmp.Client {
+	address := m.Spec.Target
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "161")
+	}
+	c := &snmp.Client{Address: address, Community: secrets.Community}
+	if c.Community == "" {
+		c.Community = "public"
+	}
+	if v3 := m.Spec.V3; v3 != nil {
+		c.V3 = &snmp.V3{
+			Username:     v3.Username,
+			AuthProtocol: snmp.AuthProtocol(v3.AuthProtocol),
+			AuthPassword: secrets.AuthPassword,
+			PrivProtocol: snmp.PrivProtocol(v3.PrivProtocol),
+			PrivPassword: secrets.PrivPassword,
+		}
+	}
+	return c
+}
+
+// Read every value in one request and check each against its thresholds
+func (m *SnmpMonitor) Execute(secrets SnmpSecrets) *SnmpResult {
+	result := &SnmpResult{Start: time.Now()}
+	defer func() {
+		result.Duration = time.Since(result.Start)
+	}()
+
+	timeout := defaultSnmpTimeout
+	if m.Spec.Timeout != nil {
+		timeout = m.Spec.Timeout.Duration
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	oids := make([]string, len(m.Spec.Oids))
+	for i, oid := range m.Spec.Oids {
+		oids[i] = oid.Oid
+	}
+	varbinds, err := m.client(secrets).Get(ctx, oids)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if len(varbinds) != len(oids) {
+		result.Err = fmt.Errorf("asked for %d values, but the device returned %d", len(oids), len(varbinds))
+		return result
+	}
+	for i, oid := range m.Spec.Oids {
+		result.Values = append(result.Values, &SnmpValueResult{
+			Name:   oid.Name,
+			Value:  varbinds[i].Value,
+			Number: varbinds[i].Number,
+			Err:    oid.check(varbinds[i]),
+		})
+	}
+	return result
+}
+
+// Why the value the device returned is not healthy, or nil if it is
+func (o *SnmpOid) check(varbind snmp.Varbind) error {
+	if varbind.Missing != "" {
+		return fmt.Errorf("the device has no value for %s (%s)", o.Oid, varbind.Missing)
+	}
+	if o.Equals != nil && varbind.Value != *o.Equals {
+		return fmt.Errorf("%q, expected %q", varbind.Value, *o.Equals)
+	}
+	if o.Min == nil && o.Max == nil {
+		return nil
+	}
+	number := varbind.Number
+	if number == nil {
+		// Some devices return numbers as strings
+		n, err := strconv.ParseFloat(varbind.Value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", varbind.Value)
+		}
+		number = &n
+	}
+	if o.Min != nil && *number < float64(*o.Min) {
+		return fmt.Errorf("%s is less than the minimum of %d", varbind.Value, *o.Min)
+	}
+	if o.Max != nil && *number > float64(*o.Max) {
+		return fmt.Errorf("%s is more than the maximum of %d", varbind.Value, *o.Max)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"github.com/oregondesignservices/monitoring-controller/internal/ber"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Answers v2c GET requests with the community secret, and ignores the rest like a real device would
+func startSnmpAgent(t *testing.T, values map[string][]byte) net.PacketConn {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		buffer := make([]byte, 65535)
+		for {
+			n, from, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			message, _, err := ber.Parse(buffer[:n])
+			if err != nil {
+				continue
+			}
+			parts, err := message.Children()
+			if err != nil || len(parts) != 3 || string(parts[1].Content) != "secret" {
+				continue
+			}
+			fields, err := parts[2].Children()
+			if err != nil || len(fields) != 4 {
+				continue
+			}
+			requests, _ := fields[3].Children()
+			var varbinds [][]byte
+			for _, request := range requests {
+				pair, _ := request.Children()
+				oid, _ := pair[0].OIDString()
+				value, ok := values[oid]
+				if !ok {
+					value = []byte{0x80, 0x00}
+				}
+				varbinds = append(varbinds, ber.Constructed(ber.TagSequence, ber.TLV(ber.TagOID, pair[0].Content), value))
+			}
+			pdu := ber.Constructed(0xa2, ber.TLV(ber.TagInteger, fields[0].Content), ber.Int(ber.TagInteger, 0),
+				ber.Int(ber.TagInteger, 0), ber.Constructed(ber.TagSequence, varbinds...))
+			response := ber.Constructed(ber.TagSequence, ber.Int(ber.TagInteger, 1), ber.OctetString("secret"), pdu)
+			_, _ = conn.WriteTo(response, from)
+		}
+	}()
+	return conn
+}
+
+func TestSnmpMonitor_Execute(t *testing.T) {
+	agent := startSnmpAgent(t, map[string][]byte{
+		"1.3.6.1.2.1.1.3.0": ber.Int(0x43, 123456),
+		"1.3.6.1.2.1.1.5.0": ber.OctetString("core-1"),
+	})
+	defer agent.Close()
+
+	minimum, maximum := int64(100), int64(10)
+	sysName := "core-1"
+	uptime := SnmpOid{Name: "uptime", Oid: "1.3.6.1.2.1.1.3.0", Min: &minimum}
+	name := SnmpOid{Name: "name", Oid: "1.3.6.1.2.1.1.5.0", Equals: &sysName}
+
+	tests := []struct {
+		TestName      string
+		Community     string
+		Oids          []SnmpOid
+		ExpectedState MonitorState
+		ExpectedError string
+	}{
+		{"healthy", "secret", []SnmpOid{uptime, name}, MonitorStateUp, ""},
+		{"above the maximum", "secret", []SnmpOid{name, {Name: "uptime", Oid: "1.3.6.1.2.1.1.3.0", Max: &maximum}},
+			MonitorStateDegraded, "uptime: 123456 is more than the maximum of 10"},
+		{"not a number", "secret", []SnmpOid{{Name: "name", Oid: "1.3.6.1.2.1.1.5.0", Min: &minimum}},
+			MonitorStateDown, `name: "core-1" is not a number`},
+		{"missing", "secret", []SnmpOid{uptime, {Name: "fans", Oid: "1.3.6.1.4.1.9.9.13.1.4.1.3.1"}},
+			MonitorStateDegraded, "fans: the device has no value for 1.3.6.1.4.1.9.9.13.1.4.1.3.1 (noSuchObject)"},
+		{"wrong community", "public", []SnmpOid{uptime}, MonitorStateDown, "no answer from"},
+	}
+
+	for _, testdata := range tests {
+		monitor := &SnmpMonitor{Spec: SnmpMonitorSpec{
+			Target:  agent.LocalAddr().String(),
+			Oids:    testdata.Oids,
+			Timeout: &metav1.Duration{Duration: 200 * time.Millisecond},
+		}}
+		result := monitor.Execute(SnmpSecrets{Community: testdata.Community})
+		if result.State() != testdata.ExpectedState {
+			t.Errorf("[%s] unexpected state %s, expected %s", testdata.TestName, result.State(), testdata.ExpectedState)
+		}
+		failure := ""
+		if err := result.Failure(); err != nil {
+			failure = err.Error()
+		}
+		if (testdata.ExpectedError == "") != (failure == "") || !strings.HasPrefix(failure, testdata.ExpectedError) {
+			t.Errorf("[%s] unexpected error %q, expected %q", testdata.TestName, failure, testdata.ExpectedError)
+		}
+	}
+}
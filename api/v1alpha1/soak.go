@@ -0,0 +1,140 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/go-logr/logr"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// The aggregate outcome of a soaked request
+// +kubebuilder:object:generate=false
+type SoakResult struct {
+	Sent   int
+	Failed int
+	P50    time.Duration
+	P95    time.Duration
+
+	// The first error seen, for context when the soak fails
+	FirstError error
+}
+
+func (s *SoakResult) errorPercent() float64 {
+	if s.Sent == 0 {
+		return 0
+	}
+	return float64(s.Failed) * 100 / float64(s.Sent)
+}
+
+// Check the aggregate assertions of a soak
+func (s *Soak) evaluate(result *SoakResult) error {
+	if result.Sent == 0 {
+		return fmt.Errorf("soak sent no requests")
+	}
+	// Compared in integers, since the percentage itself would round 1.5% down to the 1% allowed
+	if result.Failed*100 > s.MaxErrorPercent*result.Sent {
+		return fmt.Errorf("%d of %d soak requests failed (%.1f%%), more than the allowed %d%%: %v",
+			result.Failed, result.Sent, result.errorPercent(), s.MaxErrorPercent, result.FirstError)
+	}
+	if s.MaxP95 != nil && result.P95 > s.MaxP95.Duration {
+		return fmt.Errorf("soak p95 response time %s is over the allowed %s", result.P95, s.MaxP95.Duration)
+	}
+	return nil
+}
+
+// Send copies of the request at the soak rate for the soak duration, and check the aggregate results.
+// Variables are not extracted from soak responses, and each copy gets its own idempotency key.
+func (h *HttpMonitor) soak(client *http.Client, r HttpRequest, logger logr.Logger) *RequestResult {
+	r.VariablesFromResponse = nil
+	soak := r.Soak
+	result := &RequestResult{Name: r.Name, Soak: &SoakResult{}}
+	start := time.Now()
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var durations []time.Duration
+
+	ticker := time.NewTicker(time.Second / time.Duration(soak.Rate))
+	defer ticker.Stop()
+	deadline := time.After(soak.Duration.Duration)
+
+loop:
+	for {
+		select {
+		case <-deadline:
+			break loop
+		case <-ticker.C:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				request := r
+				request.newIdempotencyKey()
+				resp, sent := request.timedSendRequest(client)
+				h.protectCapturedData(sent)
+				HandleMetrics(h, request, resp)
+				if resp != nil {
+					// Drain the body so the connection is reused, like a real client would
+					_, _ = io.Copy(ioutil.Discard, resp.Body)
+					_ = resp.Body.Close()
+				}
+
+				lock.Lock()
+				defer lock.Unlock()
+				result.Soak.Sent++
+				result.BytesSent += sent.BytesSent
+				result.BytesReceived += sent.BytesReceived
+				if sent.Err != nil {
+					result.Soak.Failed++
+					if result.Soak.FirstError == nil {
+						result.Soak.FirstError = sent.Err
+					}
+				}
+				if resp != nil {
+					durations = append(durations, sent.Duration)
+					result.StatusCode = resp.StatusCode
+					result.Url = sent.Url
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	result.Soak.P50 = percentile(durations, 50)
+	result.Soak.P95 = percentile(durations, 95)
+	result.Duration = time.Since(start)
+	result.Attempts = result.Soak.Sent
+	result.Err = soak.evaluate(result.Soak)
+	logger.Info("soak completed", "sent", result.Soak.Sent, "failed", result.Soak.Failed,
+		"p50", result.Soak.P50.String(), "p95", result.Soak.P95.String())
+	return result
+}
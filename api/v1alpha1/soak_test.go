@@ -0,0 +1,58 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestSoak_evaluate(t *testing.T) {
+	soak := &Soak{MaxErrorPercent: 1, MaxP95: &metav1.Duration{Duration: 500 * time.Millisecond}}
+	failed := errors.New("failed")
+
+	tests := []struct {
+		TestName string
+		Result   SoakResult
+		Passes   bool
+	}{
+		{"healthy", SoakResult{Sent: 300, Failed: 1, P95: 400 * time.Millisecond}, true},
+		{"exactly-the-limit", SoakResult{Sent: 300, Failed: 3, P95: 400 * time.Millisecond}, true},
+		{"just-over-the-limit", SoakResult{Sent: 300, Failed: 4, P95: 400 * time.Millisecond, FirstError: failed}, false},
+		{"too-many-errors", SoakResult{Sent: 300, Failed: 7, P95: 400 * time.Millisecond, FirstError: failed}, false},
+		{"too-slow", SoakResult{Sent: 300, P95: 600 * time.Millisecond}, false},
+		{"nothing-sent", SoakResult{}, false},
+	}
+
+	for _, testdata := range tests {
+		err := soak.evaluate(&testdata.Result)
+		if (err == nil) != testdata.Passes {
+			t.Errorf("[%s] unexpected result. Got error: %v, expected to pass: %t", testdata.TestName, err, testdata.Passes)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Waits for events on a Server-Sent Events stream instead of reading a whole body, which a stream never ends
+type SseCheck struct {
+	// How many events to wait for. Default is 1
+	// +kubebuilder:validation:Minimum=1
+	Events int `json:"events,omitempty"`
+
+	// Only count events of this type, the stream's event field. Events without one are of type message. Default
+	// is any type
+	EventType string `json:"event_type,omitempty"`
+}
+
+// Events can carry a JSON document on a single line, so lines may be longer than bufio's default
+const maxSseLine = 1024 * 1024
+
+func (s *SseCheck) events() int {
+	if s.Events < 1 {
+		return 1
+	}
+	return s.Events
+}
+
+// Read events from the stream until enough have arrived, then close it. Assertions and variables then see the
+// data of the last event as the body. The request's timeout bounds the wait, with the stream's deadline.
+func (s *SseCheck) read(resp *http.Response) error {
+	stream := resp.Body
+	defer stream.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(nil))
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		return fmt.Errorf("expected an event stream, got content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(nil, maxSseLine)
+	received := 0
+	eventType, data := "", []string(nil)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// A blank line dispatches the event, if it has any data
+			if eventType == "" {
+				eventType = "message"
+			}
+			if len(data) > 0 && (s.EventType == "" || eventType == s.EventType) {
+				received++
+				if received >= s.events() {
+					resp.Body = ioutil.NopCloser(strings.NewReader(strings.Join(data, "\n")))
+					return nil
+				}
+			}
+			eventType, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// A comment, which servers send to keep the connection open
+			continue
+		}
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("received %d of %d events: %w", received, s.events(), err)
+	}
+	return fmt.Errorf("the stream ended after %d of %d events", received, s.events())
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHttpRequest_sse(t *testing.T) {
+	// A heartbeat, a status event and two notifications, then the stream stays open without sending more
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain" {
+			_, _ = fmt.Fprint(w, "data: not a stream\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, ": heartbeat\n\nevent: status\ndata: {\"connected\": true}\n\n")
+		_, _ = fmt.Fprint(w, "data: {\"id\": 1,\ndata:  \"title\": \"first\"}\n\nid: 2\ndata: {\"id\": 2}\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	httpclient.Initialize(0)
+	id := func(value string) *ResponseAssertions {
+		return &ResponseAssertions{Json: []JsonAssertion{{JsonPath: "/id", Equals: &value}}}
+	}
+
+	tests := []struct {
+		TestName      string
+		Path          string
+		Sse           SseCheck
+		Assertions    *ResponseAssertions
+		ExpectedError bool
+	}{
+		{"first event", "/", SseCheck{}, &ResponseAssertions{BodyMatches: []string{"connected"}}, false},
+		{"multi-line data", "/", SseCheck{EventType: "message"}, id("1"), false},
+		{"several events", "/", SseCheck{Events: 3}, id("2"), false},
+		{"assertion on the last event", "/", SseCheck{Events: 3}, id("1"), true},
+		{"more events than sent", "/", SseCheck{Events: 4}, nil, true},
+		{"event type never sent", "/", SseCheck{EventType: "alert"}, nil, true},
+		{"not a stream", "/plain", SseCheck{}, nil, true},
+	}
+
+	for _, testdata := range tests {
+		sse := testdata.Sse
+		r := HttpRequest{Name: testdata.TestName, Method: http.MethodGet, Url: server.URL + testdata.Path,
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, Sse: &sse,
+			ResponseAssertions: testdata.Assertions, Timeout: &metav1.Duration{Duration: 200 * time.Millisecond}}
+		_, result := r.timedSendRequest(httpclient.GetClient())
+		if (result.Err != nil) != testdata.ExpectedError {
+			t.Errorf("[%s] unexpected error: %v", testdata.TestName, result.Err)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/ber"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type StartTlsProtocol string
+
+var (
+	StartTlsSmtp     StartTlsProtocol = "smtp"
+	StartTlsImap     StartTlsProtocol = "imap"
+	StartTlsLdap     StartTlsProtocol = "ldap"
+	StartTlsPostgres StartTlsProtocol = "postgres"
+)
+
+// The port for each protocol when the url has none. SMTP uses the submission port, which is the one that
+// offers STARTTLS to clients.
+var startTlsPorts = map[StartTlsProtocol]string{
+	StartTlsSmtp:     "587",
+	StartTlsImap:     "143",
+	StartTlsLdap:     "389",
+	StartTlsPostgres: "5432",
+}
+
+// Connect to a server that speaks another protocol than HTTP and upgrade the connection to TLS. The url names
+// the server, like smtp://mail.example.com:587. Fails when the server does not offer the upgrade or its
+// certificate is not valid for the host, since clients that only use TLS opportunistically would then quietly
+// send everything in the clear.
+type StartTlsCheck struct {
+	// The protocol to speak until the upgrade
+	// +kubebuilder:validation:Enum=smtp;imap;ldap;postgres
+	Protocol StartTlsProtocol `json:"protocol"`
+
+	// Also fail when the server works without the upgrade: SMTP accepts MAIL FROM, IMAP does not advertise
+	// LOGINDISABLED, LDAP answers an anonymous bind with anything but confidentialityRequired, or Postgres
+	// starts authenticating a connection without TLS
+	Enforced bool `json:"enforced,omitempty"`
+}
+
+// The server offered no upgrade, or did not require one
+type startTlsDowngradeError struct {
+	err error
+}
+
+func (e *startTlsDowngradeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *startTlsDowngradeError) Unwrap() error {
+	return e.err
+}
+
+func downgradef(format string, args ...interface{}) error {
+	return &startTlsDowngradeError{fmt.Errorf(format, args...)}
+}
+
+// The certificates to verify servers with. Nil uses the system roots; tests replace it.
+var startTlsRootCAs *x509.CertPool
+
+// The name to check the certificate against, and the address to connect to
+func (c *StartTlsCheck) address(target string) (string, string, error) {
+	return hostAndAddress(target, startTlsPorts[c.Protocol])
+}
+
+// The host a url names, and the address to connect to, on defaultPort when the url has no port
+func hostAndAddress(target, defaultPort string) (string, string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Hostname() == "" {
+		return "", "", fmt.Errorf("url %q has no host", target)
+	}
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+	return u.Hostname(), net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// Run the check instead of sending an HTTP request
+func (r *HttpRequest) checkStartTls() *RequestResult {
+	result := &RequestResult{Name: r.Name, Url: r.AvailableVariables.newReplacer().Replace(r.Url), Attempts: 1}
+	start := time.Now()
+	host, addr, err := r.StartTls.address(result.Url)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout())
+		result.TLS, err = r.StartTls.check(ctx, host, addr)
+		cancel()
+	}
+	result.Err = err
+	result.Category = failureCategory(err)
+	result.Duration = time.Since(start)
+	result.ResponseTime = result.Duration
+	return result
+}
+
+func (c *StartTlsCheck) check(ctx context.Context, host, addr string) (*tls.ConnectionState, error) {
+	// Postgres upgrades before anything else is said, so whether it refuses plaintext takes a connection of its own
+	if c.Protocol == StartTlsPostgres && c.Enforced {
+		if err := postgresRefusesPlaintext(ctx, addr); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := dialTcp(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	switch c.Protocol {
+	case StartTlsSmtp:
+		err = smtpStartTls(rw, c.Enforced)
+	case StartTlsImap:
+		err = imapStartTls(rw, c.Enforced)
+	case StartTlsLdap:
+		err = ldapStartTls(rw, c.Enforced)
+	case StartTlsPostgres:
+		err = postgresStartTls(rw)
+	default:
+		err = fmt.Errorf("unknown protocol %q", c.Protocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{ServerName: host, RootCAs: startTlsRootCAs}
+	httpclient.Restrict(config)
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.Handshake(); err != nil {
+		if isCertificateError(err) {
+			return nil, fmt.Errorf("certificate is not valid: %w", err)
+		}
+		return nil, fmt.Errorf("TLS handshake after STARTTLS failed: %w", err)
+	}
+	state := tlsConn.ConnectionState()
+	return &state, nil
+}
+
+// Connect with the deadline of the context on all reads and writes
+func dialTcp(ctx context.Context, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	return conn, nil
+}
+
+func isCertificateError(err error) bool {
+	var verifyErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	return errors.As(err, &verifyErr) || errors.As(err, &unknownAuthority) || errors.As(err, &hostname) ||
+		errors.As(err, &invalid)
+}
+
+func writeLine(rw *bufio.ReadWriter, line string) error {
+	if _, err := rw.WriteString(line + "\r\n"); err != nil {
+		return err
+	}
+	return rw.Flush()
+}
+
+// Read an SMTP reply, which may span several lines, returning its code and text
+func readSmtpReply(rw *bufio.ReadWriter) (string, []string, error) {
+	var lines []string
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return "", nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if len(line) < 3 {
+			return "", nil, fmt.Errorf("malformed reply %q", line)
+		}
+		if len(line) == 3 {
+			return line, append(lines, ""), nil
+		}
+		lines = append(lines, line[4:])
+		if line[3] != '-' {
+			return line[:3], lines, nil
+		}
+	}
+}
+
+func smtpCommand(rw *bufio.ReadWriter, command string) (string, []string, error) {
+	if err := writeLine(rw, command); err != nil {
+		return "", nil, err
+	}
+	return readSmtpReply(rw)
+}
+
+func smtpStartTls(rw *bufio.ReadWriter, enforced bool) error {
+	if code, lines, err := readSmtpReply(rw); err != nil {
+		return err
+	} else if code != "220" {
+		return fmt.Errorf("server greeted with %s %s", code, strings.Join(lines, " "))
+	}
+	code, extensions, err := smtpCommand(rw, "EHLO monitoring-controller")
+	if err != nil {
+		return err
+	}
+	if code != "250" {
+		return fmt.Errorf("server answered EHLO with %s", code)
+	}
+	offered := false
+	for _, extension := range extensions {
+		if strings.EqualFold(strings.Fields(extension + " x")[0], "STARTTLS") {
+			offered = true
+		}
+	}
+	if !offered {
+		return downgradef("server does not offer STARTTLS")
+	}
+	if enforced {
+		code, _, err := smtpCommand(rw, "MAIL FROM:<monitoring-controller@example.invalid>")
+		if err != nil {
+			return err
+		}
+		if code[0] == '2' {
+			return downgradef("server accepted MAIL FROM without STARTTLS")
+		}
+		if _, _, err := smtpCommand(rw, "RSET"); err != nil {
+			return err
+		}
+	}
+	code, lines, err := smtpCommand(rw, "STARTTLS")
+	if err != nil {
+		return err
+	}
+	if code != "220" {
+		return downgradef("server refused STARTTLS: %s %s", code, strings.Join(lines, " "))
+	}
+	return nil
+}
+
+// Send a tagged IMAP command, returning the untagged lines before the tagged status
+func imapCommand(rw *bufio.ReadWriter, tag, command string) ([]string, string, error) {
+	if err := writeLine(rw, tag+" "+command); err != nil {
+		return nil, "", err
+	}
+	var untagged []string
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return nil, "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(line, tag+" ") {
+			return untagged, strings.TrimPrefix(line, tag+" "), nil
+		}
+		untagged = append(untagged, line)
+	}
+}
+
+func imapStartTls(rw *bufio.ReadWriter, enforced bool) error {
+	greeting, err := rw.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		return fmt.Errorf("server greeted with %q", strings.TrimSpace(greeting))
+	}
+	untagged, status, err := imapCommand(rw, "a1", "CAPABILITY")
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(status, "OK") {
+		return fmt.Errorf("server answered CAPABILITY with %q", status)
+	}
+	capabilities := make(map[string]bool)
+	for _, line := range untagged {
+		if fields := strings.Fields(line); len(fields) > 1 && strings.EqualFold(fields[1], "CAPABILITY") {
+			for _, capability := range fields[2:] {
+				capabilities[strings.ToUpper(capability)] = true
+			}
+		}
+	}
+	if !capabilities["STARTTLS"] {
+		return downgradef("server does not offer STARTTLS")
+	}
+	if enforced && !capabilities["LOGINDISABLED"] {
+		return downgradef("server allows LOGIN without STARTTLS, it does not advertise LOGINDISABLED")
+	}
+	if _, status, err = imapCommand(rw, "a2", "STARTTLS"); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(status, "OK") {
+		return downgradef("server refused STARTTLS: %s", status)
+	}
+	return nil
+}
+
+// LDAP result codes
+const (
+	ldapSuccess               = 0
+	ldapConfidentialityNeeded = 13
+)
+
+// The OID of the StartTLS extended operation
+const ldapStartTlsOid = "1.3.6.1.4.1.1466.20037"
+
+// Send an LDAP request and return the result code of the response, which must have the given tag
+func ldapRequest(rw *bufio.ReadWriter, id int64, op []byte, responseTag byte) (int64, error) {
+	if _, err := rw.Write(ber.Constructed(ber.TagSequence, ber.Int(ber.TagInteger, id), op)); err != nil {
+		return 0, err
+	}
+	if err := rw.Flush(); err != nil {
+		return 0, err
+	}
+	message, err := ber.Read(rw)
+	if err != nil {
+		return 0, err
+	}
+	parts, err := message.Children()
+	if err != nil || len(parts) < 2 || parts[1].Tag != responseTag {
+		return 0, errors.New("malformed LDAP response")
+	}
+	result, err := parts[1].Children()
+	if err != nil || len(result) == 0 {
+		return 0, errors.New("malformed LDAP response")
+	}
+	return result[0].Int()
+}
+
+func ldapStartTls(rw *bufio.ReadWriter, enforced bool) error {
+	if enforced {
+		// An anonymous simple bind: version 3, no name, no password
+		bind := ber.Constructed(0x60, ber.Int(ber.TagInteger, 3), ber.OctetString(""), ber.TLV(0x80, nil))
+		code, err := ldapRequest(rw, 1, bind, 0x61)
+		if err != nil {
+			return err
+		}
+		if code != ldapConfidentialityNeeded {
+			return downgradef("server answered an anonymous bind without STARTTLS with result code %d, not confidentialityRequired", code)
+		}
+	}
+	extended := ber.Constructed(0x77, ber.TLV(0x80, []byte(ldapStartTlsOid)))
+	code, err := ldapRequest(rw, 2, extended, 0x78)
+	if err != nil {
+		return err
+	}
+	if code != ldapSuccess {
+		return downgradef("server refused STARTTLS with result code %d", code)
+	}
+	return nil
+}
+
+// Asks a Postgres server to switch to TLS before the startup message
+var postgresSslRequest = []byte{0x00, 0x00, 0x00, 0x08, 0x04, 0xd2, 0x16, 0x2f}
+
+func postgresStartTls(rw *bufio.ReadWriter) error {
+	if _, err := rw.Write(postgresSslRequest); err != nil {
+		return err
+	}
+	if err := rw.Flush(); err != nil {
+		return err
+	}
+	answer, err := rw.ReadByte()
+	if err != nil {
+		return err
+	}
+	switch answer {
+	case 'S':
+		return nil
+	case 'N':
+		return downgradef("server does not offer SSL")
+	}
+	return fmt.Errorf("unexpected answer %q to the SSL request", answer)
+}
+
+// A server that requires TLS answers a plaintext startup message with an error, instead of asking for a password
+func postgresRefusesPlaintext(ctx context.Context, addr string) error {
+	conn, err := dialTcp(ctx, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	params := "user\x00monitoring-controller\x00database\x00postgres\x00\x00"
+	startup := make([]byte, 8, 8+len(params))
+	binary.BigEndian.PutUint32(startup, uint32(8+len(params)))
+	// Protocol version 3.0
+	binary.BigEndian.PutUint32(startup[4:], 3<<16)
+	if _, err := conn.Write(append(startup, params...)); err != nil {
+		return err
+	}
+	answer := make([]byte, 1)
+	if _, err := conn.Read(answer); err != nil {
+		return err
+	}
+	switch answer[0] {
+	case 'E':
+		return nil
+	case 'R':
+		return downgradef("server started authenticating a connection without TLS")
+	}
+	return fmt.Errorf("unexpected answer %q to the startup message", answer[0])
+}
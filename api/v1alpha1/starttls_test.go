@@ -0,0 +1,159 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A certificate for 127.0.0.1, and a pool that trusts it
+func startTlsCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "mail"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")}, NotBefore: time.Now().Add(-time.Hour),
+		NotAfter: time.Now().Add(time.Hour), IsCA: true, BasicConstraintsValid: true}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(parsed)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+// Serves a single SMTP session at a time, offering STARTTLS and requiring it if asked to
+func smtpServer(t *testing.T, cert tls.Certificate, offer, require bool) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+				reply := func(text string) {
+					_, _ = rw.WriteString(text + "\r\n")
+					_ = rw.Flush()
+				}
+				reply("220 mail ESMTP")
+				for {
+					line, err := rw.ReadString('\n')
+					if err != nil {
+						return
+					}
+					switch command := strings.TrimSpace(line); {
+					case strings.HasPrefix(command, "EHLO"):
+						if offer {
+							reply("250-mail\r\n250-SIZE 1000000\r\n250 STARTTLS")
+						} else {
+							reply("250-mail\r\n250 SIZE 1000000")
+						}
+					case strings.HasPrefix(command, "MAIL FROM"):
+						if require {
+							reply("530 5.7.0 Must issue a STARTTLS command first")
+						} else {
+							reply("250 OK")
+						}
+					case command == "RSET":
+						reply("250 OK")
+					case command == "STARTTLS":
+						reply("220 Ready to start TLS")
+						_ = tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}}).Handshake()
+						return
+					default:
+						reply("500 unknown command")
+					}
+				}
+			}()
+		}
+	}()
+	return listener
+}
+
+func TestHttpRequest_checkStartTls(t *testing.T) {
+	cert, pool := startTlsCertificate(t)
+	defer func() { startTlsRootCAs = nil }()
+
+	tests := []struct {
+		TestName         string
+		Offer            bool
+		Require          bool
+		Enforced         bool
+		Trusted          bool
+		ExpectedErr      string
+		ExpectedCategory FailureCategory
+	}{
+		{"upgraded", true, false, false, true, "", ""},
+		{"upgraded and enforced", true, true, true, true, "", ""},
+		{"not offered", false, false, false, true, "server does not offer STARTTLS", FailureCategoryStartTlsDowngrade},
+		{"not enforced", true, false, true, true, "server accepted MAIL FROM without STARTTLS",
+			FailureCategoryStartTlsDowngrade},
+		{"untrusted certificate", true, false, false, false, "certificate is not valid: ", ""},
+	}
+
+	for _, testdata := range tests {
+		listener := smtpServer(t, cert, testdata.Offer, testdata.Require)
+		startTlsRootCAs = pool
+		if !testdata.Trusted {
+			startTlsRootCAs = x509.NewCertPool()
+		}
+		r := HttpRequest{Name: testdata.TestName, Url: "smtp://" + listener.Addr().String(),
+			StartTls: &StartTlsCheck{Protocol: StartTlsSmtp, Enforced: testdata.Enforced}}
+		result := r.checkStartTls()
+		listener.Close()
+
+		out := ""
+		if result.Err != nil {
+			out = result.Err.Error()
+		}
+		if !strings.HasPrefix(out, testdata.ExpectedErr) || (out != "") != (testdata.ExpectedErr != "") {
+			t.Errorf("[%s] unexpected error. Got: %q, expected: %q", testdata.TestName, out, testdata.ExpectedErr)
+		}
+		if result.Category != testdata.ExpectedCategory {
+			t.Errorf("[%s] unexpected category. Got: %q, expected: %q", testdata.TestName, result.Category,
+				testdata.ExpectedCategory)
+		}
+		if testdata.ExpectedErr == "" && result.TLS == nil {
+			t.Errorf("[%s] expected the TLS connection state", testdata.TestName)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"strconv"
+	"strings"
+)
+
+// The status codes a response may have. Each entry is a code like 200, a class like "2xx", a range like
+// "200-204", or any of those negated with "!", like "!5xx". A code matches when it is in at least one entry and
+// in none of the negated ones. With only negated entries, every other code matches.
+type StatusCodeMatcher []intstr.IntOrString
+
+// The codes an entry covers, from low to high
+type statusCodeRange struct {
+	low, high int
+	negated   bool
+}
+
+func parseStatusCodes(entry intstr.IntOrString) (statusCodeRange, error) {
+	if entry.Type == intstr.Int {
+		return checkStatusCodes(statusCodeRange{low: int(entry.IntVal), high: int(entry.IntVal)}, entry)
+	}
+	text := strings.TrimSpace(entry.StrVal)
+	r := statusCodeRange{}
+	if strings.HasPrefix(text, "!") {
+		r.negated = true
+		text = strings.TrimSpace(text[1:])
+	}
+	var err error
+	switch {
+	case len(text) == 3 && strings.HasSuffix(strings.ToLower(text), "xx"):
+		class, convErr := strconv.Atoi(text[:1])
+		r.low, r.high, err = class*100, class*100+99, convErr
+	case strings.Contains(text, "-"):
+		pieces := strings.SplitN(text, "-", 2)
+		var highErr error
+		r.low, err = strconv.Atoi(strings.TrimSpace(pieces[0]))
+		r.high, highErr = strconv.Atoi(strings.TrimSpace(pieces[1]))
+		if err == nil {
+			err = highErr
+		}
+	default:
+		r.low, err = strconv.Atoi(text)
+		r.high = r.low
+	}
+	if err != nil {
+		return r, fmt.Errorf("%q is not a status code, class like 2xx or range like 200-204", entry.StrVal)
+	}
+	return checkStatusCodes(r, entry)
+}
+
+func checkStatusCodes(r statusCodeRange, entry intstr.IntOrString) (statusCodeRange, error) {
+	if r.low < 100 || r.high > 599 {
+		return r, fmt.Errorf("%s is outside of the status codes 100 to 599", entry.String())
+	}
+	if r.low > r.high {
+		return r, fmt.Errorf("%s is a range that ends before it starts", entry.String())
+	}
+	return r, nil
+}
+
+// Report the first entry that cannot be parsed. Such entries never match.
+func (m StatusCodeMatcher) validate() error {
+	for _, entry := range m {
+		if _, err := parseStatusCodes(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m StatusCodeMatcher) Matches(code int) bool {
+	matched, included, excluded := false, 0, 0
+	for _, entry := range m {
+		r, err := parseStatusCodes(entry)
+		if err != nil {
+			continue
+		}
+		in := code >= r.low && code <= r.high
+		if r.negated {
+			if in {
+				return false
+			}
+			excluded++
+			continue
+		}
+		included++
+		matched = matched || in
+	}
+	return matched || (included == 0 && excluded > 0)
+}
+
+func (m StatusCodeMatcher) String() string {
+	codes := make([]string, len(m))
+	for i, code := range m {
+		codes[i] = code.String()
+	}
+	return "[" + strings.Join(codes, ", ") + "]"
+}
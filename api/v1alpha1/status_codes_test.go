@@ -0,0 +1,87 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"testing"
+)
+
+func TestStatusCodeMatcher_Matches(t *testing.T) {
+	code, text := intstr.FromInt, intstr.FromString
+
+	tests := []struct {
+		TestName string
+		Matcher  StatusCodeMatcher
+		Code     int
+		Expected bool
+	}{
+		{"code", StatusCodeMatcher{code(200), code(204)}, 204, true},
+		{"other code", StatusCodeMatcher{code(200), code(204)}, 201, false},
+		{"code as text", StatusCodeMatcher{text("200")}, 200, true},
+		{"class", StatusCodeMatcher{text("2xx")}, 226, true},
+		{"other class", StatusCodeMatcher{text("2xx")}, 301, false},
+		{"range", StatusCodeMatcher{text("200-204")}, 204, true},
+		{"outside range", StatusCodeMatcher{text("200-204")}, 205, false},
+		{"negated class", StatusCodeMatcher{text("!5xx")}, 404, true},
+		{"negated class excludes", StatusCodeMatcher{text("!5xx")}, 503, false},
+		{"class with exception", StatusCodeMatcher{text("2xx"), text("3xx"), text("!204")}, 302, true},
+		{"excepted code", StatusCodeMatcher{text("2xx"), text("3xx"), text("!204")}, 204, false},
+		{"invalid entry", StatusCodeMatcher{text("20x")}, 200, false},
+		{"none", StatusCodeMatcher{}, 200, false},
+	}
+
+	for _, testdata := range tests {
+		if out := testdata.Matcher.Matches(testdata.Code); out != testdata.Expected {
+			t.Errorf("[%s] unexpected match for %d. Got: %v, expected: %v", testdata.TestName, testdata.Code, out, testdata.Expected)
+		}
+	}
+}
+
+func TestStatusCodeMatcher_validate(t *testing.T) {
+	tests := []struct {
+		TestName    string
+		Matcher     StatusCodeMatcher
+		ExpectedErr string
+	}{
+		{"valid", StatusCodeMatcher{intstr.FromInt(200), intstr.FromString("3xx"), intstr.FromString("!500-504")}, ""},
+		{"out of range", StatusCodeMatcher{intstr.FromInt(99)}, "99 is outside of the status codes 100 to 599"},
+		{"class out of range", StatusCodeMatcher{intstr.FromString("6xx")}, "6xx is outside of the status codes 100 to 599"},
+		{"backwards range", StatusCodeMatcher{intstr.FromString("204-200")}, "204-200 is a range that ends before it starts"},
+		{"not a code", StatusCodeMatcher{intstr.FromString("ok")}, `"ok" is not a status code, class like 2xx or range like 200-204`},
+	}
+
+	for _, testdata := range tests {
+		err := testdata.Matcher.validate()
+		out := ""
+		if err != nil {
+			out = err.Error()
+		}
+		if out != testdata.ExpectedErr {
+			t.Errorf("[%s] unexpected error. Got: %q, expected: %q", testdata.TestName, out, testdata.ExpectedErr)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"net/http"
+	"net/url"
+)
+
+const (
+	defaultSyntheticHeader        = "X-Synthetic"
+	defaultSyntheticHeaderValue   = "monitoring-controller"
+	defaultSyntheticMonitorHeader = "X-Synthetic-Monitor"
+)
+
+// Add the markers to a request before it is sent. The request's headers and query parameters are copied, so
+// the spec is left alone.
+func (m *SyntheticMarker) mark(r *HttpRequest, monitor string) {
+	if m == nil {
+		return
+	}
+
+	headers := make(http.Header, len(r.Headers)+len(m.Headers)+2)
+	for key, values := range r.Headers {
+		headers[key] = append([]string(nil), values...)
+	}
+	if len(m.Headers) == 0 {
+		headers.Set(defaultSyntheticHeader, defaultSyntheticHeaderValue)
+	}
+	for key, value := range m.Headers {
+		headers.Set(key, value)
+	}
+	monitorHeader := m.MonitorHeader
+	if monitorHeader == "" {
+		monitorHeader = defaultSyntheticMonitorHeader
+	}
+	headers.Set(monitorHeader, monitor)
+	if m.Contact != "" {
+		headers.Set("From", m.Contact)
+	}
+	r.Headers = headers
+
+	if len(m.QueryParams) > 0 {
+		query := make(url.Values, len(r.QueryParams)+len(m.QueryParams))
+		for key, values := range r.QueryParams {
+			query[key] = append([]string(nil), values...)
+		}
+		for key, value := range m.QueryParams {
+			query.Set(key, value)
+		}
+		r.QueryParams = query
+	}
+}
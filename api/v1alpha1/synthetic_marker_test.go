@@ -0,0 +1,71 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestSyntheticMarker_mark(t *testing.T) {
+	tests := []struct {
+		TestName      string
+		Marker        *SyntheticMarker
+		ExpectedHeads map[string]string
+		ExpectedQuery string
+	}{
+		{"none", nil, map[string]string{"Accept": "application/json", "X-Synthetic": ""}, "page=1"},
+		{"defaults", &SyntheticMarker{}, map[string]string{"Accept": "application/json",
+			"X-Synthetic": "monitoring-controller", "X-Synthetic-Monitor": "default/orders"}, "page=1"},
+		{"configured", &SyntheticMarker{Headers: map[string]string{"X-Traffic-Source": "synthetic", "Accept": "*/*"},
+			QueryParams: map[string]string{"utm_source": "synthetic"}, MonitorHeader: "X-Monitor", Contact: "sre@example.com"},
+			map[string]string{"Accept": "*/*", "X-Traffic-Source": "synthetic", "X-Synthetic": "", "X-Monitor": "default/orders",
+				"From": "sre@example.com"}, "page=1&utm_source=synthetic"},
+	}
+
+	for _, testdata := range tests {
+		spec := HttpRequest{Method: http.MethodGet, Url: "https://test.com/orders",
+			Headers: http.Header{"Accept": {"application/json"}}, QueryParams: url.Values{"page": {"1"}}}
+		r := spec
+		testdata.Marker.mark(&r, "default/orders")
+		req, err := r.BuildRequest()
+		if err != nil {
+			t.Fatalf("[%s] %v", testdata.TestName, err)
+		}
+		for key, expected := range testdata.ExpectedHeads {
+			if out := req.Header.Get(key); out != expected {
+				t.Errorf("[%s] unexpected %s header. Got: %q, expected: %q", testdata.TestName, key, out, expected)
+			}
+		}
+		if out := req.URL.RawQuery; out != testdata.ExpectedQuery {
+			t.Errorf("[%s] unexpected query. Got: %q, expected: %q", testdata.TestName, out, testdata.ExpectedQuery)
+		}
+		if len(spec.Headers) != 1 || len(spec.QueryParams) != 1 {
+			t.Errorf("[%s] the spec was modified: %v %v", testdata.TestName, spec.Headers, spec.QueryParams)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// Replace the spec with the template, the patches applied to it in order, and then the fields set in the spec
+// itself. template is the YAML the template_ref points to. The resulting spec is checked like any other.
+func (h *HttpMonitor) ApplyTemplate(template []byte) error {
+	merged, err := yaml.YAMLToJSON(template)
+	if err != nil {
+		return fmt.Errorf("the template is not valid YAML: %w", err)
+	}
+	for i, patch := range h.Spec.Patches {
+		patchJson, err := yaml.YAMLToJSON([]byte(patch))
+		if err != nil {
+			return fmt.Errorf("patch %d is not valid YAML: %w", i+1, err)
+		}
+		if merged, err = strategicpatch.StrategicMergePatch(merged, patchJson, HttpMonitorSpec{}); err != nil {
+			return fmt.Errorf("failed to apply patch %d: %w", i+1, err)
+		}
+	}
+	own, err := ownFields(h.Spec)
+	if err != nil {
+		return err
+	}
+	if merged, err = strategicpatch.StrategicMergePatch(merged, own, HttpMonitorSpec{}); err != nil {
+		return fmt.Errorf("failed to apply the monitor's own spec: %w", err)
+	}
+
+	var spec HttpMonitorSpec
+	if err := json.Unmarshal(merged, &spec); err != nil {
+		return fmt.Errorf("the patched template is not a valid spec: %w", err)
+	}
+	// Checked without the template, so the requirements it satisfies are checked too
+	resolved := &HttpMonitor{ObjectMeta: h.ObjectMeta, Spec: *spec.DeepCopy()}
+	resolved.Spec.TemplateRef, resolved.Spec.Patches = nil, nil
+	if err := resolved.checkInvariants(); err != nil {
+		return fmt.Errorf("the patched template is invalid: %w", err)
+	}
+	if err := resolved.checkRunBudget(); err != nil {
+		return fmt.Errorf("the patched template is invalid: %w", err)
+	}
+	h.Spec = spec
+	return nil
+}
+
+// The spec as a merge patch that only holds the fields it sets. Fields without omitempty, like a request's url, marshal
+// as empty strings, and those would replace what the template sets.
+func ownFields(spec HttpMonitorSpec) ([]byte, error) {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var fields interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(withoutUnset(fields))
+}
+
+func withoutUnset(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			if v = withoutUnset(v); v == nil || v == "" {
+				delete(value, k)
+			} else {
+				value[k] = v
+			}
+		}
+	case []interface{}:
+		for i, v := range value {
+			value[i] = withoutUnset(v)
+		}
+	}
+	return value
+}
+
+// Patches that are not YAML objects would only fail once the controller resolves the template
+func (h *HttpMonitor) checkPatches() error {
+	for i, patch := range h.Spec.Patches {
+		var fields map[string]interface{}
+		if err := yaml.Unmarshal([]byte(patch), &fields); err != nil {
+			return fmt.Errorf("patch %d is not a YAML object: %s", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testTemplate = `
+period: 5m
+environment:
+  host: staging.example.com
+requests:
+  - name: login
+    method: POST
+    url: "https://{host}/login"
+    expected_response_codes: [200]
+    vars_from_response:
+      - name: token
+        from: body_json
+        json_path: /token
+  - name: profile
+    url: "https://{host}/me"
+    headers:
+      Authorization: ["Bearer {token}"]
+`
+
+func TestHttpMonitor_ApplyTemplate(t *testing.T) {
+	templateRef := &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "templates"}, Key: "login.yaml"}
+	monitor := &HttpMonitor{Spec: HttpMonitorSpec{
+		TemplateRef: templateRef,
+		Patches: []string{
+			"environment:\n  host: example.com\n",
+			"requests:\n  - name: login\n    vars_from_response:\n      - name: token\n        json_path: /access_token\n",
+		},
+		Requests: []HttpRequest{{Name: "profile", Method: "HEAD"}},
+		Period:   &metav1.Duration{Duration: time.Minute},
+		Severity: SeverityWarning,
+	}}
+	if err := monitor.ApplyTemplate([]byte(testTemplate)); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := monitor.Spec
+	if spec.Period.Duration != time.Minute || spec.Severity != SeverityWarning || spec.Environment["host"] != "example.com" {
+		t.Errorf("unexpected spec %+v", spec)
+	}
+	if len(spec.Requests) != 2 || spec.Requests[0].Name != "login" || spec.Requests[1].Name != "profile" {
+		t.Fatalf("unexpected requests %+v", spec.Requests)
+	}
+	login := spec.Requests[0]
+	if login.Method != "POST" || len(login.ExpectedResponseCodes) != 1 || len(login.VariablesFromResponse) != 1 ||
+		login.VariablesFromResponse[0].From != FromTypeBodyJson || login.VariablesFromResponse[0].JsonPath != "/access_token" {
+		t.Errorf("unexpected merged login request %+v", login)
+	}
+	profile := spec.Requests[1]
+	if profile.Method != "HEAD" || profile.Url != "https://{host}/me" || len(profile.Headers["Authorization"]) != 1 {
+		t.Errorf("unexpected merged profile request %+v", profile)
+	}
+	if spec.TemplateRef == nil || *spec.TemplateRef != *templateRef {
+		t.Errorf("unexpected template_ref %v", spec.TemplateRef)
+	}
+
+	tests := []struct {
+		TestName string
+		Template string
+		Patches  []string
+		Err      string
+	}{
+		{"invalid template", "requests: [", nil, "the template is not valid YAML"},
+		{"invalid patch", testTemplate, []string{"requests: ["}, "patch 1 is not valid YAML"},
+		{"no requests", "period: 5m\n", nil, "the patched template is invalid: at least one request is required"},
+		{"no period", "requests:\n  - name: a\n    url: https://example.com\n", nil,
+			"the patched template is invalid: a period is required"},
+		{"wrong type", testTemplate, []string{"period: [1]"}, "the patched template is not a valid spec"},
+	}
+	for _, testdata := range tests {
+		monitor := &HttpMonitor{Spec: HttpMonitorSpec{TemplateRef: templateRef, Patches: testdata.Patches}}
+		err := monitor.ApplyTemplate([]byte(testdata.Template))
+		if err == nil || !strings.Contains(err.Error(), testdata.Err) {
+			t.Errorf("[%s] unexpected error %v", testdata.TestName, err)
+		}
+	}
+}
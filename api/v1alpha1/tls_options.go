@@ -0,0 +1,133 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// How a request verifies the target's certificate, for internal services signed by a private CA or reached by
+// an address their certificate does not name
+type TlsOptions struct {
+	// Accept any certificate. The connection is still encrypted, but nothing checks who is at the other end
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+
+	// PEM certificates to verify the target with instead of the system roots
+	CaBundle *CaBundleSource `json:"ca_bundle,omitempty"`
+
+	// The name sent in SNI and checked against the certificate, instead of the host in the url
+	ServerName string `json:"server_name,omitempty"`
+
+	// Present a client certificate, for targets that require mutual TLS. Overrides the monitor's
+	// client_certificate.
+	ClientCertificate *ClientCertificate `json:"client_certificate,omitempty"`
+}
+
+// A certificate and key to present to the target, read from a Secret
+type ClientCertificate struct {
+	// A Secret with tls.crt and tls.key keys, like one of type kubernetes.io/tls. The certificate may be followed
+	// by its intermediates.
+	SecretRef corev1.LocalObjectReference `json:"secret_ref"`
+
+	// The PEM certificate and key, loaded by the controller
+	Certificate []byte `json:"-"`
+	Key         []byte `json:"-"`
+	Loaded      bool   `json:"-"`
+}
+
+// CA certificates kept in a ConfigMap or a Secret key. Set exactly one of the refs.
+type CaBundleSource struct {
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"config_map_ref,omitempty"`
+
+	SecretRef *corev1.SecretKeySelector `json:"secret_ref,omitempty"`
+
+	// The certificates, loaded by the controller
+	Loaded []byte `json:"-"`
+}
+
+func (t *TlsOptions) validate() error {
+	if t.InsecureSkipVerify && t.CaBundle != nil {
+		return fmt.Errorf("invalid tls: insecure_skip_verify skips the checks ca_bundle is for, set only one")
+	}
+	if t.CaBundle != nil && (t.CaBundle.ConfigMapRef == nil) == (t.CaBundle.SecretRef == nil) {
+		return fmt.Errorf("invalid tls: set exactly one of config_map_ref and secret_ref in ca_bundle")
+	}
+	return nil
+}
+
+func (c *CaBundleSource) describe() string {
+	if c.SecretRef != nil {
+		return fmt.Sprintf("secret %s, key %s", c.SecretRef.Name, c.SecretRef.Key)
+	}
+	return fmt.Sprintf("configmap %s, key %s", c.ConfigMapRef.Name, c.ConfigMapRef.Key)
+}
+
+// Requests with a CA bundle or client certificate that was not loaded fail, rather than falling back to the
+// system roots or connecting without a certificate
+func (t *TlsOptions) loaded() error {
+	if t == nil {
+		return nil
+	}
+	if t.CaBundle != nil && t.CaBundle.Loaded == nil {
+		return fmt.Errorf("the CA bundle in %s was not loaded", t.CaBundle.describe())
+	}
+	if t.ClientCertificate != nil && !t.ClientCertificate.Loaded {
+		return fmt.Errorf("the client certificate in secret %s was not loaded", t.ClientCertificate.SecretRef.Name)
+	}
+	return nil
+}
+
+// The TLS options of a request, with the monitor's client certificate when the request has none of its own
+func (t *TlsOptions) withClientCertificate(certificate *ClientCertificate) *TlsOptions {
+	if certificate == nil || (t != nil && t.ClientCertificate != nil) {
+		return t
+	}
+	var options TlsOptions
+	if t != nil {
+		options = *t
+	}
+	options.ClientCertificate = certificate
+	return &options
+}
+
+// Add the settings to the options of a client
+func (t *TlsOptions) apply(options *httpclient.Options) {
+	if t == nil {
+		return
+	}
+	options.TLSInsecureSkipVerify = t.InsecureSkipVerify
+	options.TLSServerName = t.ServerName
+	if t.CaBundle != nil {
+		options.TLSRootCAs = string(t.CaBundle.Loaded)
+	}
+	if t.ClientCertificate != nil {
+		options.TLSClientCertificate = string(t.ClientCertificate.Certificate)
+		options.TLSClientKey = string(t.ClientCertificate.Key)
+	}
+}
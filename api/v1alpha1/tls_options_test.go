@@ -0,0 +1,150 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestHttpRequest_tls(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	httpclient.Initialize(0)
+	// The test server's certificate is its own CA, and names example.com
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	ref := &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "internal-ca"}, Key: "ca.crt"}
+
+	tests := []struct {
+		TestName      string
+		Tls           *TlsOptions
+		ExpectedError bool
+	}{
+		{"system roots", nil, true},
+		{"ca bundle", &TlsOptions{CaBundle: &CaBundleSource{ConfigMapRef: ref, Loaded: ca}}, false},
+		{"server name in the certificate", &TlsOptions{CaBundle: &CaBundleSource{ConfigMapRef: ref, Loaded: ca},
+			ServerName: "example.com"}, false},
+		{"server name not in the certificate", &TlsOptions{CaBundle: &CaBundleSource{ConfigMapRef: ref, Loaded: ca},
+			ServerName: "internal.morphic.example.org"}, true},
+		{"ca bundle not loaded", &TlsOptions{CaBundle: &CaBundleSource{ConfigMapRef: ref}}, true},
+		{"insecure", &TlsOptions{InsecureSkipVerify: true}, false},
+	}
+
+	for _, testdata := range tests {
+		r := HttpRequest{Name: testdata.TestName, Method: http.MethodGet, Url: server.URL,
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, Tls: testdata.Tls}
+		_, result := r.timedSendRequest(httpclient.GetClient())
+		if (result.Err != nil) != testdata.ExpectedError {
+			t.Errorf("[%s] unexpected error: %v", testdata.TestName, result.Err)
+		}
+	}
+}
+
+func TestHttpRequest_strictCrypto(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	httpclient.Initialize(0)
+	httpclient.SetStrictCrypto(true)
+	defer httpclient.SetStrictCrypto(false)
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	ref := &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "internal-ca"}, Key: "ca.crt"}
+
+	tests := []struct {
+		TestName      string
+		Tls           *TlsOptions
+		Fingerprint   ClientFingerprint
+		ExpectedError bool
+	}{
+		{"ca bundle", &TlsOptions{CaBundle: &CaBundleSource{ConfigMapRef: ref, Loaded: ca}}, "", false},
+		{"browser fingerprint", &TlsOptions{CaBundle: &CaBundleSource{ConfigMapRef: ref, Loaded: ca}}, ClientFingerprintChrome, false},
+		{"insecure", &TlsOptions{InsecureSkipVerify: true}, "", true},
+	}
+
+	for _, testdata := range tests {
+		r := HttpRequest{Name: testdata.TestName, Method: http.MethodGet, Url: server.URL,
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)}, Tls: testdata.Tls, Fingerprint: testdata.Fingerprint}
+		_, result := r.timedSendRequest(httpclient.GetClient())
+		if (result.Err != nil) != testdata.ExpectedError {
+			t.Errorf("[%s] unexpected error: %v", testdata.TestName, result.Err)
+		}
+		if !testdata.ExpectedError && (result.TLS == nil || result.TLS.Version != tls.VersionTLS12 ||
+			result.TLS.CipherSuite == tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305) {
+			t.Errorf("[%s] connection not restricted to TLS 1.2 with an approved suite", testdata.TestName)
+		}
+	}
+}
+
+func TestHttpMonitor_Execute_clientCertificate(t *testing.T) {
+	clientCert, clientPool := startTlsCertificate(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientPool}
+	server.StartTLS()
+	defer server.Close()
+	httpclient.Initialize(0)
+	certificate := &ClientCertificate{
+		SecretRef:   corev1.LocalObjectReference{Name: "monitoring-client"},
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: clientCert.Certificate[0]}),
+		Key: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(clientCert.PrivateKey.(*rsa.PrivateKey))}),
+		Loaded: true,
+	}
+
+	tests := []struct {
+		TestName        string
+		Monitor         *ClientCertificate
+		Request         *ClientCertificate
+		ExpectedFailure bool
+	}{
+		{"no certificate", nil, nil, true},
+		{"monitor certificate", certificate, nil, false},
+		{"request certificate", nil, certificate, false},
+		{"request certificate not loaded", certificate, &ClientCertificate{SecretRef: certificate.SecretRef}, true},
+		{"mismatched key", nil, &ClientCertificate{SecretRef: certificate.SecretRef, Certificate: certificate.Certificate,
+			Key: []byte("not a key"), Loaded: true}, true},
+	}
+	for _, testdata := range tests {
+		monitor := &HttpMonitor{Spec: HttpMonitorSpec{ClientCertificate: testdata.Monitor, Requests: []HttpRequest{
+			{Name: "internal", Method: http.MethodGet, Url: server.URL, ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(200)},
+				Tls: &TlsOptions{InsecureSkipVerify: true, ClientCertificate: testdata.Request}},
+		}}}
+		if result := monitor.Execute(); result.Failed() != testdata.ExpectedFailure {
+			t.Errorf("[%s] unexpected result: %v", testdata.TestName, result.FirstFailure())
+		}
+		// The monitor's certificate applies to the run, it is not written into the request's spec
+		if monitor.Spec.Requests[0].Tls.ClientCertificate != testdata.Request {
+			t.Errorf("[%s] unexpected client certificate in the spec", testdata.TestName)
+		}
+	}
+}
@@ -89,7 +89,11 @@ func (v *Variable) ParseFromResponse(resp *http.Response) error {
 }
 
 func (v *Variable) jsonPathToPieces() []string {
-	pieces := strings.Split(v.JsonPath, "/")
+	return splitJsonPath(v.JsonPath)
+}
+
+func splitJsonPath(path string) []string {
+	pieces := strings.Split(path, "/")
 	var finalPieces []string
 
 	for _, piece := range pieces {
@@ -100,21 +104,22 @@ func (v *Variable) jsonPathToPieces() []string {
 	return finalPieces
 }
 
-func (v *Variable) parseFromJsonBytes(jsonBody []byte) error {
-	jsonPath := v.jsonPathToPieces()
-
-	// jsoniter.Get needs a specific type. So convert to that.
-	interfaceJsonPath := make([]interface{}, len(jsonPath))
-	for i, val := range jsonPath {
+// jsoniter.Get needs a specific type. So convert to that, with numeric pieces as array indexes.
+func jsonPathKeys(pieces []string) []interface{} {
+	keys := make([]interface{}, len(pieces))
+	for i, val := range pieces {
 		intVal, err := strconv.Atoi(val)
 		if err == nil {
-			interfaceJsonPath[i] = intVal
+			keys[i] = intVal
 		} else {
-			interfaceJsonPath[i] = val
+			keys[i] = val
 		}
 	}
+	return keys
+}
 
-	getter := jsoniter.Get(jsonBody, interfaceJsonPath...)
+func (v *Variable) parseFromJsonBytes(jsonBody []byte) error {
+	getter := jsoniter.Get(jsonBody, jsonPathKeys(v.jsonPathToPieces())...)
 	err := getter.LastError()
 	if err != nil {
 		return err
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+// True when a monitor's version has differed from another monitor's for longer than the grace period
+const ConditionVersionSkew = "VersionSkew"
+
+const defaultVersionSkewGracePeriod = time.Hour
+
+// Compare a variable, like the version a /version endpoint reports, with the same variable in another monitor,
+// like staging with production or one region with another
+type VersionSkewRule struct {
+	// The monitor to compare with, in the same namespace
+	// +kubebuilder:validation:MinLength=1
+	Monitor string `json:"monitor"`
+
+	// The variable to compare, extracted by a request of this monitor
+	// +kubebuilder:validation:MinLength=1
+	Variable string `json:"variable"`
+
+	// The name of the variable in the other monitor. Default is the same as variable
+	OtherVariable string `json:"other_variable,omitempty"`
+
+	// How long the versions may differ before the condition is raised, so a rollout that reaches one
+	// environment first does not count as skew. Default is 1h
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+	GracePeriod *metav1.Duration `json:"grace_period,omitempty"`
+
+	// How important the skew is. Default is warning
+	// +kubebuilder:validation:Enum=critical;warning;info
+	Severity Severity `json:"severity,omitempty"`
+}
+
+// The name of the variable in the other monitor
+func (v *VersionSkewRule) OtherVariableName() string {
+	if v.OtherVariable == "" {
+		return v.Variable
+	}
+	return v.OtherVariable
+}
+
+func (v *VersionSkewRule) gracePeriod() time.Duration {
+	if v.GracePeriod == nil {
+		return defaultVersionSkewGracePeriod
+	}
+	return v.GracePeriod.Duration
+}
+
+// The VersionSkew condition for the values the two monitors last saw, the other one only when known. since is
+// when the values started to differ, zero while they agree, and the updated one is returned with the condition.
+func (v *VersionSkewRule) Condition(own, other string, otherKnown bool, since, now time.Time) (MonitorCondition, time.Time) {
+	condition := MonitorCondition{
+		Type:               ConditionVersionSkew,
+		LastTransitionTime: metav1.NewTime(now),
+	}
+	switch {
+	case own == "" || !otherKnown:
+		condition.Status = corev1.ConditionUnknown
+		condition.Reason = "VersionUnknown"
+		condition.Message = fmt.Sprintf("no %s from both this monitor and %s yet", v.Variable, v.Monitor)
+		return condition, since
+	case own == other:
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "VersionsMatch"
+		condition.Message = fmt.Sprintf("%s is %s, like in %s", v.Variable, own, v.Monitor)
+		return condition, time.Time{}
+	}
+	if since.IsZero() {
+		since = now
+	}
+	condition.Message = fmt.Sprintf("%s is %s, but %s in %s, since %s", v.Variable, own, other, v.Monitor,
+		since.UTC().Format(time.RFC3339))
+	if now.Sub(since) < v.gracePeriod() {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "WithinGracePeriod"
+		return condition, since
+	}
+	condition.Status = corev1.ConditionTrue
+	condition.Reason = "VersionsDiverged"
+	condition.Severity = v.Severity.Or(SeverityWarning)
+	return condition, since
+}
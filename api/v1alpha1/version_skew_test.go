@@ -0,0 +1,73 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestVersionSkewRule_Condition(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	rule := &VersionSkewRule{Monitor: "production", Variable: "version", GracePeriod: &metav1.Duration{Duration: 30 * time.Minute}}
+
+	tests := []struct {
+		TestName   string
+		Own        string
+		Other      string
+		OtherKnown bool
+		Since      time.Time
+		Status     corev1.ConditionStatus
+		Reason     string
+		Severity   Severity
+		NewSince   time.Time
+	}{
+		{"other not seen", "1.2", "", false, time.Time{}, corev1.ConditionUnknown, "VersionUnknown", "", time.Time{}},
+		{"same version", "1.2", "1.2", true, now.Add(-time.Hour), corev1.ConditionFalse, "VersionsMatch", "", time.Time{}},
+		{"just diverged", "1.3", "1.2", true, time.Time{}, corev1.ConditionFalse, "WithinGracePeriod", "", now},
+		{"within grace period", "1.3", "1.2", true, now.Add(-29 * time.Minute), corev1.ConditionFalse,
+			"WithinGracePeriod", "", now.Add(-29 * time.Minute)},
+		{"diverged", "1.3", "1.2", true, now.Add(-31 * time.Minute), corev1.ConditionTrue, "VersionsDiverged",
+			SeverityWarning, now.Add(-31 * time.Minute)},
+	}
+	for _, testdata := range tests {
+		condition, since := rule.Condition(testdata.Own, testdata.Other, testdata.OtherKnown, testdata.Since, now)
+		if condition.Type != ConditionVersionSkew || condition.Status != testdata.Status ||
+			condition.Reason != testdata.Reason || condition.Severity != testdata.Severity {
+			t.Errorf("[%s] unexpected condition %+v", testdata.TestName, condition)
+		}
+		if !since.Equal(testdata.NewSince) {
+			t.Errorf("[%s] unexpected since %s", testdata.TestName, since)
+		}
+	}
+
+	condition, _ := rule.Condition("1.3", "1.2", true, now.Add(-time.Hour), now)
+	if condition.Message != "version is 1.3, but 1.2 in production, since 2020-06-01T11:00:00Z" {
+		t.Errorf("unexpected message %q", condition.Message)
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"golang.org/x/net/websocket"
+	"io/ioutil"
+	"net"
+	"net/http"
+)
+
+// Upgrades to a WebSocket instead of sending a plain request, sends a message and waits for the replies
+type WebSocketCheck struct {
+	// The message to send once connected, with variables replaced like in the url. Default is to send nothing and
+	// wait for the server to speak first
+	Send string `json:"send,omitempty"`
+
+	// How many messages to wait for. Default is 1
+	// +kubebuilder:validation:Minimum=1
+	Messages int `json:"messages,omitempty"`
+
+	// Subprotocols to offer in the handshake, like graphql-transport-ws
+	Subprotocols []string `json:"subprotocols,omitempty"`
+}
+
+func (w *WebSocketCheck) messages() int {
+	if w.Messages < 1 {
+		return 1
+	}
+	return w.Messages
+}
+
+// Connect to the target like the client would, send the message and read the replies. The response has status 101,
+// the handshake's headers and the last reply as the body, so assertions and variables work like for any response.
+// Cookies the handshake sets go in the client's jar, and the bytes received count every reply.
+func (r *HttpRequest) exchangeWebSocket(ctx context.Context, client *http.Client, req *http.Request, result *RequestResult) (*http.Response, error) {
+	// Cookies and the Origin header are for the http URL, the handshake goes to the ws one
+	location, httpUrl := *req.URL, *req.URL
+	switch req.URL.Scheme {
+	case "http", "ws":
+		location.Scheme, httpUrl.Scheme = "ws", "http"
+	case "https", "wss":
+		location.Scheme, httpUrl.Scheme = "wss", "https"
+	default:
+		return nil, fmt.Errorf("websocket url must be ws, wss, http or https, not %q", req.URL.Scheme)
+	}
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		origin = httpUrl.Scheme + "://" + httpUrl.Host
+	}
+	config, err := websocket.NewConfig(location.String(), origin)
+	if err != nil {
+		return nil, err
+	}
+	config.Protocol = r.WebSocket.Subprotocols
+	config.Header = req.Header.Clone()
+	if client.Jar != nil {
+		for _, cookie := range client.Jar.Cookies(&httpUrl) {
+			config.Header.Add("Cookie", cookie.String())
+		}
+	}
+
+	conn, state, err := httpclient.DialerOf(client).Dial(ctx, &httpUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	result.TLS = state
+
+	handshake := &handshakeConn{Conn: conn}
+	ws, err := websocket.NewClient(config, handshake)
+	if err != nil {
+		return nil, fmt.Errorf("websocket handshake failed: %w", err)
+	}
+	defer ws.Close()
+	// The websocket package reads the handshake's response without keeping it, so it is parsed again from a copy
+	handshake.done = true
+	header := make(http.Header)
+	if resp, err := http.ReadResponse(bufio.NewReader(&handshake.response), req); err == nil {
+		header = resp.Header
+		if client.Jar != nil && len(resp.Cookies()) > 0 {
+			client.Jar.SetCookies(&httpUrl, resp.Cookies())
+		}
+	}
+	if send := r.AvailableVariables.newReplacer().Replace(r.WebSocket.Send); send != "" {
+		if err := websocket.Message.Send(ws, send); err != nil {
+			return nil, fmt.Errorf("sending the websocket message failed: %w", err)
+		}
+		result.BytesSent = int64(len(send))
+	}
+	var reply []byte
+	for received := 0; received < r.WebSocket.messages(); received++ {
+		if err := websocket.Message.Receive(ws, &reply); err != nil {
+			return nil, fmt.Errorf("received %d of %d websocket messages: %w", received, r.WebSocket.messages(), err)
+		}
+		result.BytesReceived += int64(len(reply))
+	}
+	return &http.Response{
+		Status:        "101 Switching Protocols",
+		StatusCode:    http.StatusSwitchingProtocols,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(reply)),
+		ContentLength: int64(len(reply)),
+		Request:       req,
+		TLS:           result.TLS,
+	}, nil
+}
+
+// Keeps a copy of what the server sends until the handshake is done
+type handshakeConn struct {
+	net.Conn
+	response bytes.Buffer
+	done     bool
+}
+
+func (c *handshakeConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if !c.done {
+		c.response.Write(p[:n])
+	}
+	return n, err
+}
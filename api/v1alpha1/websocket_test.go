@@ -0,0 +1,173 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package v1alpha1
+
+import (
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"golang.org/x/net/websocket"
+	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHttpRequest_webSocket(t *testing.T) {
+	// Greets every client, then answers each message with an acknowledgement that quotes it
+	mux := http.NewServeMux()
+	mux.Handle("/realtime", websocket.Handler(func(ws *websocket.Conn) {
+		if ws.Request().Header.Get("Authorization") != "Bearer realtime-token" {
+			return
+		}
+		_ = websocket.Message.Send(ws, `{"type": "welcome"}`)
+		var message string
+		for websocket.Message.Receive(ws, &message) == nil {
+			_ = websocket.Message.Send(ws, `{"type": "ack", "id": "`+strings.TrimPrefix(message, "subscribe ")+`"}`)
+		}
+	}))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	httpclient.Initialize(0)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/realtime"
+	ack := "ack"
+
+	tests := []struct {
+		TestName         string
+		Url              string
+		Authorization    string
+		WebSocket        WebSocketCheck
+		ExpectedError    bool
+		ExpectedVariable string
+	}{
+		{"greeting", url, "Bearer realtime-token", WebSocketCheck{}, false, ""},
+		{"reply to a message", url, "Bearer realtime-token", WebSocketCheck{Send: "subscribe {channel}", Messages: 2}, false, "orders"},
+		{"http url", server.URL + "/realtime", "Bearer realtime-token", WebSocketCheck{Send: "subscribe {channel}", Messages: 2}, false, "orders"},
+		{"no reply", url, "Bearer realtime-token", WebSocketCheck{Messages: 2}, true, ""},
+		{"closed by the server", url, "", WebSocketCheck{}, true, ""},
+		{"no websocket", server.URL + "/missing", "Bearer realtime-token", WebSocketCheck{}, true, ""},
+	}
+
+	for _, testdata := range tests {
+		webSocket := testdata.WebSocket
+		r := HttpRequest{Name: testdata.TestName, Method: http.MethodGet, Url: testdata.Url,
+			Headers:               http.Header{"Authorization": {testdata.Authorization}},
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(101)}, WebSocket: &webSocket,
+			Timeout:               &metav1.Duration{Duration: 200 * time.Millisecond},
+			AvailableVariables:    VariableList{{Name: "channel", Value: "orders"}},
+			VariablesFromResponse: VariableList{{Name: "subscribed", From: FromTypeBodyJson, JsonPath: "/id"}},
+		}
+		if webSocket.Send != "" {
+			r.ResponseAssertions = &ResponseAssertions{Json: []JsonAssertion{{JsonPath: "/type", Equals: &ack}}}
+		} else {
+			r.VariablesFromResponse = nil
+		}
+		_, result := r.timedSendRequest(httpclient.GetClient())
+		if (result.Err != nil) != testdata.ExpectedError {
+			t.Errorf("[%s] unexpected error: %v", testdata.TestName, result.Err)
+		}
+		if testdata.ExpectedVariable != "" && r.VariablesFromResponse[0].Value != testdata.ExpectedVariable {
+			t.Errorf("[%s] unexpected variable %q", testdata.TestName, r.VariablesFromResponse[0].Value)
+		}
+	}
+}
+
+func TestHttpRequest_webSocketHandshake(t *testing.T) {
+	// Starts a session in the handshake, then sends two replies
+	server := httptest.NewServer(websocket.Server{
+		Handshake: func(config *websocket.Config, req *http.Request) error {
+			config.Header = http.Header{"Set-Cookie": {"session=abc123; Path=/"}, "X-Region": {"eu-west"}}
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			_ = websocket.Message.Send(ws, "hello")
+			_ = websocket.Message.Send(ws, "world!")
+		},
+	})
+	defer server.Close()
+	// Tunnels CONNECT requests to the server and counts them
+	var tunnels int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target, err := net.Dial("tcp", r.Host)
+		if r.Method != http.MethodConnect || err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		atomic.AddInt32(&tunnels, 1)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go func() { _, _ = io.Copy(target, conn) }()
+		_, _ = io.Copy(conn, target)
+		conn.Close()
+		target.Close()
+	}))
+	defer proxy.Close()
+	httpclient.Initialize(0)
+
+	tests := []struct {
+		TestName string
+		ProxyUrl string
+		Tunnels  int32
+	}{
+		{"direct", "", 0},
+		{"through a proxy", proxy.URL, 1},
+	}
+	for _, testdata := range tests {
+		jar, _ := cookiejar.New(nil)
+		client := &http.Client{Jar: jar}
+		r := HttpRequest{Name: testdata.TestName, Method: http.MethodGet, Url: server.URL, ProxyUrl: testdata.ProxyUrl,
+			ExpectedResponseCodes: StatusCodeMatcher{intstr.FromInt(101)}, WebSocket: &WebSocketCheck{Messages: 2},
+			Timeout:               &metav1.Duration{Duration: time.Second},
+			VariablesFromResponse: VariableList{{Name: "region", From: FromTypeHeaders, JsonPath: "/X-Region/0"}},
+		}
+		before := atomic.LoadInt32(&tunnels)
+		_, result := r.timedSendRequest(client)
+		if result.Err != nil {
+			t.Errorf("[%s] got unexpected err: %s", testdata.TestName, result.Err)
+			continue
+		}
+		if result.BytesReceived != int64(len("hello")+len("world!")) {
+			t.Errorf("[%s] unexpected bytes received. Got: %d, expected: %d", testdata.TestName, result.BytesReceived,
+				len("hello")+len("world!"))
+		}
+		serverUrl, _ := url.Parse(server.URL)
+		if cookies := jar.Cookies(serverUrl); len(cookies) != 1 || cookies[0].Value != "abc123" {
+			t.Errorf("[%s] unexpected cookies. Got: %v, expected: session=abc123", testdata.TestName, cookies)
+		}
+		if r.VariablesFromResponse[0].Value != "eu-west" {
+			t.Errorf("[%s] unexpected variable. Got: %q, expected: eu-west", testdata.TestName, r.VariablesFromResponse[0].Value)
+		}
+		if sent := atomic.LoadInt32(&tunnels) - before; sent != testdata.Tunnels {
+			t.Errorf("[%s] unexpected tunnels. Got: %d, expected: %d", testdata.TestName, sent, testdata.Tunnels)
+		}
+	}
+}
@@ -30,203 +30,2681 @@ The R&D leading to these results received funding from the:
 package v1alpha1
 
 import (
-	"k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"net/http"
 	"net/url"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessibilityAudit) DeepCopyInto(out *AccessibilityAudit) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisableRules != nil {
+		in, out := &in.DisableRules, &out.DisableRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessibilityAudit.
+func (in *AccessibilityAudit) DeepCopy() *AccessibilityAudit {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessibilityAudit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssertionViolation) DeepCopyInto(out *AssertionViolation) {
+	*out = *in
+	in.FirstSeen.DeepCopyInto(&out.FirstSeen)
+	in.LastSeen.DeepCopyInto(&out.LastSeen)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssertionViolation.
+func (in *AssertionViolation) DeepCopy() *AssertionViolation {
+	if in == nil {
+		return nil
+	}
+	out := new(AssertionViolation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AwsSigV4Auth) DeepCopyInto(out *AwsSigV4Auth) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwsSigV4Auth.
+func (in *AwsSigV4Auth) DeepCopy() *AwsSigV4Auth {
+	if in == nil {
+		return nil
+	}
+	out := new(AwsSigV4Auth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BasicAuth) DeepCopyInto(out *BasicAuth) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BasicAuth.
+func (in *BasicAuth) DeepCopy() *BasicAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(BasicAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BearerAuth) DeepCopyInto(out *BearerAuth) {
+	*out = *in
+	in.SecretRef.DeepCopyInto(&out.SecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BearerAuth.
+func (in *BearerAuth) DeepCopy() *BearerAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(BearerAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BodySource) DeepCopyInto(out *BodySource) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Loaded != nil {
+		in, out := &in.Loaded, &out.Loaded
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodySource.
+func (in *BodySource) DeepCopy() *BodySource {
+	if in == nil {
+		return nil
+	}
+	out := new(BodySource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CaBundleSource) DeepCopyInto(out *CaBundleSource) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Loaded != nil {
+		in, out := &in.Loaded, &out.Loaded
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CaBundleSource.
+func (in *CaBundleSource) DeepCopy() *CaBundleSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CaBundleSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateTransparencyCheck) DeepCopyInto(out *CertificateTransparencyCheck) {
+	*out = *in
+	if in.AllowedLogIds != nil {
+		in, out := &in.AllowedLogIds, &out.AllowedLogIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateTransparencyCheck.
+func (in *CertificateTransparencyCheck) DeepCopy() *CertificateTransparencyCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateTransparencyCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientCertificate) DeepCopyInto(out *ClientCertificate) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientCertificate.
+func (in *ClientCertificate) DeepCopy() *ClientCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ColdStart) DeepCopyInto(out *ColdStart) {
+	*out = *in
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ColdStart.
+func (in *ColdStart) DeepCopy() *ColdStart {
+	if in == nil {
+		return nil
+	}
+	out := new(ColdStart)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommitStatusReport) DeepCopyInto(out *CommitStatusReport) {
+	*out = *in
+	in.TokenSecretRef.DeepCopyInto(&out.TokenSecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommitStatusReport.
+func (in *CommitStatusReport) DeepCopy() *CommitStatusReport {
+	if in == nil {
+		return nil
+	}
+	out := new(CommitStatusReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceCheck) DeepCopyInto(out *ComplianceCheck) {
+	*out = *in
+	if in.Hsts != nil {
+		in, out := &in.Hsts, &out.Hsts
+		*out = new(HstsCheck)
+		**out = **in
+	}
+	if in.CertificateTransparency != nil {
+		in, out := &in.CertificateTransparency, &out.CertificateTransparency
+		*out = new(CertificateTransparencyCheck)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceCheck.
+func (in *ComplianceCheck) DeepCopy() *ComplianceCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Diagnostics) DeepCopyInto(out *Diagnostics) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Diagnostics.
+func (in *Diagnostics) DeepCopy() *Diagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(Diagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EgressIpCheck) DeepCopyInto(out *EgressIpCheck) {
+	*out = *in
+	if in.Expected != nil {
+		in, out := &in.Expected, &out.Expected
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressIpCheck.
+func (in *EgressIpCheck) DeepCopy() *EgressIpCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(EgressIpCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailSink) DeepCopyInto(out *EmailSink) {
+	*out = *in
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailSink.
+func (in *EmailSink) DeepCopy() *EmailSink {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Executions) DeepCopyInto(out *Executions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Executions.
+func (in *Executions) DeepCopy() *Executions {
+	if in == nil {
+		return nil
+	}
+	out := new(Executions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForEach) DeepCopyInto(out *ForEach) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForEach.
+func (in *ForEach) DeepCopy() *ForEach {
+	if in == nil {
+		return nil
+	}
+	out := new(ForEach)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FormBody) DeepCopyInto(out *FormBody) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]FormField, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FormBody.
+func (in *FormBody) DeepCopy() *FormBody {
+	if in == nil {
+		return nil
+	}
+	out := new(FormBody)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FormField) DeepCopyInto(out *FormField) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Loaded != nil {
+		in, out := &in.Loaded, &out.Loaded
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FormField.
+func (in *FormField) DeepCopy() *FormField {
+	if in == nil {
+		return nil
+	}
+	out := new(FormField)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaSink) DeepCopyInto(out *GrafanaSink) {
+	*out = *in
+	in.ApiTokenSecretRef.DeepCopyInto(&out.ApiTokenSecretRef)
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaSink.
+func (in *GrafanaSink) DeepCopy() *GrafanaSink {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeaderAssertion) DeepCopyInto(out *HeaderAssertion) {
+	*out = *in
+	if in.Present != nil {
+		in, out := &in.Present, &out.Present
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Equals != nil {
+		in, out := &in.Equals, &out.Equals
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeaderAssertion.
+func (in *HeaderAssertion) DeepCopy() *HeaderAssertion {
+	if in == nil {
+		return nil
+	}
+	out := new(HeaderAssertion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeaderObservation) DeepCopyInto(out *HeaderObservation) {
+	*out = *in
+	in.FirstSeen.DeepCopyInto(&out.FirstSeen)
+	in.LastSeen.DeepCopyInto(&out.LastSeen)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeaderObservation.
+func (in *HeaderObservation) DeepCopy() *HeaderObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(HeaderObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HmacSignature) DeepCopyInto(out *HmacSignature) {
+	*out = *in
+	in.SecretRef.DeepCopyInto(&out.SecretRef)
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HmacSignature.
+func (in *HmacSignature) DeepCopy() *HmacSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(HmacSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HstsCheck) DeepCopyInto(out *HstsCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HstsCheck.
+func (in *HstsCheck) DeepCopy() *HstsCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(HstsCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Http2Check) DeepCopyInto(out *Http2Check) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Http2Check.
+func (in *Http2Check) DeepCopy() *Http2Check {
+	if in == nil {
+		return nil
+	}
+	out := new(Http2Check)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpCheck) DeepCopyInto(out *HttpCheck) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpCheck.
+func (in *HttpCheck) DeepCopy() *HttpCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HttpCheck) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpCheckList) DeepCopyInto(out *HttpCheckList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HttpCheck, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpCheckList.
+func (in *HttpCheckList) DeepCopy() *HttpCheckList {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpCheckList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HttpCheckList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpCheckSpec) DeepCopyInto(out *HttpCheckSpec) {
+	*out = *in
+	if in.Environment != nil {
+		in, out := &in.Environment, &out.Environment
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make([]HttpRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Cleanup != nil {
+		in, out := &in.Cleanup, &out.Cleanup
+		*out = make([]HttpRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(RequestAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCertificate != nil {
+		in, out := &in.ClientCertificate, &out.ClientCertificate
+		*out = new(ClientCertificate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CommitStatus != nil {
+		in, out := &in.CommitStatus, &out.CommitStatus
+		*out = new(CommitStatusReport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyntheticMarker != nil {
+		in, out := &in.SyntheticMarker, &out.SyntheticMarker
+		*out = new(SyntheticMarker)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RandomSeed != nil {
+		in, out := &in.RandomSeed, &out.RandomSeed
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Scrubbing != nil {
+		in, out := &in.Scrubbing, &out.Scrubbing
+		*out = new(ScrubbingRules)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpCheckSpec.
+func (in *HttpCheckSpec) DeepCopy() *HttpCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpCheckStatus) DeepCopyInto(out *HttpCheckStatus) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpCheckStatus.
+func (in *HttpCheckStatus) DeepCopy() *HttpCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HttpMonitor) DeepCopyInto(out *HttpMonitor) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpMonitor.
+func (in *HttpMonitor) DeepCopy() *HttpMonitor {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpMonitor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HttpMonitor) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpMonitorList) DeepCopyInto(out *HttpMonitorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HttpMonitor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpMonitorList.
+func (in *HttpMonitorList) DeepCopy() *HttpMonitorList {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpMonitorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HttpMonitorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpMonitorSpec) DeepCopyInto(out *HttpMonitorSpec) {
+	*out = *in
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Environment != nil {
+		in, out := &in.Environment, &out.Environment
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make([]HttpRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Cleanup != nil {
+		in, out := &in.Cleanup, &out.Cleanup
+		*out = make([]HttpRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(RequestAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCertificate != nil {
+		in, out := &in.ClientCertificate, &out.ClientCertificate
+		*out = new(ClientCertificate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Executions != nil {
+		in, out := &in.Executions, &out.Executions
+		*out = new(Executions)
+		**out = **in
+	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(Diagnostics)
+		**out = **in
+	}
+	if in.Scrubbing != nil {
+		in, out := &in.Scrubbing, &out.Scrubbing
+		*out = new(ScrubbingRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VersionSkew != nil {
+		in, out := &in.VersionSkew, &out.VersionSkew
+		*out = new(VersionSkewRule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]NotificationSink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(TargetWorkload)
+		**out = **in
+	}
+	if in.SyntheticMarker != nil {
+		in, out := &in.SyntheticMarker, &out.SyntheticMarker
+		*out = new(SyntheticMarker)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Ownership = in.Ownership
+	if in.RandomSeed != nil {
+		in, out := &in.RandomSeed, &out.RandomSeed
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpMonitorSpec.
+func (in *HttpMonitorSpec) DeepCopy() *HttpMonitorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpMonitorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpMonitorStatus) DeepCopyInto(out *HttpMonitorStatus) {
+	*out = *in
+	if in.LastExecution != nil {
+		in, out := &in.LastExecution, &out.LastExecution
+		*out = (*in).DeepCopy()
+	}
+	if in.LastFailure != nil {
+		in, out := &in.LastFailure, &out.LastFailure
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]MonitorCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LintWarnings != nil {
+		in, out := &in.LintWarnings, &out.LintWarnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VariableLineage != nil {
+		in, out := &in.VariableLineage, &out.VariableLineage
+		*out = make([]VariableLineage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Violations != nil {
+		in, out := &in.Violations, &out.Violations
+		*out = make([]AssertionViolation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = make([]RequestLatency, len(*in))
+		copy(*out, *in)
+	}
+	if in.CleanupDebt != nil {
+		in, out := &in.CleanupDebt, &out.CleanupDebt
+		*out = make([]PendingCleanup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(RequestUsage)
+		**out = **in
+	}
+	if in.HeaderHistory != nil {
+		in, out := &in.HeaderHistory, &out.HeaderHistory
+		*out = make([]HeaderObservation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Performance != nil {
+		in, out := &in.Performance, &out.Performance
+		*out = make([]PagePerformance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastRunRecord != nil {
+		in, out := &in.LastRunRecord, &out.LastRunRecord
+		*out = new(SignedRunRecord)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpMonitorStatus.
+func (in *HttpMonitorStatus) DeepCopy() *HttpMonitorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpMonitorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HttpRequest) DeepCopyInto(out *HttpRequest) {
+	*out = *in
+	out.Ownership = in.Ownership
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForEach != nil {
+		in, out := &in.ForEach, &out.ForEach
+		*out = new(ForEach)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.QueryParams != nil {
+		in, out := &in.QueryParams, &out.QueryParams
+		*out = make(url.Values, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.BodyFrom != nil {
+		in, out := &in.BodyFrom, &out.BodyFrom
+		*out = new(BodySource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Form != nil {
+		in, out := &in.Form, &out.Form
+		*out = new(FormBody)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(http.Header, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(RequestAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedClockSkew != nil {
+		in, out := &in.AllowedClockSkew, &out.AllowedClockSkew
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.HmacSignature != nil {
+		in, out := &in.HmacSignature, &out.HmacSignature
+		*out = new(HmacSignature)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Jwt != nil {
+		in, out := &in.Jwt, &out.Jwt
+		*out = new(JwtCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OidcDiscovery != nil {
+		in, out := &in.OidcDiscovery, &out.OidcDiscovery
+		*out = new(OidcDiscoveryCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Saml != nil {
+		in, out := &in.Saml, &out.Saml
+		*out = new(SamlCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Script != nil {
+		in, out := &in.Script, &out.Script
+		*out = new(ScriptStep)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Accessibility != nil {
+		in, out := &in.Accessibility, &out.Accessibility
+		*out = new(AccessibilityAudit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Performance != nil {
+		in, out := &in.Performance, &out.Performance
+		*out = new(PerformanceAudit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Protobuf != nil {
+		in, out := &in.Protobuf, &out.Protobuf
+		*out = new(ProtobufCodec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VariablesFromResponse != nil {
+		in, out := &in.VariablesFromResponse, &out.VariablesFromResponse
+		*out = make(VariableList, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Variable)
+				**out = **in
+			}
+		}
+	}
+	if in.CaptureHeaders != nil {
+		in, out := &in.CaptureHeaders, &out.CaptureHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetryBackoff != nil {
+		in, out := &in.RetryBackoff, &out.RetryBackoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RetryOn != nil {
+		in, out := &in.RetryOn, &out.RetryOn
+		*out = make([]RetryCondition, len(*in))
+		copy(*out, *in)
+	}
+	if in.Redirects != nil {
+		in, out := &in.Redirects, &out.Redirects
+		*out = new(Redirects)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExpectedResponseCodes != nil {
+		in, out := &in.ExpectedResponseCodes, &out.ExpectedResponseCodes
+		*out = make(StatusCodeMatcher, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxResponseBytes != nil {
+		in, out := &in.MaxResponseBytes, &out.MaxResponseBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ExpectConnectionReused != nil {
+		in, out := &in.ExpectConnectionReused, &out.ExpectConnectionReused
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ResponseAssertions != nil {
+		in, out := &in.ResponseAssertions, &out.ResponseAssertions
+		*out = new(ResponseAssertions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Language != nil {
+		in, out := &in.Language, &out.Language
+		*out = new(LanguageCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Compliance != nil {
+		in, out := &in.Compliance, &out.Compliance
+		*out = new(ComplianceCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EgressIp != nil {
+		in, out := &in.EgressIp, &out.EgressIp
+		*out = new(EgressIpCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tls != nil {
+		in, out := &in.Tls, &out.Tls
+		*out = new(TlsOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinCertificateRemaining != nil {
+		in, out := &in.MinCertificateRemaining, &out.MinCertificateRemaining
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Http2 != nil {
+		in, out := &in.Http2, &out.Http2
+		*out = new(Http2Check)
+		**out = **in
+	}
+	if in.Sse != nil {
+		in, out := &in.Sse, &out.Sse
+		*out = new(SseCheck)
+		**out = **in
+	}
+	if in.WebSocket != nil {
+		in, out := &in.WebSocket, &out.WebSocket
+		*out = new(WebSocketCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartTls != nil {
+		in, out := &in.StartTls, &out.StartTls
+		*out = new(StartTlsCheck)
+		**out = **in
+	}
+	if in.Modbus != nil {
+		in, out := &in.Modbus, &out.Modbus
+		*out = new(ModbusCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OpcUa != nil {
+		in, out := &in.OpcUa, &out.OpcUa
+		*out = new(OpcUaCheck)
+		**out = **in
+	}
+	if in.Soak != nil {
+		in, out := &in.Soak, &out.Soak
+		*out = new(Soak)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ColdStart != nil {
+		in, out := &in.ColdStart, &out.ColdStart
+		*out = new(ColdStart)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AvailableVariables != nil {
+		in, out := &in.AvailableVariables, &out.AvailableVariables
+		*out = make(VariableList, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Variable)
+				**out = **in
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpRequest.
+func (in *HttpRequest) DeepCopy() *HttpRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(HttpRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Journey) DeepCopyInto(out *Journey) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Journey.
+func (in *Journey) DeepCopy() *Journey {
+	if in == nil {
+		return nil
+	}
+	out := new(Journey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Journey) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JourneyList) DeepCopyInto(out *JourneyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Journey, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JourneyList.
+func (in *JourneyList) DeepCopy() *JourneyList {
+	if in == nil {
+		return nil
+	}
+	out := new(JourneyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *JourneyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JourneySpec) DeepCopyInto(out *JourneySpec) {
+	*out = *in
+	if in.Environment != nil {
+		in, out := &in.Environment, &out.Environment
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]JourneyStage, len(*in))
+		copy(*out, *in)
+	}
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JourneySpec.
+func (in *JourneySpec) DeepCopy() *JourneySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JourneySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JourneyStage) DeepCopyInto(out *JourneyStage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JourneyStage.
+func (in *JourneyStage) DeepCopy() *JourneyStage {
+	if in == nil {
+		return nil
+	}
+	out := new(JourneyStage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JourneyStageStatus) DeepCopyInto(out *JourneyStageStatus) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JourneyStageStatus.
+func (in *JourneyStageStatus) DeepCopy() *JourneyStageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JourneyStageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JourneyStatus) DeepCopyInto(out *JourneyStatus) {
+	*out = *in
+	if in.LastExecution != nil {
+		in, out := &in.LastExecution, &out.LastExecution
+		*out = (*in).DeepCopy()
+	}
+	if in.LastFailure != nil {
+		in, out := &in.LastFailure, &out.LastFailure
+		*out = (*in).DeepCopy()
+	}
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]JourneyStageStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]MonitorCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JourneyStatus.
+func (in *JourneyStatus) DeepCopy() *JourneyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JourneyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JsonAssertion) DeepCopyInto(out *JsonAssertion) {
+	*out = *in
+	if in.Equals != nil {
+		in, out := &in.Equals, &out.Equals
+		*out = new(string)
+		**out = **in
+	}
+	if in.MinLength != nil {
+		in, out := &in.MinLength, &out.MinLength
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JsonAssertion.
+func (in *JsonAssertion) DeepCopy() *JsonAssertion {
+	if in == nil {
+		return nil
+	}
+	out := new(JsonAssertion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JsonSchema) DeepCopyInto(out *JsonSchema) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Loaded != nil {
+		in, out := &in.Loaded, &out.Loaded
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JsonSchema.
+func (in *JsonSchema) DeepCopy() *JsonSchema {
+	if in == nil {
+		return nil
+	}
+	out := new(JsonSchema)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JwtCheck) DeepCopyInto(out *JwtCheck) {
+	*out = *in
+	if in.MinRemaining != nil {
+		in, out := &in.MinRemaining, &out.MinRemaining
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JwtCheck.
+func (in *JwtCheck) DeepCopy() *JwtCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(JwtCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LanguageCheck) DeepCopyInto(out *LanguageCheck) {
+	*out = *in
+	if in.Markers != nil {
+		in, out := &in.Markers, &out.Markers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LanguageCheck.
+func (in *LanguageCheck) DeepCopy() *LanguageCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(LanguageCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModbusCheck) DeepCopyInto(out *ModbusCheck) {
+	*out = *in
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModbusCheck.
+func (in *ModbusCheck) DeepCopy() *ModbusCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ModbusCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorCondition) DeepCopyInto(out *MonitorCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorCondition.
+func (in *MonitorCondition) DeepCopy() *MonitorCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorSet) DeepCopyInto(out *MonitorSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorSet.
+func (in *MonitorSet) DeepCopy() *MonitorSet {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonitorSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorSetList) DeepCopyInto(out *MonitorSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MonitorSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorSetList.
+func (in *MonitorSetList) DeepCopy() *MonitorSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonitorSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorSetSpec) DeepCopyInto(out *MonitorSetSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]MonitorTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TargetsFrom != nil {
+		in, out := &in.TargetsFrom, &out.TargetsFrom
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceSelector != nil {
+		in, out := &in.ServiceSelector, &out.ServiceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorSetSpec.
+func (in *MonitorSetSpec) DeepCopy() *MonitorSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorSetStatus) DeepCopyInto(out *MonitorSetStatus) {
+	*out = *in
+	if in.Monitors != nil {
+		in, out := &in.Monitors, &out.Monitors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorSetStatus.
+func (in *MonitorSetStatus) DeepCopy() *MonitorSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorSilence) DeepCopyInto(out *MonitorSilence) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorSilence.
+func (in *MonitorSilence) DeepCopy() *MonitorSilence {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorSilence)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonitorSilence) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorSilenceList) DeepCopyInto(out *MonitorSilenceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MonitorSilence, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorSilenceList.
+func (in *MonitorSilenceList) DeepCopy() *MonitorSilenceList {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorSilenceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonitorSilenceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorSilenceSpec) DeepCopyInto(out *MonitorSilenceSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorSilenceSpec.
+func (in *MonitorSilenceSpec) DeepCopy() *MonitorSilenceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorSilenceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorSilenceStatus) DeepCopyInto(out *MonitorSilenceStatus) {
+	*out = *in
+	if in.MatchedMonitors != nil {
+		in, out := &in.MatchedMonitors, &out.MatchedMonitors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorSilenceStatus.
+func (in *MonitorSilenceStatus) DeepCopy() *MonitorSilenceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorSilenceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorTarget) DeepCopyInto(out *MonitorTarget) {
+	*out = *in
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorTarget.
+func (in *MonitorTarget) DeepCopy() *MonitorTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorTemplate) DeepCopyInto(out *MonitorTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorTemplate.
+func (in *MonitorTemplate) DeepCopy() *MonitorTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSink) DeepCopyInto(out *NotificationSink) {
+	*out = *in
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Opsgenie != nil {
+		in, out := &in.Opsgenie, &out.Opsgenie
+		*out = new(OpsgenieSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Email != nil {
+		in, out := &in.Email, &out.Email
+		*out = new(EmailSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Grafana != nil {
+		in, out := &in.Grafana, &out.Grafana
+		*out = new(GrafanaSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StatusPage != nil {
+		in, out := &in.StatusPage, &out.StatusPage
+		*out = new(StatusPageSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ticket != nil {
+		in, out := &in.Ticket, &out.Ticket
+		*out = new(TicketSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EscalateAfter != nil {
+		in, out := &in.EscalateAfter, &out.EscalateAfter
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RepeatInterval != nil {
+		in, out := &in.RepeatInterval, &out.RepeatInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSink.
+func (in *NotificationSink) DeepCopy() *NotificationSink {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ClientCredentials) DeepCopyInto(out *OAuth2ClientCredentials) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ClientCredentials.
+func (in *OAuth2ClientCredentials) DeepCopy() *OAuth2ClientCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2ClientCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OidcDiscoveryCheck) DeepCopyInto(out *OidcDiscoveryCheck) {
+	*out = *in
+	if in.MinCertificateRemaining != nil {
+		in, out := &in.MinCertificateRemaining, &out.MinCertificateRemaining
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OidcDiscoveryCheck.
+func (in *OidcDiscoveryCheck) DeepCopy() *OidcDiscoveryCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(OidcDiscoveryCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpcUaCheck) DeepCopyInto(out *OpcUaCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpcUaCheck.
+func (in *OpcUaCheck) DeepCopy() *OpcUaCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(OpcUaCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsgenieSink) DeepCopyInto(out *OpsgenieSink) {
+	*out = *in
+	in.ApiKeySecretRef.DeepCopyInto(&out.ApiKeySecretRef)
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsgenieSink.
+func (in *OpsgenieSink) DeepCopy() *OpsgenieSink {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsgenieSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Ownership) DeepCopyInto(out *Ownership) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Ownership.
+func (in *Ownership) DeepCopy() *Ownership {
+	if in == nil {
+		return nil
+	}
+	out := new(Ownership)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagePerformance) DeepCopyInto(out *PagePerformance) {
+	*out = *in
+	out.Ttfb = in.Ttfb
+	in.MeasuredAt.DeepCopyInto(&out.MeasuredAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagePerformance.
+func (in *PagePerformance) DeepCopy() *PagePerformance {
+	if in == nil {
+		return nil
+	}
+	out := new(PagePerformance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingCleanup) DeepCopyInto(out *PendingCleanup) {
+	*out = *in
+	if in.FirstFailure != nil {
+		in, out := &in.FirstFailure, &out.FirstFailure
+		*out = (*in).DeepCopy()
+	}
+	if in.NextAttempt != nil {
+		in, out := &in.NextAttempt, &out.NextAttempt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingCleanup.
+func (in *PendingCleanup) DeepCopy() *PendingCleanup {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingCleanup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerformanceAudit) DeepCopyInto(out *PerformanceAudit) {
+	*out = *in
+	if in.MaxTtfb != nil {
+		in, out := &in.MaxTtfb, &out.MaxTtfb
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxPageWeightBytes != nil {
+		in, out := &in.MaxPageWeightBytes, &out.MaxPageWeightBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxRequests != nil {
+		in, out := &in.MaxRequests, &out.MaxRequests
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerformanceAudit.
+func (in *PerformanceAudit) DeepCopy() *PerformanceAudit {
+	if in == nil {
+		return nil
+	}
+	out := new(PerformanceAudit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProtobufCodec) DeepCopyInto(out *ProtobufCodec) {
+	*out = *in
+	in.DescriptorSetRef.DeepCopyInto(&out.DescriptorSetRef)
+	if in.DescriptorSet != nil {
+		in, out := &in.DescriptorSet, &out.DescriptorSet
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProtobufCodec.
+func (in *ProtobufCodec) DeepCopy() *ProtobufCodec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProtobufCodec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Redirects) DeepCopyInto(out *Redirects) {
+	*out = *in
+	if in.Follow != nil {
+		in, out := &in.Follow, &out.Follow
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxHops != nil {
+		in, out := &in.MaxHops, &out.MaxHops
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Redirects.
+func (in *Redirects) DeepCopy() *Redirects {
+	if in == nil {
+		return nil
+	}
+	out := new(Redirects)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestAuth) DeepCopyInto(out *RequestAuth) {
+	*out = *in
+	if in.Basic != nil {
+		in, out := &in.Basic, &out.Basic
+		*out = new(BasicAuth)
+		**out = **in
+	}
+	if in.Bearer != nil {
+		in, out := &in.Bearer, &out.Bearer
+		*out = new(BearerAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OAuth2 != nil {
+		in, out := &in.OAuth2, &out.OAuth2
+		*out = new(OAuth2ClientCredentials)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AwsSigV4 != nil {
+		in, out := &in.AwsSigV4, &out.AwsSigV4
+		*out = new(AwsSigV4Auth)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestAuth.
+func (in *RequestAuth) DeepCopy() *RequestAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestBudget) DeepCopyInto(out *RequestBudget) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestBudget.
+func (in *RequestBudget) DeepCopy() *RequestBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RequestBudget) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestBudgetList) DeepCopyInto(out *RequestBudgetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RequestBudget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestBudgetList.
+func (in *RequestBudgetList) DeepCopy() *RequestBudgetList {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestBudgetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RequestBudgetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestBudgetSpec) DeepCopyInto(out *RequestBudgetSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestBudgetSpec.
+func (in *RequestBudgetSpec) DeepCopy() *RequestBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestBudgetStatus) DeepCopyInto(out *RequestBudgetStatus) {
+	*out = *in
+	out.Usage = in.Usage
+	if in.MatchedMonitors != nil {
+		in, out := &in.MatchedMonitors, &out.MatchedMonitors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestBudgetStatus.
+func (in *RequestBudgetStatus) DeepCopy() *RequestBudgetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestBudgetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestLatency) DeepCopyInto(out *RequestLatency) {
+	*out = *in
+	out.P50 = in.P50
+	out.P95 = in.P95
+	out.P99 = in.P99
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestLatency.
+func (in *RequestLatency) DeepCopy() *RequestLatency {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestLatency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestUsage) DeepCopyInto(out *RequestUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestUsage.
+func (in *RequestUsage) DeepCopy() *RequestUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResponseAssertions) DeepCopyInto(out *ResponseAssertions) {
+	*out = *in
+	if in.BodyMatches != nil {
+		in, out := &in.BodyMatches, &out.BodyMatches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BodyNotContains != nil {
+		in, out := &in.BodyNotContains, &out.BodyNotContains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BodyNotMatches != nil {
+		in, out := &in.BodyNotMatches, &out.BodyNotMatches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Json != nil {
+		in, out := &in.Json, &out.Json
+		*out = make([]JsonAssertion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]HeaderAssertion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.JsonSchema != nil {
+		in, out := &in.JsonSchema, &out.JsonSchema
+		*out = new(JsonSchema)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResponseAssertions.
+func (in *ResponseAssertions) DeepCopy() *ResponseAssertions {
+	if in == nil {
+		return nil
+	}
+	out := new(ResponseAssertions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SamlCheck) DeepCopyInto(out *SamlCheck) {
+	*out = *in
+	if in.MinCertificateRemaining != nil {
+		in, out := &in.MinCertificateRemaining, &out.MinCertificateRemaining
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SamlCheck.
+func (in *SamlCheck) DeepCopy() *SamlCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(SamlCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScriptStep) DeepCopyInto(out *ScriptStep) {
+	*out = *in
+	in.ScriptRef.DeepCopyInto(&out.ScriptRef)
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScriptStep.
+func (in *ScriptStep) DeepCopy() *ScriptStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ScriptStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScrubbingRules) DeepCopyInto(out *ScrubbingRules) {
+	*out = *in
+	if in.Builtin != nil {
+		in, out := &in.Builtin, &out.Builtin
+		*out = make([]PiiPattern, len(*in))
+		copy(*out, *in)
+	}
+	if in.Patterns != nil {
+		in, out := &in.Patterns, &out.Patterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScrubbingRules.
+func (in *ScrubbingRules) DeepCopy() *ScrubbingRules {
+	if in == nil {
+		return nil
+	}
+	out := new(ScrubbingRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignedRunRecord) DeepCopyInto(out *SignedRunRecord) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignedRunRecord.
+func (in *SignedRunRecord) DeepCopy() *SignedRunRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(SignedRunRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnmpMonitor) DeepCopyInto(out *SnmpMonitor) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnmpMonitor.
+func (in *SnmpMonitor) DeepCopy() *SnmpMonitor {
+	if in == nil {
+		return nil
+	}
+	out := new(SnmpMonitor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SnmpMonitor) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnmpMonitorList) DeepCopyInto(out *SnmpMonitorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SnmpMonitor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnmpMonitorList.
+func (in *SnmpMonitorList) DeepCopy() *SnmpMonitorList {
+	if in == nil {
+		return nil
+	}
+	out := new(SnmpMonitorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SnmpMonitorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnmpMonitorSpec) DeepCopyInto(out *SnmpMonitorSpec) {
+	*out = *in
+	if in.CommunitySecretRef != nil {
+		in, out := &in.CommunitySecretRef, &out.CommunitySecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.V3 != nil {
+		in, out := &in.V3, &out.V3
+		*out = new(SnmpV3Credentials)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Oids != nil {
+		in, out := &in.Oids, &out.Oids
+		*out = make([]SnmpOid, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnmpMonitorSpec.
+func (in *SnmpMonitorSpec) DeepCopy() *SnmpMonitorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SnmpMonitorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnmpMonitorStatus) DeepCopyInto(out *SnmpMonitorStatus) {
+	*out = *in
+	if in.LastExecution != nil {
+		in, out := &in.LastExecution, &out.LastExecution
+		*out = (*in).DeepCopy()
+	}
+	if in.LastFailure != nil {
+		in, out := &in.LastFailure, &out.LastFailure
+		*out = (*in).DeepCopy()
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]SnmpValueStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]MonitorCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnmpMonitorStatus.
+func (in *SnmpMonitorStatus) DeepCopy() *SnmpMonitorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SnmpMonitorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnmpOid) DeepCopyInto(out *SnmpOid) {
+	*out = *in
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Equals != nil {
+		in, out := &in.Equals, &out.Equals
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnmpOid.
+func (in *SnmpOid) DeepCopy() *SnmpOid {
+	if in == nil {
+		return nil
+	}
+	out := new(SnmpOid)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnmpV3Credentials) DeepCopyInto(out *SnmpV3Credentials) {
+	*out = *in
+	if in.AuthPasswordSecretRef != nil {
+		in, out := &in.AuthPasswordSecretRef, &out.AuthPasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrivPasswordSecretRef != nil {
+		in, out := &in.PrivPasswordSecretRef, &out.PrivPasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpMonitor.
-func (in *HttpMonitor) DeepCopy() *HttpMonitor {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnmpV3Credentials.
+func (in *SnmpV3Credentials) DeepCopy() *SnmpV3Credentials {
 	if in == nil {
 		return nil
 	}
-	out := new(HttpMonitor)
+	out := new(SnmpV3Credentials)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HttpMonitor) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnmpValueStatus) DeepCopyInto(out *SnmpValueStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnmpValueStatus.
+func (in *SnmpValueStatus) DeepCopy() *SnmpValueStatus {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(SnmpValueStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HttpMonitorList) DeepCopyInto(out *HttpMonitorList) {
+func (in *Soak) DeepCopyInto(out *Soak) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]HttpMonitor, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out.Duration = in.Duration
+	if in.MaxP95 != nil {
+		in, out := &in.MaxP95, &out.MaxP95
+		*out = new(metav1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpMonitorList.
-func (in *HttpMonitorList) DeepCopy() *HttpMonitorList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Soak.
+func (in *Soak) DeepCopy() *Soak {
 	if in == nil {
 		return nil
 	}
-	out := new(HttpMonitorList)
+	out := new(Soak)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HttpMonitorList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SseCheck) DeepCopyInto(out *SseCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SseCheck.
+func (in *SseCheck) DeepCopy() *SseCheck {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(SseCheck)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HttpMonitorSpec) DeepCopyInto(out *HttpMonitorSpec) {
+func (in *StartTlsCheck) DeepCopyInto(out *StartTlsCheck) {
 	*out = *in
-	if in.Environment != nil {
-		in, out := &in.Environment, &out.Environment
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartTlsCheck.
+func (in *StartTlsCheck) DeepCopy() *StartTlsCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(StartTlsCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in StatusCodeMatcher) DeepCopyInto(out *StatusCodeMatcher) {
+	{
+		in := &in
+		*out = make(StatusCodeMatcher, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusCodeMatcher.
+func (in StatusCodeMatcher) DeepCopy() StatusCodeMatcher {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusCodeMatcher)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusPageSink) DeepCopyInto(out *StatusPageSink) {
+	*out = *in
+	in.ApiKeySecretRef.DeepCopyInto(&out.ApiKeySecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusPageSink.
+func (in *StatusPageSink) DeepCopy() *StatusPageSink {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusPageSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyntheticMarker) DeepCopyInto(out *SyntheticMarker) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	if in.Requests != nil {
-		in, out := &in.Requests, &out.Requests
-		*out = make([]HttpRequest, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Cleanup != nil {
-		in, out := &in.Cleanup, &out.Cleanup
-		*out = make([]HttpRequest, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.QueryParams != nil {
+		in, out := &in.QueryParams, &out.QueryParams
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
-	if in.Period != nil {
-		in, out := &in.Period, &out.Period
-		*out = new(v1.Duration)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpMonitorSpec.
-func (in *HttpMonitorSpec) DeepCopy() *HttpMonitorSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyntheticMarker.
+func (in *SyntheticMarker) DeepCopy() *SyntheticMarker {
 	if in == nil {
 		return nil
 	}
-	out := new(HttpMonitorSpec)
+	out := new(SyntheticMarker)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HttpMonitorStatus) DeepCopyInto(out *HttpMonitorStatus) {
+func (in *TargetWorkload) DeepCopyInto(out *TargetWorkload) {
 	*out = *in
-	if in.LastExecution != nil {
-		in, out := &in.LastExecution, &out.LastExecution
-		*out = (*in).DeepCopy()
-	}
-	if in.LastFailure != nil {
-		in, out := &in.LastFailure, &out.LastFailure
-		*out = (*in).DeepCopy()
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpMonitorStatus.
-func (in *HttpMonitorStatus) DeepCopy() *HttpMonitorStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetWorkload.
+func (in *TargetWorkload) DeepCopy() *TargetWorkload {
 	if in == nil {
 		return nil
 	}
-	out := new(HttpMonitorStatus)
+	out := new(TargetWorkload)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HttpRequest) DeepCopyInto(out *HttpRequest) {
+func (in *TicketSink) DeepCopyInto(out *TicketSink) {
 	*out = *in
-	if in.QueryParams != nil {
-		in, out := &in.QueryParams, &out.QueryParams
-		*out = make(url.Values, len(*in))
+	in.PasswordSecretRef.DeepCopyInto(&out.PasswordSecretRef)
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
-			var outVal []string
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				in, out := &val, &outVal
-				*out = make([]string, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
+			(*out)[key] = val
 		}
 	}
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(http.Header, len(*in))
+	if in.ResolveFields != nil {
+		in, out := &in.ResolveFields, &out.ResolveFields
+		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
-			var outVal []string
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				in, out := &val, &outVal
-				*out = make([]string, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
+			(*out)[key] = val
 		}
 	}
-	if in.VariablesFromResponse != nil {
-		in, out := &in.VariablesFromResponse, &out.VariablesFromResponse
-		*out = make(VariableList, len(*in))
-		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(Variable)
-				**out = **in
-			}
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TicketSink.
+func (in *TicketSink) DeepCopy() *TicketSink {
+	if in == nil {
+		return nil
 	}
-	if in.ExpectedResponseCodes != nil {
-		in, out := &in.ExpectedResponseCodes, &out.ExpectedResponseCodes
-		*out = make([]int, len(*in))
-		copy(*out, *in)
+	out := new(TicketSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TlsOptions) DeepCopyInto(out *TlsOptions) {
+	*out = *in
+	if in.CaBundle != nil {
+		in, out := &in.CaBundle, &out.CaBundle
+		*out = new(CaBundleSource)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.AvailableVariables != nil {
-		in, out := &in.AvailableVariables, &out.AvailableVariables
-		*out = make(VariableList, len(*in))
-		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(Variable)
-				**out = **in
-			}
-		}
+	if in.ClientCertificate != nil {
+		in, out := &in.ClientCertificate, &out.ClientCertificate
+		*out = new(ClientCertificate)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HttpRequest.
-func (in *HttpRequest) DeepCopy() *HttpRequest {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TlsOptions.
+func (in *TlsOptions) DeepCopy() *TlsOptions {
 	if in == nil {
 		return nil
 	}
-	out := new(HttpRequest)
+	out := new(TlsOptions)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -246,6 +2724,26 @@ func (in *Variable) DeepCopy() *Variable {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VariableLineage) DeepCopyInto(out *VariableLineage) {
+	*out = *in
+	if in.ConsumedBy != nil {
+		in, out := &in.ConsumedBy, &out.ConsumedBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VariableLineage.
+func (in *VariableLineage) DeepCopy() *VariableLineage {
+	if in == nil {
+		return nil
+	}
+	out := new(VariableLineage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in VariableList) DeepCopyInto(out *VariableList) {
 	{
@@ -270,3 +2768,73 @@ func (in VariableList) DeepCopy() VariableList {
 	in.DeepCopyInto(out)
 	return *out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionSkewRule) DeepCopyInto(out *VersionSkewRule) {
+	*out = *in
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionSkewRule.
+func (in *VersionSkewRule) DeepCopy() *VersionSkewRule {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionSkewRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebSocketCheck) DeepCopyInto(out *WebSocketCheck) {
+	*out = *in
+	if in.Subprotocols != nil {
+		in, out := &in.Subprotocols, &out.Subprotocols
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebSocketCheck.
+func (in *WebSocketCheck) DeepCopy() *WebSocketCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(WebSocketCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookSink) DeepCopyInto(out *WebhookSink) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(http.Header, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSink.
+func (in *WebhookSink) DeepCopy() *WebhookSink {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookSink)
+	in.DeepCopyInto(out)
+	return out
+}
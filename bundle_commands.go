@@ -0,0 +1,174 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ghodss/yaml"
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/blackbox"
+	"github.com/oregondesignservices/monitoring-controller/internal/bundle"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"os"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
+)
+
+// Verbs for moving monitors between clusters. They use the current kubeconfig, like kubectl.
+var bundleCommands = []*cli.Command{
+	{
+		Name:   "export",
+		Usage:  "write the monitors matching a selector as a bundle",
+		Action: exportBundle,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "namespace", Usage: "only export monitors in this namespace. Default is all namespaces"},
+			&cli.StringFlag{Name: "selector", Aliases: []string{"l"}, Usage: "only export monitors with matching labels, like app=shop"},
+			&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "write the bundle to this file instead of stdout"},
+		},
+	},
+	{
+		Name:   "import",
+		Usage:  "create or update the monitors in a bundle",
+		Action: importBundle,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "file", Aliases: []string{"f"}, Required: true, Usage: "the bundle to import, or - for stdin"},
+			&cli.StringFlag{Name: "namespace", Usage: "import every monitor into this namespace instead of the one it was exported from"},
+			&cli.StringSliceFlag{Name: "set-env", Usage: "set a variable in the environment of every monitor. Format: 'key=value'"},
+		},
+	},
+	{
+		Name:   "convert-blackbox",
+		Usage:  "write a bundle with a monitor for each target of a blackbox_exporter configuration",
+		Action: convertBlackbox,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "config", Required: true, Usage: "the blackbox_exporter configuration file"},
+			&cli.StringFlag{Name: "targets", Required: true, Usage: "the targets, in Prometheus file_sd format. The module comes from the module or __param_module label"},
+			&cli.StringFlag{Name: "module", Value: "http_2xx", Usage: "the module for targets without a module label"},
+			&cli.StringFlag{Name: "namespace", Value: "default", Usage: "the namespace of the monitors"},
+			&cli.DurationFlag{Name: "period", Value: time.Minute, Usage: "how often each monitor runs, usually the scrape interval"},
+			&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "write the bundle to this file instead of stdout"},
+		},
+	},
+}
+
+func bundleClient() (client.Client, error) {
+	return client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+}
+
+func exportBundle(c *cli.Context) error {
+	selector, err := labels.Parse(c.String("selector"))
+	if err != nil {
+		return err
+	}
+	kubeClient, err := bundleClient()
+	if err != nil {
+		return err
+	}
+	exported, err := bundle.Export(context.Background(), kubeClient, c.String("namespace"), selector)
+	if err != nil {
+		return err
+	}
+	return writeBundle(exported, c.String("output"))
+}
+
+func writeBundle(b *monitoringraisingthefloororgv1alpha1.MonitorBundle, output string) error {
+	out, err := yaml.Marshal(b)
+	if err != nil {
+		return err
+	}
+	if output != "" {
+		return ioutil.WriteFile(output, out, 0644)
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+// Conversions only read files, so the bundle can be reviewed before it is imported
+func convertBlackbox(c *cli.Context) error {
+	configData, err := ioutil.ReadFile(c.String("config"))
+	if err != nil {
+		return err
+	}
+	targetsData, err := ioutil.ReadFile(c.String("targets"))
+	if err != nil {
+		return err
+	}
+	monitors, warnings, err := blackbox.Convert(configData, targetsData, blackbox.Options{
+		Namespace:     c.String("namespace"),
+		Period:        c.Duration("period"),
+		DefaultModule: c.String("module"),
+	})
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+	if err != nil {
+		return err
+	}
+	return writeBundle(monitoringraisingthefloororgv1alpha1.NewMonitorBundle(monitors, metav1.Now()), c.String("output"))
+}
+
+func importBundle(c *cli.Context) error {
+	overrides := make(map[string]string)
+	for _, v := range c.StringSlice("set-env") {
+		pieces := strings.SplitN(v, "=", 2)
+		if len(pieces) != 2 {
+			return errors.New("--set-env format must be 'key=value'")
+		}
+		overrides[pieces[0]] = pieces[1]
+	}
+
+	var data []byte
+	var err error
+	if file := c.String("file"); file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+	parsed, err := monitoringraisingthefloororgv1alpha1.ParseMonitorBundle(data)
+	if err != nil {
+		return err
+	}
+
+	kubeClient, err := bundleClient()
+	if err != nil {
+		return err
+	}
+	applied, err := bundle.Import(context.Background(), kubeClient, parsed.MonitorsFor(c.String("namespace"), overrides))
+	for _, name := range applied {
+		fmt.Println("applied", name)
+	}
+	return err
+}
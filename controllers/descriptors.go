@@ -0,0 +1,169 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/secrets"
+)
+
+// Load the protobuf descriptor sets the requests refer to. A request whose descriptor set cannot be loaded
+// fails when it is sent, so the problem shows up in status like any other failure.
+func loadDescriptorSets(ctx context.Context, c client.Reader, namespace string, requests []monitoringraisingthefloororgv1alpha1.HttpRequest) error {
+	var firstErr error
+	for i := range requests {
+		codec := requests[i].Protobuf
+		if codec == nil {
+			continue
+		}
+		descriptorSet, err := readConfigMapKey(ctx, c, namespace, &codec.DescriptorSetRef)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		codec.DescriptorSet = descriptorSet
+	}
+	return firstErr
+}
+
+// Load the JSON schemas the requests' response assertions keep in ConfigMaps. Like descriptor sets, a schema
+// that cannot be loaded fails the request when it is checked.
+func loadResponseSchemas(ctx context.Context, c client.Reader, namespace string, requests []monitoringraisingthefloororgv1alpha1.HttpRequest) error {
+	var firstErr error
+	for i := range requests {
+		assertions := requests[i].ResponseAssertions
+		if assertions == nil || assertions.JsonSchema == nil || assertions.JsonSchema.ConfigMapRef == nil {
+			continue
+		}
+		schema, err := readConfigMapKey(ctx, c, namespace, assertions.JsonSchema.ConfigMapRef)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		assertions.JsonSchema.Loaded = schema
+	}
+	return firstErr
+}
+
+// Load the files form fields keep in ConfigMaps. A file that cannot be loaded fails its request when it is sent.
+func loadFormFiles(ctx context.Context, c client.Reader, namespace string, requests []monitoringraisingthefloororgv1alpha1.HttpRequest) error {
+	var firstErr error
+	for i := range requests {
+		form := requests[i].Form
+		if form == nil {
+			continue
+		}
+		for j := range form.Fields {
+			field := &form.Fields[j]
+			if field.ConfigMapRef == nil {
+				continue
+			}
+			content, err := readConfigMapKey(ctx, c, namespace, field.ConfigMapRef)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			field.Loaded = content
+		}
+	}
+	return firstErr
+}
+
+// Load the bodies requests keep in ConfigMaps and Secrets. A body that cannot be loaded fails its request when
+// it is sent.
+func loadBodies(ctx context.Context, c client.Reader, namespace string, requests []monitoringraisingthefloororgv1alpha1.HttpRequest) error {
+	var firstErr error
+	for i := range requests {
+		source := requests[i].BodyFrom
+		if source == nil {
+			continue
+		}
+		var body []byte
+		var err error
+		if source.SecretRef != nil {
+			var value string
+			value, err = secrets.Read(ctx, c, namespace, source.SecretRef)
+			body = []byte(value)
+		} else if source.ConfigMapRef != nil {
+			body, err = readConfigMapKey(ctx, c, namespace, source.ConfigMapRef)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		source.Loaded = body
+	}
+	return firstErr
+}
+
+// Replace the spec of a monitor that has a template with the patched template. Returns the resource version of the
+// template's ConfigMap, which is empty for monitors without one.
+func resolveTemplate(ctx context.Context, c client.Reader, monitor *monitoringraisingthefloororgv1alpha1.HttpMonitor) (string, error) {
+	if monitor.Spec.TemplateRef == nil {
+		return "", nil
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: monitor.Namespace, Name: monitor.Spec.TemplateRef.Name}, configMap); err != nil {
+		return "", err
+	}
+	template, err := configMapKey(configMap, monitor.Spec.TemplateRef)
+	if err != nil {
+		return "", err
+	}
+	return configMap.ResourceVersion, monitor.ApplyTemplate(template)
+}
+
+func readConfigMapKey(ctx context.Context, c client.Reader, namespace string, ref *corev1.ConfigMapKeySelector) ([]byte, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, configMap); err != nil {
+		return nil, err
+	}
+	return configMapKey(configMap, ref)
+}
+
+func configMapKey(configMap *corev1.ConfigMap, ref *corev1.ConfigMapKeySelector) ([]byte, error) {
+	if value, ok := configMap.BinaryData[ref.Key]; ok {
+		return value, nil
+	}
+	if value, ok := configMap.Data[ref.Key]; ok {
+		return []byte(value), nil
+	}
+	return nil, fmt.Errorf("configmap %s/%s has no key %s", configMap.Namespace, configMap.Name, ref.Key)
+}
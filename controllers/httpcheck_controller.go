@@ -0,0 +1,174 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package controllers
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	"github.com/oregondesignservices/monitoring-controller/internal/forge"
+	runnverv1alpha1 "github.com/oregondesignservices/monitoring-controller/internal/runner/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/secrets"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"time"
+
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+)
+
+// HttpCheckReconciler reconciles a HttpCheck object
+type HttpCheckReconciler struct {
+	client.Client
+	// Reads checks around the cache, which can still hold a check from before it started
+	APIReader client.Reader
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=httpchecks,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=httpchecks/status,verbs=get;update;patch
+
+// Checks run at most once. Once a check has started, reconciling it does nothing, so a check the controller was
+// restarted during stays started and is not run again.
+func (r *HttpCheckReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	instance := &monitoringraisingthefloororgv1alpha1.HttpCheck{}
+	ctx := context.Background()
+	logger := r.Log.WithValues("httpcheck", req.NamespacedName)
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if instance.Status.StartedAt != nil || instance.Status.CompletedAt != nil {
+		return reconcile.Result{}, nil
+	}
+	if runnverv1alpha1.Standby() {
+		// Left pending, so the check runs if this installation takes over
+		logger.Info("not running check, the controller is a standby")
+		return reconcile.Result{RequeueAfter: time.Minute}, nil
+	}
+
+	// The cache may not have seen the update that started the check yet
+	if err := r.APIReader.Get(ctx, req.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if instance.Status.StartedAt != nil {
+		return reconcile.Result{}, nil
+	}
+	now := metav1.Now()
+	instance.Status.StartedAt = &now
+	// Fails with a conflict if the check changed since it was read, so only one reconcile gets to run it
+	if err := r.Status().Update(ctx, instance); err != nil {
+		if errors.IsConflict(err) {
+			logger.V(1).Info("check changed before it started, reading it again")
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	r.reportCommitStatus(ctx, logger, instance, forge.StatePending, "running")
+
+	logger.Info("running check")
+	monitor := instance.AsHttpMonitor()
+	monitor.Spec.Environment = withGlobalRequestVars(logger, monitor.Spec.Environment)
+	for _, requests := range [][]monitoringraisingthefloororgv1alpha1.HttpRequest{monitor.Spec.Requests, monitor.Spec.Cleanup} {
+		if err := loadDescriptorSets(ctx, r.Client, instance.Namespace, requests); err != nil {
+			logger.Error(err, "failed to load protobuf descriptor set")
+		}
+		if err := loadResponseSchemas(ctx, r.Client, instance.Namespace, requests); err != nil {
+			logger.Error(err, "failed to load response schema")
+		}
+		if err := loadFormFiles(ctx, r.Client, instance.Namespace, requests); err != nil {
+			logger.Error(err, "failed to load form file")
+		}
+		if err := loadBodies(ctx, r.Client, instance.Namespace, requests); err != nil {
+			logger.Error(err, "failed to load request body")
+		}
+	}
+	if err := secrets.LoadCredentials(ctx, r.Client, instance.Namespace, &monitor.Spec); err != nil {
+		logger.Error(err, "failed to load credentials")
+	}
+	// Checks can take as long as their timeouts and retries allow, so they run on their own rather than holding up
+	// the reconciles of every other check
+	go r.runCheck(logger, instance, monitor)
+	return reconcile.Result{}, nil
+}
+
+// Run the check and report its result in status and as the commit status
+func (r *HttpCheckReconciler) runCheck(logger logr.Logger, instance *monitoringraisingthefloororgv1alpha1.HttpCheck, monitor *monitoringraisingthefloororgv1alpha1.HttpMonitor) {
+	ctx := context.Background()
+	result := monitor.Execute()
+
+	// A patch, since the check can change while it runs
+	patch := client.MergeFrom(instance.DeepCopy())
+	completed := metav1.Now()
+	instance.Status.CompletedAt = &completed
+	instance.Status.Succeeded = !result.Failed()
+	instance.Status.RandomSeed = result.RandomSeed
+	state, description := forge.StateSuccess, "all requests succeeded"
+	if failure := result.FirstFailure(); failure != nil {
+		instance.Status.FailedRequest = failure.Name
+		instance.Status.Error = failure.Err.Error()
+		state, description = forge.StateFailure, result.FailureSummary()
+	}
+	logger.Info("check completed", "succeeded", instance.Status.Succeeded)
+
+	if err := r.reportCommitStatus(ctx, logger, instance, state, description); err != nil {
+		instance.Status.CommitStatusError = err.Error()
+	}
+	if err := r.Status().Patch(ctx, instance, patch); err != nil {
+		logger.Error(err, "failed to update the check status")
+	}
+}
+
+func (r *HttpCheckReconciler) reportCommitStatus(ctx context.Context, logger logr.Logger, instance *monitoringraisingthefloororgv1alpha1.HttpCheck, state forge.State, description string) error {
+	report := instance.Spec.CommitStatus
+	if report == nil {
+		return nil
+	}
+	token, err := secrets.Read(ctx, r.Client, instance.Namespace, &report.TokenSecretRef)
+	if err == nil {
+		err = forge.Report(ctx, report, instance.Name, state, description, token)
+	}
+	if err != nil {
+		logger.Error(err, "failed to report commit status", "state", state)
+	}
+	return err
+}
+
+func (r *HttpCheckReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&monitoringraisingthefloororgv1alpha1.HttpCheck{}).
+		Complete(r)
+}
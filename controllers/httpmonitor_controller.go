@@ -27,18 +27,27 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"github.com/go-logr/logr"
 	"github.com/oregondesignservices/monitoring-controller/internal/conf"
 	"github.com/oregondesignservices/monitoring-controller/internal/metrics"
 	runnverv1alpha1 "github.com/oregondesignservices/monitoring-controller/internal/runner/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/secrets"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	"strconv"
+	"time"
 
 	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
 )
@@ -48,10 +57,20 @@ type HttpMonitorReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+
+	// Records an Event on the monitor whenever it starts failing or recovers
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=httpmonitors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=httpmonitors/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;create;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=list
 
 func (r *HttpMonitorReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	instance := &monitoringraisingthefloororgv1alpha1.HttpMonitor{}
@@ -65,6 +84,7 @@ func (r *HttpMonitorReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			removeKnownHttpCrdGauge(logger, req.Namespace, req.Name)
+			monitoringraisingthefloororgv1alpha1.SetLogVerbosity(runnerKey, 0, time.Time{})
 			// Object not found. See if we need to stop a monitor
 			if runnerExists {
 				logger.Info("removing monitor")
@@ -76,45 +96,148 @@ func (r *HttpMonitorReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
+	templateVersion, err := resolveTemplate(ctx, r.Client, instance)
+	if err != nil {
+		logger.Error(err, "failed to resolve the template")
+		return reconcile.Result{}, err
+	}
+	if err := instance.CheckRunnable(); err != nil {
+		// The webhook only lets this through for templated monitors, whose template changes later
+		logger.Error(err, "http monitor cannot run")
+		if runnerExists {
+			knownRunner.Stop()
+			delete(runnverv1alpha1.KnownRunners, runnerKey)
+			removeKnownHttpCrdGauge(logger, req.Namespace, req.Name)
+		}
+		return reconcile.Result{}, nil
+	}
 
 	logger = logger.WithValues("period", instance.Spec.Period.Duration.String())
 
-	if instance.Spec.Environment == nil {
-		instance.Spec.Environment = make(map[string]string)
+	// Annotations do not change the generation, so this is read on every reconcile
+	r.updateLogVerbosity(logger, runnerKey, instance)
+
+	if !runnerExists {
+		logger.Info("detected a new http monitor")
+	} else if instance.GetGeneration() == knownRunner.GetGeneration() && templateVersion == knownRunner.TemplateVersion {
+		// If the generation is the same, we have nothing to do. We know about the exact spec.
+		// Runners update status, which changes the resource version but not the generation.
+		// Templates are read on every reconcile, so their changes are caught by their resource version.
+		logger.V(3).Info("received a known http monitor with no changes")
+		return reconcile.Result{}, nil
 	}
 
-	for k, v := range conf.GlobalConfig.GlobalRequestVars {
-		if _, exists := instance.Spec.Environment[k]; exists {
-			logger.Info("warning: HttpMonitor.spec.globals redefines existing --set-var", "key", k)
-		} else {
-			instance.Spec.Environment[k] = v
+	// Before the old runner stops, so a failed patch requeues with the old runner still running and known
+	if !instance.Spec.Suspend {
+		if err := r.updateLintStatus(ctx, logger, instance); err != nil {
+			return reconcile.Result{}, err
 		}
 	}
+	if runnerExists {
+		logger.Info("detected http monitor changes")
+		knownRunner.Stop()
+		removeKnownHttpCrdGauge(logger, req.Namespace, req.Name)
+	}
 
-	if !runnerExists {
-		logger.Info("detected a new http monitor")
-	} else {
-		// If the resource version is the same, we have nothing to do. We know about the exact object.
-		if instance.GetResourceVersion() == knownRunner.GetResourceVersion() {
-			logger.V(3).Info("received a known http monitor with no changes")
-			return reconcile.Result{}, nil
-		} else {
-			logger.Info("detected http monitor changes")
-			knownRunner.Stop()
-			removeKnownHttpCrdGauge(logger, req.Namespace, req.Name)
-		}
+	if instance.Spec.Suspend {
+		// Suspended monitors have no runner. Journeys that use them read and run them on their own.
+		logger.Info("http monitor is suspended")
+		delete(runnverv1alpha1.KnownRunners, runnerKey)
+		return reconcile.Result{}, nil
 	}
 
 	recordKnownHttpCrdGauge(instance)
 
+	instance.Spec.Environment = withGlobalRequestVars(logger, instance.Spec.Environment)
+	for _, requests := range [][]monitoringraisingthefloororgv1alpha1.HttpRequest{instance.Spec.Requests, instance.Spec.Cleanup} {
+		if err := loadDescriptorSets(ctx, r.Client, instance.Namespace, requests); err != nil {
+			logger.Error(err, "failed to load protobuf descriptor set")
+		}
+		if err := loadResponseSchemas(ctx, r.Client, instance.Namespace, requests); err != nil {
+			logger.Error(err, "failed to load response schema")
+		}
+		if err := loadFormFiles(ctx, r.Client, instance.Namespace, requests); err != nil {
+			logger.Error(err, "failed to load form file")
+		}
+		if err := loadBodies(ctx, r.Client, instance.Namespace, requests); err != nil {
+			logger.Error(err, "failed to load request body")
+		}
+	}
+	if err := secrets.LoadCredentials(ctx, r.Client, instance.Namespace, &instance.Spec); err != nil {
+		logger.Error(err, "failed to load credentials")
+	}
+
 	// At this point, we need to store the http monitor and restart its worker routine
-	newRunner := runnverv1alpha1.NewHttpMonitorRunner(instance)
+	newRunner := runnverv1alpha1.NewHttpMonitorRunner(instance, r.Client, r.Recorder)
+	newRunner.TemplateVersion = templateVersion
+	if runnerExists {
+		newRunner.InheritState(knownRunner)
+	}
 	runnverv1alpha1.KnownRunners[runnerKey] = newRunner
 	newRunner.Start()
 
 	return ctrl.Result{}, nil
 }
 
+// Apply the log verbosity annotations. Invalid ones are logged and otherwise ignored.
+func (r *HttpMonitorReconciler) updateLogVerbosity(logger logr.Logger, key string, instance *monitoringraisingthefloororgv1alpha1.HttpMonitor) {
+	level, until, err := monitoringraisingthefloororgv1alpha1.LogVerbosityFrom(instance.Annotations, time.Now())
+	if err != nil {
+		logger.Error(err, "ignoring log verbosity annotations")
+	}
+	if changed := monitoringraisingthefloororgv1alpha1.SetLogVerbosity(key, level, until); changed && level > 0 {
+		logger.Info("raising log verbosity", "verbosity", level, "until", until)
+	}
+}
+
+// Lint the spec and report the warnings in status
+func (r *HttpMonitorReconciler) updateLintStatus(ctx context.Context, logger logr.Logger, instance *monitoringraisingthefloororgv1alpha1.HttpMonitor) error {
+	var globals []string
+	for k := range conf.GlobalConfig.GlobalRequestVars {
+		globals = append(globals, k)
+	}
+	warnings := instance.Lint(globals)
+
+	// Patched on a copy, since the response would replace a spec resolved from a template
+	monitor := instance.DeepCopy()
+	patch := client.MergeFrom(monitor.DeepCopy())
+	monitor.Status.LintWarnings = warnings
+	condition := monitoringraisingthefloororgv1alpha1.MonitorCondition{
+		Type:               monitoringraisingthefloororgv1alpha1.ConditionLintClean,
+		Status:             corev1.ConditionTrue,
+		Reason:             "NoWarnings",
+		LastTransitionTime: metav1.Now(),
+	}
+	if len(warnings) > 0 {
+		logger.Info("spec has lint warnings", "warnings", warnings)
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "LintWarnings"
+		condition.Message = fmt.Sprintf("%d lint warnings, see status.lint_warnings", len(warnings))
+	}
+	monitor.Status.Conditions = monitoringraisingthefloororgv1alpha1.SetCondition(monitor.Status.Conditions, condition)
+	if err := r.Status().Patch(ctx, monitor, patch); err != nil {
+		return err
+	}
+	instance.Status = monitor.Status
+	return nil
+}
+
+// Add the --set-var variables to an environment, without overriding what it already defines
+func withGlobalRequestVars(logger logr.Logger, environment map[string]string) map[string]string {
+	if environment == nil {
+		environment = make(map[string]string)
+	}
+
+	for k, v := range conf.GlobalConfig.GlobalRequestVars {
+		if _, exists := environment[k]; exists {
+			logger.Info("warning: spec.environment redefines existing --set-var", "key", k)
+		} else {
+			environment[k] = v
+		}
+	}
+	return environment
+}
+
 func labelPairsToLabels(pairs []*dto.LabelPair) prometheus.Labels {
 	m := prometheus.Labels{}
 
@@ -166,5 +289,24 @@ func recordKnownHttpCrdGauge(crd *monitoringraisingthefloororgv1alpha1.HttpMonit
 func (r *HttpMonitorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&monitoringraisingthefloororgv1alpha1.HttpMonitor{}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.monitorsUsingTemplate),
+		}).
 		Complete(r)
 }
+
+// The monitors in the ConfigMap's namespace whose template_ref points to it
+func (r *HttpMonitorReconciler) monitorsUsingTemplate(o handler.MapObject) []reconcile.Request {
+	monitors := &monitoringraisingthefloororgv1alpha1.HttpMonitorList{}
+	if err := r.List(context.Background(), monitors, client.InNamespace(o.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list http monitors for a template", "configmap", o.Meta.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, monitor := range monitors.Items {
+		if monitor.Spec.TemplateRef != nil && monitor.Spec.TemplateRef.Name == o.Meta.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: monitor.Namespace, Name: monitor.Name}})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package controllers
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	runnverv1alpha1 "github.com/oregondesignservices/monitoring-controller/internal/runner/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+)
+
+// JourneyReconciler reconciles a Journey object
+type JourneyReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+
+	// Records an Event on the journey whenever it starts failing or recovers
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=journeys,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=journeys/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Like monitors, each journey has a runner that runs it every period. The runner is replaced when the spec changes.
+func (r *JourneyReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	instance := &monitoringraisingthefloororgv1alpha1.Journey{}
+	ctx := context.Background()
+	logger := r.Log.WithValues("journey", req.NamespacedName)
+
+	runnerKey := req.NamespacedName.String()
+	knownRunner, runnerExists := runnverv1alpha1.KnownJourneyRunners[runnerKey]
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if runnerExists {
+				logger.Info("removing journey")
+				knownRunner.Stop()
+				delete(runnverv1alpha1.KnownJourneyRunners, runnerKey)
+			}
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if runnerExists {
+		if instance.GetGeneration() == knownRunner.GetGeneration() {
+			return reconcile.Result{}, nil
+		}
+		logger.Info("detected journey changes")
+		knownRunner.Stop()
+	} else {
+		logger.Info("detected a new journey")
+	}
+
+	newRunner := runnverv1alpha1.NewJourneyRunner(instance, r.Client, r.Recorder, r.resolveStage)
+	runnverv1alpha1.KnownJourneyRunners[runnerKey] = newRunner
+	newRunner.Start()
+	return ctrl.Result{}, nil
+}
+
+// Read a stage's monitor and prepare it the same way the monitor controller does before a run
+func (r *JourneyReconciler) resolveStage(ctx context.Context, namespace, name string) (*monitoringraisingthefloororgv1alpha1.HttpMonitor, error) {
+	monitor := &monitoringraisingthefloororgv1alpha1.HttpMonitor{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, monitor); err != nil {
+		return nil, err
+	}
+	if _, err := resolveTemplate(ctx, r.Client, monitor); err != nil {
+		return nil, err
+	}
+	if err := monitor.CheckRunnable(); err != nil {
+		return nil, err
+	}
+	logger := r.Log.WithValues("httpmonitor", client.ObjectKey{Namespace: namespace, Name: name})
+	monitor.Spec.Environment = withGlobalRequestVars(logger, monitor.Spec.Environment)
+	for _, requests := range [][]monitoringraisingthefloororgv1alpha1.HttpRequest{monitor.Spec.Requests, monitor.Spec.Cleanup} {
+		if err := loadDescriptorSets(ctx, r.Client, namespace, requests); err != nil {
+			logger.Error(err, "failed to load protobuf descriptor set")
+		}
+		if err := loadResponseSchemas(ctx, r.Client, namespace, requests); err != nil {
+			logger.Error(err, "failed to load response schema")
+		}
+		if err := loadFormFiles(ctx, r.Client, namespace, requests); err != nil {
+			logger.Error(err, "failed to load form file")
+		}
+		if err := loadBodies(ctx, r.Client, namespace, requests); err != nil {
+			logger.Error(err, "failed to load request body")
+		}
+	}
+	// Credentials are read by the journey runner, the same way monitor runners read them before every run
+	return monitor, nil
+}
+
+func (r *JourneyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&monitoringraisingthefloororgv1alpha1.Journey{}).
+		Complete(r)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"time"
+
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+)
+
+// How often to look for new targets. Services and ConfigMaps are not watched, so changes to them are picked
+// up on the next resync.
+const monitorSetResyncPeriod = time.Minute
+
+// MonitorSetReconciler reconciles a MonitorSet object
+type MonitorSetReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=monitorsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=monitorsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+
+// Generated monitors are owned by their set, so they are garbage collected along with it. Reconciling creates
+// the monitors for new targets, updates the ones whose template changed and deletes the ones for targets
+// that are gone.
+func (r *MonitorSetReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	instance := &monitoringraisingthefloororgv1alpha1.MonitorSet{}
+	ctx := context.Background()
+	logger := r.Log.WithValues("monitorset", req.NamespacedName)
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	status := monitoringraisingthefloororgv1alpha1.MonitorSetStatus{}
+	monitors, err := r.desiredMonitors(ctx, instance)
+	if err == nil {
+		status.Targets = len(monitors)
+		err = r.sync(ctx, logger, instance, monitors)
+	}
+	if err != nil {
+		logger.Error(err, "failed to sync monitors")
+		status.Error = err.Error()
+	}
+	for _, monitor := range monitors {
+		status.Monitors = append(status.Monitors, monitor.Name)
+	}
+
+	if !reflect.DeepEqual(status, instance.Status) {
+		instance.Status = status
+		if err := r.Status().Update(ctx, instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: monitorSetResyncPeriod}, nil
+}
+
+// Read the targets from every source and render a monitor for each
+func (r *MonitorSetReconciler) desiredMonitors(ctx context.Context, instance *monitoringraisingthefloororgv1alpha1.MonitorSet) ([]monitoringraisingthefloororgv1alpha1.HttpMonitor, error) {
+	targets := append([]monitoringraisingthefloororgv1alpha1.MonitorTarget(nil), instance.Spec.Targets...)
+
+	if ref := instance.Spec.TargetsFrom; ref != nil {
+		configMap := &corev1.ConfigMap{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: instance.Namespace, Name: ref.Name}, configMap); err != nil {
+			return nil, fmt.Errorf("could not read targets from %s: %w", ref.Name, err)
+		}
+		data, ok := configMap.Data[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("config map %s has no key %s", ref.Name, ref.Key)
+		}
+		fromConfigMap, err := monitoringraisingthefloororgv1alpha1.ParseMonitorTargets(data)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, fromConfigMap...)
+	}
+
+	if instance.Spec.ServiceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(instance.Spec.ServiceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid service selector: %w", err)
+		}
+		services := &corev1.ServiceList{}
+		if err := r.List(ctx, services, client.InNamespace(instance.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		targets = append(targets, monitoringraisingthefloororgv1alpha1.ServiceTargets(services.Items)...)
+	}
+
+	return instance.MonitorsFor(targets)
+}
+
+// Create or update the desired monitors and delete the set's monitors that are not desired anymore
+func (r *MonitorSetReconciler) sync(ctx context.Context, logger logr.Logger, instance *monitoringraisingthefloororgv1alpha1.MonitorSet, monitors []monitoringraisingthefloororgv1alpha1.HttpMonitor) error {
+	desired := make(map[string]bool)
+	for i := range monitors {
+		monitor := &monitors[i]
+		desired[monitor.Name] = true
+		if err := controllerutil.SetControllerReference(instance, monitor, r.Scheme); err != nil {
+			return err
+		}
+
+		existing := &monitoringraisingthefloororgv1alpha1.HttpMonitor{}
+		err := r.Get(ctx, client.ObjectKey{Namespace: monitor.Namespace, Name: monitor.Name}, existing)
+		switch {
+		case errors.IsNotFound(err):
+			logger.Info("creating monitor", "monitor", monitor.Name)
+			err = r.Create(ctx, monitor)
+		case err != nil:
+		case !metav1.IsControlledBy(existing, instance):
+			// Never take over a monitor someone else created
+			err = fmt.Errorf("monitor %s already exists and is not managed by this set", monitor.Name)
+		case !reflect.DeepEqual(existing.Spec, monitor.Spec) || !reflect.DeepEqual(existing.Labels, monitor.Labels) ||
+			!reflect.DeepEqual(existing.Annotations, monitor.Annotations):
+			logger.Info("updating monitor", "monitor", monitor.Name)
+			existing.Labels = monitor.Labels
+			existing.Annotations = monitor.Annotations
+			existing.Spec = monitor.Spec
+			err = r.Update(ctx, existing)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	owned := &monitoringraisingthefloororgv1alpha1.HttpMonitorList{}
+	if err := r.List(ctx, owned, client.InNamespace(instance.Namespace),
+		client.MatchingLabels{monitoringraisingthefloororgv1alpha1.MonitorSetLabel: instance.Name}); err != nil {
+		return err
+	}
+	for i := range owned.Items {
+		monitor := &owned.Items[i]
+		if desired[monitor.Name] || !metav1.IsControlledBy(monitor, instance) {
+			continue
+		}
+		logger.Info("deleting monitor for removed target", "monitor", monitor.Name)
+		if err := r.Delete(ctx, monitor); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Owned monitors are watched for spec changes only. Their status is updated after every run, and reconciling the set
+// each time would only compare specs that have not changed. builder.WithPredicates on Owns would say the same thing,
+// but controller-runtime v0.5 does not have it, so the watch is added to the built controller instead.
+func (r *MonitorSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&monitoringraisingthefloororgv1alpha1.MonitorSet{}).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	return c.Watch(&source.Kind{Type: &monitoringraisingthefloororgv1alpha1.HttpMonitor{}},
+		&handler.EnqueueRequestForOwner{OwnerType: &monitoringraisingthefloororgv1alpha1.MonitorSet{}, IsController: true},
+		predicate.GenerationChangedPredicate{})
+}
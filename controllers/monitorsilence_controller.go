@@ -0,0 +1,116 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package controllers
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"time"
+
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+)
+
+// How often to refresh the list of matched monitors while a silence is active
+const silenceResyncPeriod = time.Minute
+
+// MonitorSilenceReconciler reconciles a MonitorSilence object
+type MonitorSilenceReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=monitorsilences,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=monitorsilences/status,verbs=get;update;patch
+
+// Silences are enforced by the runners when they notify. Reconciling only keeps the status up to date,
+// so kubectl shows what is silenced and whether the silence has expired.
+func (r *MonitorSilenceReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	instance := &monitoringraisingthefloororgv1alpha1.MonitorSilence{}
+	ctx := context.Background()
+	logger := r.Log.WithValues("monitorsilence", req.NamespacedName)
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	now := time.Now()
+	status := monitoringraisingthefloororgv1alpha1.MonitorSilenceStatus{
+		Expired: instance.IsExpired(now),
+	}
+
+	if !status.Expired {
+		monitors := &monitoringraisingthefloororgv1alpha1.HttpMonitorList{}
+		if err := r.List(ctx, monitors, client.InNamespace(req.Namespace)); err != nil {
+			return reconcile.Result{}, err
+		}
+		for i := range monitors.Items {
+			matches, err := instance.Matches(&monitors.Items[i])
+			if err != nil {
+				logger.Error(err, "invalid selector")
+				return reconcile.Result{}, nil
+			}
+			if matches {
+				status.MatchedMonitors = append(status.MatchedMonitors, monitors.Items[i].Name)
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(status, instance.Status) {
+		if status.Expired {
+			logger.Info("silence expired", "reason", instance.Spec.Reason)
+		}
+		instance.Status = status
+		if err := r.Status().Update(ctx, instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if status.Expired {
+		return ctrl.Result{}, nil
+	}
+	requeueAfter := instance.Spec.ExpiresAt.Sub(now)
+	if requeueAfter > silenceResyncPeriod {
+		requeueAfter = silenceResyncPeriod
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+func (r *MonitorSilenceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&monitoringraisingthefloororgv1alpha1.MonitorSilence{}).
+		Complete(r)
+}
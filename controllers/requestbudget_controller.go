@@ -0,0 +1,115 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package controllers
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	"github.com/oregondesignservices/monitoring-controller/internal/metrics"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"time"
+
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+)
+
+// How often to refresh the usage of a budget from the monitors it matches
+const budgetResyncPeriod = time.Minute
+
+// RequestBudgetReconciler reconciles a RequestBudget object
+type RequestBudgetReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=requestbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=requestbudgets/status,verbs=get;update;patch
+
+// Budgets are enforced by the runners before each run, from the usage each monitor records in its own status.
+// Reconciling only adds that usage up, so kubectl and the exceeded gauge show where a budget stands.
+func (r *RequestBudgetReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	instance := &monitoringraisingthefloororgv1alpha1.RequestBudget{}
+	ctx := context.Background()
+	logger := r.Log.WithValues("requestbudget", req.NamespacedName)
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			metrics.RequestBudgetExceededGauge.DeleteLabelValues(req.Namespace, req.Name)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	monitors := &monitoringraisingthefloororgv1alpha1.HttpMonitorList{}
+	if err := r.List(ctx, monitors, client.InNamespace(req.Namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	usage, err := instance.UsageOf(monitors.Items, monitoringraisingthefloororgv1alpha1.UsageMonth(time.Now()))
+	if err != nil {
+		logger.Error(err, "invalid selector")
+		return reconcile.Result{}, nil
+	}
+	status := monitoringraisingthefloororgv1alpha1.RequestBudgetStatus{
+		Usage:    usage,
+		Exceeded: instance.ExceededBy(usage),
+	}
+	for i := range monitors.Items {
+		// The selector was already checked by UsageOf
+		if matches, _ := instance.Matches(&monitors.Items[i]); matches {
+			status.MatchedMonitors = append(status.MatchedMonitors, monitors.Items[i].Name)
+		}
+	}
+
+	exceeded := 0.0
+	if status.Exceeded {
+		exceeded = 1
+	}
+	metrics.RequestBudgetExceededGauge.WithLabelValues(req.Namespace, req.Name).Set(exceeded)
+
+	if !reflect.DeepEqual(status, instance.Status) {
+		if status.Exceeded && !instance.Status.Exceeded {
+			logger.Info("request budget exceeded, matching monitors will skip runs until next month",
+				"requests", usage.Requests, "bytes", usage.Bytes)
+		}
+		instance.Status = status
+		if err := r.Status().Update(ctx, instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: budgetResyncPeriod}, nil
+}
+
+func (r *RequestBudgetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&monitoringraisingthefloororgv1alpha1.RequestBudget{}).
+		Complete(r)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 Raising the Floor - International
+
+Licensed under the New BSD license. You may not use this file except in
+compliance with this License.
+
+You may obtain a copy of the License at
+https://github.com/GPII/universal/blob/master/LICENSE.txt
+
+The R&D leading to these results received funding from the:
+* Rehabilitation Services Administration, US Dept. of Education under
+  grant H421A150006 (APCP)
+* National Institute on Disability, Independent Living, and
+  Rehabilitation Research (NIDILRR)
+* Administration for Independent Living & Dept. of Education under grants
+  H133E080022 (RERC-IT) and H133E130028/90RE5003-01-00 (UIITA-RERC)
+* European Union's Seventh Framework Programme (FP7/2007-2013) grant
+  agreement nos. 289016 (Cloud4all) and 610510 (Prosperity4All)
+* William and Flora Hewlett Foundation
+* Ontario Ministry of Research and Innovation
+* Canadian Foundation for Innovation
+* Adobe Foundation
+* Consumer Electronics Association Foundation
+*/
+
+package controllers
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	runnverv1alpha1 "github.com/oregondesignservices/monitoring-controller/internal/runner/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+)
+
+// SnmpMonitorReconciler reconciles a SnmpMonitor object
+type SnmpMonitorReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=snmpmonitors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.raisingthefloor.org,resources=snmpmonitors/status,verbs=get;update;patch
+
+// Each monitor has a runner that reads the device every period. The runner is replaced when the spec changes.
+func (r *SnmpMonitorReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	instance := &monitoringraisingthefloororgv1alpha1.SnmpMonitor{}
+	ctx := context.Background()
+	logger := r.Log.WithValues("snmpmonitor", req.NamespacedName)
+
+	runnerKey := req.NamespacedName.String()
+	knownRunner, runnerExists := runnverv1alpha1.KnownSnmpRunners[runnerKey]
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if runnerExists {
+				logger.Info("removing snmp monitor")
+				knownRunner.Stop()
+				delete(runnverv1alpha1.KnownSnmpRunners, runnerKey)
+			}
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if runnerExists {
+		if instance.GetGeneration() == knownRunner.GetGeneration() {
+			return reconcile.Result{}, nil
+		}
+		logger.Info("detected snmp monitor changes")
+		knownRunner.Stop()
+	} else {
+		logger.Info("detected a new snmp monitor")
+	}
+
+	newRunner := runnverv1alpha1.NewSnmpMonitorRunner(instance, r.Client)
+	runnverv1alpha1.KnownSnmpRunners[runnerKey] = newRunner
+	newRunner.Start()
+	return ctrl.Result{}, nil
+}
+
+func (r *SnmpMonitorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&monitoringraisingthefloororgv1alpha1.SnmpMonitor{}).
+		Complete(r)
+}
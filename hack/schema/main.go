@@ -0,0 +1,88 @@
+// Command schema writes the OpenAPI v3 schema of each CRD as a standalone JSON schema, for infrastructure as
+// code tools and editors that validate manifests before they reach a cluster.
+//
+// Usage: go run ./hack/schema config/crd/bases config/schema
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type crd struct {
+	Spec struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+		Version    string `json:"version"`
+		Validation struct {
+			OpenAPIV3Schema map[string]interface{} `json:"openAPIV3Schema"`
+		} `json:"validation"`
+	} `json:"spec"`
+}
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "usage: schema <crd directory> <output directory>")
+		os.Exit(2)
+	}
+	if err := run(os.Args[1], os.Args[2]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(crdDir, outDir string) error {
+	files, err := filepath.Glob(filepath.Join(crdDir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		definition := &crd{}
+		if err := yaml.Unmarshal(data, definition); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		kind := definition.Spec.Names.Kind
+		schema := definition.Spec.Validation.OpenAPIV3Schema
+		if schema == nil {
+			return fmt.Errorf("%s has no openAPIV3Schema", file)
+		}
+
+		schema["$schema"] = "http://json-schema.org/draft-04/schema#"
+		schema["title"] = kind
+		setEnum(schema, "apiVersion", v1alpha1.GroupVersion.String())
+		setEnum(schema, "kind", kind)
+
+		out, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return err
+		}
+		name := filepath.Join(outDir, strings.ToLower(kind)+".json")
+		if err := ioutil.WriteFile(name, append(out, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setEnum(schema map[string]interface{}, property, value string) {
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		if p, ok := properties[property].(map[string]interface{}); ok {
+			p["enum"] = []string{value}
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package ber encodes and decodes the subset of ASN.1 BER that LDAP and SNMP messages use: single byte tags and
+// definite lengths.
+package ber
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Universal tags
+const (
+	TagInteger     byte = 0x02
+	TagOctetString byte = 0x04
+	TagNull        byte = 0x05
+	TagOID         byte = 0x06
+	TagEnumerated  byte = 0x0a
+	TagSequence    byte = 0x30
+)
+
+// Messages larger than this are not read, so a misbehaving server cannot make the controller allocate without limit
+const maxLength = 1 << 20
+
+// A decoded element. Constructed elements, like sequences, keep their children encoded in Content.
+type Value struct {
+	Tag     byte
+	Content []byte
+}
+
+// TLV encodes an element from its tag and content
+func TLV(tag byte, content []byte) []byte {
+	out := []byte{tag}
+	n := len(content)
+	switch {
+	case n < 0x80:
+		out = append(out, byte(n))
+	default:
+		var length []byte
+		for ; n > 0; n >>= 8 {
+			length = append([]byte{byte(n)}, length...)
+		}
+		out = append(append(out, 0x80|byte(len(length))), length...)
+	}
+	return append(out, content...)
+}
+
+// Constructed encodes a constructed element, like a sequence, from its encoded children
+func Constructed(tag byte, children ...[]byte) []byte {
+	return TLV(tag, bytes.Join(children, nil))
+}
+
+// Int encodes an integer with the given tag, like TagInteger or TagEnumerated
+func Int(tag byte, n int64) []byte {
+	content := []byte{byte(n)}
+	for rest := n >> 8; ; rest >>= 8 {
+		// Stop once the remaining bytes are only sign extension
+		sign := content[0] & 0x80
+		if (rest == 0 && sign == 0) || (rest == -1 && sign != 0) {
+			break
+		}
+		content = append([]byte{byte(rest)}, content...)
+	}
+	return TLV(tag, content)
+}
+
+// OctetString encodes a string
+func OctetString(s string) []byte {
+	return TLV(TagOctetString, []byte(s))
+}
+
+// Null encodes a null
+func Null() []byte {
+	return []byte{TagNull, 0x00}
+}
+
+// OID encodes a dotted object identifier, like 1.3.6.1.2.1.1.3.0
+func OID(text string) ([]byte, error) {
+	pieces := strings.Split(strings.TrimPrefix(text, "."), ".")
+	if len(pieces) < 2 {
+		return nil, fmt.Errorf("%q is not an object identifier", text)
+	}
+	arcs := make([]uint64, len(pieces))
+	for i, piece := range pieces {
+		arc, err := strconv.ParseUint(piece, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an object identifier", text)
+		}
+		arcs[i] = arc
+	}
+	if arcs[0] > 2 || (arcs[0] < 2 && arcs[1] > 39) {
+		return nil, fmt.Errorf("%q is not an object identifier", text)
+	}
+	content := base128(arcs[0]*40 + arcs[1])
+	for _, arc := range arcs[2:] {
+		content = append(content, base128(arc)...)
+	}
+	return TLV(TagOID, content), nil
+}
+
+func base128(n uint64) []byte {
+	out := []byte{byte(n & 0x7f)}
+	for n >>= 7; n > 0; n >>= 7 {
+		out = append([]byte{byte(n&0x7f) | 0x80}, out...)
+	}
+	return out
+}
+
+// Parse decodes the first element of data, returning what follows it
+func Parse(data []byte) (Value, []byte, error) {
+	if len(data) < 2 {
+		return Value{}, nil, io.ErrUnexpectedEOF
+	}
+	tag, length, header := data[0], int(data[1]), 2
+	if length&0x80 != 0 {
+		size := length & 0x7f
+		if size == 0 || size > 3 {
+			return Value{}, nil, fmt.Errorf("unsupported length encoding 0x%02x", data[1])
+		}
+		if len(data) < 2+size {
+			return Value{}, nil, io.ErrUnexpectedEOF
+		}
+		length = 0
+		for _, b := range data[2 : 2+size] {
+			length = length<<8 | int(b)
+		}
+		header += size
+	}
+	if len(data) < header+length {
+		return Value{}, nil, io.ErrUnexpectedEOF
+	}
+	return Value{Tag: tag, Content: data[header : header+length]}, data[header+length:], nil
+}
+
+// Read decodes one element from a stream, reading no further than its end
+func Read(r io.Reader) (Value, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return Value{}, err
+	}
+	length := int(header[1])
+	if length&0x80 != 0 {
+		size := length & 0x7f
+		if size == 0 || size > 3 {
+			return Value{}, fmt.Errorf("unsupported length encoding 0x%02x", header[1])
+		}
+		extra := make([]byte, size)
+		if _, err := io.ReadFull(r, extra); err != nil {
+			return Value{}, err
+		}
+		length = 0
+		for _, b := range extra {
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maxLength {
+		return Value{}, fmt.Errorf("element of %d bytes is too large", length)
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return Value{}, err
+	}
+	return Value{Tag: header[0], Content: content}, nil
+}
+
+// Children decodes the content of a constructed element
+func (v Value) Children() ([]Value, error) {
+	var children []Value
+	for rest := v.Content; len(rest) > 0; {
+		child, next, err := Parse(rest)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+		rest = next
+	}
+	return children, nil
+}
+
+// Int decodes an integer, of any integer-like tag
+func (v Value) Int() (int64, error) {
+	if len(v.Content) == 0 || len(v.Content) > 8 {
+		return 0, fmt.Errorf("invalid integer of %d bytes", len(v.Content))
+	}
+	n := int64(int8(v.Content[0]))
+	for _, b := range v.Content[1:] {
+		n = n<<8 | int64(b)
+	}
+	return n, nil
+}
+
+// Uint decodes an unsigned integer, like the counters and gauges of SNMP, which may use all 64 bits
+func (v Value) Uint() (uint64, error) {
+	content := v.Content
+	// A leading zero only keeps the sign bit clear
+	if len(content) > 1 && content[0] == 0 {
+		content = content[1:]
+	}
+	if len(content) == 0 || len(content) > 8 {
+		return 0, fmt.Errorf("invalid unsigned integer of %d bytes", len(v.Content))
+	}
+	var n uint64
+	for _, b := range content {
+		n = n<<8 | uint64(b)
+	}
+	return n, nil
+}
+
+// OIDString decodes an object identifier to its dotted form
+func (v Value) OIDString() (string, error) {
+	if len(v.Content) == 0 {
+		return "", errors.New("empty object identifier")
+	}
+	var arcs []string
+	var arc uint64
+	for i, b := range v.Content {
+		arc = arc<<7 | uint64(b&0x7f)
+		if b&0x80 != 0 {
+			if i == len(v.Content)-1 {
+				return "", errors.New("truncated object identifier")
+			}
+			continue
+		}
+		if len(arcs) == 0 {
+			first := arc / 40
+			if first > 2 {
+				first = 2
+			}
+			arcs = append(arcs, strconv.FormatUint(first, 10), strconv.FormatUint(arc-first*40, 10))
+		} else {
+			arcs = append(arcs, strconv.FormatUint(arc, 10))
+		}
+		arc = 0
+	}
+	return strings.Join(arcs, "."), nil
+}
@@ -0,0 +1,312 @@
+package blackbox
+
+import (
+	"fmt"
+	"github.com/ghodss/yaml"
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The parts of a blackbox_exporter configuration that can be translated
+type config struct {
+	Modules map[string]module `json:"modules"`
+}
+
+type module struct {
+	Prober  string     `json:"prober"`
+	Timeout string     `json:"timeout"`
+	Http    *httpProbe `json:"http"`
+}
+
+type httpProbe struct {
+	ValidStatusCodes []int             `json:"valid_status_codes"`
+	Method           string            `json:"method"`
+	Headers          map[string]string `json:"headers"`
+	Body             string            `json:"body"`
+
+	FailIfBodyMatchesRegexp     []string   `json:"fail_if_body_matches_regexp"`
+	FailIfBodyNotMatchesRegexp  []string   `json:"fail_if_body_not_matches_regexp"`
+	NoFollowRedirects           bool       `json:"no_follow_redirects"`
+	BasicAuth                   *basicAuth `json:"basic_auth"`
+	BearerToken                 string     `json:"bearer_token"`
+	BearerTokenFile             string     `json:"bearer_token_file"`
+	TlsConfig                   *tlsConfig `json:"tls_config"`
+	FailIfSslCertificateExpires string     `json:"fail_if_ssl_certificate_expires"`
+
+	// Recognized so they can be reported, they have no equivalent yet
+	FailIfNotSsl           bool          `json:"fail_if_not_ssl"`
+	FailIfSsl              bool          `json:"fail_if_ssl"`
+	FailIfHeaderMatches    []interface{} `json:"fail_if_header_matches"`
+	FailIfHeaderNotMatches []interface{} `json:"fail_if_header_not_matches"`
+	ValidHttpVersions      []string      `json:"valid_http_versions"`
+	PreferredIpProtocol    string        `json:"preferred_ip_protocol"`
+	IpProtocolFallback     *bool         `json:"ip_protocol_fallback"`
+}
+
+type basicAuth struct {
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+	PasswordFile string `json:"password_file"`
+}
+
+type tlsConfig struct {
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+	ServerName         string `json:"server_name"`
+	CaFile             string `json:"ca_file"`
+	CertFile           string `json:"cert_file"`
+	KeyFile            string `json:"key_file"`
+	MinVersion         string `json:"min_version"`
+}
+
+// A Prometheus file_sd target group. The module comes from the module or __param_module label.
+type targetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+type Options struct {
+	// Namespace of the monitors
+	Namespace string
+	// How often every monitor runs, usually the scrape interval of the blackbox job
+	Period time.Duration
+	// The module for target groups without a module label
+	DefaultModule string
+}
+
+// blackbox_exporter treats every 2xx as success when valid_status_codes is not set
+var defaultStatusCodes = v1alpha1.StatusCodeMatcher{intstr.FromString("2xx")}
+
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Translate a blackbox_exporter configuration and its targets into one HttpMonitor per target. Settings that
+// have no equivalent, and modules for other probers, are returned as warnings rather than failing the whole
+// conversion.
+func Convert(configData, targetsData []byte, opts Options) ([]v1alpha1.HttpMonitor, []string, error) {
+	parsed := &config{}
+	if err := yaml.Unmarshal(configData, parsed); err != nil {
+		return nil, nil, fmt.Errorf("invalid blackbox_exporter configuration: %w", err)
+	}
+	var groups []targetGroup
+	if err := yaml.Unmarshal(targetsData, &groups); err != nil {
+		return nil, nil, fmt.Errorf("invalid targets: %w", err)
+	}
+
+	var monitors []v1alpha1.HttpMonitor
+	var warnings []string
+	warned := make(map[string]bool)
+	warn := func(moduleName, message string) {
+		if key := moduleName + ": " + message; !warned[key] {
+			warned[key] = true
+			warnings = append(warnings, key)
+		}
+	}
+	used := make(map[string]bool)
+
+	for _, group := range groups {
+		moduleName := group.Labels["__param_module"]
+		if moduleName == "" {
+			moduleName = group.Labels["module"]
+		}
+		if moduleName == "" {
+			moduleName = opts.DefaultModule
+		}
+		m, found := parsed.Modules[moduleName]
+		if !found {
+			return nil, warnings, fmt.Errorf("targets refer to module %q, which is not in the configuration", moduleName)
+		}
+		if m.Prober != "http" {
+			warn(moduleName, fmt.Sprintf("the %s prober has no equivalent, its targets were skipped", m.Prober))
+			continue
+		}
+		request, err := translateHttp(moduleName, m, warn)
+		if err != nil {
+			return nil, warnings, err
+		}
+
+		for _, target := range group.Targets {
+			r := request
+			r.Url = target
+			if !strings.Contains(target, "://") {
+				r.Url = "http://" + target
+			}
+			u, err := url.Parse(r.Url)
+			if err != nil {
+				return nil, warnings, fmt.Errorf("invalid target %q: %w", target, err)
+			}
+			r.TargetService = u.Hostname()
+
+			// Suffixed names can themselves be the name of another target, like a path ending in -2
+			base := monitorName(moduleName, u)
+			name := base
+			for n := 2; used[name]; n++ {
+				name = fmt.Sprintf("%s-%d", base, n)
+			}
+			used[name] = true
+			monitors = append(monitors, v1alpha1.HttpMonitor{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: opts.Namespace, Labels: monitorLabels(group.Labels)},
+				Spec: v1alpha1.HttpMonitorSpec{
+					Period:   &metav1.Duration{Duration: opts.Period},
+					Requests: []v1alpha1.HttpRequest{r},
+				},
+			})
+		}
+	}
+	return monitors, warnings, nil
+}
+
+func translateHttp(moduleName string, m module, warn func(string, string)) (v1alpha1.HttpRequest, error) {
+	r := v1alpha1.HttpRequest{Name: moduleName, Method: http.MethodGet, ExpectedResponseCodes: defaultStatusCodes}
+	if m.Timeout != "" {
+		timeout, err := time.ParseDuration(m.Timeout)
+		if err != nil {
+			return r, fmt.Errorf("module %q has an invalid timeout %q: %w", moduleName, m.Timeout, err)
+		}
+		r.Timeout = &metav1.Duration{Duration: timeout}
+	}
+	probe := m.Http
+	if probe == nil {
+		return r, nil
+	}
+	if probe.Method != "" {
+		r.Method = strings.ToUpper(probe.Method)
+	}
+	if len(probe.ValidStatusCodes) > 0 {
+		r.ExpectedResponseCodes = nil
+		for _, code := range probe.ValidStatusCodes {
+			r.ExpectedResponseCodes = append(r.ExpectedResponseCodes, intstr.FromInt(code))
+		}
+	}
+	if len(probe.Headers) > 0 {
+		r.Headers = make(http.Header)
+		for key, value := range probe.Headers {
+			r.Headers.Set(key, value)
+		}
+	}
+	r.Body = probe.Body
+
+	if len(probe.FailIfBodyMatchesRegexp) > 0 || len(probe.FailIfBodyNotMatchesRegexp) > 0 {
+		r.ResponseAssertions = &v1alpha1.ResponseAssertions{
+			BodyNotMatches: probe.FailIfBodyMatchesRegexp,
+			BodyMatches:    probe.FailIfBodyNotMatchesRegexp,
+		}
+	}
+	if probe.NoFollowRedirects {
+		follow := false
+		r.Redirects = &v1alpha1.Redirects{Follow: &follow}
+	}
+	// Credentials are not copied into the monitors, they are read from Secrets the warnings name
+	if probe.BasicAuth != nil {
+		secret := objectName(moduleName, "basic-auth")
+		r.Auth = &v1alpha1.RequestAuth{Basic: &v1alpha1.BasicAuth{SecretRef: corev1.LocalObjectReference{Name: secret}}}
+		warn(moduleName, fmt.Sprintf("basic_auth reads Secret %q, create it with the username and password", secret))
+	}
+	if probe.BearerToken != "" || probe.BearerTokenFile != "" {
+		secret := objectName(moduleName, "bearer-token")
+		r.Auth = &v1alpha1.RequestAuth{Bearer: &v1alpha1.BearerAuth{SecretRef: corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secret}, Key: "token"}}}
+		warn(moduleName, fmt.Sprintf("bearer_token reads key token of Secret %q, create it with the token", secret))
+	}
+	if probe.TlsConfig != nil {
+		r.Tls = translateTls(moduleName, probe.TlsConfig, warn)
+	}
+	if probe.FailIfSslCertificateExpires != "" {
+		remaining, err := parseDuration(probe.FailIfSslCertificateExpires)
+		if err != nil {
+			return r, fmt.Errorf("module %q has an invalid fail_if_ssl_certificate_expires %q: %w", moduleName,
+				probe.FailIfSslCertificateExpires, err)
+		}
+		r.MinCertificateRemaining = &metav1.Duration{Duration: remaining}
+	}
+
+	unsupported := map[string]bool{
+		"fail_if_not_ssl":            probe.FailIfNotSsl,
+		"fail_if_ssl":                probe.FailIfSsl,
+		"fail_if_header_matches":     len(probe.FailIfHeaderMatches) > 0,
+		"fail_if_header_not_matches": len(probe.FailIfHeaderNotMatches) > 0,
+		"valid_http_versions":        len(probe.ValidHttpVersions) > 0,
+		"preferred_ip_protocol":      probe.PreferredIpProtocol != "",
+		"ip_protocol_fallback":       probe.IpProtocolFallback != nil,
+	}
+	if probe.TlsConfig != nil {
+		unsupported["tls_config.min_version"] = probe.TlsConfig.MinVersion != ""
+	}
+	var settings []string
+	for setting, set := range unsupported {
+		if set {
+			settings = append(settings, setting)
+		}
+	}
+	sort.Strings(settings)
+	for _, setting := range settings {
+		warn(moduleName, setting+" has no equivalent and was left out")
+	}
+	return r, nil
+}
+
+// Files the exporter reads become ConfigMap and Secret refs, which the warnings ask for
+func translateTls(moduleName string, c *tlsConfig, warn func(string, string)) *v1alpha1.TlsOptions {
+	options := &v1alpha1.TlsOptions{InsecureSkipVerify: c.InsecureSkipVerify, ServerName: c.ServerName}
+	if c.CaFile != "" {
+		configMap := objectName(moduleName, "ca")
+		options.CaBundle = &v1alpha1.CaBundleSource{ConfigMapRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: configMap}, Key: "ca.crt"}}
+		warn(moduleName, fmt.Sprintf("tls_config.ca_file reads key ca.crt of ConfigMap %q, create it from %s",
+			configMap, c.CaFile))
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		secret := objectName(moduleName, "client-certificate")
+		options.ClientCertificate = &v1alpha1.ClientCertificate{SecretRef: corev1.LocalObjectReference{Name: secret}}
+		warn(moduleName, fmt.Sprintf("tls_config.cert_file reads Secret %q, create it from %s and %s with kubectl create secret tls",
+			secret, c.CertFile, c.KeyFile))
+	}
+	return options
+}
+
+// Prometheus durations, which also have d and w
+func parseDuration(value string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if count, err := strconv.Atoi(strings.TrimSuffix(value, suffix)); strings.HasSuffix(value, suffix) && err == nil {
+			return time.Duration(count) * unit, nil
+		}
+	}
+	return time.ParseDuration(value)
+}
+
+// A valid object name for something the module needs, like http-2xx-basic-auth
+func objectName(moduleName, suffix string) string {
+	return strings.Trim(invalidNameCharacters.ReplaceAllString(strings.ToLower(moduleName+"-"+suffix), "-"), "-")
+}
+
+// A valid object name from the module and target, like http-2xx-shop-example-com-health
+func monitorName(moduleName string, u *url.URL) string {
+	name := strings.ToLower(moduleName + "-" + u.Hostname() + u.Path)
+	name = strings.Trim(invalidNameCharacters.ReplaceAllString(name, "-"), "-")
+	if len(name) > 60 {
+		name = strings.TrimRight(name[:60], "-")
+	}
+	return name
+}
+
+// Labels of the target group, without the ones Prometheus reserves
+func monitorLabels(groupLabels map[string]string) map[string]string {
+	var labels map[string]string
+	for key, value := range groupLabels {
+		if strings.HasPrefix(key, "__") || key == "module" {
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[key] = value
+	}
+	return labels
+}
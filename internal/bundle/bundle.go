@@ -0,0 +1,138 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"github.com/go-logr/logr"
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"net"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+// Bundle the monitors in namespace, or in every namespace if it is empty, that match selector
+func Export(ctx context.Context, reader client.Reader, namespace string, selector labels.Selector) (*v1alpha1.MonitorBundle, error) {
+	monitors := &v1alpha1.HttpMonitorList{}
+	if err := reader.List(ctx, monitors, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	return v1alpha1.NewMonitorBundle(monitors.Items, metav1.Now()), nil
+}
+
+// Create the bundled monitors, or update the specs of the ones that exist. Returns the namespace/name of
+// each monitor applied before an error.
+func Import(ctx context.Context, c client.Client, monitors []v1alpha1.HttpMonitor) ([]string, error) {
+	var applied []string
+	for i := range monitors {
+		monitor := &monitors[i]
+		key := client.ObjectKey{Namespace: monitor.Namespace, Name: monitor.Name}
+
+		existing := &v1alpha1.HttpMonitor{}
+		err := c.Get(ctx, key, existing)
+		switch {
+		case apierrors.IsNotFound(err):
+			err = c.Create(ctx, monitor)
+		case err == nil:
+			existing.Labels = monitor.Labels
+			existing.Annotations = monitor.Annotations
+			existing.Spec = monitor.Spec
+			err = c.Update(ctx, existing)
+		}
+		if err != nil {
+			return applied, fmt.Errorf("could not apply %s: %w", key, err)
+		}
+		applied = append(applied, key.String())
+	}
+	return applied, nil
+}
+
+// Serves bundles at /bundle, with a namespace and an optional selector query parameter. Only exports are served,
+// imports go through the API server so its authorization applies. Exports are not authenticated, so the server
+// only listens on a loopback address, to be reached with kubectl port-forward.
+type Server struct {
+	Addr   string
+	Reader client.Reader
+	Log    logr.Logger
+}
+
+// Every replica can serve exports, not just the leader
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *Server) Start(stop <-chan struct{}) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bundle", s.serveBundle)
+	server := &http.Server{Handler: mux}
+
+	addr, err := LoopbackAddr(s.Addr)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+	s.Log.Info("serving monitor bundles", "addr", addr)
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) serveBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	// An empty namespace would export every namespace's monitors
+	namespace := r.URL.Query().Get("namespace")
+	if namespace == "" {
+		http.Error(w, "the namespace query parameter is required", http.StatusBadRequest)
+		return
+	}
+	selector, err := labels.Parse(r.URL.Query().Get("selector"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	bundle, err := Export(r.Context(), s.Reader, namespace, selector)
+	if err != nil {
+		s.Log.Error(err, "failed to export monitors")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	out, err := yaml.Marshal(bundle)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(out)
+}
+
+// The address to serve on: addr, on 127.0.0.1 if it has no host. Addresses that are not loopback are rejected.
+func LoopbackAddr(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if host == "" {
+		return net.JoinHostPort("127.0.0.1", port), nil
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return "", fmt.Errorf("%s is not a loopback address, and monitor bundles are served without authentication", addr)
+	}
+	return addr, nil
+}
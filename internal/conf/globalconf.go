@@ -2,6 +2,8 @@ package conf
 
 import (
 	"errors"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/bundle"
 	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
 	"github.com/oregondesignservices/monitoring-controller/internal/metrics"
 	"github.com/urfave/cli/v2"
@@ -28,10 +30,60 @@ var (
 			Value: 29 * time.Second,
 			Usage: "the http client timeout duration",
 		},
+		&cli.BoolFlag{
+			Name:  "strict-crypto",
+			Usage: "restrict TLS to FIPS-approved versions, cipher suites and curves, and always verify certificates, whatever monitors ask for",
+		},
+		&cli.DurationFlag{
+			Name:  "startup-ramp",
+			Value: 2 * time.Minute,
+			Usage: "spread the first runs of the monitors over this long after the controller starts, or over their period if it is shorter. 0 runs every monitor a period after it starts",
+		},
+		&cli.StringFlag{
+			Name:  "bundle-addr",
+			Usage: "the loopback address to serve monitor bundle exports on, like 127.0.0.1:8082, or :8082 for the same. Exports are not authenticated. Default is not to serve them",
+		},
+		&cli.StringFlag{
+			Name:  "debug-addr",
+			Usage: "the address to serve runtime diagnostics on at /debug/runtime, like :8083. Default is not to serve them",
+		},
+		&cli.BoolFlag{
+			Name:  "enable-pprof",
+			Usage: "also serve pprof profiles at /debug/pprof/ on --debug-addr",
+		},
+		&cli.StringFlag{
+			Name:  "run-record-key",
+			Usage: "a PEM file with the Ed25519 private key, in PKCS #8, to sign a record of every run with. Default is not to sign them",
+		},
+		&cli.StringFlag{
+			Name:  "run-record-addr",
+			Usage: "the address to serve the public key of --run-record-key on at /run-records/public-key, like :8084. Default is not to serve it",
+		},
+		&cli.BoolFlag{
+			Name:  "standby",
+			Usage: "load and lint monitors but send no requests, for a standby cluster that must not probe targets until failover",
+		},
+		&cli.StringFlag{
+			Name:  "failover-primary-url",
+			Usage: "with --standby, take over while this URL of the primary installation, like its /debug/runtime, does not answer with a 2xx status",
+		},
+		&cli.DurationFlag{
+			Name:  "failover-after",
+			Value: 2 * time.Minute,
+			Usage: "how long the primary installation must be down before taking over, and up again before handing back",
+		},
 		&cli.BoolFlag{
 			Name:  "enable-leader-election",
 			Usage: "Enable leader election for controller manager",
 		},
+		&cli.BoolFlag{
+			Name:  "enable-webhooks",
+			Usage: "serve the admission webhooks, which reject monitors whose runs can take longer than their period",
+		},
+		&cli.StringSliceFlag{
+			Name:  "aws-web-identity-namespace",
+			Usage: "let aws_sigv4 requests without a secret_ref in this namespace sign with the controller's IAM role. Repeat for more namespaces, or use * for all. Default is none",
+		},
 		&cli.StringSliceFlag{
 			Name:  "set-var",
 			Usage: "set a global variable available to all requests. Format: 'key=value'",
@@ -47,8 +99,21 @@ type configuration struct {
 	MetricsAddr          string
 	Namespace            string
 	HttpClientTimeout    time.Duration
+	StrictCrypto         bool
 	EnableLeaderElection bool
-	GlobalRequestVars    map[string]string
+	EnableWebhooks       bool
+	StartupRamp          time.Duration
+	Standby              bool
+	FailoverPrimaryUrl   string
+	FailoverAfter        time.Duration
+	BundleAddr           string
+	DebugAddr            string
+	EnablePprof          bool
+	RunRecordKey         string
+	RunRecordAddr        string
+	// Namespaces whose monitors may sign with the controller's IAM role
+	AwsWebIdentityNamespaces []string
+	GlobalRequestVars        map[string]string
 }
 
 func (c *configuration) UpdateFromCli(ctx *cli.Context) error {
@@ -56,8 +121,35 @@ func (c *configuration) UpdateFromCli(ctx *cli.Context) error {
 	c.Namespace = ctx.String("namespace")
 	c.HttpClientTimeout = ctx.Duration("http-client-timeout")
 	c.EnableLeaderElection = ctx.Bool("enable-leader-election")
+	c.EnableWebhooks = ctx.Bool("enable-webhooks")
+	c.StartupRamp = ctx.Duration("startup-ramp")
+	c.Standby = ctx.Bool("standby")
+	c.FailoverPrimaryUrl = ctx.String("failover-primary-url")
+	c.FailoverAfter = ctx.Duration("failover-after")
+	if c.FailoverPrimaryUrl != "" && !c.Standby {
+		return errors.New("--failover-primary-url needs --standby")
+	}
+	c.BundleAddr = ctx.String("bundle-addr")
+	if c.BundleAddr != "" {
+		if _, err := bundle.LoopbackAddr(c.BundleAddr); err != nil {
+			return fmt.Errorf("invalid --bundle-addr: %w", err)
+		}
+	}
+	c.DebugAddr = ctx.String("debug-addr")
+	c.EnablePprof = ctx.Bool("enable-pprof")
+	if c.EnablePprof && c.DebugAddr == "" {
+		return errors.New("--enable-pprof needs --debug-addr")
+	}
+	c.RunRecordKey = ctx.String("run-record-key")
+	c.RunRecordAddr = ctx.String("run-record-addr")
+	if c.RunRecordAddr != "" && c.RunRecordKey == "" {
+		return errors.New("--run-record-addr needs --run-record-key")
+	}
+	c.AwsWebIdentityNamespaces = ctx.StringSlice("aws-web-identity-namespace")
 
+	c.StrictCrypto = ctx.Bool("strict-crypto")
 	httpclient.Initialize(c.HttpClientTimeout)
+	httpclient.SetStrictCrypto(c.StrictCrypto)
 	ctrl.SetLogger(zap.New(zap.UseDevMode(false)))
 
 	logger := ctrl.Log.WithName("configuration").WithName("UpdateFromCli")
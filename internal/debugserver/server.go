@@ -0,0 +1,68 @@
+package debugserver
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	jsoniter "github.com/json-iterator/go"
+	runnerv1alpha1 "github.com/oregondesignservices/monitoring-controller/internal/runner/v1alpha1"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// Serves runtime diagnostics at /debug/runtime, and the pprof profiles under /debug/pprof/ if EnablePprof is set.
+// Neither is authenticated, so Addr should only be reachable from inside the cluster.
+type Server struct {
+	Addr        string
+	EnablePprof bool
+	Log         logr.Logger
+}
+
+// Every replica has its own runtime worth looking at, not just the leader
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *Server) Start(stop <-chan struct{}) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/runtime", s.serveRuntime)
+	if s.EnablePprof {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+	server := &http.Server{Handler: mux}
+
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+	s.Log.Info("serving runtime diagnostics", "addr", s.Addr, "pprof", s.EnablePprof)
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) serveRuntime(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	out, err := jsoniter.MarshalIndent(runnerv1alpha1.Runtime(), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
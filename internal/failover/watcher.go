@@ -0,0 +1,113 @@
+package failover
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	jsoniter "github.com/json-iterator/go"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultInterval = 10 * time.Second
+	requestTimeout  = 5 * time.Second
+	// Runtime reports are small. Anything past this is not one.
+	maxReportBytes = 1 << 20
+)
+
+// Watches the primary installation from a standby one, and runs the monitors here while the primary is down.
+// The primary counts as up while PrimaryUrl answers with a 2xx status. A URL that serves the primary's
+// /debug/runtime report is best, since a primary that is itself in standby then counts as down too.
+type Watcher struct {
+	PrimaryUrl string
+
+	// How long the primary must be down before this installation takes over, and up again before it hands back
+	After time.Duration
+
+	// How often to check the primary. Default is 10s
+	Interval time.Duration
+
+	// Called with true to start sending requests, and with false to go back to standby
+	SetActive func(active bool)
+
+	Log logr.Logger
+}
+
+// Only the leader runs monitors, so only the leader needs to know whether to
+func (w *Watcher) NeedLeaderElection() bool {
+	return true
+}
+
+func (w *Watcher) Start(stop <-chan struct{}) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	client := &http.Client{Timeout: requestTimeout}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	w.Log.Info("watching the primary installation", "url", w.PrimaryUrl, "after", w.After.String())
+	active := false
+	// When the primary was last seen in the other state than the one it is in now
+	changedAt := time.Now()
+	primaryUp := true
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+
+		err := w.checkPrimary(client)
+		if up := err == nil; up != primaryUp {
+			primaryUp, changedAt = up, time.Now()
+			if err != nil {
+				w.Log.Info("primary installation is down", "error", err.Error())
+			} else {
+				w.Log.Info("primary installation is up")
+			}
+		}
+		if primaryUp == active && time.Since(changedAt) >= w.After {
+			active = !primaryUp
+			if active {
+				w.Log.Info("taking over from the primary installation", "down_for", time.Since(changedAt).String())
+			} else {
+				w.Log.Info("handing back to the primary installation", "up_for", time.Since(changedAt).String())
+			}
+			w.SetActive(active)
+		}
+	}
+}
+
+func (w *Watcher) checkPrimary(client *http.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.PrimaryUrl, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxReportBytes))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+	}
+	// Other responses than a runtime report only tell us the primary is reachable
+	var report struct {
+		Standby bool `json:"standby"`
+	}
+	if jsoniter.Unmarshal(body, &report) == nil && report.Standby {
+		return fmt.Errorf("primary is in standby")
+	}
+	return nil
+}
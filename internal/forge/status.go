@@ -0,0 +1,131 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type State string
+
+var (
+	StatePending State = "pending"
+	StateSuccess State = "success"
+	StateFailure State = "failure"
+)
+
+const (
+	defaultGithubUrl = "https://api.github.com"
+	defaultGitlabUrl = "https://gitlab.com/api/v4"
+
+	// Both forges reject long descriptions
+	maxDescriptionLength = 140
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Report the state of a check on a commit or deployment
+func Report(ctx context.Context, report *v1alpha1.CommitStatusReport, name string, state State, description, token string) error {
+	statusContext := report.Context
+	if statusContext == "" {
+		statusContext = "monitoring-controller/" + name
+	}
+	if runes := []rune(description); len(runes) > maxDescriptionLength {
+		description = string(runes[:maxDescriptionLength])
+	}
+
+	var req *http.Request
+	var err error
+	switch report.Provider {
+	case v1alpha1.ForgeProviderGithub:
+		req, err = githubRequest(report, statusContext, state, description)
+		if err == nil {
+			req.Header.Set("Authorization", "token "+token)
+		}
+	case v1alpha1.ForgeProviderGitlab:
+		req, err = gitlabRequest(report, statusContext, state, description)
+		if err == nil {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}
+	default:
+		err = fmt.Errorf("not a known provider: %s", report.Provider)
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status from %s: %d", req.URL.Host, resp.StatusCode)
+	}
+	return nil
+}
+
+func apiUrl(report *v1alpha1.CommitStatusReport, defaultUrl string) string {
+	if report.ApiUrl == "" {
+		return defaultUrl
+	}
+	return strings.TrimSuffix(report.ApiUrl, "/")
+}
+
+func githubRequest(report *v1alpha1.CommitStatusReport, statusContext string, state State, description string) (*http.Request, error) {
+	base := apiUrl(report, defaultGithubUrl)
+	endpoint := fmt.Sprintf("%s/repos/%s/statuses/%s", base, report.Repository, report.Sha)
+	payload := map[string]string{
+		"state":       string(state),
+		"context":     statusContext,
+		"description": description,
+	}
+	if report.DeploymentId != 0 {
+		endpoint = fmt.Sprintf("%s/repos/%s/deployments/%d/statuses", base, report.Repository, report.DeploymentId)
+		delete(payload, "context")
+		if state == StatePending {
+			payload["state"] = "in_progress"
+		}
+	}
+	if report.TargetUrl != "" {
+		payload["target_url"] = report.TargetUrl
+	}
+
+	body, err := jsoniter.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	return req, nil
+}
+
+func gitlabRequest(report *v1alpha1.CommitStatusReport, statusContext string, state State, description string) (*http.Request, error) {
+	gitlabState := string(state)
+	if state == StateFailure {
+		gitlabState = "failed"
+	}
+	query := url.Values{}
+	query.Set("state", gitlabState)
+	query.Set("name", statusContext)
+	query.Set("description", description)
+	if report.TargetUrl != "" {
+		query.Set("target_url", report.TargetUrl)
+	}
+	endpoint := fmt.Sprintf("%s/projects/%s/statuses/%s?%s",
+		apiUrl(report, defaultGitlabUrl), url.PathEscape(report.Repository), report.Sha, query.Encode())
+	return http.NewRequest(http.MethodPost, endpoint, nil)
+}
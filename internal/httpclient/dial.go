@@ -0,0 +1,165 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"golang.org/x/net/proxy"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// Connects the way a client's transport does, for protocols the client cannot speak itself, like WebSockets
+type Dialer struct {
+	dial      func(ctx context.Context, network, addr string) (net.Conn, error)
+	tlsConfig *tls.Config
+	proxy     func(*http.Request) (*url.URL, error)
+}
+
+// The dialer of the client's transport. Clients with a transport of their own dial like the default one.
+func DialerOf(client *http.Client) *Dialer {
+	switch transport := client.Transport.(type) {
+	case *http.Transport:
+		return dialerOf(transport)
+	case *orderedTransport:
+		return &Dialer{dial: transport.dial, tlsConfig: transport.tlsConfig}
+	default:
+		return dialerOf(http.DefaultTransport.(*http.Transport))
+	}
+}
+
+func dialerOf(transport *http.Transport) *Dialer {
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	return &Dialer{dial: dial, tlsConfig: transport.TLSClientConfig, proxy: transport.Proxy}
+}
+
+// Open a connection to the host of an http or https URL, through the proxy if there is one. https connections
+// negotiate HTTP/1.1 and come with their TLS state.
+func (d *Dialer) Dial(ctx context.Context, u *url.URL) (net.Conn, *tls.ConnectionState, error) {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	addr := net.JoinHostPort(u.Hostname(), port)
+	var proxyUrl *url.URL
+	if d.proxy != nil {
+		var err error
+		if proxyUrl, err = d.proxy(&http.Request{URL: u}); err != nil {
+			return nil, nil, err
+		}
+	}
+	conn, err := d.dialThrough(ctx, proxyUrl, addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if u.Scheme != "https" {
+		return conn, nil, nil
+	}
+	config := d.config(u.Hostname())
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	state := tlsConn.ConnectionState()
+	return tlsConn, &state, nil
+}
+
+func (d *Dialer) config(serverName string) *tls.Config {
+	config := &tls.Config{}
+	if d.tlsConfig != nil {
+		config = d.tlsConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = serverName
+	}
+	config.NextProtos = []string{"http/1.1"}
+	return config
+}
+
+func (d *Dialer) dialThrough(ctx context.Context, proxyUrl *url.URL, addr string) (net.Conn, error) {
+	if proxyUrl == nil {
+		return d.dial(ctx, "tcp", addr)
+	}
+	switch proxyUrl.Scheme {
+	case "socks5":
+		dialer, err := proxy.FromURL(proxyUrl, contextDialer(d.dial))
+		if err != nil {
+			return nil, err
+		}
+		if dialer, ok := dialer.(proxy.ContextDialer); ok {
+			return dialer.DialContext(ctx, "tcp", addr)
+		}
+		return dialer.Dial("tcp", addr)
+	case "http", "https":
+		return d.connect(ctx, proxyUrl, addr)
+	default:
+		return nil, fmt.Errorf("invalid proxy: unsupported scheme %q", proxyUrl.Scheme)
+	}
+}
+
+// Open a tunnel to the address through an HTTP proxy
+func (d *Dialer) connect(ctx context.Context, proxyUrl *url.URL, addr string) (net.Conn, error) {
+	port := proxyUrl.Port()
+	if port == "" {
+		port = "80"
+		if proxyUrl.Scheme == "https" {
+			port = "443"
+		}
+	}
+	conn, err := d.dial(ctx, "tcp", net.JoinHostPort(proxyUrl.Hostname(), port))
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if proxyUrl.Scheme == "https" {
+		conn = tls.Client(conn, d.config(proxyUrl.Hostname()))
+	}
+	req := &http.Request{Method: http.MethodConnect, URL: &url.URL{Opaque: addr}, Host: addr, Header: make(http.Header)}
+	if proxyUrl.User != nil {
+		password, _ := proxyUrl.User.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+
+			base64.StdEncoding.EncodeToString([]byte(proxyUrl.User.Username()+":"+password)))
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// Nothing comes after the response until the caller writes to the target, so the reader buffers nothing else
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused the tunnel to %s: %s", addr, resp.Status)
+	}
+	return conn, nil
+}
+
+// Forwards the dials of a SOCKS5 proxy to the transport's dial
+type contextDialer func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func (d contextDialer) Dial(network, addr string) (net.Conn, error) {
+	return d(context.Background(), network, addr)
+}
+
+func (d contextDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return d(ctx, network, addr)
+}
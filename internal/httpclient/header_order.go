@@ -0,0 +1,131 @@
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+)
+
+// Sends HTTP/1.1 requests with their headers in a given order. The standard transport always writes them sorted
+// by name, which some WAFs and CDNs tell apart from browsers. Every request opens a connection of its own.
+type orderedTransport struct {
+	order     []string
+	tlsConfig *tls.Config
+	dial      func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+func (t *orderedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Written to a buffer first, so the body is framed the way the standard transport frames it
+	var message bytes.Buffer
+	if err := req.Write(&message); err != nil {
+		return nil, err
+	}
+
+	ctx := req.Context()
+	trace := httptrace.ContextClientTrace(ctx)
+	port := req.URL.Port()
+	if port == "" {
+		port = "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	conn, err := t.dial(ctx, "tcp", net.JoinHostPort(req.URL.Hostname(), port))
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	var state *tls.ConnectionState
+	if req.URL.Scheme == "https" {
+		config := t.tlsConfig.Clone()
+		if config.ServerName == "" {
+			config.ServerName = req.URL.Hostname()
+		}
+		config.NextProtos = []string{"http/1.1"}
+		tlsConn := tls.Client(conn, config)
+		if trace != nil && trace.TLSHandshakeStart != nil {
+			trace.TLSHandshakeStart()
+		}
+		err := tlsConn.Handshake()
+		connectionState := tlsConn.ConnectionState()
+		if trace != nil && trace.TLSHandshakeDone != nil {
+			trace.TLSHandshakeDone(connectionState, err)
+		}
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		state, conn = &connectionState, tlsConn
+	}
+
+	if _, err := conn.Write(orderHeaders(message.Bytes(), t.order)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	if _, err := reader.Peek(1); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if trace != nil && trace.GotFirstResponseByte != nil {
+		trace.GotFirstResponseByte()
+	}
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.TLS = state
+	resp.Body = &connBody{ReadCloser: resp.Body, conn: conn}
+	return resp, nil
+}
+
+// Closes the connection along with the body
+type connBody struct {
+	io.ReadCloser
+	conn net.Conn
+}
+
+func (b *connBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.conn.Close()
+	return err
+}
+
+// Move the header lines of a written request into the given order. Headers that are not listed keep their
+// order, after the listed ones.
+func orderHeaders(message []byte, order []string) []byte {
+	end := bytes.Index(message, []byte("\r\n\r\n"))
+	if end < 0 {
+		return message
+	}
+	lines := strings.Split(string(message[:end]), "\r\n")
+	rank := func(line string) int {
+		name := line
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			name = line[:i]
+		}
+		for i, ordered := range order {
+			if strings.EqualFold(name, ordered) {
+				return i
+			}
+		}
+		return len(order)
+	}
+	headers := lines[1:]
+	sort.SliceStable(headers, func(i, j int) bool {
+		return rank(headers[i]) < rank(headers[j])
+	})
+	ordered := []byte(strings.Join(lines, "\r\n"))
+	return append(ordered, message[end:]...)
+}
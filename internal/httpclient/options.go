@@ -0,0 +1,154 @@
+package httpclient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/proxyproto"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Connection settings for requests that cannot use the shared client
+type Options struct {
+	// TLS settings. Only set fields are applied.
+	TLSMinVersion       uint16
+	TLSMaxVersion       uint16
+	TLSCipherSuites     []uint16
+	TLSCurvePreferences []tls.CurveID
+	TLSNextProtos       []string
+
+	// Accept any server certificate
+	TLSInsecureSkipVerify bool
+	// The name sent in SNI and checked against the certificate, instead of the URL's host
+	TLSServerName string
+	// PEM certificates to verify servers with instead of the system roots
+	TLSRootCAs string
+	// A PEM certificate and key to present to servers that ask for one
+	TLSClientCertificate string
+	TLSClientKey         string
+
+	// Send a PROXY protocol header at the start of every connection
+	ProxyProtocol proxyproto.Version
+
+	// Send requests through this HTTP, HTTPS or SOCKS5 proxy instead of the one in the environment
+	Proxy string
+
+	// Write the headers of HTTP/1.1 requests in this order, by name. Does not apply with a proxy.
+	HeaderOrder []string
+}
+
+// The TLS settings as a config, for connections that are dialed by hand
+func (o Options) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         o.TLSMinVersion,
+		MaxVersion:         o.TLSMaxVersion,
+		CipherSuites:       o.TLSCipherSuites,
+		CurvePreferences:   o.TLSCurvePreferences,
+		NextProtos:         o.TLSNextProtos,
+		InsecureSkipVerify: o.TLSInsecureSkipVerify,
+		ServerName:         o.TLSServerName,
+	}
+	if o.TLSRootCAs != "" {
+		// Certificates are checked when they are loaded, a bundle without any verifies nothing
+		config.RootCAs = x509.NewCertPool()
+		config.RootCAs.AppendCertsFromPEM([]byte(o.TLSRootCAs))
+	}
+	if o.TLSClientCertificate != "" {
+		pair, err := tls.X509KeyPair([]byte(o.TLSClientCertificate), []byte(o.TLSClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+	Restrict(config)
+	return config, nil
+}
+
+// Clients are cached by their options, so requests with the same settings share connections. Certificates and
+// keys are hashed, so the key does not hold them.
+func (o Options) key() string {
+	hash := func(pem string) string {
+		if pem == "" {
+			return ""
+		}
+		sum := sha256.Sum256([]byte(pem))
+		return hex.EncodeToString(sum[:])
+	}
+	o.TLSRootCAs, o.TLSClientCertificate, o.TLSClientKey = hash(o.TLSRootCAs), hash(o.TLSClientCertificate), hash(o.TLSClientKey)
+	return fmt.Sprintf("%t %+v", StrictCrypto(), o)
+}
+
+// Clients that are not used for this long are dropped, so rotated certificates and removed monitors do not keep
+// theirs forever. Periods are usually much shorter, and a dropped client is only rebuilt.
+const clientIdleTimeout = 6 * time.Hour
+
+type cachedClient struct {
+	client   *http.Client
+	lastUsed time.Time
+}
+
+var (
+	clientsLock sync.Mutex
+	clients     = make(map[string]*cachedClient)
+)
+
+// A client with the given settings, and the same timeout as the shared client
+func GetClientWithOptions(o Options) (*http.Client, error) {
+	clientsLock.Lock()
+	defer clientsLock.Unlock()
+
+	now := time.Now()
+	for key, cached := range clients {
+		if now.Sub(cached.lastUsed) > clientIdleTimeout {
+			cached.client.CloseIdleConnections()
+			delete(clients, key)
+		}
+	}
+	key := o.key()
+	if cached, ok := clients[key]; ok {
+		cached.lastUsed = now
+		return cached.client, nil
+	}
+
+	tlsConfig, err := o.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	// A custom TLS config turns off HTTP/2 unless it is asked for
+	for _, proto := range o.TLSNextProtos {
+		if proto == "h2" {
+			transport.ForceAttemptHTTP2 = true
+		}
+	}
+
+	if o.ProxyProtocol != "" {
+		transport.DialContext = proxyproto.DialContext(o.ProxyProtocol, transport.DialContext)
+	}
+	if o.Proxy != "" {
+		proxy, err := url.Parse(o.Proxy)
+		// Requests fail with a proxy that cannot be used, rather than going around it
+		transport.Proxy = func(*http.Request) (*url.URL, error) {
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy: %w", err)
+			}
+			return proxy, nil
+		}
+	}
+
+	client := &http.Client{Transport: transport}
+	if len(o.HeaderOrder) > 0 && o.Proxy == "" {
+		client.Transport = &orderedTransport{order: o.HeaderOrder, tlsConfig: tlsConfig, dial: transport.DialContext}
+	}
+	if httpClient != nil {
+		client.Timeout = httpClient.Timeout
+	}
+	clients[key] = &cachedClient{client: client, lastUsed: now}
+	return client, nil
+}
@@ -0,0 +1,79 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync/atomic"
+)
+
+// FIPS 140-2 approved suites: ECDHE key exchange with AES-GCM. TLS 1.3 suites cannot be restricted, so strict
+// connections stay on TLS 1.2.
+var strictCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// The NIST curves. X25519 is not approved.
+var strictCurves = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+
+// 1 in strict crypto mode
+var strictCrypto int32
+
+// Restrict every TLS connection the controller makes to FIPS-approved versions, suites and curves, and always
+// verify certificates, whatever a monitor asks for. Set before any client is used.
+func SetStrictCrypto(enabled bool) {
+	if !enabled {
+		atomic.StoreInt32(&strictCrypto, 0)
+		http.DefaultTransport.(*http.Transport).TLSClientConfig = nil
+		return
+	}
+	atomic.StoreInt32(&strictCrypto, 1)
+	// Used by the shared client, notifications and everything else without a transport of its own
+	config := &tls.Config{}
+	Restrict(config)
+	http.DefaultTransport.(*http.Transport).TLSClientConfig = config
+}
+
+// Whether the controller is in strict crypto mode
+func StrictCrypto() bool {
+	return atomic.LoadInt32(&strictCrypto) == 1
+}
+
+// Tighten config in strict crypto mode. Suites and curves it already limits are narrowed to the approved ones,
+// and replaced by all of them if none are left.
+func Restrict(config *tls.Config) {
+	if !StrictCrypto() {
+		return
+	}
+	config.MinVersion = tls.VersionTLS12
+	config.MaxVersion = tls.VersionTLS12
+	config.InsecureSkipVerify = false
+
+	suites := make([]uint16, 0, len(strictCipherSuites))
+	for _, suite := range config.CipherSuites {
+		for _, approved := range strictCipherSuites {
+			if suite == approved {
+				suites = append(suites, suite)
+			}
+		}
+	}
+	if len(suites) == 0 {
+		suites = strictCipherSuites
+	}
+	config.CipherSuites = suites
+
+	curves := make([]tls.CurveID, 0, len(strictCurves))
+	for _, curve := range config.CurvePreferences {
+		for _, approved := range strictCurves {
+			if curve == approved {
+				curves = append(curves, curve)
+			}
+		}
+	}
+	if len(curves) == 0 {
+		curves = strictCurves
+	}
+	config.CurvePreferences = curves
+}
@@ -0,0 +1,401 @@
+// Package jsonschema validates JSON documents against the commonly used subset of JSON Schema: type, enum,
+// const, the numeric, string, array and object keywords, allOf/anyOf/oneOf/not and $ref within the same
+// document. Annotations like title and format are ignored, as are keywords it does not know. Patterns use Go
+// regular expression syntax.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	escapePointer   = strings.NewReplacer("~", "~0", "/", "~1").Replace
+	unescapePointer = strings.NewReplacer("~1", "/", "~0", "~").Replace
+)
+
+var knownTypes = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true, "number": true, "string": true, "integer": true,
+}
+
+// A compiled schema
+type Schema struct {
+	// Set for the boolean schemas true and false
+	always *bool
+
+	types []string
+	enum  []interface{}
+	// Set when the schema has a const, which may itself be null
+	hasConst   bool
+	constValue interface{}
+
+	minimum, maximum                   *float64
+	exclusiveMinimum, exclusiveMaximum *float64
+	multipleOf                         *float64
+
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+
+	items                []*Schema
+	itemsTuple           bool
+	additionalItems      *Schema
+	minItems, maxItems   *int
+	uniqueItems          bool
+	properties           map[string]*Schema
+	patternProperties    map[*regexp.Regexp]*Schema
+	additionalProperties *Schema
+	required             []string
+	minProperties        *int
+	maxProperties        *int
+
+	allOf, anyOf, oneOf []*Schema
+	not                 *Schema
+
+	// The schema a $ref points to. It is resolved once the whole document is compiled, since refs may be
+	// recursive.
+	ref       string
+	refSchema *Schema
+}
+
+type compiler struct {
+	root     interface{}
+	compiled map[string]*Schema
+	refs     []*Schema
+}
+
+// Compile parses a schema written as JSON
+func Compile(data []byte) (*Schema, error) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("schema is not valid JSON: %w", err)
+	}
+	c := &compiler{root: root, compiled: make(map[string]*Schema)}
+	schema, err := c.compile(root, "#")
+	if err != nil {
+		return nil, err
+	}
+	// Resolving a ref may compile parts of the document that have refs of their own
+	for i := 0; i < len(c.refs); i++ {
+		target, err := c.resolve(c.refs[i].ref)
+		if err != nil {
+			return nil, err
+		}
+		c.refs[i].refSchema = target
+	}
+	if err := c.checkRefCycles(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// Refs, and allOf, anyOf, oneOf and not, apply to the same value rather than to one inside it. A cycle of
+// them, like {"$ref": "#"}, would validate the same value forever.
+func (c *compiler) checkRefCycles() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*Schema]int)
+	var path []*Schema
+	var visit func(s *Schema) error
+	visit = func(s *Schema) error {
+		switch state[s] {
+		case visited:
+			return nil
+		case visiting:
+			start := len(path) - 1
+			for path[start] != s {
+				start--
+			}
+			// A cycle always goes through a ref, since the rest of the document is a tree
+			for _, in := range path[start:] {
+				if in.ref != "" {
+					return fmt.Errorf("$ref %q refers back to itself without applying to a value inside it", in.ref)
+				}
+			}
+		}
+		state[s] = visiting
+		path = append(path, s)
+		next := append(append(append([]*Schema{s.refSchema, s.not}, s.allOf...), s.anyOf...), s.oneOf...)
+		for _, n := range next {
+			if n == nil {
+				continue
+			}
+			if err := visit(n); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[s] = visited
+		return nil
+	}
+	for _, s := range c.refs {
+		if err := visit(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *compiler) resolve(ref string) (*Schema, error) {
+	if s, ok := c.compiled[ref]; ok {
+		return s, nil
+	}
+	if ref != "#" && !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("$ref %q: only references within the schema, like #/definitions/item, are supported", ref)
+	}
+	value := c.root
+	var pieces []string
+	if ref != "#" {
+		pieces = strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+	}
+	for _, piece := range pieces {
+		piece = unescapePointer(piece)
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[piece]
+			if !ok {
+				return nil, fmt.Errorf("$ref %q does not point to anything in the schema", ref)
+			}
+			value = next
+		case []interface{}:
+			i, err := strconv.Atoi(piece)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("$ref %q does not point to anything in the schema", ref)
+			}
+			value = v[i]
+		default:
+			return nil, fmt.Errorf("$ref %q does not point to anything in the schema", ref)
+		}
+	}
+	return c.compile(value, ref)
+}
+
+func (c *compiler) compile(value interface{}, location string) (*Schema, error) {
+	if s, ok := c.compiled[location]; ok {
+		return s, nil
+	}
+	s := &Schema{}
+	c.compiled[location] = s
+	if b, ok := value.(bool); ok {
+		s.always = &b
+		return s, nil
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: a schema must be an object or a boolean", location)
+	}
+	fail := func(keyword, format string, args ...interface{}) error {
+		return fmt.Errorf("%s/%s: %s", location, keyword, fmt.Sprintf(format, args...))
+	}
+	sub := func(keyword string) (*Schema, error) {
+		v, ok := obj[keyword]
+		if !ok {
+			return nil, nil
+		}
+		return c.compile(v, location+"/"+keyword)
+	}
+	subList := func(keyword string) ([]*Schema, error) {
+		v, ok := obj[keyword]
+		if !ok {
+			return nil, nil
+		}
+		list, ok := v.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, fail(keyword, "must be a non-empty array of schemas")
+		}
+		schemas := make([]*Schema, len(list))
+		for i, item := range list {
+			compiled, err := c.compile(item, fmt.Sprintf("%s/%s/%d", location, keyword, i))
+			if err != nil {
+				return nil, err
+			}
+			schemas[i] = compiled
+		}
+		return schemas, nil
+	}
+	number := func(keyword string) (*float64, error) {
+		v, ok := obj[keyword]
+		if !ok {
+			return nil, nil
+		}
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fail(keyword, "must be a number")
+		}
+		return &f, nil
+	}
+	count := func(keyword string) (*int, error) {
+		f, err := number(keyword)
+		if err != nil || f == nil {
+			return nil, err
+		}
+		if *f < 0 || *f != float64(int(*f)) {
+			return nil, fail(keyword, "must be a non-negative integer")
+		}
+		i := int(*f)
+		return &i, nil
+	}
+	pattern := func(keyword, expr string) (*regexp.Regexp, error) {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fail(keyword, "invalid pattern %q: %s", expr, err)
+		}
+		return re, nil
+	}
+
+	if ref, ok := obj["$ref"]; ok {
+		text, ok := ref.(string)
+		if !ok {
+			return nil, fail("$ref", "must be a string")
+		}
+		s.ref = text
+		c.refs = append(c.refs, s)
+	}
+
+	switch t := obj["type"].(type) {
+	case nil:
+	case string:
+		s.types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			name, _ := item.(string)
+			s.types = append(s.types, name)
+		}
+	default:
+		return nil, fail("type", "must be a string or an array of strings")
+	}
+	for _, name := range s.types {
+		if !knownTypes[name] {
+			return nil, fail("type", "unknown type %q", name)
+		}
+	}
+
+	if v, ok := obj["enum"]; ok {
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, fail("enum", "must be an array")
+		}
+		s.enum = list
+	}
+	if v, ok := obj["const"]; ok {
+		s.hasConst, s.constValue = true, v
+	}
+
+	var err error
+	for keyword, target := range map[string]**float64{
+		"minimum": &s.minimum, "maximum": &s.maximum, "exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum, "multipleOf": &s.multipleOf,
+	} {
+		if *target, err = number(keyword); err != nil {
+			return nil, err
+		}
+	}
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		return nil, fail("multipleOf", "must be greater than 0")
+	}
+	for keyword, target := range map[string]**int{
+		"minLength": &s.minLength, "maxLength": &s.maxLength, "minItems": &s.minItems, "maxItems": &s.maxItems,
+		"minProperties": &s.minProperties, "maxProperties": &s.maxProperties,
+	} {
+		if *target, err = count(keyword); err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok := obj["pattern"]; ok {
+		expr, ok := v.(string)
+		if !ok {
+			return nil, fail("pattern", "must be a string")
+		}
+		if s.pattern, err = pattern("pattern", expr); err != nil {
+			return nil, err
+		}
+	}
+
+	switch obj["items"].(type) {
+	case nil:
+	case []interface{}:
+		s.itemsTuple = true
+		if s.items, err = subList("items"); err != nil {
+			return nil, err
+		}
+	default:
+		item, err := sub("items")
+		if err != nil {
+			return nil, err
+		}
+		s.items = []*Schema{item}
+	}
+	if s.additionalItems, err = sub("additionalItems"); err != nil {
+		return nil, err
+	}
+	if v, ok := obj["uniqueItems"]; ok {
+		if s.uniqueItems, ok = v.(bool); !ok {
+			return nil, fail("uniqueItems", "must be a boolean")
+		}
+	}
+
+	if v, ok := obj["properties"]; ok {
+		props, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fail("properties", "must be an object")
+		}
+		s.properties = make(map[string]*Schema, len(props))
+		for name, prop := range props {
+			if s.properties[name], err = c.compile(prop, location+"/properties/"+escapePointer(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if v, ok := obj["patternProperties"]; ok {
+		props, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fail("patternProperties", "must be an object")
+		}
+		s.patternProperties = make(map[*regexp.Regexp]*Schema, len(props))
+		for expr, prop := range props {
+			re, err := pattern("patternProperties", expr)
+			if err != nil {
+				return nil, err
+			}
+			if s.patternProperties[re], err = c.compile(prop, location+"/patternProperties/"+escapePointer(expr)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if s.additionalProperties, err = sub("additionalProperties"); err != nil {
+		return nil, err
+	}
+	if v, ok := obj["required"]; ok {
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, fail("required", "must be an array of strings")
+		}
+		for _, item := range list {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fail("required", "must be an array of strings")
+			}
+			s.required = append(s.required, name)
+		}
+	}
+
+	if s.allOf, err = subList("allOf"); err != nil {
+		return nil, err
+	}
+	if s.anyOf, err = subList("anyOf"); err != nil {
+		return nil, err
+	}
+	if s.oneOf, err = subList("oneOf"); err != nil {
+		return nil, err
+	}
+	if s.not, err = sub("not"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
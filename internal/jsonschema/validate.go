@@ -0,0 +1,250 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// How many problems an error message lists before summarizing the rest
+const maxReported = 3
+
+// The ways a document does not match a schema. Each problem starts with the JSON pointer of the value it is
+// about, like /items/0/id.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) <= maxReported {
+		return strings.Join(e.Problems, "; ")
+	}
+	return fmt.Sprintf("%s; and %d more", strings.Join(e.Problems[:maxReported], "; "), len(e.Problems)-maxReported)
+}
+
+// Validate checks a JSON document against the schema. Documents that do not match return a *ValidationError.
+func (s *Schema) Validate(data []byte) error {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("not valid JSON: %w", err)
+	}
+	if problems := s.validate(doc, ""); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func (s *Schema) validate(value interface{}, path string) []string {
+	at := path
+	if at == "" {
+		at = "/"
+	}
+	var problems []string
+	fail := func(format string, args ...interface{}) {
+		problems = append(problems, at+": "+fmt.Sprintf(format, args...))
+	}
+
+	if s.always != nil {
+		if !*s.always {
+			fail("no value is allowed here")
+		}
+		return problems
+	}
+	if s.refSchema != nil {
+		problems = append(problems, s.refSchema.validate(value, path)...)
+	}
+
+	if len(s.types) > 0 && !s.hasType(value) {
+		fail("expected %s, got %s", strings.Join(s.types, " or "), typeOf(value))
+		// The other keywords would only repeat the problem
+		return problems
+	}
+	if s.enum != nil && !contains(s.enum, value) {
+		fail("%s is not one of the allowed values", describe(value))
+	}
+	if s.hasConst && !reflect.DeepEqual(value, s.constValue) {
+		fail("%s, expected %s", describe(value), describe(s.constValue))
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			fail("%v is less than the minimum of %v", v, *s.minimum)
+		}
+		if s.maximum != nil && v > *s.maximum {
+			fail("%v is more than the maximum of %v", v, *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && v <= *s.exclusiveMinimum {
+			fail("%v is not more than %v", v, *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && v >= *s.exclusiveMaximum {
+			fail("%v is not less than %v", v, *s.exclusiveMaximum)
+		}
+		if s.multipleOf != nil {
+			if q := v / *s.multipleOf; q != math.Trunc(q) {
+				fail("%v is not a multiple of %v", v, *s.multipleOf)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.minLength != nil && length < *s.minLength {
+			fail("has a length of %d, expected at least %d", length, *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			fail("has a length of %d, expected at most %d", length, *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("%q does not match %q", v, s.pattern)
+		}
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			fail("has %d items, expected at least %d", len(v), *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			fail("has %d items, expected at most %d", len(v), *s.maxItems)
+		}
+		if s.uniqueItems {
+			for i := range v {
+				for j := i + 1; j < len(v); j++ {
+					if reflect.DeepEqual(v[i], v[j]) {
+						fail("items %d and %d are the same", i, j)
+					}
+				}
+			}
+		}
+		for i, item := range v {
+			var itemSchema *Schema
+			switch {
+			case !s.itemsTuple && len(s.items) == 1:
+				itemSchema = s.items[0]
+			case s.itemsTuple && i < len(s.items):
+				itemSchema = s.items[i]
+			case s.itemsTuple:
+				itemSchema = s.additionalItems
+			}
+			if itemSchema != nil {
+				problems = append(problems, itemSchema.validate(item, fmt.Sprintf("%s/%d", path, i))...)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		if s.minProperties != nil && len(v) < *s.minProperties {
+			fail("has %d properties, expected at least %d", len(v), *s.minProperties)
+		}
+		if s.maxProperties != nil && len(v) > *s.maxProperties {
+			fail("has %d properties, expected at most %d", len(v), *s.maxProperties)
+		}
+		// Sorted, so the same document always reports the same problems first
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			childPath := path + "/" + escapePointer(name)
+			matched := false
+			if prop, ok := s.properties[name]; ok {
+				matched = true
+				problems = append(problems, prop.validate(v[name], childPath)...)
+			}
+			for re, prop := range s.patternProperties {
+				if re.MatchString(name) {
+					matched = true
+					problems = append(problems, prop.validate(v[name], childPath)...)
+				}
+			}
+			if !matched && s.additionalProperties != nil {
+				if s.additionalProperties.always != nil && !*s.additionalProperties.always {
+					fail("property %q is not allowed", name)
+				} else {
+					problems = append(problems, s.additionalProperties.validate(v[name], childPath)...)
+				}
+			}
+		}
+	}
+
+	for _, sub := range s.allOf {
+		problems = append(problems, sub.validate(value, path)...)
+	}
+	if len(s.anyOf) > 0 && s.countMatching(s.anyOf, value, path) == 0 {
+		fail("does not match any of the anyOf schemas")
+	}
+	if len(s.oneOf) > 0 {
+		if n := s.countMatching(s.oneOf, value, path); n != 1 {
+			fail("matches %d of the oneOf schemas, expected exactly 1", n)
+		}
+	}
+	if s.not != nil && len(s.not.validate(value, path)) == 0 {
+		fail("matches a schema it must not")
+	}
+	return problems
+}
+
+func (s *Schema) countMatching(schemas []*Schema, value interface{}, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(sub.validate(value, path)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Schema) hasType(value interface{}) bool {
+	actual := typeOf(value)
+	for _, t := range s.types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// The JSON Schema type of a decoded value. Whole numbers are integers, like in the specification.
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+func contains(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// A short form of a value for messages. Arrays and objects are only named, since they can be large.
+func describe(value interface{}) string {
+	switch value.(type) {
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+	text, _ := json.Marshal(value)
+	return string(text)
+}
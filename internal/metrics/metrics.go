@@ -16,6 +16,33 @@ var (
 		Help: "response status totals for each request in a CRD",
 	}, []string{"type", "crd", "requestName", "status"})
 
+	CrdRequestFailureCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "monitor_crd_request_failures_total",
+		Help: "failed requests in a CRD, by failure category",
+	}, []string{"type", "crd", "requestName", "category"})
+
+	CrdRequestRetryCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "monitor_crd_request_retries_total",
+		Help: "retries of requests in a CRD, by the failure that caused them (network_error, timeout or 5xx)",
+	}, []string{"type", "crd", "requestName", "reason"})
+
+	CrdAssertionViolationCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "monitor_crd_assertion_violations_total",
+		Help: "assertions that did not hold for observe-only requests in a CRD",
+	}, []string{"type", "crd", "requestName", "assertion"})
+
+	CrdColdStartHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "monitor_crd_cold_start_seconds",
+		Help:    "how long wake-up requests took for requests in a CRD with a cold start",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+	}, []string{"type", "crd", "requestName"})
+
+	ScheduleDriftHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "monitor_schedule_drift_seconds",
+		Help:    "how long after it was due each run of a CRD started",
+		Buckets: prometheus.ExponentialBuckets(0.001, 4, 9),
+	}, []string{"type", "crd"})
+
 	KnownHttpCrdGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "monitor_http_crd_details",
 		Help: "details for HttpMonitor CRDs",
@@ -25,12 +52,89 @@ var (
 		Name: "monitor_global_var_details",
 		Help: "information about globally accessible variables",
 	}, []string{"key", "value"})
+
+	NotificationCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "monitor_notifications_total",
+		Help: "notifications sent for each sink in a CRD",
+	}, []string{"type", "crd", "sink", "state", "result"})
+
+	MonitorFailingGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitor_failing",
+		Help: "1 for the severity of an ongoing failure of a CRD, 0 for the other severities",
+	}, []string{"type", "crd", "severity"})
+
+	MonitorStateGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitor_state",
+		Help: "1 for the current state of a CRD (Up, Degraded, Down, TargetAbsent, BudgetExceeded or Standby), 0 for the other states",
+	}, []string{"type", "crd", "state"})
+
+	CleanupDebtGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitor_cleanup_debt",
+		Help: "failed cleanup requests waiting to be retried for a CRD",
+	}, []string{"type", "crd"})
+
+	CrdRequestsSentCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "monitor_crd_requests_sent_total",
+		Help: "requests sent by a CRD, including retries, cleanup and soaks",
+	}, []string{"type", "crd"})
+
+	CrdBytesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "monitor_crd_bytes_total",
+		Help: "bytes of request and response bodies transferred by a CRD, by direction (sent or received)",
+	}, []string{"type", "crd", "direction"})
+
+	CrdPageTtfbGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitor_crd_page_ttfb_seconds",
+		Help: "the time to first byte of the last performance audit of a page",
+	}, []string{"type", "crd", "name"})
+
+	CrdPageWeightGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitor_crd_page_weight_bytes",
+		Help: "the bytes a page transferred in its last performance audit, headers included",
+	}, []string{"type", "crd", "name"})
+
+	CrdPageRequestsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitor_crd_page_requests",
+		Help: "the requests a page made in its last performance audit",
+	}, []string{"type", "crd", "name"})
+
+	SnmpValueGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitor_snmp_value",
+		Help: "the last numeric value read for each OID of an SnmpMonitor",
+	}, []string{"crd", "name"})
+
+	ControllerStandbyGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "monitor_controller_standby",
+		Help: "1 while the controller is a standby and sends no requests",
+	})
+
+	RequestBudgetExceededGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitor_request_budget_exceeded",
+		Help: "1 when the monthly limits of a RequestBudget are used up and matching monitors are skipping runs",
+	}, []string{"namespace", "name"})
 )
 
 func init() {
 	metrics.Registry.MustRegister(
 		HttpResponseCounter,
 		CrdHttpResponseCounter,
+		CrdRequestFailureCounter,
+		CrdRequestRetryCounter,
+		CrdAssertionViolationCounter,
+		CrdColdStartHistogram,
+		ScheduleDriftHistogram,
 		KnownHttpCrdGauge,
-		GlobalVarsDetails)
+		GlobalVarsDetails,
+		NotificationCounter,
+		MonitorFailingGauge,
+		MonitorStateGauge,
+		CleanupDebtGauge,
+		CrdRequestsSentCounter,
+		CrdBytesCounter,
+		CrdPageTtfbGauge,
+		CrdPageWeightGauge,
+		CrdPageRequestsGauge,
+		RequestBudgetExceededGauge,
+		SnmpValueGauge,
+		ControllerStandbyGauge)
 }
@@ -0,0 +1,107 @@
+package netdiag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxHops    = 30
+	defaultHopTimeout = time.Second
+	// Traditional traceroute base port. Each hop probes basePort + ttl.
+	defaultBasePort = 33434
+)
+
+var errUnsupported = errors.New("traceroute is not supported on this platform")
+
+// A single step along the network path to a target
+type Hop struct {
+	TTL int
+	// Address of the router or target that replied. Empty if nothing replied in time.
+	Address string
+	RTT     time.Duration
+	// True if this hop is the target itself
+	Reached bool
+}
+
+// The network path to a target, as seen from the controller
+type Report struct {
+	Target  string
+	Address string
+	Hops    []Hop
+}
+
+func (r *Report) String() string {
+	if r == nil {
+		return ""
+	}
+	pieces := make([]string, len(r.Hops))
+	for i, hop := range r.Hops {
+		if hop.Address == "" {
+			pieces[i] = fmt.Sprintf("%d *", hop.TTL)
+		} else {
+			pieces[i] = fmt.Sprintf("%d %s %s", hop.TTL, hop.Address, hop.RTT.Round(time.Microsecond))
+		}
+	}
+	return strings.Join(pieces, ", ")
+}
+
+type Options struct {
+	MaxHops    int
+	HopTimeout time.Duration
+	BasePort   int
+}
+
+func (o *Options) setDefaults() {
+	if o.MaxHops <= 0 {
+		o.MaxHops = defaultMaxHops
+	}
+	if o.HopTimeout <= 0 {
+		o.HopTimeout = defaultHopTimeout
+	}
+	if o.BasePort <= 0 {
+		o.BasePort = defaultBasePort
+	}
+}
+
+// Trace probes the path to host one hop at a time using UDP datagrams with an increasing TTL.
+// It does not need raw sockets, so it works for an unprivileged controller.
+// The trace stops at the target, after MaxHops, or when ctx is done.
+func Trace(ctx context.Context, host string, opts Options) (*Report, error) {
+	opts.setDefaults()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var target net.IP
+	for _, addr := range addrs {
+		if ip4 := addr.IP.To4(); ip4 != nil {
+			target = ip4
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("no IPv4 address found for %s", host)
+	}
+
+	report := &Report{Target: host, Address: target.String()}
+	for ttl := 1; ttl <= opts.MaxHops; ttl++ {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		hop, err := probe(target, opts.BasePort+ttl, ttl, opts.HopTimeout)
+		if err != nil {
+			return report, err
+		}
+		report.Hops = append(report.Hops, hop)
+		if hop.Reached {
+			break
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,90 @@
+package netdiag
+
+import (
+	"net"
+	"syscall"
+	"time"
+)
+
+const (
+	// From linux/errqueue.h and linux/icmp.h
+	soEeOriginICMP      = 2
+	icmpDestUnreachable = 3
+
+	// struct sock_extended_err is followed by the sockaddr_in of the host that sent the ICMP message
+	sockExtendedErrLen = 16
+	sockaddrInLen      = 8
+)
+
+// Send one datagram with the given TTL and wait for whoever answers. With IP_RECVERR set, ICMP errors
+// (time exceeded from routers, port unreachable from the target) are queued on the socket along with the
+// address of the sender.
+func probe(target net.IP, port, ttl int, timeout time.Duration) (Hop, error) {
+	hop := Hop{TTL: ttl}
+
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return hop, err
+	}
+	defer syscall.Close(fd)
+
+	if err := syscall.SetsockoptInt(fd, syscall.IPPROTO_IP, syscall.IP_RECVERR, 1); err != nil {
+		return hop, err
+	}
+	if err := syscall.SetsockoptInt(fd, syscall.IPPROTO_IP, syscall.IP_TTL, ttl); err != nil {
+		return hop, err
+	}
+	tv := syscall.NsecToTimeval(timeout.Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		return hop, err
+	}
+
+	sa := &syscall.SockaddrInet4{Port: port}
+	copy(sa.Addr[:], target.To4())
+	if err := syscall.Connect(fd, sa); err != nil {
+		return hop, err
+	}
+
+	start := time.Now()
+	if _, err := syscall.Write(fd, []byte("monitoring-controller")); err != nil {
+		return hop, err
+	}
+
+	buf := make([]byte, 512)
+	_, _, err = syscall.Recvfrom(fd, buf, 0)
+	hop.RTT = time.Since(start)
+	switch err {
+	case nil:
+		// Something is listening on the port and answered us directly
+		hop.Address = target.String()
+		hop.Reached = true
+		return hop, nil
+	case syscall.EAGAIN:
+		// Nothing replied in time
+		hop.RTT = 0
+		return hop, nil
+	}
+
+	oob := make([]byte, 512)
+	_, oobn, _, _, err := syscall.Recvmsg(fd, buf, oob, syscall.MSG_ERRQUEUE)
+	if err != nil {
+		return hop, err
+	}
+	messages, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return hop, err
+	}
+	for _, m := range messages {
+		if m.Header.Level != syscall.IPPROTO_IP || m.Header.Type != syscall.IP_RECVERR {
+			continue
+		}
+		if len(m.Data) < sockExtendedErrLen+sockaddrInLen || m.Data[4] != soEeOriginICMP {
+			continue
+		}
+		// sockaddr_in: family (2 bytes), port (2 bytes), address (4 bytes)
+		offender := m.Data[sockExtendedErrLen : sockExtendedErrLen+sockaddrInLen]
+		hop.Address = net.IP(offender[4:8]).String()
+		hop.Reached = m.Data[5] == icmpDestUnreachable
+	}
+	return hop, nil
+}
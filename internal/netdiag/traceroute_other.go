@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package netdiag
+
+import (
+	"net"
+	"time"
+)
+
+func probe(target net.IP, port, ttl int, timeout time.Duration) (Hop, error) {
+	return Hop{TTL: ttl}, errUnsupported
+}
@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+const defaultEmailBody = `{{ .Monitor.Namespace }}/{{ .Monitor.Name }} is {{ .State }}.
+{{ if .FailedRequest }}
+Failed request: {{ .FailedRequest }}
+Error: {{ .Error }}
+{{ end }}{{ with .Ownership.Owner }}
+Owner: {{ . }}{{ end }}{{ with .Ownership.RunbookUrl }}
+Runbook: {{ . }}{{ end }}{{ with .Ownership.DashboardUrl }}
+Dashboard: {{ . }}{{ end }}
+`
+
+func sendEmail(sink *v1alpha1.NotificationSink, event *Event, secrets SecretReader) error {
+	email := sink.Email
+
+	subject := []byte(summary(event))
+	if email.Subject != "" {
+		var err error
+		subject, err = renderTemplate(sink.Name+"-subject", email.Subject, event)
+		if err != nil {
+			return err
+		}
+	}
+
+	bodyTemplate := sink.Template
+	if bodyTemplate == "" {
+		bodyTemplate = defaultEmailBody
+	}
+	body, err := renderTemplate(sink.Name, bodyTemplate, event)
+	if err != nil {
+		return err
+	}
+
+	host, _, err := net.SplitHostPort(email.Server)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if email.Username != "" {
+		password := ""
+		if email.PasswordSecretRef != nil {
+			password, err = secrets(email.PasswordSecretRef)
+			if err != nil {
+				return err
+			}
+		}
+		auth = smtp.PlainAuth("", email.Username, password, host)
+	}
+
+	msg := &bytes.Buffer{}
+	fmt.Fprintf(msg, "From: %s\r\n", email.From)
+	fmt.Fprintf(msg, "To: %s\r\n", strings.Join(email.To, ", "))
+	// Templates may render newlines, which would break the header
+	fmt.Fprintf(msg, "Subject: %s\r\n", strings.Join(strings.Fields(string(subject)), " "))
+	fmt.Fprintf(msg, "Date: %s\r\n", event.Time.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.Write(body)
+
+	return sendMail(email.Server, host, auth, email.From, email.To, msg.Bytes())
+}
+
+// Like smtp.SendMail, but bounded by sendTimeout so a stuck server cannot block the runner
+func sendMail(addr, host string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	conn, err := net.DialTimeout("tcp", addr, sendTimeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(sendTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		config := &tls.Config{ServerName: host}
+		httpclient.Restrict(config)
+		if err := c.StartTLS(config); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := c.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type grafanaAnnotation struct {
+	DashboardUid string   `json:"dashboardUID,omitempty"`
+	PanelId      int      `json:"panelId,omitempty"`
+	Time         int64    `json:"time"`
+	TimeEnd      int64    `json:"timeEnd,omitempty"`
+	Tags         []string `json:"tags"`
+	Text         string   `json:"text"`
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func sendGrafana(ctx context.Context, sink *v1alpha1.NotificationSink, event *Event, secrets SecretReader) error {
+	grafana := sink.Grafana
+	token, err := secrets(&grafana.ApiTokenSecretRef)
+	if err != nil {
+		return err
+	}
+
+	annotation := grafanaAnnotation{
+		DashboardUid: grafana.DashboardUid,
+		PanelId:      grafana.PanelId,
+		Time:         toMillis(event.Time),
+		Tags:         append([]string{"monitoring-controller", event.Monitor.Namespace + "/" + event.Monitor.Name, string(event.State)}, grafana.Tags...),
+		Text:         summary(event),
+	}
+	if event.Error != "" {
+		annotation.Text += ": " + event.Error
+	}
+	if event.State == StateRecovered && !event.FailingSince.IsZero() {
+		// A region from the start of the outage until now
+		annotation.Time = toMillis(event.FailingSince)
+		annotation.TimeEnd = toMillis(event.Time)
+	}
+	if sink.Template != "" {
+		text, err := renderTemplate(sink.Name, sink.Template, event)
+		if err != nil {
+			return err
+		}
+		annotation.Text = string(text)
+	}
+
+	body, err := jsoniter.Marshal(annotation)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(grafana.Url, "/")+"/api/annotations", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	return do(ctx, req)
+}
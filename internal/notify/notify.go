@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"io"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+type State string
+
+var (
+	StateFailing   State = "failing"
+	StateRecovered State = "recovered"
+)
+
+const sendTimeout = 10 * time.Second
+
+var httpClient = &http.Client{Timeout: sendTimeout}
+
+// Resolves a Secret key in the monitor's namespace, for sinks that need credentials
+type SecretReader func(ref *corev1.SecretKeySelector) (string, error)
+
+// Everything a notification template has access to
+type Event struct {
+	Monitor *v1alpha1.HttpMonitor
+	State   State
+	Time    time.Time
+	Run     *v1alpha1.RunResult
+
+	// When the outage started
+	FailingSince time.Time
+
+	// Details of the request that failed the run. Empty when recovering.
+	FailedRequest string
+	Error         string
+	Severity      v1alpha1.Severity
+	Category      v1alpha1.FailureCategory
+
+	// Who owns the failed request, or the monitor when recovering
+	Ownership v1alpha1.Ownership
+}
+
+func NewEvent(m *v1alpha1.HttpMonitor, state State, run *v1alpha1.RunResult, failingSince time.Time) *Event {
+	event := &Event{
+		Monitor:      m,
+		State:        state,
+		Time:         time.Now(),
+		Run:          run,
+		FailingSince: failingSince,
+		Ownership:    m.Spec.Ownership,
+	}
+	if failure := run.FirstFailure(); failure != nil {
+		event.FailedRequest = failure.Name
+		event.Error = failure.Err.Error()
+		event.Severity = failure.Severity
+		event.Category = failure.Category
+		event.Ownership = failure.Ownership
+	}
+	return event
+}
+
+var templateFuncs = template.FuncMap{
+	// Encode a value as JSON, so templates can safely embed strings in JSON payloads
+	"json": func(v interface{}) (string, error) {
+		out, err := jsoniter.Marshal(v)
+		return string(out), err
+	},
+}
+
+type defaultPayload struct {
+	Monitor       string `json:"monitor"`
+	State         State  `json:"state"`
+	Time          string `json:"time"`
+	FailedRequest string `json:"failed_request,omitempty"`
+	Error         string `json:"error,omitempty"`
+	Severity      string `json:"severity,omitempty"`
+	Category      string `json:"category,omitempty"`
+	Owner         string `json:"owner,omitempty"`
+	RunbookUrl    string `json:"runbook_url,omitempty"`
+	DashboardUrl  string `json:"dashboard_url,omitempty"`
+}
+
+// Render the payload for a sink, using its template if it has one
+func Render(sink *v1alpha1.NotificationSink, event *Event) ([]byte, error) {
+	if sink.Template == "" {
+		return jsoniter.Marshal(defaultPayload{
+			Monitor:       fmt.Sprintf("%s/%s", event.Monitor.Namespace, event.Monitor.Name),
+			State:         event.State,
+			Time:          event.Time.UTC().Format(time.RFC3339),
+			FailedRequest: event.FailedRequest,
+			Error:         event.Error,
+			Severity:      string(event.Severity),
+			Category:      string(event.Category),
+			Owner:         event.Ownership.Owner,
+			RunbookUrl:    event.Ownership.RunbookUrl,
+			DashboardUrl:  event.Ownership.DashboardUrl,
+		})
+	}
+
+	return renderTemplate(sink.Name, sink.Template, event)
+}
+
+func renderTemplate(name, text string, event *Event) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, event); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// A one line description of the state change
+func summary(event *Event) string {
+	return fmt.Sprintf("HttpMonitor %s/%s is %s", event.Monitor.Namespace, event.Monitor.Name, event.State)
+}
+
+// Send a notification to a sink
+func Send(ctx context.Context, sink *v1alpha1.NotificationSink, event *Event, secrets SecretReader) error {
+	switch {
+	case sink.Webhook != nil:
+		payload, err := Render(sink, event)
+		if err != nil {
+			return err
+		}
+		return sendWebhook(ctx, sink.Webhook, payload)
+	case sink.Opsgenie != nil:
+		return sendOpsgenie(ctx, sink, event, secrets)
+	case sink.Email != nil:
+		return sendEmail(sink, event, secrets)
+	case sink.Grafana != nil:
+		return sendGrafana(ctx, sink, event, secrets)
+	case sink.StatusPage != nil:
+		return sendStatusPage(ctx, sink.StatusPage, event, secrets)
+	case sink.Ticket != nil:
+		return sendTicket(ctx, sink, event, secrets)
+	}
+	return fmt.Errorf("notification sink %s has no destination", sink.Name)
+}
+
+func sendWebhook(ctx context.Context, webhook *v1alpha1.WebhookSink, payload []byte) error {
+	method := webhook.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequest(method, webhook.Url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for key, values := range webhook.Headers {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return do(ctx, req)
+}
+
+// Send the request, treating anything other than a 2xx response as an error
+func do(ctx context.Context, req *http.Request) error {
+	return doJSON(ctx, req, nil)
+}
+
+// Send the request and decode the JSON response into out, unless out is nil
+func doJSON(ctx context.Context, req *http.Request, out interface{}) error {
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return fmt.Errorf("unexpected response status from %s: %d", req.URL.Host, resp.StatusCode)
+	}
+	if out == nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+	return jsoniter.NewDecoder(resp.Body).Decode(out)
+}
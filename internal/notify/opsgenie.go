@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultOpsgenieUrl = "https://api.opsgenie.com"
+
+type opsgenieAlert struct {
+	Message     string   `json:"message"`
+	Alias       string   `json:"alias"`
+	Description string   `json:"description,omitempty"`
+	Priority    string   `json:"priority,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Source      string   `json:"source"`
+
+	// Shown as extra properties on the alert
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// Opsgenie deduplicates alerts by alias, so repeated failures update the same alert and recovery can close it
+func opsgenieAlias(event *Event) string {
+	return fmt.Sprintf("monitoring-controller/%s/%s", event.Monitor.Namespace, event.Monitor.Name)
+}
+
+func sendOpsgenie(ctx context.Context, sink *v1alpha1.NotificationSink, event *Event, secrets SecretReader) error {
+	opsgenie := sink.Opsgenie
+	apiKey, err := secrets(&opsgenie.ApiKeySecretRef)
+	if err != nil {
+		return err
+	}
+	apiUrl := strings.TrimSuffix(opsgenie.ApiUrl, "/")
+	if apiUrl == "" {
+		apiUrl = defaultOpsgenieUrl
+	}
+	alias := opsgenieAlias(event)
+
+	var req *http.Request
+	if event.State == StateRecovered {
+		body := []byte(`{"source":"monitoring-controller","note":"monitor recovered"}`)
+		req, err = http.NewRequest(http.MethodPost,
+			fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", apiUrl, url.PathEscape(alias)),
+			bytes.NewReader(body))
+	} else {
+		alert := opsgenieAlert{
+			Message:     summary(event),
+			Alias:       alias,
+			Description: event.Error,
+			Priority:    opsgenie.Priority,
+			Tags:        opsgenie.Tags,
+			Source:      "monitoring-controller",
+		}
+		for key, value := range map[string]string{
+			"owner":     event.Ownership.Owner,
+			"runbook":   event.Ownership.RunbookUrl,
+			"dashboard": event.Ownership.DashboardUrl,
+		} {
+			if value == "" {
+				continue
+			}
+			if alert.Details == nil {
+				alert.Details = make(map[string]string)
+			}
+			alert.Details[key] = value
+		}
+		if sink.Template != "" {
+			description, err := renderTemplate(sink.Name, sink.Template, event)
+			if err != nil {
+				return err
+			}
+			alert.Description = string(description)
+		}
+		body, err := jsoniter.Marshal(alert)
+		if err != nil {
+			return err
+		}
+		req, err = http.NewRequest(http.MethodPost, apiUrl+"/v2/alerts", bytes.NewReader(body))
+	}
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+apiKey)
+	return do(ctx, req)
+}
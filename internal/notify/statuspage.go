@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"net/http"
+	"strings"
+)
+
+const (
+	statuspageApiUrl = "https://api.statuspage.io/v1"
+	instatusApiUrl   = "https://api.instatus.com/v1"
+
+	componentOperational = "operational"
+	componentMajorOutage = "major_outage"
+)
+
+// The component status for an event, in statuspage.io terms
+func componentStatus(sink *v1alpha1.StatusPageSink, event *Event) string {
+	if event.State == StateRecovered {
+		return componentOperational
+	}
+	if sink.FailingStatus != "" {
+		return sink.FailingStatus
+	}
+	return componentMajorOutage
+}
+
+func sendStatusPage(ctx context.Context, sink *v1alpha1.StatusPageSink, event *Event, secrets SecretReader) error {
+	apiKey, err := secrets(&sink.ApiKeySecretRef)
+	if err != nil {
+		return err
+	}
+	status := componentStatus(sink, event)
+
+	var req *http.Request
+	switch sink.Provider {
+	case v1alpha1.StatusPageProviderStatuspage:
+		body, err := jsoniter.Marshal(map[string]interface{}{
+			"component": map[string]string{"status": status},
+		})
+		if err != nil {
+			return err
+		}
+		req, err = http.NewRequest(http.MethodPatch,
+			fmt.Sprintf("%s/pages/%s/components/%s", statuspageApiUrl, sink.PageId, sink.ComponentId),
+			bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "OAuth "+apiKey)
+	case v1alpha1.StatusPageProviderInstatus:
+		// Instatus uses the same statuses, spelled like MAJOROUTAGE
+		body, err := jsoniter.Marshal(map[string]string{
+			"status": strings.ToUpper(strings.ReplaceAll(status, "_", "")),
+		})
+		if err != nil {
+			return err
+		}
+		req, err = http.NewRequest(http.MethodPut,
+			fmt.Sprintf("%s/%s/components/%s", instatusApiUrl, sink.PageId, sink.ComponentId),
+			bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	default:
+		return fmt.Errorf("not a known status page provider: %s", sink.Provider)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	return do(ctx, req)
+}
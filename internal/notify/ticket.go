@@ -0,0 +1,229 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultJiraIssueType = "Bug"
+
+	// The ServiceNow incident state for resolved
+	serviceNowResolved = "6"
+)
+
+// Tickets are found again by this reference, stored as a Jira label or a ServiceNow correlation ID, so the
+// controller does not need to remember which ticket belongs to which outage
+func ticketReference(event *Event) string {
+	return fmt.Sprintf("monitoring-controller-%s-%s", event.Monitor.Namespace, event.Monitor.Name)
+}
+
+// The ticket description on open, and the comment added while failing or on recovery
+func ticketText(sink *v1alpha1.NotificationSink, event *Event) (string, error) {
+	if sink.Template == "" {
+		if event.State == StateRecovered {
+			return fmt.Sprintf("%s after failing since %s", summary(event), event.FailingSince.UTC().Format(time.RFC3339)), nil
+		}
+		text := fmt.Sprintf("%s since %s\n\n%s: %s", summary(event), event.FailingSince.UTC().Format(time.RFC3339),
+			event.FailedRequest, event.Error)
+		if ownership := event.Ownership.String(); ownership != "" {
+			text += "\n\n" + ownership
+		}
+		return text, nil
+	}
+	text, err := renderTemplate(sink.Name, sink.Template, event)
+	return string(text), err
+}
+
+// Render templated ticket fields. Values that look like JSON are decoded, so they can be objects.
+func renderFields(name string, fields map[string]string, event *Event) (map[string]interface{}, error) {
+	rendered := make(map[string]interface{}, len(fields))
+	for key, text := range fields {
+		value, err := renderTemplate(name+"/"+key, text, event)
+		if err != nil {
+			return nil, err
+		}
+		trimmed := bytes.TrimSpace(value)
+		var decoded interface{}
+		if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && jsoniter.Unmarshal(trimmed, &decoded) == nil {
+			rendered[key] = decoded
+		} else {
+			rendered[key] = string(value)
+		}
+	}
+	return rendered, nil
+}
+
+type ticketClient struct {
+	sink     *v1alpha1.TicketSink
+	baseUrl  string
+	password string
+}
+
+func (t *ticketClient) request(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := jsoniter.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, t.baseUrl+path, reader)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(t.sink.Username, t.password)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(ctx, req, out)
+}
+
+func sendTicket(ctx context.Context, sink *v1alpha1.NotificationSink, event *Event, secrets SecretReader) error {
+	password, err := secrets(&sink.Ticket.PasswordSecretRef)
+	if err != nil {
+		return err
+	}
+	t := &ticketClient{sink: sink.Ticket, baseUrl: strings.TrimSuffix(sink.Ticket.Url, "/"), password: password}
+	text, err := ticketText(sink, event)
+	if err != nil {
+		return err
+	}
+
+	switch sink.Ticket.Provider {
+	case v1alpha1.TicketProviderJira:
+		return t.sendJira(ctx, sink, event, text)
+	case v1alpha1.TicketProviderServiceNow:
+		return t.sendServiceNow(ctx, sink, event, text)
+	}
+	return fmt.Errorf("not a known ticket provider: %s", sink.Ticket.Provider)
+}
+
+// Failures open an issue, or comment on the one that is already open. Recovery comments and resolves it.
+func (t *ticketClient) sendJira(ctx context.Context, sink *v1alpha1.NotificationSink, event *Event, text string) error {
+	reference := ticketReference(event)
+	var found struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	jql := fmt.Sprintf(`labels = "%s" AND statusCategory != Done ORDER BY created DESC`, reference)
+	err := t.request(ctx, http.MethodGet,
+		"/rest/api/2/search?maxResults=1&fields=key&jql="+url.QueryEscape(jql), nil, &found)
+	if err != nil {
+		return err
+	}
+
+	if len(found.Issues) == 0 {
+		if event.State == StateRecovered {
+			return nil
+		}
+		fields, err := renderFields(sink.Name, t.sink.Fields, event)
+		if err != nil {
+			return err
+		}
+		if err := t.setSummary(sink, event, fields, "summary"); err != nil {
+			return err
+		}
+		issueType := t.sink.IssueType
+		if issueType == "" {
+			issueType = defaultJiraIssueType
+		}
+		fields["project"] = map[string]string{"key": t.sink.Project}
+		fields["issuetype"] = map[string]string{"name": issueType}
+		fields["description"] = text
+		fields["labels"] = []string{reference}
+		return t.request(ctx, http.MethodPost, "/rest/api/2/issue", map[string]interface{}{"fields": fields}, nil)
+	}
+
+	key := found.Issues[0].Key
+	err = t.request(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment",
+		map[string]string{"body": text}, nil)
+	if err != nil || event.State != StateRecovered || t.sink.ResolveTransition == "" {
+		return err
+	}
+	fields, err := renderFields(sink.Name, t.sink.ResolveFields, event)
+	if err != nil {
+		return err
+	}
+	transition := map[string]interface{}{
+		"transition": map[string]string{"id": t.sink.ResolveTransition},
+	}
+	if len(fields) > 0 {
+		transition["fields"] = fields
+	}
+	return t.request(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/transitions", transition, nil)
+}
+
+// Failures open an incident, or add a comment to the active one. Recovery resolves it.
+func (t *ticketClient) sendServiceNow(ctx context.Context, sink *v1alpha1.NotificationSink, event *Event, text string) error {
+	reference := ticketReference(event)
+	var found struct {
+		Result []struct {
+			SysId string `json:"sys_id"`
+		} `json:"result"`
+	}
+	query := url.Values{
+		"sysparm_query":  {fmt.Sprintf("correlation_id=%s^active=true", reference)},
+		"sysparm_fields": {"sys_id"},
+		"sysparm_limit":  {"1"},
+	}
+	if err := t.request(ctx, http.MethodGet, "/api/now/table/incident?"+query.Encode(), nil, &found); err != nil {
+		return err
+	}
+
+	if len(found.Result) == 0 {
+		if event.State == StateRecovered {
+			return nil
+		}
+		fields, err := renderFields(sink.Name, t.sink.Fields, event)
+		if err != nil {
+			return err
+		}
+		if err := t.setSummary(sink, event, fields, "short_description"); err != nil {
+			return err
+		}
+		fields["description"] = text
+		fields["correlation_id"] = reference
+		return t.request(ctx, http.MethodPost, "/api/now/table/incident", fields, nil)
+	}
+
+	path := "/api/now/table/incident/" + url.PathEscape(found.Result[0].SysId)
+	if event.State != StateRecovered {
+		return t.request(ctx, http.MethodPatch, path, map[string]string{"comments": text}, nil)
+	}
+	fields := map[string]interface{}{
+		"state":       serviceNowResolved,
+		"close_code":  "Solved (Permanently)",
+		"close_notes": text,
+	}
+	resolveFields, err := renderFields(sink.Name, t.sink.ResolveFields, event)
+	if err != nil {
+		return err
+	}
+	for key, value := range resolveFields {
+		fields[key] = value
+	}
+	return t.request(ctx, http.MethodPatch, path, fields, nil)
+}
+
+func (t *ticketClient) setSummary(sink *v1alpha1.NotificationSink, event *Event, fields map[string]interface{}, field string) error {
+	if t.sink.Summary == "" {
+		fields[field] = summary(event)
+		return nil
+	}
+	text, err := renderTemplate(sink.Name+"/summary", t.sink.Summary, event)
+	if err != nil {
+		return err
+	}
+	fields[field] = string(text)
+	return nil
+}
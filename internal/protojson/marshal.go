@@ -0,0 +1,292 @@
+package protojson
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Wire types, from the protobuf encoding spec
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// Encode the JSON form of a message to its binary form
+func (r *Registry) Marshal(messageName string, jsonBody []byte) ([]byte, error) {
+	message, err := r.message(messageName)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonBody))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("message body is not a JSON object: %w", err)
+	}
+	return r.encodeMessage(message, object)
+}
+
+func (r *Registry) encodeMessage(message *descriptor.DescriptorProto, object map[string]interface{}) ([]byte, error) {
+	// Encode in a stable order, so the same body always produces the same bytes
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out []byte
+	for _, name := range names {
+		value := object[name]
+		if value == nil {
+			continue
+		}
+		field := fieldByName(message, name)
+		if field == nil {
+			return nil, fmt.Errorf("%s has no field %s", message.GetName(), name)
+		}
+
+		if entry := r.mapEntry(field); entry != nil {
+			entries, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("field %s is a map, expected a JSON object", name)
+			}
+			keys := make([]string, 0, len(entries))
+			for key := range entries {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				encoded, err := r.encodeMessage(entry, map[string]interface{}{
+					jsonName(fieldByNumber(entry, 1)): key,
+					jsonName(fieldByNumber(entry, 2)): entries[key],
+				})
+				if err != nil {
+					return nil, fmt.Errorf("field %s[%s]: %w", name, key, err)
+				}
+				out = appendTag(out, field.GetNumber(), wireBytes)
+				out = appendBytes(out, encoded)
+			}
+			continue
+		}
+
+		values := []interface{}{value}
+		if field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+			list, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("field %s is repeated, expected a JSON array", name)
+			}
+			values = list
+		}
+		for _, v := range values {
+			var err error
+			out, err = r.appendField(out, field, v)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", name, err)
+			}
+		}
+	}
+	return out, nil
+}
+
+func appendTag(out []byte, number int32, wireType int) []byte {
+	return appendVarint(out, uint64(number)<<3|uint64(wireType))
+}
+
+func appendVarint(out []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(out, buf[:n]...)
+}
+
+func appendBytes(out []byte, b []byte) []byte {
+	out = appendVarint(out, uint64(len(b)))
+	return append(out, b...)
+}
+
+func appendFixed32(out []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	return append(out, buf[:]...)
+}
+
+func appendFixed64(out []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(out, buf[:]...)
+}
+
+func (r *Registry) appendField(out []byte, field *descriptor.FieldDescriptorProto, value interface{}) ([]byte, error) {
+	number := field.GetNumber()
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_STRING:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %v", value)
+		}
+		return appendBytes(appendTag(out, number, wireBytes), []byte(s)), nil
+	case descriptor.FieldDescriptorProto_TYPE_BYTES:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a base64 string, got %v", value)
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			if b, err = base64.URLEncoding.DecodeString(s); err != nil {
+				return nil, fmt.Errorf("expected a base64 string: %w", err)
+			}
+		}
+		return appendBytes(appendTag(out, number, wireBytes), b), nil
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a JSON object, got %v", value)
+		}
+		message, err := r.message(field.GetTypeName())
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := r.encodeMessage(message, object)
+		if err != nil {
+			return nil, err
+		}
+		return appendBytes(appendTag(out, number, wireBytes), encoded), nil
+	case descriptor.FieldDescriptorProto_TYPE_BOOL:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected a boolean, got %v", value)
+		}
+		v := uint64(0)
+		if b {
+			v = 1
+		}
+		return appendVarint(appendTag(out, number, wireVarint), v), nil
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		v, err := r.enumNumber(field, value)
+		if err != nil {
+			return nil, err
+		}
+		return appendVarint(appendTag(out, number, wireVarint), uint64(v)), nil
+	case descriptor.FieldDescriptorProto_TYPE_INT32, descriptor.FieldDescriptorProto_TYPE_INT64:
+		v, err := parseInt(value)
+		if err != nil {
+			return nil, err
+		}
+		return appendVarint(appendTag(out, number, wireVarint), uint64(v)), nil
+	case descriptor.FieldDescriptorProto_TYPE_UINT32, descriptor.FieldDescriptorProto_TYPE_UINT64:
+		v, err := parseUint(value)
+		if err != nil {
+			return nil, err
+		}
+		return appendVarint(appendTag(out, number, wireVarint), v), nil
+	case descriptor.FieldDescriptorProto_TYPE_SINT32, descriptor.FieldDescriptorProto_TYPE_SINT64:
+		v, err := parseInt(value)
+		if err != nil {
+			return nil, err
+		}
+		return appendVarint(appendTag(out, number, wireVarint), uint64(v<<1)^uint64(v>>63)), nil
+	case descriptor.FieldDescriptorProto_TYPE_FIXED32, descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+		v, err := parseInt(value)
+		if err != nil {
+			return nil, err
+		}
+		return appendFixed32(appendTag(out, number, wireFixed32), uint32(v)), nil
+	case descriptor.FieldDescriptorProto_TYPE_FIXED64, descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		var v uint64
+		if field.GetType() == descriptor.FieldDescriptorProto_TYPE_FIXED64 {
+			u, err := parseUint(value)
+			if err != nil {
+				return nil, err
+			}
+			v = u
+		} else {
+			i, err := parseInt(value)
+			if err != nil {
+				return nil, err
+			}
+			v = uint64(i)
+		}
+		return appendFixed64(appendTag(out, number, wireFixed64), v), nil
+	case descriptor.FieldDescriptorProto_TYPE_FLOAT:
+		v, err := parseFloat(value)
+		if err != nil {
+			return nil, err
+		}
+		return appendFixed32(appendTag(out, number, wireFixed32), math.Float32bits(float32(v))), nil
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE:
+		v, err := parseFloat(value)
+		if err != nil {
+			return nil, err
+		}
+		return appendFixed64(appendTag(out, number, wireFixed64), math.Float64bits(v)), nil
+	}
+	return nil, fmt.Errorf("unsupported field type %s", field.GetType())
+}
+
+func (r *Registry) enumNumber(field *descriptor.FieldDescriptorProto, value interface{}) (int32, error) {
+	if name, ok := value.(string); ok {
+		enum, err := r.enum(field.GetTypeName())
+		if err != nil {
+			return 0, err
+		}
+		for _, v := range enum.GetValue() {
+			if v.GetName() == name {
+				return v.GetNumber(), nil
+			}
+		}
+		return 0, fmt.Errorf("%s is not a value of %s", name, enum.GetName())
+	}
+	v, err := parseInt(value)
+	return int32(v), err
+}
+
+// 64-bit integers are strings in protobuf JSON, so numbers may come quoted
+func numberText(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.String(), nil
+	case string:
+		return v, nil
+	}
+	return "", fmt.Errorf("expected a number, got %v", value)
+}
+
+func parseInt(value interface{}) (int64, error) {
+	text, err := numberText(value)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(text, 10, 64)
+}
+
+func parseUint(value interface{}) (uint64, error) {
+	text, err := numberText(value)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(text, 10, 64)
+}
+
+func parseFloat(value interface{}) (float64, error) {
+	text, err := numberText(value)
+	if err != nil {
+		return 0, err
+	}
+	switch text {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(text, 64)
+}
@@ -0,0 +1,115 @@
+// Package protojson converts between the JSON form of protobuf messages and their binary encoding, using the
+// message definitions in a FileDescriptorSet instead of generated code.
+//
+// It covers the encoding of scalar, enum, message, repeated and map fields. Well-known types like Timestamp are
+// treated as the plain messages they are rather than their special JSON forms, and groups are not supported.
+package protojson
+
+import (
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"strings"
+)
+
+// The messages and enums defined in a FileDescriptorSet, by fully qualified name without the leading dot
+type Registry struct {
+	messages map[string]*descriptor.DescriptorProto
+	enums    map[string]*descriptor.EnumDescriptorProto
+}
+
+// Parse a binary FileDescriptorSet, as written by `protoc --include_imports --descriptor_set_out`
+func NewRegistry(descriptorSet []byte) (*Registry, error) {
+	set := &descriptor.FileDescriptorSet{}
+	if err := proto.Unmarshal(descriptorSet, set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+	r := &Registry{
+		messages: make(map[string]*descriptor.DescriptorProto),
+		enums:    make(map[string]*descriptor.EnumDescriptorProto),
+	}
+	for _, file := range set.GetFile() {
+		prefix := file.GetPackage()
+		for _, enum := range file.GetEnumType() {
+			r.enums[qualify(prefix, enum.GetName())] = enum
+		}
+		for _, message := range file.GetMessageType() {
+			r.addMessage(prefix, message)
+		}
+	}
+	return r, nil
+}
+
+func qualify(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func (r *Registry) addMessage(prefix string, message *descriptor.DescriptorProto) {
+	name := qualify(prefix, message.GetName())
+	r.messages[name] = message
+	for _, enum := range message.GetEnumType() {
+		r.enums[qualify(name, enum.GetName())] = enum
+	}
+	for _, nested := range message.GetNestedType() {
+		r.addMessage(name, nested)
+	}
+}
+
+func (r *Registry) message(name string) (*descriptor.DescriptorProto, error) {
+	message, ok := r.messages[strings.TrimPrefix(name, ".")]
+	if !ok {
+		return nil, fmt.Errorf("message %s is not in the descriptor set", strings.TrimPrefix(name, "."))
+	}
+	return message, nil
+}
+
+func (r *Registry) enum(name string) (*descriptor.EnumDescriptorProto, error) {
+	enum, ok := r.enums[strings.TrimPrefix(name, ".")]
+	if !ok {
+		return nil, fmt.Errorf("enum %s is not in the descriptor set", strings.TrimPrefix(name, "."))
+	}
+	return enum, nil
+}
+
+// The map entry message of a map field, or nil if the field is not a map
+func (r *Registry) mapEntry(field *descriptor.FieldDescriptorProto) *descriptor.DescriptorProto {
+	if field.GetType() != descriptor.FieldDescriptorProto_TYPE_MESSAGE ||
+		field.GetLabel() != descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		return nil
+	}
+	entry, err := r.message(field.GetTypeName())
+	if err != nil || !entry.GetOptions().GetMapEntry() {
+		return nil
+	}
+	return entry
+}
+
+// The name of a field in JSON. protoc fills in json_name, but hand-written descriptor sets may not.
+func jsonName(field *descriptor.FieldDescriptorProto) string {
+	if field.GetJsonName() != "" {
+		return field.GetJsonName()
+	}
+	return field.GetName()
+}
+
+func fieldByNumber(message *descriptor.DescriptorProto, number int32) *descriptor.FieldDescriptorProto {
+	for _, field := range message.GetField() {
+		if field.GetNumber() == number {
+			return field
+		}
+	}
+	return nil
+}
+
+// Fields can be named by their JSON name or their name in the .proto file, like jsonpb accepts
+func fieldByName(message *descriptor.DescriptorProto, name string) *descriptor.FieldDescriptorProto {
+	for _, field := range message.GetField() {
+		if jsonName(field) == name || field.GetName() == name {
+			return field
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package protojson
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"math"
+	"strconv"
+)
+
+var errTruncated = errors.New("message is truncated")
+
+// Decode the binary form of a message to its JSON form. Fields that are not in the descriptor set are dropped.
+func (r *Registry) Unmarshal(messageName string, data []byte) ([]byte, error) {
+	message, err := r.message(messageName)
+	if err != nil {
+		return nil, err
+	}
+	object, err := r.decodeMessage(message, data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(object)
+}
+
+// Reads the fields of one message
+type reader struct {
+	data []byte
+}
+
+func (rd *reader) varint() (uint64, error) {
+	v, n := binary.Uvarint(rd.data)
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	rd.data = rd.data[n:]
+	return v, nil
+}
+
+func (rd *reader) fixed(size int) (uint64, error) {
+	if len(rd.data) < size {
+		return 0, errTruncated
+	}
+	var v uint64
+	if size == 4 {
+		v = uint64(binary.LittleEndian.Uint32(rd.data))
+	} else {
+		v = binary.LittleEndian.Uint64(rd.data)
+	}
+	rd.data = rd.data[size:]
+	return v, nil
+}
+
+func (rd *reader) bytes() ([]byte, error) {
+	length, err := rd.varint()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(rd.data)) < length {
+		return nil, errTruncated
+	}
+	b := rd.data[:length]
+	rd.data = rd.data[length:]
+	return b, nil
+}
+
+// Read a value of the given wire type. Length-delimited values are returned as bytes, the others as numbers.
+func (rd *reader) value(wireType int) (uint64, []byte, error) {
+	switch wireType {
+	case wireVarint:
+		v, err := rd.varint()
+		return v, nil, err
+	case wireFixed64:
+		v, err := rd.fixed(8)
+		return v, nil, err
+	case wireFixed32:
+		v, err := rd.fixed(4)
+		return v, nil, err
+	case wireBytes:
+		b, err := rd.bytes()
+		return 0, b, err
+	}
+	return 0, nil, fmt.Errorf("unsupported wire type %d", wireType)
+}
+
+func (r *Registry) decodeMessage(message *descriptor.DescriptorProto, data []byte) (map[string]interface{}, error) {
+	object := make(map[string]interface{})
+	rd := &reader{data: data}
+	for len(rd.data) > 0 {
+		key, err := rd.varint()
+		if err != nil {
+			return nil, err
+		}
+		wireType := int(key & 7)
+		number, raw, err := rd.value(wireType)
+		if err != nil {
+			return nil, err
+		}
+		field := fieldByNumber(message, int32(key>>3))
+		if field == nil {
+			continue
+		}
+		name := jsonName(field)
+
+		if entry := r.mapEntry(field); entry != nil {
+			decoded, err := r.decodeMessage(entry, raw)
+			if err != nil {
+				return nil, err
+			}
+			entries, _ := object[name].(map[string]interface{})
+			if entries == nil {
+				entries = make(map[string]interface{})
+				object[name] = entries
+			}
+			entries[fmt.Sprint(decoded[jsonName(fieldByNumber(entry, 1))])] = decoded[jsonName(fieldByNumber(entry, 2))]
+			continue
+		}
+
+		var values []interface{}
+		if wireType == wireBytes && isScalar(field) {
+			// Packed repeated scalars
+			packed := &reader{data: raw}
+			for len(packed.data) > 0 {
+				v, _, err := packed.value(scalarWireType(field))
+				if err != nil {
+					return nil, err
+				}
+				decoded, err := r.decodeScalar(field, v)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, decoded)
+			}
+		} else {
+			decoded, err := r.decodeValue(field, number, raw)
+			if err != nil {
+				return nil, err
+			}
+			values = []interface{}{decoded}
+		}
+
+		if field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+			list, _ := object[name].([]interface{})
+			object[name] = append(list, values...)
+		} else if len(values) > 0 {
+			// The last value wins for singular fields
+			object[name] = values[len(values)-1]
+		}
+	}
+	return object, nil
+}
+
+func isScalar(field *descriptor.FieldDescriptorProto) bool {
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_STRING, descriptor.FieldDescriptorProto_TYPE_BYTES,
+		descriptor.FieldDescriptorProto_TYPE_MESSAGE, descriptor.FieldDescriptorProto_TYPE_GROUP:
+		return false
+	}
+	return true
+}
+
+func scalarWireType(field *descriptor.FieldDescriptorProto) int {
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_FIXED64, descriptor.FieldDescriptorProto_TYPE_SFIXED64,
+		descriptor.FieldDescriptorProto_TYPE_DOUBLE:
+		return wireFixed64
+	case descriptor.FieldDescriptorProto_TYPE_FIXED32, descriptor.FieldDescriptorProto_TYPE_SFIXED32,
+		descriptor.FieldDescriptorProto_TYPE_FLOAT:
+		return wireFixed32
+	}
+	return wireVarint
+}
+
+func (r *Registry) decodeValue(field *descriptor.FieldDescriptorProto, number uint64, raw []byte) (interface{}, error) {
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_STRING:
+		return string(raw), nil
+	case descriptor.FieldDescriptorProto_TYPE_BYTES:
+		return base64.StdEncoding.EncodeToString(raw), nil
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+		message, err := r.message(field.GetTypeName())
+		if err != nil {
+			return nil, err
+		}
+		return r.decodeMessage(message, raw)
+	case descriptor.FieldDescriptorProto_TYPE_GROUP:
+		return nil, errors.New("groups are not supported")
+	}
+	return r.decodeScalar(field, number)
+}
+
+// Scalars as protobuf JSON has them: 64-bit integers are strings, enums are names
+func (r *Registry) decodeScalar(field *descriptor.FieldDescriptorProto, v uint64) (interface{}, error) {
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_BOOL:
+		return v != 0, nil
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		if enum, err := r.enum(field.GetTypeName()); err == nil {
+			for _, value := range enum.GetValue() {
+				if value.GetNumber() == int32(v) {
+					return value.GetName(), nil
+				}
+			}
+		}
+		return int32(v), nil
+	case descriptor.FieldDescriptorProto_TYPE_INT32, descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+		return int32(v), nil
+	case descriptor.FieldDescriptorProto_TYPE_UINT32, descriptor.FieldDescriptorProto_TYPE_FIXED32:
+		return uint32(v), nil
+	case descriptor.FieldDescriptorProto_TYPE_SINT32:
+		return int32(uint32(v)>>1) ^ -int32(v&1), nil
+	case descriptor.FieldDescriptorProto_TYPE_INT64, descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		return strconv.FormatInt(int64(v), 10), nil
+	case descriptor.FieldDescriptorProto_TYPE_UINT64, descriptor.FieldDescriptorProto_TYPE_FIXED64:
+		return strconv.FormatUint(v, 10), nil
+	case descriptor.FieldDescriptorProto_TYPE_SINT64:
+		return strconv.FormatInt(int64(v>>1)^-int64(v&1), 10), nil
+	case descriptor.FieldDescriptorProto_TYPE_FLOAT:
+		return formatFloat(float64(math.Float32frombits(uint32(v))), 32), nil
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE:
+		return formatFloat(math.Float64frombits(v), 64), nil
+	}
+	return nil, fmt.Errorf("unsupported field type %s", field.GetType())
+}
+
+// Floats are numbers in the shortest form that round trips at their size, except the special values, which
+// JSON has no numbers for
+func formatFloat(f float64, bitSize int) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, bitSize))
+}
@@ -0,0 +1,91 @@
+// Package proxyproto writes PROXY protocol headers, which load balancers send in front of a connection to tell
+// the backend who the client is. Backends that expect the header reject connections without one, so probes
+// that go to them directly have to send it too.
+package proxyproto
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// Version 1 is the human readable header, version 2 the binary one
+type Version string
+
+const (
+	V1 Version = "v1"
+	V2 Version = "v2"
+)
+
+// Starts every version 2 header
+var v2Signature = []byte{0x0d, 0x0a, 0x0d, 0x0a, 0x00, 0x0d, 0x0a, 0x51, 0x55, 0x49, 0x54, 0x0a}
+
+// Header is the PROXY header for a connection from src to dst. Addresses that are not TCP, or not of the same
+// family, are sent as unknown, which backends treat as a connection from the load balancer itself.
+func Header(version Version, src, dst net.Addr) ([]byte, error) {
+	srcTCP, srcOk := src.(*net.TCPAddr)
+	dstTCP, dstOk := dst.(*net.TCPAddr)
+	family := ""
+	if srcOk && dstOk {
+		switch {
+		case srcTCP.IP.To4() != nil && dstTCP.IP.To4() != nil:
+			family = "TCP4"
+		case srcTCP.IP.To4() == nil && dstTCP.IP.To4() == nil:
+			family = "TCP6"
+		}
+	}
+
+	switch version {
+	case V1:
+		if family == "" {
+			return []byte("PROXY UNKNOWN\r\n"), nil
+		}
+		return []byte(fmt.Sprintf("PROXY %s %s %s %d %d\r\n", family, srcTCP.IP, dstTCP.IP, srcTCP.Port,
+			dstTCP.Port)), nil
+	case V2:
+		header := append([]byte(nil), v2Signature...)
+		var addresses []byte
+		switch family {
+		case "":
+			// A LOCAL command, with no addresses
+			return append(header, 0x20, 0x00, 0x00, 0x00), nil
+		case "TCP4":
+			header = append(header, 0x21, 0x11)
+			addresses = append(append(addresses, srcTCP.IP.To4()...), dstTCP.IP.To4()...)
+		case "TCP6":
+			header = append(header, 0x21, 0x21)
+			addresses = append(append(addresses, srcTCP.IP.To16()...), dstTCP.IP.To16()...)
+		}
+		addresses = binary.BigEndian.AppendUint16(addresses, uint16(srcTCP.Port))
+		addresses = binary.BigEndian.AppendUint16(addresses, uint16(dstTCP.Port))
+		header = binary.BigEndian.AppendUint16(header, uint16(len(addresses)))
+		return append(header, addresses...), nil
+	}
+	return nil, fmt.Errorf("unknown PROXY protocol version %q", version)
+}
+
+// Send the header for a connection that was just opened, before anything else is written to it
+func Write(conn net.Conn, version Version) error {
+	header, err := Header(version, conn.LocalAddr(), conn.RemoteAddr())
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(header)
+	return err
+}
+
+// DialContext wraps a dial function so every connection it opens starts with a PROXY header
+func DialContext(version Version, dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		if err := Write(conn, version); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("sending PROXY header: %w", err)
+		}
+		return conn, nil
+	}
+}
@@ -0,0 +1,71 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/metrics"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+var budgetLogger = ctrl.Log.WithName("runner").WithName("budget")
+
+// Whether a RequestBudget that applies to this monitor is used up for the month. When budgets cannot be read,
+// the run goes ahead.
+func (h *HttpMonitorRunner) budgetExceeded() (string, bool) {
+	ctx := context.Background()
+	logger := budgetLogger.WithValues("namespace", h.Namespace, "name", h.Name)
+
+	budgets := &monitoringraisingthefloororgv1alpha1.RequestBudgetList{}
+	if err := h.client.List(ctx, budgets, client.InNamespace(h.Namespace)); err != nil {
+		logger.Error(err, "failed to list request budgets")
+		return "", false
+	}
+	if len(budgets.Items) == 0 {
+		return "", false
+	}
+	monitors := &monitoringraisingthefloororgv1alpha1.HttpMonitorList{}
+	if err := h.client.List(ctx, monitors, client.InNamespace(h.Namespace)); err != nil {
+		logger.Error(err, "failed to list monitors")
+		return "", false
+	}
+	// The cache can lag behind the usage this runner last recorded
+	for i := range monitors.Items {
+		if monitors.Items[i].Name == h.Name {
+			monitors.Items[i].Status.Usage = h.Status.Usage
+		}
+	}
+
+	month := monitoringraisingthefloororgv1alpha1.UsageMonth(time.Now())
+	for i := range budgets.Items {
+		budget := &budgets.Items[i]
+		matches, err := budget.Matches(h.HttpMonitor)
+		if err != nil {
+			logger.Error(err, "invalid request budget selector", "budget", budget.Name)
+			continue
+		}
+		if !matches {
+			continue
+		}
+		usage, err := budget.UsageOf(monitors.Items, month)
+		if err != nil {
+			logger.Error(err, "invalid request budget selector", "budget", budget.Name)
+			continue
+		}
+		if budget.ExceededBy(usage) {
+			return fmt.Sprintf("request budget %s is used up for %s: %d requests, %d bytes", budget.Name, month,
+				usage.Requests, usage.Bytes), true
+		}
+	}
+	return "", false
+}
+
+// Count what a run sent and received
+func (h *HttpMonitorRunner) recordTrafficMetrics(result *monitoringraisingthefloororgv1alpha1.RunResult) {
+	traffic := result.Traffic()
+	metrics.CrdRequestsSentCounter.WithLabelValues("HttpMonitor/v1alpha1", h.crdLabel()).Add(float64(traffic.Requests))
+	metrics.CrdBytesCounter.WithLabelValues("HttpMonitor/v1alpha1", h.crdLabel(), "sent").Add(float64(traffic.BytesSent))
+	metrics.CrdBytesCounter.WithLabelValues("HttpMonitor/v1alpha1", h.crdLabel(), "received").Add(float64(traffic.BytesReceived))
+}
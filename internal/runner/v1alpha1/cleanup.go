@@ -0,0 +1,113 @@
+package v1alpha1
+
+import (
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/metrics"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"time"
+)
+
+var cleanupLogger = ctrl.Log.WithName("runner").WithName("cleanup")
+
+const (
+	// Give up on a cleanup request after this many attempts across runs
+	cleanupMaxAttempts = 10
+
+	// Retries back off from one period, doubling up to this
+	cleanupMaxBackoff = time.Hour
+)
+
+// A failed cleanup request waiting to be sent again
+type pendingCleanup struct {
+	request      *monitoringraisingthefloororgv1alpha1.HttpRequest
+	attempts     int
+	firstFailure time.Time
+	nextAttempt  time.Time
+	lastError    string
+}
+
+func (p *pendingCleanup) scheduleRetry(period time.Duration, now time.Time) {
+	backoff := period
+	for i := 1; i < p.attempts && backoff < cleanupMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > cleanupMaxBackoff {
+		backoff = cleanupMaxBackoff
+	}
+	p.nextAttempt = now.Add(backoff)
+}
+
+// Queue the cleanup requests that failed during a run
+func (h *HttpMonitorRunner) queueFailedCleanup(result *monitoringraisingthefloororgv1alpha1.RunResult) {
+	now := time.Now()
+	// Every execution of the tick created its own resources
+	for _, execution := range append([]*monitoringraisingthefloororgv1alpha1.RunResult{result}, result.OtherExecutions...) {
+		for _, r := range execution.Cleanup {
+			if r.Err == nil || r.Request == nil {
+				continue
+			}
+			pending := &pendingCleanup{
+				request:      r.Request.DeepCopy(),
+				attempts:     r.Attempts,
+				firstFailure: execution.Start,
+				lastError:    r.Err.Error(),
+			}
+			pending.scheduleRetry(h.Spec.Period.Duration, now)
+			h.cleanupDebt = append(h.cleanupDebt, pending)
+			cleanupLogger.Info("queued failed cleanup request for retry", "namespace", h.Namespace, "name", h.Name,
+				"request", r.Name, "next_attempt", pending.nextAttempt)
+		}
+	}
+}
+
+// Retry the queued cleanup requests that are due, dropping the ones that succeed or run out of attempts.
+// The retries are recorded with the run that just happened, so their traffic is counted.
+func (h *HttpMonitorRunner) retryCleanupDebt(run *monitoringraisingthefloororgv1alpha1.RunResult) {
+	now := time.Now()
+	remaining := h.cleanupDebt[:0]
+	for _, pending := range h.cleanupDebt {
+		if now.Before(pending.nextAttempt) {
+			remaining = append(remaining, pending)
+			continue
+		}
+		logger := cleanupLogger.WithValues("namespace", h.Namespace, "name", h.Name, "request", pending.request.Name)
+		result := h.RetryCleanup(pending.request)
+		run.RetriedCleanup = append(run.RetriedCleanup, result)
+		pending.attempts++
+		if result.Err == nil {
+			logger.Info("retried cleanup request succeeded", "attempts", pending.attempts)
+			continue
+		}
+		if pending.attempts >= cleanupMaxAttempts {
+			logger.Error(result.Err, "giving up on cleanup request", "attempts", pending.attempts)
+			continue
+		}
+		pending.lastError = result.Err.Error()
+		pending.scheduleRetry(h.Spec.Period.Duration, now)
+		remaining = append(remaining, pending)
+	}
+	h.cleanupDebt = remaining
+}
+
+// The cleanup debt, as reported in status
+func (h *HttpMonitorRunner) cleanupDebtStatus() []monitoringraisingthefloororgv1alpha1.PendingCleanup {
+	var debt []monitoringraisingthefloororgv1alpha1.PendingCleanup
+	for _, pending := range h.cleanupDebt {
+		firstFailure := metav1.NewTime(pending.firstFailure)
+		nextAttempt := metav1.NewTime(pending.nextAttempt)
+		debt = append(debt, monitoringraisingthefloororgv1alpha1.PendingCleanup{
+			Name:         pending.request.Name,
+			Url:          pending.request.Url,
+			Attempts:     pending.attempts,
+			FirstFailure: &firstFailure,
+			NextAttempt:  &nextAttempt,
+			LastError:    pending.lastError,
+		})
+	}
+	return debt
+}
+
+func (h *HttpMonitorRunner) recordCleanupDebtGauge() {
+	metrics.CleanupDebtGauge.WithLabelValues("HttpMonitor/v1alpha1", h.crdLabel()).Set(float64(len(h.cleanupDebt)))
+}
@@ -0,0 +1,25 @@
+package v1alpha1
+
+import (
+	"context"
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/secrets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var credentialsLogger = ctrl.Log.WithName("runner").WithName("credentials")
+
+// Read the credentials the requests send again before a run, so rotated Secrets are picked up. Credentials that
+// cannot be read keep their previous values.
+func (h *HttpMonitorRunner) refreshCredentials() {
+	refreshMonitorCredentials(h.client, h.HttpMonitor)
+}
+
+// Same for the monitor of a journey stage, which is read again at the start of every stage
+func refreshMonitorCredentials(c client.Client, monitor *monitoringraisingthefloororgv1alpha1.HttpMonitor) {
+	err := secrets.LoadCredentials(context.Background(), c, monitor.Namespace, &monitor.Spec)
+	if err != nil {
+		credentialsLogger.Error(err, "failed to read credentials", "namespace", monitor.Namespace, "name", monitor.Name)
+	}
+}
@@ -0,0 +1,56 @@
+package v1alpha1
+
+import (
+	"context"
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var diagnosticsLogger = ctrl.Log.WithName("runner").WithName("diagnostics")
+
+func (h *HttpMonitorRunner) diagnosticBundleName() string {
+	return h.Name + "-diagnostics"
+}
+
+// Save a diagnostic bundle for a failed run to a ConfigMap owned by the monitor, and link it from status
+func (h *HttpMonitorRunner) saveDiagnosticBundle(result *monitoringraisingthefloororgv1alpha1.RunResult) {
+	logger := diagnosticsLogger.WithValues("namespace", h.Namespace, "name", h.Name)
+
+	data, err := h.CollectDiagnosticBundle(result)
+	if err != nil {
+		logger.Error(err, "failed to collect diagnostic bundle")
+		return
+	}
+
+	ctx := context.Background()
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      h.diagnosticBundleName(),
+			Namespace: h.Namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, h.client, configMap, func() error {
+		configMap.OwnerReferences = []metav1.OwnerReference{
+			*metav1.NewControllerRef(h.HttpMonitor, monitoringraisingthefloororgv1alpha1.GroupVersion.WithKind("HttpMonitor")),
+		}
+		configMap.Data = data
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "failed to save diagnostic bundle")
+		return
+	}
+
+	monitor := h.HttpMonitor.DeepCopy()
+	patch := client.MergeFrom(monitor.DeepCopy())
+	monitor.Status.DiagnosticBundle = configMap.Name
+	if err := h.client.Status().Patch(ctx, monitor, patch); err != nil {
+		logger.Error(err, "failed to link diagnostic bundle from status")
+		return
+	}
+	logger.Info("saved diagnostic bundle", "configmap", configMap.Name, "consecutive_failures", h.consecutiveFailures)
+}
@@ -0,0 +1,52 @@
+package v1alpha1
+
+import (
+	"fmt"
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"time"
+)
+
+// Reasons of the Events recorded when a monitor or journey changes between passing and failing. Events are only
+// recorded on these transitions, not for every run, so kubectl describe shows when an outage started and ended.
+const (
+	eventReasonFailing   = "Failing"
+	eventReasonRecovered = "Recovered"
+)
+
+// Record that the monitor started failing, with the failed request and who owns it
+func (h *HttpMonitorRunner) recordFailingEvent(result *monitoringraisingthefloororgv1alpha1.RunResult) {
+	failure := result.FirstFailure()
+	if h.recorder == nil || failure == nil {
+		return
+	}
+	h.recorder.Event(h.HttpMonitor, corev1.EventTypeWarning, eventReasonFailing, failureMessage(failure))
+}
+
+// Record that the monitor passed again, with who owns it
+func (h *HttpMonitorRunner) recordRecoveredEvent() {
+	if h.recorder == nil {
+		return
+	}
+	message := fmt.Sprintf("requests succeeded after failing since %s", h.failingSince.UTC().Format(time.RFC3339))
+	if ownership := h.Spec.Ownership.String(); ownership != "" {
+		message = fmt.Sprintf("%s. %s", message, ownership)
+	}
+	h.recorder.Event(h.HttpMonitor, corev1.EventTypeNormal, eventReasonRecovered, message)
+}
+
+// Record a journey that started failing or passed again. A journey that has not run before only gets an Event
+// when it fails.
+func (j *JourneyRunner) recordTransitionEvent(previous, current monitoringraisingthefloororgv1alpha1.JourneyStatus, message string) {
+	if j.recorder == nil {
+		return
+	}
+	wasFailing, failing := previous.FailedStage != "", current.FailedStage != ""
+	switch {
+	case failing && !wasFailing:
+		j.recorder.Event(j.Journey, corev1.EventTypeWarning, eventReasonFailing, message)
+	case !failing && wasFailing:
+		j.recorder.Event(j.Journey, corev1.EventTypeNormal, eventReasonRecovered,
+			fmt.Sprintf("stages succeeded after stage %q failed", previous.FailedStage))
+	}
+}
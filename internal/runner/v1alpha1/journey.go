@@ -0,0 +1,155 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reads the monitor a stage runs, ready to execute
+type StageResolver func(ctx context.Context, namespace, name string) (*monitoringraisingthefloororgv1alpha1.HttpMonitor, error)
+
+type JourneyRunner struct {
+	*monitoringraisingthefloororgv1alpha1.Journey
+	client   client.Client
+	recorder record.EventRecorder
+	resolve  StageResolver
+	schedule *schedule
+	closer   chan bool
+}
+
+func NewJourneyRunner(j *monitoringraisingthefloororgv1alpha1.Journey, c client.Client, recorder record.EventRecorder,
+	resolve StageResolver) *JourneyRunner {
+	return &JourneyRunner{
+		Journey:  j,
+		client:   c,
+		recorder: recorder,
+		resolve:  resolve,
+	}
+}
+
+func (j *JourneyRunner) Start() {
+	if j.schedule != nil {
+		panic("tried to start an already started Journey")
+	}
+
+	period := j.Spec.Period.Duration
+	j.schedule = newSchedule(firstRunDelay(j.crdLabel(), period), period)
+	j.closer = make(chan bool)
+	go func() {
+		for {
+			select {
+			case now := <-j.schedule.C():
+				recordDrift("Journey/v1alpha1", j.crdLabel(), j.schedule.due(now))
+				if Standby() {
+					j.updateSkippedStatus(monitoringraisingthefloororgv1alpha1.MonitorStateStandby, standbyReason)
+					j.recordStateGauges(monitoringraisingthefloororgv1alpha1.MonitorStateStandby)
+					continue
+				}
+				// Stage monitors are read at the start of each stage, so edits to them apply on the next run
+				result := j.Execute(func(name string) (*monitoringraisingthefloororgv1alpha1.HttpMonitor, error) {
+					monitor, err := j.resolve(context.Background(), j.Namespace, name)
+					if err != nil {
+						return nil, err
+					}
+					refreshMonitorCredentials(j.client, monitor)
+					return monitor, nil
+				})
+				j.updateStatus(result)
+				j.recordStateGauges(result.State())
+			case <-j.closer:
+				return
+			}
+		}
+	}()
+}
+
+func (j *JourneyRunner) Stop() {
+	j.closer <- true
+	j.schedule.stop()
+	metrics.ScheduleDriftHistogram.DeleteLabelValues("Journey/v1alpha1", j.crdLabel())
+	for _, s := range monitoringraisingthefloororgv1alpha1.MonitorStates() {
+		metrics.MonitorStateGauge.DeleteLabelValues("Journey/v1alpha1", j.crdLabel(), string(s))
+	}
+}
+
+func (j *JourneyRunner) crdLabel() string {
+	return fmt.Sprintf("%s/%s", j.Namespace, j.Name)
+}
+
+// Record the outcome of a run in the journey status
+func (j *JourneyRunner) updateStatus(result *monitoringraisingthefloororgv1alpha1.JourneyResult) {
+	journey := j.Journey.DeepCopy()
+	patch := client.MergeFrom(journey.DeepCopy())
+
+	executed := metav1.NewTime(result.Start)
+	journey.Status.LastExecution = &executed
+	journey.Status.State = result.State()
+	journey.Status.Stages = result.StageStatus()
+	journey.Status.FailedStage = ""
+	journey.Status.Error = ""
+	condition := monitoringraisingthefloororgv1alpha1.MonitorCondition{
+		Type:               monitoringraisingthefloororgv1alpha1.ConditionHealthy,
+		Status:             corev1.ConditionTrue,
+		Reason:             "StagesSucceeded",
+		LastTransitionTime: executed,
+	}
+	if failure := result.FirstFailure(); failure != nil {
+		journey.Status.LastFailure = &executed
+		journey.Status.FailedStage = failure.Name
+		journey.Status.Error = failure.Failure().Error()
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = string(journey.Status.State)
+		condition.Message = fmt.Sprintf("%s: %s", failure.Name, journey.Status.Error)
+		if failure.Result != nil && failure.Result.FirstFailure() != nil {
+			if ownership := failure.Result.FirstFailure().Ownership.String(); ownership != "" {
+				condition.Message = fmt.Sprintf("%s. %s", condition.Message, ownership)
+			}
+		}
+	}
+	journey.Status.Conditions = monitoringraisingthefloororgv1alpha1.SetCondition(journey.Status.Conditions, condition)
+
+	if err := j.client.Status().Patch(context.Background(), journey, patch); err != nil {
+		statusLogger.Error(err, "failed to update journey status", "namespace", j.Namespace, "name", j.Name)
+		return
+	}
+	j.recordTransitionEvent(j.Status, journey.Status, condition.Message)
+	j.Status = journey.Status
+}
+
+// Record a skipped run in the journey status. Health is unknown, since nothing was checked.
+func (j *JourneyRunner) updateSkippedStatus(state monitoringraisingthefloororgv1alpha1.MonitorState, reason string) {
+	journey := j.Journey.DeepCopy()
+	patch := client.MergeFrom(journey.DeepCopy())
+
+	journey.Status.State = state
+	journey.Status.Conditions = monitoringraisingthefloororgv1alpha1.SetCondition(journey.Status.Conditions,
+		monitoringraisingthefloororgv1alpha1.MonitorCondition{
+			Type:               monitoringraisingthefloororgv1alpha1.ConditionHealthy,
+			Status:             corev1.ConditionUnknown,
+			Reason:             string(state),
+			Message:            reason,
+			LastTransitionTime: metav1.Now(),
+		})
+
+	if err := j.client.Status().Patch(context.Background(), journey, patch); err != nil {
+		statusLogger.Error(err, "failed to update journey status", "namespace", j.Namespace, "name", j.Name)
+		return
+	}
+	j.Status = journey.Status
+}
+
+func (j *JourneyRunner) recordStateGauges(state monitoringraisingthefloororgv1alpha1.MonitorState) {
+	for _, s := range monitoringraisingthefloororgv1alpha1.MonitorStates() {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		metrics.MonitorStateGauge.WithLabelValues("Journey/v1alpha1", j.crdLabel(), string(s)).Set(value)
+	}
+}
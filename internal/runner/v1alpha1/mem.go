@@ -2,6 +2,12 @@ package v1alpha1
 
 var KnownRunners map[string]*HttpMonitorRunner
 
+var KnownJourneyRunners map[string]*JourneyRunner
+
+var KnownSnmpRunners map[string]*SnmpMonitorRunner
+
 func init() {
 	KnownRunners = make(map[string]*HttpMonitorRunner)
+	KnownJourneyRunners = make(map[string]*JourneyRunner)
+	KnownSnmpRunners = make(map[string]*SnmpMonitorRunner)
 }
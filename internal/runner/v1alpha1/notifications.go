@@ -0,0 +1,122 @@
+package v1alpha1
+
+import (
+	"context"
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/metrics"
+	"github.com/oregondesignservices/monitoring-controller/internal/notify"
+	"github.com/oregondesignservices/monitoring-controller/internal/secrets"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+var notificationsLogger = ctrl.Log.WithName("runner").WithName("notifications")
+
+// Read a Secret key from the monitor's namespace
+func (h *HttpMonitorRunner) readSecret(ref *corev1.SecretKeySelector) (string, error) {
+	return secrets.Read(context.Background(), h.client, h.Namespace, ref)
+}
+
+// Whether an active MonitorSilence matches this monitor
+func (h *HttpMonitorRunner) silenced(now time.Time) bool {
+	silences := &monitoringraisingthefloororgv1alpha1.MonitorSilenceList{}
+	if err := h.client.List(context.Background(), silences, client.InNamespace(h.Namespace)); err != nil {
+		notificationsLogger.Error(err, "failed to list silences, notifying anyway", "namespace", h.Namespace, "name", h.Name)
+		return false
+	}
+	for i := range silences.Items {
+		silence := &silences.Items[i]
+		if silence.IsExpired(now) {
+			continue
+		}
+		matches, err := silence.Matches(h.HttpMonitor)
+		if err != nil {
+			notificationsLogger.Error(err, "invalid silence selector", "namespace", silence.Namespace, "silence", silence.Name)
+			continue
+		}
+		if matches {
+			notificationsLogger.V(2).Info("monitor is silenced", "namespace", h.Namespace, "name", h.Name,
+				"silence", silence.Name, "reason", silence.Spec.Reason)
+			return true
+		}
+	}
+	return false
+}
+
+// Decide whether a sink should hear about the current outage right now, taking severity, escalation and repeats
+// into account
+func (h *HttpMonitorRunner) sinkIsDue(sink *monitoringraisingthefloororgv1alpha1.NotificationSink, event *notify.Event, now time.Time) bool {
+	if sink.MinSeverity != "" && !event.Severity.AtLeast(sink.MinSeverity) {
+		return false
+	}
+	if sink.EscalateAfter != nil && now.Sub(h.failingSince) < sink.EscalateAfter.Duration {
+		return false
+	}
+	lastNotified, notified := h.notifiedSinks[sink.Name]
+	if !notified {
+		return true
+	}
+	return sink.RepeatInterval != nil && now.Sub(lastNotified) >= sink.RepeatInterval.Duration
+}
+
+// Notify the sinks that are due about an ongoing failure
+func (h *HttpMonitorRunner) notifyFailing(result *monitoringraisingthefloororgv1alpha1.RunResult) {
+	now := time.Now()
+	event := notify.NewEvent(h.HttpMonitor, notify.StateFailing, result, h.failingSince)
+	silenced := h.silenced(now)
+	for i := range h.Spec.Notifications {
+		sink := &h.Spec.Notifications[i]
+		if !h.sinkIsDue(sink, event, now) {
+			continue
+		}
+		// Silenced sinks are not marked as notified, so they still hear about the outage if it outlasts the silence
+		if silenced {
+			h.recordNotification(sink, event, "silenced")
+			continue
+		}
+		h.send(sink, event)
+		h.notifiedSinks[sink.Name] = now
+	}
+}
+
+// Tell every sink that heard about the outage that it is over
+func (h *HttpMonitorRunner) notifyRecovered(result *monitoringraisingthefloororgv1alpha1.RunResult) {
+	event := notify.NewEvent(h.HttpMonitor, notify.StateRecovered, result, h.failingSince)
+	silenced := h.silenced(time.Now())
+	for i := range h.Spec.Notifications {
+		sink := &h.Spec.Notifications[i]
+		if _, notified := h.notifiedSinks[sink.Name]; !notified {
+			continue
+		}
+		if silenced {
+			h.recordNotification(sink, event, "silenced")
+			continue
+		}
+		h.send(sink, event)
+	}
+	h.notifiedSinks = make(map[string]time.Time)
+}
+
+func (h *HttpMonitorRunner) send(sink *monitoringraisingthefloororgv1alpha1.NotificationSink, event *notify.Event) {
+	logger := notificationsLogger.WithValues("namespace", h.Namespace, "name", h.Name, "state", event.State, "sink", sink.Name)
+
+	outcome := "success"
+	if err := notify.Send(context.Background(), sink, event, h.readSecret); err != nil {
+		outcome = "failure"
+		logger.Error(err, "failed to send notification")
+	} else {
+		logger.V(2).Info("sent notification")
+	}
+	h.recordNotification(sink, event, outcome)
+}
+
+func (h *HttpMonitorRunner) recordNotification(sink *monitoringraisingthefloororgv1alpha1.NotificationSink, event *notify.Event, outcome string) {
+	metrics.NotificationCounter.WithLabelValues(
+		"HttpMonitor/v1alpha1",
+		h.crdLabel(),
+		sink.Name,
+		string(event.State),
+		outcome).Inc()
+}
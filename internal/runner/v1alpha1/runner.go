@@ -2,31 +2,107 @@ package v1alpha1
 
 import (
 	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"time"
 )
 
 type HttpMonitorRunner struct {
 	*monitoringraisingthefloororgv1alpha1.HttpMonitor
-	ticker *time.Ticker
-	closer chan bool
+	client   client.Client
+	recorder record.EventRecorder
+	schedule *schedule
+	closer   chan bool
+
+	// The resource version of the template ConfigMap the spec was resolved from, if it has one
+	TemplateVersion string
+
+	consecutiveFailures int
+	failingSince        time.Time
+
+	// The last time each sink was notified about the current outage
+	notifiedSinks map[string]time.Time
+
+	// Failed cleanup requests, retried on later ticks
+	cleanupDebt []*pendingCleanup
+
+	latency *monitoringraisingthefloororgv1alpha1.LatencyWindow
+
+	// Violations of observe-only assertions, accumulated across runs
+	violations []monitoringraisingthefloororgv1alpha1.AssertionViolation
+
+	// When the variable compared by version_skew started to differ from the other monitor's
+	skewSince time.Time
+
+	// The last signed run record, which the next one is chained to
+	lastRunRecord *monitoringraisingthefloororgv1alpha1.SignedRunRecord
+}
+
+func (h *HttpMonitorRunner) failing() bool {
+	return h.consecutiveFailures > 0
+}
+
+func NewHttpMonitorRunner(m *monitoringraisingthefloororgv1alpha1.HttpMonitor, c client.Client, recorder record.EventRecorder) *HttpMonitorRunner {
+	return &HttpMonitorRunner{
+		HttpMonitor:   m,
+		client:        c,
+		recorder:      recorder,
+		notifiedSinks: make(map[string]time.Time),
+		latency:       monitoringraisingthefloororgv1alpha1.NewLatencyWindow(m.Spec.LatencyWindow),
+		violations:    append([]monitoringraisingthefloororgv1alpha1.AssertionViolation(nil), m.Status.Violations...),
+		lastRunRecord: m.Status.LastRunRecord,
+	}
 }
 
-func NewHttpMonitorRunner(m *monitoringraisingthefloororgv1alpha1.HttpMonitor) *HttpMonitorRunner {
-	return &HttpMonitorRunner{HttpMonitor: m}
+// Take over what the runner this one replaces has learned across runs: queued cleanup requests, recent
+// response times, how long versions have differed and the last run record, which status may not have caught up
+// with. Violations are kept in status, so they carry over on their own.
+func (h *HttpMonitorRunner) InheritState(previous *HttpMonitorRunner) {
+	h.cleanupDebt = append(h.cleanupDebt, previous.cleanupDebt...)
+	h.latency.Merge(previous.latency)
+	h.skewSince = previous.skewSince
+	if previous.lastRunRecord != nil {
+		h.lastRunRecord = previous.lastRunRecord
+	}
 }
 
 func (h *HttpMonitorRunner) Start() {
-	if h.ticker != nil {
+	if h.schedule != nil {
 		panic("tried to start an already started HttpMonitor")
 	}
 
-	h.ticker = time.NewTicker(h.Spec.Period.Duration)
+	period := h.Spec.Period.Duration
+	h.schedule = newSchedule(firstRunDelay(h.crdLabel(), period), period)
 	h.closer = make(chan bool)
 	go func() {
 		for {
 			select {
-			case <-h.ticker.C:
-				h.Execute()
+			case now := <-h.schedule.C():
+				due := h.schedule.due(now)
+				h.recordTick(due)
+				if Standby() {
+					h.skipRun(monitoringraisingthefloororgv1alpha1.MonitorStateStandby, standbyReason)
+					continue
+				}
+				if reason, absent := h.targetAbsent(); absent {
+					h.skipRun(monitoringraisingthefloororgv1alpha1.MonitorStateTargetAbsent, reason)
+					continue
+				}
+				if reason, exceeded := h.budgetExceeded(); exceeded {
+					h.skipRun(monitoringraisingthefloororgv1alpha1.MonitorStateBudgetExceeded, reason)
+					continue
+				}
+				h.refreshCredentials()
+				result := h.ExecuteTick(due)
+				if result == nil {
+					// The other runner reports the tick, so the status and notifications are left alone
+					statusLogger.V(1).Info("skipping run", "namespace", h.Namespace, "name", h.Name,
+						"reason", "another runner of the monitor has claimed the tick")
+					continue
+				}
+				h.retryCleanupDebt(result)
+				h.queueFailedCleanup(result)
+				h.handleResult(result)
 			case <-h.closer:
 				return
 			}
@@ -37,5 +113,47 @@ func (h *HttpMonitorRunner) Start() {
 func (h *HttpMonitorRunner) Stop() {
 	// Stop does not close the channel, so the closer channel handles that.
 	h.closer <- true
-	h.ticker.Stop()
+	h.schedule.stop()
+	h.removeStateGauges()
+	h.removeStats()
+	forgetVariables(h.crdLabel())
+	var others []*monitoringraisingthefloororgv1alpha1.HttpMonitor
+	for _, runner := range KnownRunners {
+		if runner != h {
+			others = append(others, runner.HttpMonitor)
+		}
+	}
+	h.ForgetOAuth2Tokens(others)
+}
+
+func (h *HttpMonitorRunner) handleResult(result *monitoringraisingthefloororgv1alpha1.RunResult) {
+	h.latency.Add(result)
+	h.recordViolations(result)
+	recordVariables(h.crdLabel(), result.Variables)
+	h.updateStatus(result)
+	h.recordStateGauges(result.State(), result.Severity())
+	h.recordCleanupDebtGauge()
+	h.recordTrafficMetrics(result)
+
+	if !result.Failed() {
+		if h.failing() {
+			h.recordRecoveredEvent()
+			h.notifyRecovered(result)
+		}
+		h.consecutiveFailures = 0
+		return
+	}
+	if !h.failing() {
+		h.failingSince = result.Start
+		h.recordFailingEvent(result)
+	}
+	h.consecutiveFailures++
+	h.notifyFailing(result)
+
+	// Only collect once per streak of failures, when the threshold is crossed
+	// Monitors that may not store captured data get no bundle, even when the webhook did not check the spec
+	if d := h.Spec.Diagnostics; d != nil && d.BundleAfterFailures > 0 && h.consecutiveFailures == d.BundleAfterFailures &&
+		h.MayStoreCapturedData() {
+		h.saveDiagnosticBundle(result)
+	}
 }
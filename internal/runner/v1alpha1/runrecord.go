@@ -0,0 +1,38 @@
+package v1alpha1
+
+import (
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/runrecord"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var runRecordLogger = ctrl.Log.WithName("runner").WithName("runrecord")
+
+// Set before the runners start. Nil when run records are not signed.
+var runRecordSigner *runrecord.Signer
+
+// Sign a record of every run with signer. The records are logged, for the log pipeline to archive, and the
+// last one is kept in status.
+func SetRunRecordSigner(signer *runrecord.Signer) {
+	runRecordSigner = signer
+}
+
+// The signed record of the run, or nil when run records are not signed
+func (h *HttpMonitorRunner) signRunRecord(result *monitoringraisingthefloororgv1alpha1.RunResult) *monitoringraisingthefloororgv1alpha1.SignedRunRecord {
+	if runRecordSigner == nil {
+		return nil
+	}
+	record := h.RunRecord(result)
+	if err := record.Follow(h.lastRunRecord); err != nil {
+		runRecordLogger.Error(err, "starting a new chain of run records", "monitor", h.crdLabel())
+	}
+	signed, err := runRecordSigner.Sign(record)
+	if err != nil {
+		runRecordLogger.Error(err, "failed to sign run record", "monitor", h.crdLabel())
+		return nil
+	}
+	h.lastRunRecord = signed
+	runRecordLogger.Info("run record", "monitor", h.crdLabel(), "record", signed.Record,
+		"signature", signed.Signature, "key_id", signed.KeyId)
+	return signed
+}
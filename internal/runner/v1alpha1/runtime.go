@@ -0,0 +1,108 @@
+package v1alpha1
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	"github.com/oregondesignservices/monitoring-controller/internal/metrics"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// What a runner reports about itself on each tick, for the runtime diagnostics endpoint
+type RunnerStats struct {
+	Monitor string        `json:"monitor"`
+	Period  time.Duration `json:"period_ns"`
+
+	LastTick time.Time `json:"last_tick"`
+	// How long after it was due the last run started, and the longest such delay seen
+	LastDrift time.Duration `json:"last_drift_ns"`
+	MaxDrift  time.Duration `json:"max_drift_ns"`
+
+	// A rough estimate of the memory the runner holds on to: its monitor, queued cleanup and latency samples
+	MemoryEstimate int64 `json:"memory_estimate_bytes"`
+}
+
+// The controller's runtime, as served by the diagnostics endpoint
+type RuntimeReport struct {
+	Goroutines int    `json:"goroutines"`
+	HeapAlloc  uint64 `json:"heap_alloc_bytes"`
+	Runners    int    `json:"runners"`
+	// Whether the controller is a standby, sending no requests
+	Standby bool `json:"standby"`
+
+	Monitors []RunnerStats `json:"monitors"`
+}
+
+var (
+	runnerStats     = make(map[string]*RunnerStats)
+	runnerStatsLock sync.Mutex
+)
+
+// How late a run started, compared to when its schedule said it was due
+func recordDrift(crdType, crd string, due time.Time) time.Duration {
+	drift := time.Since(due)
+	metrics.ScheduleDriftHistogram.WithLabelValues(crdType, crd).Observe(drift.Seconds())
+	return drift
+}
+
+// Record a tick of a monitor's runner. due is when the run was scheduled.
+func (h *HttpMonitorRunner) recordTick(due time.Time) {
+	drift := recordDrift("HttpMonitor/v1alpha1", h.crdLabel(), due)
+	memory := h.memoryEstimate()
+
+	runnerStatsLock.Lock()
+	defer runnerStatsLock.Unlock()
+	stats, found := runnerStats[h.crdLabel()]
+	if !found {
+		stats = &RunnerStats{Monitor: h.crdLabel()}
+		runnerStats[h.crdLabel()] = stats
+	}
+	stats.Period = h.Spec.Period.Duration
+	stats.LastTick = due
+	stats.LastDrift = drift
+	if drift > stats.MaxDrift {
+		stats.MaxDrift = drift
+	}
+	stats.MemoryEstimate = memory
+}
+
+func (h *HttpMonitorRunner) removeStats() {
+	metrics.ScheduleDriftHistogram.DeleteLabelValues("HttpMonitor/v1alpha1", h.crdLabel())
+	runnerStatsLock.Lock()
+	defer runnerStatsLock.Unlock()
+	delete(runnerStats, h.crdLabel())
+}
+
+func (h *HttpMonitorRunner) memoryEstimate() int64 {
+	var size int64
+	if encoded, err := jsoniter.Marshal(h.HttpMonitor); err == nil {
+		size += int64(len(encoded))
+	}
+	for _, pending := range h.cleanupDebt {
+		if encoded, err := jsoniter.Marshal(pending.request); err == nil {
+			size += int64(len(encoded))
+		}
+	}
+	return size + int64(h.latency.Samples())*8
+}
+
+// Describe the controller's runtime and every runner that has ticked at least once
+func Runtime() *RuntimeReport {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	report := &RuntimeReport{
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  mem.HeapAlloc,
+		Standby:    Standby(),
+	}
+
+	runnerStatsLock.Lock()
+	for _, stats := range runnerStats {
+		report.Monitors = append(report.Monitors, *stats)
+	}
+	runnerStatsLock.Unlock()
+	report.Runners = len(report.Monitors)
+	sort.Slice(report.Monitors, func(i, j int) bool { return report.Monitors[i].Monitor < report.Monitors[j].Monitor })
+	return report
+}
@@ -0,0 +1,48 @@
+package v1alpha1
+
+import (
+	"time"
+)
+
+// Fires at fixed absolute times, a period apart. A run that starts late because the controller was busy does
+// not push back the runs after it, and slots missed entirely, like during a run longer than the period, are
+// skipped rather than queued.
+type schedule struct {
+	period time.Duration
+	next   time.Time
+	timer  *time.Timer
+}
+
+// The first run is due after first, and the rest a period apart from it
+func newSchedule(first, period time.Duration) *schedule {
+	return &schedule{
+		period: period,
+		next:   time.Now().Add(first),
+		timer:  time.NewTimer(first),
+	}
+}
+
+// Fires when a run is due
+func (s *schedule) C() <-chan time.Time {
+	return s.timer.C
+}
+
+// Call after C fires. Returns when the run was due, and arms the timer for the next slot after now.
+func (s *schedule) due(now time.Time) time.Time {
+	due := s.next
+	s.next = nextSlot(due, s.period, now)
+	s.timer.Reset(s.next.Sub(now))
+	return due
+}
+
+func (s *schedule) stop() {
+	s.timer.Stop()
+}
+
+// The first slot after now on the grid of due + n*period
+func nextSlot(due time.Time, period time.Duration, now time.Time) time.Time {
+	if now.Before(due) {
+		return due.Add(period)
+	}
+	return due.Add((now.Sub(due)/period + 1) * period)
+}
@@ -0,0 +1,174 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/metrics"
+	"github.com/oregondesignservices/monitoring-controller/internal/secrets"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type SnmpMonitorRunner struct {
+	*monitoringraisingthefloororgv1alpha1.SnmpMonitor
+	client   client.Client
+	schedule *schedule
+	closer   chan bool
+}
+
+func NewSnmpMonitorRunner(m *monitoringraisingthefloororgv1alpha1.SnmpMonitor, c client.Client) *SnmpMonitorRunner {
+	return &SnmpMonitorRunner{
+		SnmpMonitor: m,
+		client:      c,
+	}
+}
+
+func (s *SnmpMonitorRunner) Start() {
+	if s.schedule != nil {
+		panic("tried to start an already started SnmpMonitor")
+	}
+
+	period := s.Spec.Period.Duration
+	s.schedule = newSchedule(firstRunDelay(s.crdLabel(), period), period)
+	s.closer = make(chan bool)
+	go func() {
+		for {
+			select {
+			case now := <-s.schedule.C():
+				recordDrift("SnmpMonitor/v1alpha1", s.crdLabel(), s.schedule.due(now))
+				if Standby() {
+					s.updateSkippedStatus(monitoringraisingthefloororgv1alpha1.MonitorStateStandby, standbyReason)
+					s.recordStateGauges(monitoringraisingthefloororgv1alpha1.MonitorStateStandby)
+					continue
+				}
+				// Secrets are read on every run, so rotated credentials apply without editing the monitor
+				result := &monitoringraisingthefloororgv1alpha1.SnmpResult{}
+				if credentials, err := s.readSecrets(context.Background()); err != nil {
+					result.Err = fmt.Errorf("could not read credentials: %w", err)
+				} else {
+					result = s.Execute(credentials)
+				}
+				s.updateStatus(result)
+				s.recordStateGauges(result.State())
+				s.recordValueGauges(result)
+			case <-s.closer:
+				return
+			}
+		}
+	}()
+}
+
+func (s *SnmpMonitorRunner) Stop() {
+	s.closer <- true
+	s.schedule.stop()
+	metrics.ScheduleDriftHistogram.DeleteLabelValues("SnmpMonitor/v1alpha1", s.crdLabel())
+	for _, state := range monitoringraisingthefloororgv1alpha1.MonitorStates() {
+		metrics.MonitorStateGauge.DeleteLabelValues("SnmpMonitor/v1alpha1", s.crdLabel(), string(state))
+	}
+	for _, oid := range s.Spec.Oids {
+		metrics.SnmpValueGauge.DeleteLabelValues(s.crdLabel(), oid.Name)
+	}
+}
+
+func (s *SnmpMonitorRunner) crdLabel() string {
+	return fmt.Sprintf("%s/%s", s.Namespace, s.Name)
+}
+
+func (s *SnmpMonitorRunner) readSecrets(ctx context.Context) (monitoringraisingthefloororgv1alpha1.SnmpSecrets, error) {
+	var result monitoringraisingthefloororgv1alpha1.SnmpSecrets
+	type secretRef struct {
+		ref    *corev1.SecretKeySelector
+		target *string
+	}
+	refs := []secretRef{{s.Spec.CommunitySecretRef, &result.Community}}
+	if v3 := s.Spec.V3; v3 != nil {
+		refs = append(refs, secretRef{v3.AuthPasswordSecretRef, &result.AuthPassword},
+			secretRef{v3.PrivPasswordSecretRef, &result.PrivPassword})
+	}
+	for _, r := range refs {
+		if r.ref == nil {
+			continue
+		}
+		value, err := secrets.Read(ctx, s.client, s.Namespace, r.ref)
+		if err != nil {
+			return result, err
+		}
+		*r.target = value
+	}
+	return result, nil
+}
+
+// Record the outcome of a run in the monitor status
+func (s *SnmpMonitorRunner) updateStatus(result *monitoringraisingthefloororgv1alpha1.SnmpResult) {
+	monitor := s.SnmpMonitor.DeepCopy()
+	patch := client.MergeFrom(monitor.DeepCopy())
+
+	executed := metav1.NewTime(result.Start)
+	monitor.Status.LastExecution = &executed
+	monitor.Status.State = result.State()
+	monitor.Status.Values = result.ValueStatus()
+	monitor.Status.Error = ""
+	condition := monitoringraisingthefloororgv1alpha1.MonitorCondition{
+		Type:               monitoringraisingthefloororgv1alpha1.ConditionHealthy,
+		Status:             corev1.ConditionTrue,
+		Reason:             "ValuesHealthy",
+		LastTransitionTime: executed,
+	}
+	if failure := result.Failure(); failure != nil {
+		monitor.Status.LastFailure = &executed
+		monitor.Status.Error = failure.Error()
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = string(monitor.Status.State)
+		condition.Message = monitor.Status.Error
+	}
+	monitor.Status.Conditions = monitoringraisingthefloororgv1alpha1.SetCondition(monitor.Status.Conditions, condition)
+
+	if err := s.client.Status().Patch(context.Background(), monitor, patch); err != nil {
+		statusLogger.Error(err, "failed to update snmp monitor status", "namespace", s.Namespace, "name", s.Name)
+		return
+	}
+	s.Status = monitor.Status
+}
+
+// Record a skipped run in the monitor status. Health is unknown, since nothing was checked.
+func (s *SnmpMonitorRunner) updateSkippedStatus(state monitoringraisingthefloororgv1alpha1.MonitorState, reason string) {
+	monitor := s.SnmpMonitor.DeepCopy()
+	patch := client.MergeFrom(monitor.DeepCopy())
+
+	monitor.Status.State = state
+	monitor.Status.Conditions = monitoringraisingthefloororgv1alpha1.SetCondition(monitor.Status.Conditions,
+		monitoringraisingthefloororgv1alpha1.MonitorCondition{
+			Type:               monitoringraisingthefloororgv1alpha1.ConditionHealthy,
+			Status:             corev1.ConditionUnknown,
+			Reason:             string(state),
+			Message:            reason,
+			LastTransitionTime: metav1.Now(),
+		})
+
+	if err := s.client.Status().Patch(context.Background(), monitor, patch); err != nil {
+		statusLogger.Error(err, "failed to update snmp monitor status", "namespace", s.Namespace, "name", s.Name)
+		return
+	}
+	s.Status = monitor.Status
+}
+
+func (s *SnmpMonitorRunner) recordStateGauges(state monitoringraisingthefloororgv1alpha1.MonitorState) {
+	for _, st := range monitoringraisingthefloororgv1alpha1.MonitorStates() {
+		value := 0.0
+		if st == state {
+			value = 1
+		}
+		metrics.MonitorStateGauge.WithLabelValues("SnmpMonitor/v1alpha1", s.crdLabel(), string(st)).Set(value)
+	}
+}
+
+// Values that could not be read keep the last one that was
+func (s *SnmpMonitorRunner) recordValueGauges(result *monitoringraisingthefloororgv1alpha1.SnmpResult) {
+	for _, value := range result.Values {
+		if value.Number != nil {
+			metrics.SnmpValueGauge.WithLabelValues(s.crdLabel(), value.Name).Set(*value.Number)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package v1alpha1
+
+import (
+	"github.com/oregondesignservices/monitoring-controller/internal/metrics"
+	"sync/atomic"
+)
+
+// 1 while in standby. Failover changes it while runners are running.
+var standby int32
+
+const standbyReason = "the controller is a standby and sends no requests"
+
+// Run no requests, for a standby cluster kept in sync with the active one. Monitors are still loaded and
+// linted, and each run is recorded as Standby, so the cluster is ready to take over when started without it.
+func SetStandby(enabled bool) {
+	value := 0.0
+	if enabled {
+		atomic.StoreInt32(&standby, 1)
+		value = 1
+	} else {
+		atomic.StoreInt32(&standby, 0)
+	}
+	metrics.ControllerStandbyGauge.Set(value)
+}
+
+// Whether the controller is in standby
+func Standby() bool {
+	return atomic.LoadInt32(&standby) == 1
+}
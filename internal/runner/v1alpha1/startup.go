@@ -0,0 +1,49 @@
+package v1alpha1
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+var (
+	startupRamp     time.Duration
+	startupRampEnds time.Time
+	startupLock     sync.Mutex
+)
+
+// Spread the first runs of the runners started within ramp of the first one, instead of running every monitor a
+// period after the controller starts. Without it, thousands of monitors all run within the same few seconds of
+// each period, which overloads DNS, conntrack and the targets after every restart of the controller.
+func SetStartupRamp(ramp time.Duration) {
+	startupLock.Lock()
+	defer startupLock.Unlock()
+	startupRamp = ramp
+	startupRampEnds = time.Time{}
+}
+
+// How long a new runner waits before its first run. During the ramp, each monitor gets its own offset within the
+// ramp or its period, whichever is shorter. The offset comes from the monitor's name, so it stays the same across
+// restarts and does not depend on the order the monitors are reconciled in.
+func firstRunDelay(key string, period time.Duration) time.Duration {
+	startupLock.Lock()
+	defer startupLock.Unlock()
+	if startupRamp <= 0 {
+		return period
+	}
+	// The ramp starts with the first runner, which with leader election can be long after the controller starts
+	now := time.Now()
+	if startupRampEnds.IsZero() {
+		startupRampEnds = now.Add(startupRamp)
+	}
+	if now.After(startupRampEnds) {
+		return period
+	}
+	window := startupRamp
+	if period < window {
+		window = period
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return time.Duration(h.Sum64() % uint64(window))
+}
@@ -0,0 +1,153 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var statusLogger = ctrl.Log.WithName("runner").WithName("status")
+
+func (h *HttpMonitorRunner) crdLabel() string {
+	return fmt.Sprintf("%s/%s", h.Namespace, h.Name)
+}
+
+// The failed request, its error and who owns it, for conditions and Events
+func failureMessage(failure *monitoringraisingthefloororgv1alpha1.RequestResult) string {
+	message := fmt.Sprintf("%s: %s", failure.Name, failure.Err)
+	if failure.Category != "" {
+		message = fmt.Sprintf("%s (%s)", message, failure.Category)
+	}
+	if ownership := failure.Ownership.String(); ownership != "" {
+		message = fmt.Sprintf("%s. %s", message, ownership)
+	}
+	return message
+}
+
+// Record the outcome of a run in the monitor status
+func (h *HttpMonitorRunner) updateStatus(result *monitoringraisingthefloororgv1alpha1.RunResult) {
+	monitor := h.HttpMonitor.DeepCopy()
+	patch := client.MergeFrom(monitor.DeepCopy())
+
+	executed := metav1.NewTime(result.Start)
+	monitor.Status.LastExecution = &executed
+	monitor.Status.State = result.State()
+	monitor.Status.CleanupDebt = h.cleanupDebtStatus()
+	monitor.Status.Latency = h.latency.Percentiles()
+	monitor.Status.Violations = h.violations
+	monitor.Status.VariableLineage = result.Lineage
+	monitor.Status.RandomSeed = result.RandomSeed
+	if monitor.MayStoreCapturedData() {
+		monitor.Status.HeaderHistory = monitoringraisingthefloororgv1alpha1.RecordHeaders(monitor.Status.HeaderHistory, result)
+	} else {
+		monitor.Status.HeaderHistory = nil
+	}
+	monitor.Status.Performance = monitor.RecordPerformance(monitor.Status.Performance, result)
+	monitor.Status.LastRunRecord = h.signRunRecord(result)
+	traffic := result.Traffic()
+	monitor.Status.Usage = monitor.Status.Usage.Add(monitoringraisingthefloororgv1alpha1.UsageMonth(result.Start),
+		traffic.Requests, traffic.BytesSent+traffic.BytesReceived)
+	condition := monitoringraisingthefloororgv1alpha1.MonitorCondition{
+		Type:               monitoringraisingthefloororgv1alpha1.ConditionHealthy,
+		Status:             corev1.ConditionTrue,
+		Reason:             "RequestsSucceeded",
+		LastTransitionTime: executed,
+	}
+	if failure := result.FirstFailure(); failure != nil {
+		monitor.Status.LastFailure = &executed
+		condition.Status = corev1.ConditionFalse
+		condition.Severity = failure.Severity
+		condition.Reason = string(monitor.Status.State)
+		condition.Message = failureMessage(failure)
+	}
+	if executions := len(result.OtherExecutions) + 1; executions > 1 {
+		passed := fmt.Sprintf("%d of %d executions passed", result.ExecutionsPassed, executions)
+		if condition.Message == "" {
+			condition.Message = passed
+		} else {
+			condition.Message = fmt.Sprintf("%s. %s", condition.Message, passed)
+		}
+	}
+	monitor.Status.Conditions = monitoringraisingthefloororgv1alpha1.SetCondition(monitor.Status.Conditions, condition)
+	if skew := h.versionSkewCondition(result.Start); skew != nil {
+		monitor.Status.Conditions = monitoringraisingthefloororgv1alpha1.SetCondition(monitor.Status.Conditions, *skew)
+	}
+
+	if err := h.client.Status().Patch(context.Background(), monitor, patch); err != nil {
+		statusLogger.Error(err, "failed to update status", "namespace", h.Namespace, "name", h.Name)
+		return
+	}
+	h.Status = monitor.Status
+}
+
+// Record that a run was skipped, and why. The failure streak is left alone, so a monitor that was failing
+// before still notifies when it recovers.
+func (h *HttpMonitorRunner) skipRun(state monitoringraisingthefloororgv1alpha1.MonitorState, reason string) {
+	statusLogger.V(1).Info("skipping run", "namespace", h.Namespace, "name", h.Name, "reason", reason)
+	h.updateSkippedStatus(state, reason)
+	h.recordStateGauges(state, "")
+}
+
+// Record a skipped run in the monitor status. Health is unknown rather than false, since nothing was checked.
+func (h *HttpMonitorRunner) updateSkippedStatus(state monitoringraisingthefloororgv1alpha1.MonitorState, reason string) {
+	monitor := h.HttpMonitor.DeepCopy()
+	patch := client.MergeFrom(monitor.DeepCopy())
+
+	now := metav1.Now()
+	monitor.Status.State = state
+	monitor.Status.Conditions = monitoringraisingthefloororgv1alpha1.SetCondition(monitor.Status.Conditions,
+		monitoringraisingthefloororgv1alpha1.MonitorCondition{
+			Type:               monitoringraisingthefloororgv1alpha1.ConditionHealthy,
+			Status:             corev1.ConditionUnknown,
+			Reason:             string(state),
+			Message:            reason,
+			LastTransitionTime: now,
+		})
+
+	if err := h.client.Status().Patch(context.Background(), monitor, patch); err != nil {
+		statusLogger.Error(err, "failed to update status", "namespace", h.Namespace, "name", h.Name)
+		return
+	}
+	h.Status = monitor.Status
+}
+
+// Export the state of the monitor, and which severity, if any, it is failing at
+func (h *HttpMonitorRunner) recordStateGauges(state monitoringraisingthefloororgv1alpha1.MonitorState, failing monitoringraisingthefloororgv1alpha1.Severity) {
+	for _, s := range monitoringraisingthefloororgv1alpha1.MonitorStates() {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		metrics.MonitorStateGauge.WithLabelValues("HttpMonitor/v1alpha1", h.crdLabel(), string(s)).Set(value)
+	}
+
+	for _, severity := range monitoringraisingthefloororgv1alpha1.Severities() {
+		value := 0.0
+		if severity == failing {
+			value = 1
+		}
+		metrics.MonitorFailingGauge.WithLabelValues("HttpMonitor/v1alpha1", h.crdLabel(), string(severity)).Set(value)
+	}
+}
+
+func (h *HttpMonitorRunner) removeStateGauges() {
+	for _, s := range monitoringraisingthefloororgv1alpha1.MonitorStates() {
+		metrics.MonitorStateGauge.DeleteLabelValues("HttpMonitor/v1alpha1", h.crdLabel(), string(s))
+	}
+	for _, severity := range monitoringraisingthefloororgv1alpha1.Severities() {
+		metrics.MonitorFailingGauge.DeleteLabelValues("HttpMonitor/v1alpha1", h.crdLabel(), string(severity))
+	}
+	metrics.CleanupDebtGauge.DeleteLabelValues("HttpMonitor/v1alpha1", h.crdLabel())
+	for _, requests := range [][]monitoringraisingthefloororgv1alpha1.HttpRequest{h.Spec.Requests, h.Spec.Cleanup} {
+		for _, r := range requests {
+			metrics.CrdPageTtfbGauge.DeleteLabelValues("HttpMonitor/v1alpha1", h.crdLabel(), r.Name)
+			metrics.CrdPageWeightGauge.DeleteLabelValues("HttpMonitor/v1alpha1", h.crdLabel(), r.Name)
+			metrics.CrdPageRequestsGauge.DeleteLabelValues("HttpMonitor/v1alpha1", h.crdLabel(), r.Name)
+		}
+	}
+}
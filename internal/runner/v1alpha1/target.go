@@ -0,0 +1,68 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var targetLogger = ctrl.Log.WithName("runner").WithName("target")
+
+// Whether the target workload is intentionally gone: its namespace is terminating or deleted, or it is scaled to
+// zero. A workload that does not exist is not absent, since that is more likely a mistake than a teardown. When
+// the target cannot be read, the run goes ahead.
+func (h *HttpMonitorRunner) targetAbsent() (string, bool) {
+	target := h.Spec.Target
+	if target == nil {
+		return "", false
+	}
+	namespace := target.Namespace
+	if namespace == "" {
+		namespace = h.Namespace
+	}
+	logger := targetLogger.WithValues("namespace", h.Namespace, "name", h.Name, "target", target.Name)
+	ctx := context.Background()
+
+	ns := &corev1.Namespace{}
+	if err := h.client.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("namespace %s does not exist", namespace), true
+		}
+		logger.Error(err, "failed to get target namespace")
+		return "", false
+	}
+	if ns.Status.Phase == corev1.NamespaceTerminating {
+		return fmt.Sprintf("namespace %s is terminating", namespace), true
+	}
+
+	key := client.ObjectKey{Namespace: namespace, Name: target.Name}
+	var replicas *int32
+	switch target.Kind {
+	case "Deployment":
+		deployment := &appsv1.Deployment{}
+		if err := h.client.Get(ctx, key, deployment); err != nil {
+			logger.Error(err, "failed to get target deployment")
+			return "", false
+		}
+		replicas = deployment.Spec.Replicas
+	case "StatefulSet":
+		statefulSet := &appsv1.StatefulSet{}
+		if err := h.client.Get(ctx, key, statefulSet); err != nil {
+			logger.Error(err, "failed to get target statefulset")
+			return "", false
+		}
+		replicas = statefulSet.Spec.Replicas
+	default:
+		logger.Info("unknown target kind", "kind", target.Kind)
+		return "", false
+	}
+	// Unset replicas default to one
+	if replicas != nil && *replicas == 0 {
+		return fmt.Sprintf("%s %s/%s is scaled to zero", target.Kind, namespace, target.Name), true
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package v1alpha1
+
+import (
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sync"
+	"time"
+)
+
+// The last value of each variable monitors extracted, by monitor, so version_skew rules can compare monitors.
+// Values only live in memory, so after a restart the rules wait for both monitors to run again.
+var lastVariables = struct {
+	lock   sync.Mutex
+	values map[string]map[string]string
+}{values: make(map[string]map[string]string)}
+
+// Remember the variables of a run. Variables a run did not extract, like after a failure, keep their last value.
+func recordVariables(monitor string, variables map[string]string) {
+	lastVariables.lock.Lock()
+	defer lastVariables.lock.Unlock()
+	values, ok := lastVariables.values[monitor]
+	if !ok {
+		values = make(map[string]string, len(variables))
+		lastVariables.values[monitor] = values
+	}
+	for name, value := range variables {
+		values[name] = value
+	}
+}
+
+// Forget the variables of a monitor whose runner stopped, so removed monitors do not stay in memory. A runner that
+// replaces it records them again on its first run.
+func forgetVariables(monitor string) {
+	lastVariables.lock.Lock()
+	defer lastVariables.lock.Unlock()
+	delete(lastVariables.values, monitor)
+}
+
+func lastVariable(monitor, name string) (string, bool) {
+	lastVariables.lock.Lock()
+	defer lastVariables.lock.Unlock()
+	value, ok := lastVariables.values[monitor][name]
+	return value, ok
+}
+
+// The VersionSkew condition after a run, for monitors with a version_skew rule
+func (h *HttpMonitorRunner) versionSkewCondition(now time.Time) *monitoringraisingthefloororgv1alpha1.MonitorCondition {
+	rule := h.Spec.VersionSkew
+	if rule == nil {
+		return nil
+	}
+	own, _ := lastVariable(h.crdLabel(), rule.Variable)
+	other, otherKnown := lastVariable(h.Namespace+"/"+rule.Monitor, rule.OtherVariableName())
+	condition, since := rule.Condition(own, other, otherKnown, h.skewSince, now)
+	h.skewSince = since
+	if condition.Status == corev1.ConditionTrue {
+		statusLogger.Info("versions diverged", "namespace", h.Namespace, "name", h.Name, "other", rule.Monitor,
+			"message", condition.Message)
+	}
+	return &condition
+}
@@ -0,0 +1,36 @@
+package v1alpha1
+
+import (
+	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Accumulate the violations of observe-only assertions, so status shows how often a trial assertion would have
+// failed the monitor
+func (h *HttpMonitorRunner) recordViolations(result *monitoringraisingthefloororgv1alpha1.RunResult) {
+	seen := metav1.NewTime(result.Start)
+	for _, r := range result.Requests {
+		for _, violation := range r.Violations {
+			h.violations = recordViolation(h.violations, r.Name, violation, seen)
+		}
+	}
+}
+
+func recordViolation(violations []monitoringraisingthefloororgv1alpha1.AssertionViolation, request string, violation monitoringraisingthefloororgv1alpha1.Violation, seen metav1.Time) []monitoringraisingthefloororgv1alpha1.AssertionViolation {
+	for i := range violations {
+		if violations[i].Request == request && violations[i].Assertion == violation.Assertion {
+			violations[i].Count++
+			violations[i].LastSeen = seen
+			violations[i].Message = violation.Err.Error()
+			return violations
+		}
+	}
+	return append(violations, monitoringraisingthefloororgv1alpha1.AssertionViolation{
+		Request:   request,
+		Assertion: violation.Assertion,
+		Count:     1,
+		FirstSeen: seen,
+		LastSeen:  seen,
+		Message:   violation.Err.Error(),
+	})
+}
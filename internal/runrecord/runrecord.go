@@ -0,0 +1,89 @@
+// Package runrecord signs the records of monitor runs with a controller key, so the uptime evidence kept from
+// them can be shown not to have been changed since.
+package runrecord
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	"io/ioutil"
+)
+
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyId string
+}
+
+// Load the Ed25519 private key in the PKCS #8 PEM file at path, like one made with
+// openssl genpkey -algorithm ed25519
+func LoadSigner(path string) (*Signer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 key", path)
+	}
+	return NewSigner(key)
+}
+
+func NewSigner(key ed25519.PrivateKey) (*Signer, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("not an Ed25519 private key")
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	return &Signer{key: key, keyId: hex.EncodeToString(sum[:8])}, nil
+}
+
+// The public key records are verified with, in PEM
+func (s *Signer) PublicKeyPEM() []byte {
+	der, _ := x509.MarshalPKIXPublicKey(s.key.Public())
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func (s *Signer) KeyId() string {
+	return s.keyId
+}
+
+// Sign the record as JSON. The signature covers the exact bytes of the record it returns, so verifying needs no
+// canonical form.
+func (s *Signer) Sign(record v1alpha1.RunRecord) (*v1alpha1.SignedRunRecord, error) {
+	data, err := jsoniter.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return &v1alpha1.SignedRunRecord{
+		Record:    string(data),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, data)),
+		KeyId:     s.keyId,
+	}, nil
+}
+
+// Whether record was signed by the private half of publicKey
+func Verify(publicKey ed25519.PublicKey, record *v1alpha1.SignedRunRecord) bool {
+	signature, err := base64.StdEncoding.DecodeString(record.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(publicKey, []byte(record.Record), signature)
+}
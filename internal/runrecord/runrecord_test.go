@@ -0,0 +1,106 @@
+package runrecord
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+)
+
+func TestSigner_Sign(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	signer, err := NewSigner(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{8}, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+	record := v1alpha1.RunRecord{Monitor: "shop/checkout", Uid: "1234", Sequence: 1,
+		Start: time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC), State: v1alpha1.MonitorStateUp,
+		Requests: []v1alpha1.RunRecordRequest{{Name: "cart", StatusCode: 200}}}
+
+	tests := []struct {
+		TestName string
+		Tamper   func(signed *v1alpha1.SignedRunRecord)
+		Key      ed25519.PublicKey
+		Expected bool
+	}{
+		{"round trip", func(signed *v1alpha1.SignedRunRecord) {}, key.Public().(ed25519.PublicKey), true},
+		{"changed state", func(signed *v1alpha1.SignedRunRecord) {
+			signed.Record = strings.Replace(signed.Record, `"state":"Up"`, `"state":"Down"`, 1)
+		}, key.Public().(ed25519.PublicKey), false},
+		{"changed signature", func(signed *v1alpha1.SignedRunRecord) {
+			signature, _ := base64.StdEncoding.DecodeString(signed.Signature)
+			signature[0] ^= 1
+			signed.Signature = base64.StdEncoding.EncodeToString(signature)
+		}, key.Public().(ed25519.PublicKey), false},
+		{"invalid signature", func(signed *v1alpha1.SignedRunRecord) {
+			signed.Signature = "not base64"
+		}, key.Public().(ed25519.PublicKey), false},
+		{"other key", func(signed *v1alpha1.SignedRunRecord) {}, other, false},
+	}
+
+	for _, testdata := range tests {
+		signed, err := signer.Sign(record)
+		if err != nil {
+			t.Fatalf("[%s] unexpected error: %v", testdata.TestName, err)
+		}
+		if signed.KeyId != signer.KeyId() {
+			t.Errorf("[%s] unexpected key id %q", testdata.TestName, signed.KeyId)
+		}
+		testdata.Tamper(signed)
+		if out := Verify(testdata.Key, signed); out != testdata.Expected {
+			t.Errorf("[%s] unexpected result. Got: %v, expected: %v", testdata.TestName, out, testdata.Expected)
+		}
+	}
+}
+
+func TestSigner_Sign_chain(t *testing.T) {
+	signer, err := NewSigner(ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := signer.Sign(v1alpha1.RunRecord{Monitor: "shop/checkout", Sequence: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := v1alpha1.RunRecord{Monitor: "shop/checkout"}
+	if err := record.Follow(first); err != nil {
+		t.Fatal(err)
+	}
+	second, err := signer.Sign(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replacing the first record, even with one that is validly signed, breaks the link to it
+	replaced, err := signer.Sign(v1alpha1.RunRecord{Monitor: "shop/checkout", Sequence: 1, State: v1alpha1.MonitorStateDown})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		TestName string
+		Previous *v1alpha1.SignedRunRecord
+		Expected bool
+	}{
+		{"original", first, true},
+		{"replaced", replaced, false},
+	}
+
+	for _, testdata := range tests {
+		expected := v1alpha1.RunRecord{}
+		if err := expected.Follow(testdata.Previous); err != nil {
+			t.Fatalf("[%s] unexpected error: %v", testdata.TestName, err)
+		}
+		if out := strings.Contains(second.Record, `"previous":"`+expected.Previous+`"`); out != testdata.Expected {
+			t.Errorf("[%s] unexpected link. Got: %v, expected: %v, record: %s", testdata.TestName, out,
+				testdata.Expected, second.Record)
+		}
+		if !strings.Contains(second.Record, `"sequence":2`) {
+			t.Errorf("[%s] unexpected sequence in %s", testdata.TestName, second.Record)
+		}
+	}
+}
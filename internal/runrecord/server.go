@@ -0,0 +1,54 @@
+package runrecord
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Serves the public key run records are verified with at /run-records/public-key, in PEM, and its key id in the
+// X-Key-Id header
+type Server struct {
+	Addr   string
+	Signer *Signer
+	Log    logr.Logger
+}
+
+// Every replica signs with the same key, so any of them can serve it
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *Server) Start(stop <-chan struct{}) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/run-records/public-key", s.servePublicKey)
+	server := &http.Server{Handler: mux}
+
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+	s.Log.Info("serving the run record public key", "addr", s.Addr, "key_id", s.Signer.KeyId())
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) servePublicKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	w.Header().Set("X-Key-Id", s.Signer.KeyId())
+	_, _ = w.Write(s.Signer.PublicKeyPEM())
+}
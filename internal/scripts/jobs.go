@@ -0,0 +1,80 @@
+package scripts
+
+import (
+	"context"
+	"fmt"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
+)
+
+const pollInterval = 2 * time.Second
+
+// Runs script steps as Jobs in the monitor's namespace. Reader should read from the API server rather than
+// the cache, so the controller does not need to watch every Job in the cluster.
+type JobRunner struct {
+	Client client.Client
+	Reader client.Reader
+}
+
+// Create the Job and wait for it to succeed or fail, returning its termination message when it succeeds. The
+// Job is deleted afterwards either way.
+func (r *JobRunner) RunScript(ctx context.Context, job *batchv1.Job) (string, error) {
+	if err := r.Client.Create(ctx, job); err != nil {
+		return "", fmt.Errorf("could not create script job: %w", err)
+	}
+	defer r.delete(job)
+
+	key := client.ObjectKey{Namespace: job.Namespace, Name: job.Name}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("script job %s did not finish: %w", key, ctx.Err())
+		case <-ticker.C:
+		}
+
+		current := &batchv1.Job{}
+		if err := r.Reader.Get(ctx, key, current); err != nil {
+			return "", fmt.Errorf("could not get script job %s: %w", key, err)
+		}
+		if current.Status.Succeeded > 0 {
+			return r.terminationMessage(ctx, current), nil
+		}
+		for _, condition := range current.Status.Conditions {
+			if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+				if message := r.terminationMessage(ctx, current); message != "" {
+					return "", fmt.Errorf("script job %s failed: %s: %s", key, condition.Message, message)
+				}
+				return "", fmt.Errorf("script job %s failed: %s", key, condition.Message)
+			}
+		}
+	}
+}
+
+// What the Job's container wrote to its termination message, or the end of its log for containers that ask for
+// it. For failed Jobs it usually says why.
+func (r *JobRunner) terminationMessage(ctx context.Context, job *batchv1.Job) string {
+	pods := &corev1.PodList{}
+	if err := r.Reader.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name}); err != nil {
+		return ""
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if terminated := status.State.Terminated; terminated != nil && terminated.Message != "" {
+				return strings.TrimSpace(terminated.Message)
+			}
+		}
+	}
+	return ""
+}
+
+func (r *JobRunner) delete(job *batchv1.Job) {
+	// The run's context may already be done, and a leftover Job still has a TTL
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = r.Client.Delete(ctx, job, client.PropagationPolicy("Background"))
+}
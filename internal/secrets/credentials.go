@@ -0,0 +1,183 @@
+package secrets
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+)
+
+// Load the credentials of a monitor and its requests, the keys requests are signed with, the client
+// certificates they present and the CA bundles they verify their targets with. Credentials that cannot be read
+// keep what was loaded before, and requests whose credentials were never loaded fail when they are sent.
+func LoadCredentials(ctx context.Context, c client.Reader, namespace string, spec *v1alpha1.HttpMonitorSpec) error {
+	var firstErr error
+	auths := []*v1alpha1.RequestAuth{spec.Auth}
+	certificates := []*v1alpha1.ClientCertificate{spec.ClientCertificate}
+	for _, list := range [][]v1alpha1.HttpRequest{spec.Requests, spec.Cleanup} {
+		for i := range list {
+			auths = append(auths, list[i].Auth)
+			if list[i].Tls != nil {
+				certificates = append(certificates, list[i].Tls.ClientCertificate)
+				if err := loadCaBundle(ctx, c, namespace, list[i].Name, list[i].Tls.CaBundle); err != nil && firstErr == nil {
+					firstErr = err
+				}
+			}
+			if err := loadHmacKey(ctx, c, namespace, list[i].HmacSignature); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	for _, certificate := range certificates {
+		if certificate == nil {
+			continue
+		}
+		if err := loadClientCertificate(ctx, c, namespace, certificate); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, auth := range auths {
+		if auth == nil {
+			continue
+		}
+		if err := loadAuth(ctx, c, namespace, auth); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func loadAuth(ctx context.Context, c client.Reader, namespace string, auth *v1alpha1.RequestAuth) error {
+	if basic := auth.Basic; basic != nil {
+		username, err := Read(ctx, c, namespace, basic.UsernameSecretKey())
+		if err != nil {
+			return err
+		}
+		password, err := Read(ctx, c, namespace, basic.PasswordSecretKey())
+		if err != nil {
+			return err
+		}
+		basic.Username, basic.Password, basic.Loaded = username, password, true
+	}
+	if bearer := auth.Bearer; bearer != nil {
+		token, err := Read(ctx, c, namespace, &bearer.SecretRef)
+		if err != nil {
+			return err
+		}
+		// Tokens written from files often end with a newline
+		bearer.Token, bearer.Loaded = strings.TrimSpace(token), true
+	}
+	if oauth2 := auth.OAuth2; oauth2 != nil {
+		clientId, err := Read(ctx, c, namespace, oauth2.ClientIdSecretKey())
+		if err != nil {
+			return err
+		}
+		clientSecret, err := Read(ctx, c, namespace, oauth2.ClientSecretSecretKey())
+		if err != nil {
+			return err
+		}
+		oauth2.ClientId, oauth2.ClientSecret, oauth2.Loaded = strings.TrimSpace(clientId), strings.TrimSpace(clientSecret), true
+	}
+	if sigV4 := auth.AwsSigV4; sigV4 != nil {
+		return loadAwsCredentials(ctx, c, namespace, sigV4)
+	}
+	return nil
+}
+
+func loadHmacKey(ctx context.Context, c client.Reader, namespace string, signature *v1alpha1.HmacSignature) error {
+	if signature == nil {
+		return nil
+	}
+	key, err := Read(ctx, c, namespace, &signature.SecretRef)
+	if err != nil {
+		return err
+	}
+	signature.Key, signature.Loaded = []byte(strings.TrimSpace(key)), true
+	return nil
+}
+
+func loadClientCertificate(ctx context.Context, c client.Reader, namespace string, certificate *v1alpha1.ClientCertificate) error {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: certificate.SecretRef.Name}, secret); err != nil {
+		return err
+	}
+	for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+		if _, ok := secret.Data[key]; !ok {
+			return fmt.Errorf("secret %s/%s has no key %s", namespace, certificate.SecretRef.Name, key)
+		}
+	}
+	cert, key := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if _, err := tls.X509KeyPair(cert, key); err != nil {
+		return fmt.Errorf("secret %s/%s does not hold a client certificate and its key: %w", namespace, certificate.SecretRef.Name, err)
+	}
+	certificate.Certificate, certificate.Key, certificate.Loaded = cert, key, true
+	return nil
+}
+
+// A bundle without certificates is not loaded, so it fails its request rather than verifying nothing
+func loadCaBundle(ctx context.Context, c client.Reader, namespace, request string, source *v1alpha1.CaBundleSource) error {
+	if source == nil {
+		return nil
+	}
+	var bundle []byte
+	if source.SecretRef != nil {
+		value, err := Read(ctx, c, namespace, source.SecretRef)
+		if err != nil {
+			return err
+		}
+		bundle = []byte(value)
+	} else if source.ConfigMapRef != nil {
+		configMap := &corev1.ConfigMap{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: source.ConfigMapRef.Name}, configMap); err != nil {
+			return err
+		}
+		if value, ok := configMap.BinaryData[source.ConfigMapRef.Key]; ok {
+			bundle = value
+		} else if value, ok := configMap.Data[source.ConfigMapRef.Key]; ok {
+			bundle = []byte(value)
+		} else {
+			return fmt.Errorf("configmap %s/%s has no key %s", namespace, source.ConfigMapRef.Name, source.ConfigMapRef.Key)
+		}
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(bundle) {
+		return fmt.Errorf("the CA bundle of request %q has no PEM certificates", request)
+	}
+	source.Loaded = bundle
+	return nil
+}
+
+func loadAwsCredentials(ctx context.Context, c client.Reader, namespace string, auth *v1alpha1.AwsSigV4Auth) error {
+	if auth.SecretRef == nil {
+		if !webIdentityAllowed(namespace) {
+			return fmt.Errorf("monitors in namespace %s may not sign with the controller's IAM role: set secret_ref, "+
+				"or start the controller with --aws-web-identity-namespace=%s", namespace, namespace)
+		}
+		credentials, err := WebIdentityCredentials(ctx)
+		if err != nil {
+			return err
+		}
+		auth.AccessKeyId, auth.SecretAccessKey, auth.SessionToken = credentials.AccessKeyId,
+			credentials.SecretAccessKey, credentials.SessionToken
+		auth.Loaded = true
+		return nil
+	}
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: auth.SecretRef.Name}, secret); err != nil {
+		return err
+	}
+	for _, key := range []string{v1alpha1.AwsAccessKeyIdKey, v1alpha1.AwsSecretAccessKeyKey} {
+		if _, ok := secret.Data[key]; !ok {
+			return fmt.Errorf("secret %s/%s has no key %s", namespace, auth.SecretRef.Name, key)
+		}
+	}
+	auth.AccessKeyId = strings.TrimSpace(string(secret.Data[v1alpha1.AwsAccessKeyIdKey]))
+	auth.SecretAccessKey = strings.TrimSpace(string(secret.Data[v1alpha1.AwsSecretAccessKeyKey]))
+	auth.SessionToken = strings.TrimSpace(string(secret.Data[v1alpha1.AwsSessionTokenKey]))
+	auth.Loaded = true
+	return nil
+}
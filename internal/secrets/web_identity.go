@@ -0,0 +1,127 @@
+package secrets
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/httpclient"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Temporary credentials are exchanged again this long before they expire
+const webIdentityRefreshMargin = 5 * time.Minute
+
+// Temporary AWS credentials
+type AwsCredentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      time.Time
+}
+
+var webIdentity = struct {
+	lock        sync.Mutex
+	credentials *AwsCredentials
+	// The namespaces whose monitors may use the role, or * for all
+	namespaces map[string]bool
+}{}
+
+// Let the monitors in these namespaces sign with the controller's IAM role. The role is usually far more
+// privileged than a monitor needs, so by default no namespace may use it. Set before any monitor is loaded.
+func SetWebIdentityNamespaces(namespaces []string) {
+	webIdentity.lock.Lock()
+	defer webIdentity.lock.Unlock()
+	webIdentity.namespaces = make(map[string]bool, len(namespaces))
+	for _, namespace := range namespaces {
+		webIdentity.namespaces[namespace] = true
+	}
+}
+
+func webIdentityAllowed(namespace string) bool {
+	webIdentity.lock.Lock()
+	defer webIdentity.lock.Unlock()
+	return webIdentity.namespaces["*"] || webIdentity.namespaces[namespace]
+}
+
+// The credentials of the IAM role for the controller's service account. The role and the token come from the
+// environment EKS sets up, and the credentials are kept until shortly before they expire.
+func WebIdentityCredentials(ctx context.Context) (*AwsCredentials, error) {
+	webIdentity.lock.Lock()
+	defer webIdentity.lock.Unlock()
+	if cached := webIdentity.credentials; cached != nil && time.Until(cached.Expiration) > webIdentityRefreshMargin {
+		return cached, nil
+	}
+	credentials, err := assumeRoleWithWebIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	webIdentity.credentials = credentials
+	return credentials, nil
+}
+
+type assumeRoleWithWebIdentityResponse struct {
+	Credentials struct {
+		AccessKeyId     string
+		SecretAccessKey string
+		SessionToken    string
+		Expiration      time.Time
+	} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+}
+
+func assumeRoleWithWebIdentity(ctx context.Context) (*AwsCredentials, error) {
+	roleArn, tokenFile := os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	if roleArn == "" || tokenFile == "" {
+		return nil, fmt.Errorf("no AWS credentials: the controller has no IAM role for its service account, " +
+			"AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE are not set")
+	}
+	// The token is rotated by the kubelet, so it is read for every exchange
+	token, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		return nil, err
+	}
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = "monitoring-controller"
+	}
+	endpoint := "https://sts.amazonaws.com/"
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		endpoint = "https://sts." + region + ".amazonaws.com/"
+	}
+	query := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleArn},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := httpclient.GetClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to assume role %s: %s: %s", roleArn, resp.Status, body)
+	}
+	var parsed assumeRoleWithWebIdentityResponse
+	if err := xml.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse the credentials for role %s: %w", roleArn, err)
+	}
+	c := parsed.Credentials
+	return &AwsCredentials{AccessKeyId: c.AccessKeyId, SecretAccessKey: c.SecretAccessKey, SessionToken: c.SessionToken,
+		Expiration: c.Expiration}, nil
+}
@@ -0,0 +1,203 @@
+// Package snmp sends SNMP GET requests, over v2c with a community, or over v3 with the user-based security
+// model, authenticated with HMAC-MD5, HMAC-SHA or HMAC-SHA-256 and optionally encrypted with AES-128.
+package snmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/ber"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// PDU tags
+const (
+	tagGetRequest byte = 0xa0
+	tagResponse   byte = 0xa2
+	tagReport     byte = 0xa8
+)
+
+// How long to wait for an answer before sending a request again. UDP gives no other sign that it was lost.
+const resendInterval = time.Second
+
+// Reads values from a device. Requests are sent over v2c unless V3 is set.
+type Client struct {
+	// The device, as host:port
+	Address   string
+	Community string
+	V3        *V3
+}
+
+// The names of error-status values, by number
+var errorStatuses = []string{"noError", "tooBig", "noSuchName", "badValue", "readOnly", "genErr", "noAccess",
+	"wrongType", "wrongLength", "wrongEncoding", "wrongValue", "noCreation", "inconsistentValue",
+	"resourceUnavailable", "commitFailed", "undoFailed", "authorizationError", "notWritable", "inconsistentName"}
+
+// Get reads the values of the OIDs in a single request, in the order they were asked for
+func (c *Client) Get(ctx context.Context, oids []string) ([]Varbind, error) {
+	var varbinds [][]byte
+	for _, oid := range oids {
+		encoded, err := ber.OID(oid)
+		if err != nil {
+			return nil, err
+		}
+		varbinds = append(varbinds, ber.Constructed(ber.TagSequence, encoded, ber.Null()))
+	}
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "udp", c.Address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if c.V3 != nil {
+		return c.V3.get(ctx, conn, varbinds)
+	}
+
+	requestID := rand.Int31()
+	message := ber.Constructed(ber.TagSequence, ber.Int(ber.TagInteger, 1), ber.OctetString(c.Community),
+		getRequest(requestID, varbinds))
+	var pdu ber.Value
+	_, err = roundTrip(ctx, conn, message, func(packet []byte) bool {
+		parts, err := children(packet)
+		if err != nil || len(parts) != 3 {
+			return false
+		}
+		pdu = parts[2]
+		id, _, _ := pduRequestID(pdu)
+		return id == int64(requestID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponse(pdu)
+}
+
+func getRequest(requestID int32, varbinds [][]byte) []byte {
+	return ber.Constructed(tagGetRequest, ber.Int(ber.TagInteger, int64(requestID)), ber.Int(ber.TagInteger, 0),
+		ber.Int(ber.TagInteger, 0), ber.Constructed(ber.TagSequence, varbinds...))
+}
+
+// The children of the sequence a packet holds
+func children(packet []byte) ([]ber.Value, error) {
+	message, _, err := ber.Parse(packet)
+	if err != nil {
+		return nil, err
+	}
+	if message.Tag != ber.TagSequence {
+		return nil, errors.New("not an SNMP message")
+	}
+	return message.Children()
+}
+
+func pduRequestID(pdu ber.Value) (int64, []ber.Value, error) {
+	fields, err := pdu.Children()
+	if err != nil || len(fields) != 4 {
+		return 0, nil, errors.New("malformed PDU")
+	}
+	id, err := fields[0].Int()
+	return id, fields, err
+}
+
+// The values of a response PDU
+func decodeResponse(pdu ber.Value) ([]Varbind, error) {
+	if pdu.Tag != tagResponse {
+		return nil, fmt.Errorf("unexpected PDU type 0x%02x", pdu.Tag)
+	}
+	_, fields, err := pduRequestID(pdu)
+	if err != nil {
+		return nil, err
+	}
+	status, _ := fields[1].Int()
+	index, _ := fields[2].Int()
+	if status != 0 {
+		name := fmt.Sprint(status)
+		if status > 0 && int(status) < len(errorStatuses) {
+			name = errorStatuses[status]
+		}
+		return nil, fmt.Errorf("device answered with %s for value %d", name, index)
+	}
+	list, err := fields[3].Children()
+	if err != nil {
+		return nil, err
+	}
+	varbinds := make([]Varbind, 0, len(list))
+	for _, item := range list {
+		varbind, err := decodeVarbind(item)
+		if err != nil {
+			return nil, err
+		}
+		varbinds = append(varbinds, varbind)
+	}
+	return varbinds, nil
+}
+
+// Send a request until a packet it accepts comes back or the context is done. Packets it does not accept, like
+// late answers to earlier requests, are ignored.
+func roundTrip(ctx context.Context, conn net.Conn, request []byte, accept func(packet []byte) bool) ([]byte, error) {
+	buffer := make([]byte, 65535)
+	for {
+		if _, err := conn.Write(request); err != nil {
+			return nil, err
+		}
+		wait := time.Now().Add(resendInterval)
+		if deadline, ok := ctx.Deadline(); ok && deadline.Before(wait) {
+			wait = deadline
+		}
+		_ = conn.SetReadDeadline(wait)
+		for {
+			n, err := conn.Read(buffer)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+				return nil, err
+			}
+			packet := append([]byte(nil), buffer[:n]...)
+			if accept(packet) {
+				return packet, nil
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("no answer from %s: %w", conn.RemoteAddr(), err)
+		}
+		if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("no answer from %s: %w", conn.RemoteAddr(), context.DeadlineExceeded)
+		}
+	}
+}
+
+// The OIDs of the USM statistics a report names, and what they mean
+var reports = map[string]string{
+	"1.3.6.1.6.3.15.1.1.1.0": "the device does not support the security level",
+	"1.3.6.1.6.3.15.1.1.2.0": "the request was outside the device's time window",
+	"1.3.6.1.6.3.15.1.1.3.0": "the device does not know the user",
+	"1.3.6.1.6.3.15.1.1.4.0": "the device does not know the engine ID",
+	"1.3.6.1.6.3.15.1.1.5.0": "wrong authentication password or protocol",
+	"1.3.6.1.6.3.15.1.1.6.0": "wrong privacy password or protocol",
+}
+
+const notInTimeWindow = "1.3.6.1.6.3.15.1.1.2.0"
+
+// The OID a report PDU is about, and an error describing it
+func reportError(pdu ber.Value) (string, error) {
+	_, fields, err := pduRequestID(pdu)
+	if err != nil {
+		return "", fmt.Errorf("malformed report: %w", err)
+	}
+	list, err := fields[3].Children()
+	if err != nil || len(list) == 0 {
+		return "", errors.New("malformed report")
+	}
+	varbind, err := decodeVarbind(list[0])
+	if err != nil {
+		return "", fmt.Errorf("malformed report: %w", err)
+	}
+	if reason, ok := reports[varbind.Oid]; ok {
+		return varbind.Oid, errors.New(reason)
+	}
+	return varbind.Oid, fmt.Errorf("device reported %s", varbind.Oid)
+}
@@ -0,0 +1,322 @@
+package snmp
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/ber"
+	"hash"
+	mathrand "math/rand"
+	"net"
+	"time"
+)
+
+type AuthProtocol string
+
+const (
+	AuthMD5    AuthProtocol = "md5"
+	AuthSHA    AuthProtocol = "sha"
+	AuthSHA256 AuthProtocol = "sha256"
+)
+
+type PrivProtocol string
+
+const (
+	PrivAES PrivProtocol = "aes"
+)
+
+// msgFlags bits
+const (
+	flagAuth       byte = 0x01
+	flagPriv       byte = 0x02
+	flagReportable byte = 0x04
+)
+
+// The user-based security model
+const securityModelUsm = 3
+
+// The largest message we accept, advertised to the device
+const maxMessageSize = 65507
+
+// Credentials for the user-based security model. Without an auth protocol requests are neither authenticated
+// nor encrypted, and encryption needs authentication.
+type V3 struct {
+	Username     string
+	AuthProtocol AuthProtocol
+	AuthPassword string
+	PrivProtocol PrivProtocol
+	PrivPassword string
+}
+
+// What was learned about the device's engine during discovery
+type engine struct {
+	id           []byte
+	boots, time  int64
+	discoveredAt time.Time
+}
+
+// The engine time now, going by the clock since discovery
+func (e *engine) now() int64 {
+	return e.time + int64(time.Since(e.discoveredAt)/time.Second)
+}
+
+func (v *V3) hash() (func() hash.Hash, int, error) {
+	switch v.AuthProtocol {
+	case AuthMD5:
+		return md5.New, 12, nil
+	case AuthSHA:
+		return sha1.New, 12, nil
+	case AuthSHA256:
+		return sha256.New, 24, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported authentication protocol %q", v.AuthProtocol)
+}
+
+func (v *V3) flags() byte {
+	flags := flagReportable
+	if v.AuthProtocol != "" {
+		flags |= flagAuth
+	}
+	if v.PrivProtocol != "" {
+		flags |= flagPriv
+	}
+	return flags
+}
+
+func (v *V3) validate() error {
+	if v.AuthProtocol != "" {
+		if _, _, err := v.hash(); err != nil {
+			return err
+		}
+		if len(v.AuthPassword) < 8 {
+			return errors.New("the authentication password must have at least 8 characters")
+		}
+	}
+	if v.PrivProtocol != "" {
+		if v.PrivProtocol != PrivAES {
+			return fmt.Errorf("unsupported privacy protocol %q", v.PrivProtocol)
+		}
+		if v.AuthProtocol == "" {
+			return errors.New("encryption needs an authentication protocol")
+		}
+		if len(v.PrivPassword) < 8 {
+			return errors.New("the privacy password must have at least 8 characters")
+		}
+	}
+	return nil
+}
+
+func (v *V3) get(ctx context.Context, conn net.Conn, varbinds [][]byte) ([]Varbind, error) {
+	if err := v.validate(); err != nil {
+		return nil, err
+	}
+	// Discovery is an unauthenticated request the device answers with a report carrying its engine ID and clock
+	discovered, _, err := v.exchange(ctx, conn, &engine{}, nil, flagReportable)
+	if err != nil {
+		return nil, fmt.Errorf("engine discovery failed: %w", err)
+	}
+
+	// A device only learns its clock is ahead of ours from the first authenticated request, so try once more
+	// with the time it reports
+	for attempt := 0; ; attempt++ {
+		next, pdu, err := v.exchange(ctx, conn, discovered, varbinds, v.flags())
+		if err != nil {
+			return nil, err
+		}
+		if pdu.Tag == tagReport {
+			oid, err := reportError(pdu)
+			if oid == notInTimeWindow && attempt == 0 {
+				discovered = next
+				continue
+			}
+			return nil, err
+		}
+		return decodeResponse(pdu)
+	}
+}
+
+// Send a get request as the user and return the engine the answer came from, along with its PDU
+func (v *V3) exchange(ctx context.Context, conn net.Conn, e *engine, varbinds [][]byte, flags byte) (*engine, ber.Value, error) {
+	var authKey, privKey []byte
+	var newHash func() hash.Hash
+	authLength := 0
+	if flags&flagAuth != 0 {
+		var err error
+		if newHash, authLength, err = v.hash(); err != nil {
+			return nil, ber.Value{}, err
+		}
+		authKey = localizeKey(newHash, v.AuthPassword, e.id)
+		if flags&flagPriv != 0 {
+			privKey = localizeKey(newHash, v.PrivPassword, e.id)[:16]
+		}
+	}
+
+	messageID, requestID := mathrand.Int31(), mathrand.Int31()
+	boots, engineTime := e.boots, e.now()
+	scopedPdu := ber.Constructed(ber.TagSequence, ber.TLV(ber.TagOctetString, e.id), ber.OctetString(""),
+		getRequest(requestID, varbinds))
+	var salt []byte
+	data := scopedPdu
+	if privKey != nil {
+		salt = make([]byte, 8)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, ber.Value{}, err
+		}
+		data = ber.TLV(ber.TagOctetString, aesCFB(privKey, boots, engineTime, salt, scopedPdu, true))
+	}
+	username := v.Username
+	if e.id == nil {
+		username = ""
+	}
+
+	usmFields := [][]byte{
+		ber.TLV(ber.TagOctetString, e.id),
+		ber.Int(ber.TagInteger, boots),
+		ber.Int(ber.TagInteger, engineTime),
+		ber.OctetString(username),
+		ber.TLV(ber.TagOctetString, make([]byte, authLength)),
+		ber.TLV(ber.TagOctetString, salt),
+	}
+	usm := ber.Constructed(ber.TagSequence, usmFields...)
+	header := ber.Constructed(ber.TagSequence, ber.Int(ber.TagInteger, int64(messageID)),
+		ber.Int(ber.TagInteger, maxMessageSize), ber.TLV(ber.TagOctetString, []byte{flags}),
+		ber.Int(ber.TagInteger, securityModelUsm))
+	message := ber.Constructed(ber.TagSequence, ber.Int(ber.TagInteger, 3), header,
+		ber.TLV(ber.TagOctetString, usm), data)
+	if authKey != nil {
+		// The digest covers the whole message, with the digest itself zeroed
+		digest := hmac.New(newHash, authKey)
+		digest.Write(message)
+		offset := bytes.Index(message, usm) + len(usm) - len(usmFields[5]) - authLength
+		copy(message[offset:], digest.Sum(nil)[:authLength])
+	}
+
+	var answer *engine
+	var pdu ber.Value
+	var failure error
+	_, err := roundTrip(ctx, conn, message, func(packet []byte) bool {
+		var ok bool
+		answer, pdu, ok, failure = v.parse(packet, messageID, newHash, authKey, privKey, authLength)
+		return ok
+	})
+	if err != nil {
+		return nil, ber.Value{}, err
+	}
+	if failure != nil {
+		return nil, ber.Value{}, failure
+	}
+	return answer, pdu, nil
+}
+
+// Decode an answer to the message with the ID. Answers to other messages are not ok, and answers that fail
+// authentication or decryption are ok with an error, since waiting longer would not help.
+func (v *V3) parse(packet []byte, messageID int32, newHash func() hash.Hash, authKey, privKey []byte, authLength int) (*engine, ber.Value, bool, error) {
+	parts, err := children(packet)
+	if err != nil || len(parts) != 4 {
+		return nil, ber.Value{}, false, nil
+	}
+	headerFields, err := parts[1].Children()
+	if err != nil || len(headerFields) != 4 {
+		return nil, ber.Value{}, false, nil
+	}
+	if id, err := headerFields[0].Int(); err != nil || id != int64(messageID) {
+		return nil, ber.Value{}, false, nil
+	}
+	fail := func(format string, args ...interface{}) (*engine, ber.Value, bool, error) {
+		return nil, ber.Value{}, true, fmt.Errorf(format, args...)
+	}
+	if len(headerFields[2].Content) != 1 {
+		return fail("malformed message flags")
+	}
+	flags := headerFields[2].Content[0]
+	usm, _, err := ber.Parse(parts[2].Content)
+	if err != nil {
+		return fail("malformed security parameters: %s", err)
+	}
+	usmFields, err := usm.Children()
+	if err != nil || len(usmFields) != 6 {
+		return fail("malformed security parameters")
+	}
+	boots, _ := usmFields[1].Int()
+	engineTime, _ := usmFields[2].Int()
+	answer := &engine{id: usmFields[0].Content, boots: boots, time: engineTime, discoveredAt: time.Now()}
+
+	if flags&flagAuth != 0 && authKey != nil {
+		received := usmFields[4].Content
+		if len(received) != authLength {
+			return fail("the answer is not authenticated")
+		}
+		// Content slices share the packet's array, so the capacity left tells where the digest is
+		offset := cap(packet) - cap(received)
+		zeroed := append([]byte(nil), packet...)
+		copy(zeroed[offset:offset+authLength], make([]byte, authLength))
+		digest := hmac.New(newHash, authKey)
+		digest.Write(zeroed)
+		if !hmac.Equal(received, digest.Sum(nil)[:authLength]) {
+			return fail("the answer failed authentication")
+		}
+	}
+
+	data := parts[3]
+	if flags&flagPriv != 0 {
+		if privKey == nil || data.Tag != ber.TagOctetString || len(usmFields[5].Content) != 8 {
+			return fail("cannot decrypt the answer")
+		}
+		plain := aesCFB(privKey, boots, engineTime, usmFields[5].Content, data.Content, false)
+		if data, _, err = ber.Parse(plain); err != nil {
+			return fail("cannot decrypt the answer: wrong privacy password or protocol")
+		}
+	}
+	scoped, err := data.Children()
+	if err != nil || len(scoped) != 3 {
+		return fail("malformed scoped PDU")
+	}
+	if authKey != nil && flags&flagAuth == 0 && scoped[2].Tag != tagReport {
+		return fail("the answer is not authenticated")
+	}
+	return answer, scoped[2], true, nil
+}
+
+// Turn a password into a key for one engine, as RFC 3414 describes
+func localizeKey(newHash func() hash.Hash, password string, engineID []byte) []byte {
+	h := newHash()
+	buffer := make([]byte, 64)
+	for index := 0; index < 1048576; {
+		for i := range buffer {
+			buffer[i] = password[index%len(password)]
+			index++
+		}
+		h.Write(buffer)
+	}
+	ku := h.Sum(nil)
+	h = newHash()
+	h.Write(ku)
+	h.Write(engineID)
+	h.Write(ku)
+	return h.Sum(nil)
+}
+
+// AES-128 in CFB mode, with the IV made from the engine clock and a salt, as RFC 3826 describes
+func aesCFB(key []byte, boots, engineTime int64, salt, data []byte, encrypt bool) []byte {
+	block, _ := aes.NewCipher(key)
+	iv := make([]byte, 16)
+	binary.BigEndian.PutUint32(iv[0:], uint32(boots))
+	binary.BigEndian.PutUint32(iv[4:], uint32(engineTime))
+	copy(iv[8:], salt)
+	out := make([]byte, len(data))
+	if encrypt {
+		cipher.NewCFBEncrypter(block, iv).XORKeyStream(out, data)
+	} else {
+		cipher.NewCFBDecrypter(block, iv).XORKeyStream(out, data)
+	}
+	return out
+}
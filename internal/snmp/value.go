@@ -0,0 +1,101 @@
+package snmp
+
+import (
+	"fmt"
+	"github.com/oregondesignservices/monitoring-controller/internal/ber"
+	"net"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Application and context tags of SNMP values
+const (
+	tagIpAddress      byte = 0x40
+	tagCounter32      byte = 0x41
+	tagGauge32        byte = 0x42
+	tagTimeTicks      byte = 0x43
+	tagOpaque         byte = 0x44
+	tagCounter64      byte = 0x46
+	tagNoSuchObject   byte = 0x80
+	tagNoSuchInstance byte = 0x81
+	tagEndOfMibView   byte = 0x82
+)
+
+// One value the device returned
+type Varbind struct {
+	Oid string
+
+	// The value as text. Numbers are in decimal, and strings that are not printable are in hex.
+	Value string
+
+	// Set for integers, counters, gauges and time ticks
+	Number *float64
+
+	// Set when the device has no value for the OID
+	Missing string
+}
+
+func decodeVarbind(v ber.Value) (Varbind, error) {
+	parts, err := v.Children()
+	if err != nil || len(parts) != 2 || parts[0].Tag != ber.TagOID {
+		return Varbind{}, fmt.Errorf("malformed variable binding")
+	}
+	oid, err := parts[0].OIDString()
+	if err != nil {
+		return Varbind{}, err
+	}
+	varbind := Varbind{Oid: oid}
+	value := parts[1]
+	number := func(n float64) {
+		varbind.Number = &n
+		varbind.Value = strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	switch value.Tag {
+	case ber.TagInteger:
+		n, err := value.Int()
+		if err != nil {
+			return varbind, err
+		}
+		number(float64(n))
+	case tagCounter32, tagGauge32, tagTimeTicks, tagCounter64:
+		n, err := value.Uint()
+		if err != nil {
+			return varbind, err
+		}
+		number(float64(n))
+	case ber.TagOctetString, tagOpaque:
+		if utf8.Valid(value.Content) && printable(value.Content) {
+			varbind.Value = string(value.Content)
+		} else {
+			varbind.Value = fmt.Sprintf("%x", value.Content)
+		}
+	case ber.TagOID:
+		if varbind.Value, err = value.OIDString(); err != nil {
+			return varbind, err
+		}
+	case tagIpAddress:
+		if len(value.Content) != 4 {
+			return varbind, fmt.Errorf("malformed IP address")
+		}
+		varbind.Value = net.IP(value.Content).String()
+	case ber.TagNull:
+	case tagNoSuchObject:
+		varbind.Missing = "noSuchObject"
+	case tagNoSuchInstance:
+		varbind.Missing = "noSuchInstance"
+	case tagEndOfMibView:
+		varbind.Missing = "endOfMibView"
+	default:
+		varbind.Value = fmt.Sprintf("%x", value.Content)
+	}
+	return varbind, nil
+}
+
+func printable(data []byte) bool {
+	for _, r := range string(data) {
+		if (r < 0x20 && r != '\n' && r != '\r' && r != '\t') || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
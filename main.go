@@ -19,7 +19,14 @@ package main
 import (
 	monitoringraisingthefloororgv1alpha1 "github.com/oregondesignservices/monitoring-controller/api/v1alpha1"
 	"github.com/oregondesignservices/monitoring-controller/controllers"
+	"github.com/oregondesignservices/monitoring-controller/internal/bundle"
 	"github.com/oregondesignservices/monitoring-controller/internal/conf"
+	"github.com/oregondesignservices/monitoring-controller/internal/debugserver"
+	"github.com/oregondesignservices/monitoring-controller/internal/failover"
+	runnverv1alpha1 "github.com/oregondesignservices/monitoring-controller/internal/runner/v1alpha1"
+	"github.com/oregondesignservices/monitoring-controller/internal/runrecord"
+	"github.com/oregondesignservices/monitoring-controller/internal/scripts"
+	"github.com/oregondesignservices/monitoring-controller/internal/secrets"
 	"github.com/urfave/cli/v2"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -47,6 +54,8 @@ func main() {
 		Usage:  "periodically make requests to monitor service health",
 		Action: run,
 		Flags:  conf.Flags,
+
+		Commands: bundleCommands,
 	}
 
 	err := app.Run(os.Args)
@@ -78,13 +87,126 @@ func run(c *cli.Context) error {
 	}
 
 	if err = (&controllers.HttpMonitorReconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("HttpMonitor"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("httpmonitor-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HttpMonitor")
+		os.Exit(1)
+	}
+	if err = (&controllers.MonitorSilenceReconciler{
 		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("HttpMonitor"),
+		Log:    ctrl.Log.WithName("controllers").WithName("MonitorSilence"),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "HttpMonitor")
+		setupLog.Error(err, "unable to create controller", "controller", "MonitorSilence")
+		os.Exit(1)
+	}
+	if err = (&controllers.HttpCheckReconciler{
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Log:       ctrl.Log.WithName("controllers").WithName("HttpCheck"),
+		Scheme:    mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HttpCheck")
+		os.Exit(1)
+	}
+	if err = (&controllers.RequestBudgetReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("RequestBudget"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RequestBudget")
+		os.Exit(1)
+	}
+	if err = (&controllers.JourneyReconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("Journey"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("journey-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Journey")
 		os.Exit(1)
 	}
+	if err = (&controllers.MonitorSetReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("MonitorSet"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MonitorSet")
+		os.Exit(1)
+	}
+	if err = (&controllers.SnmpMonitorReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("SnmpMonitor"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SnmpMonitor")
+		os.Exit(1)
+	}
+	monitoringraisingthefloororgv1alpha1.SetScriptRunner(&scripts.JobRunner{
+		Client: mgr.GetClient(),
+		Reader: mgr.GetAPIReader(),
+	})
+	runnverv1alpha1.SetStartupRamp(conf.GlobalConfig.StartupRamp)
+	runnverv1alpha1.SetStandby(conf.GlobalConfig.Standby)
+	secrets.SetWebIdentityNamespaces(conf.GlobalConfig.AwsWebIdentityNamespaces)
+	if conf.GlobalConfig.FailoverPrimaryUrl != "" {
+		if err = mgr.Add(&failover.Watcher{
+			PrimaryUrl: conf.GlobalConfig.FailoverPrimaryUrl,
+			After:      conf.GlobalConfig.FailoverAfter,
+			SetActive:  func(active bool) { runnverv1alpha1.SetStandby(!active) },
+			Log:        ctrl.Log.WithName("failover"),
+		}); err != nil {
+			setupLog.Error(err, "unable to watch the primary installation")
+			os.Exit(1)
+		}
+	}
+	if conf.GlobalConfig.BundleAddr != "" {
+		if err = mgr.Add(&bundle.Server{
+			Addr:   conf.GlobalConfig.BundleAddr,
+			Reader: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("bundle"),
+		}); err != nil {
+			setupLog.Error(err, "unable to serve monitor bundles")
+			os.Exit(1)
+		}
+	}
+	if conf.GlobalConfig.DebugAddr != "" {
+		if err = mgr.Add(&debugserver.Server{
+			Addr:        conf.GlobalConfig.DebugAddr,
+			EnablePprof: conf.GlobalConfig.EnablePprof,
+			Log:         ctrl.Log.WithName("debug"),
+		}); err != nil {
+			setupLog.Error(err, "unable to serve runtime diagnostics")
+			os.Exit(1)
+		}
+	}
+	if conf.GlobalConfig.RunRecordKey != "" {
+		signer, err := runrecord.LoadSigner(conf.GlobalConfig.RunRecordKey)
+		if err != nil {
+			setupLog.Error(err, "unable to load the run record key")
+			os.Exit(1)
+		}
+		runnverv1alpha1.SetRunRecordSigner(signer)
+		if conf.GlobalConfig.RunRecordAddr != "" {
+			if err = mgr.Add(&runrecord.Server{
+				Addr:   conf.GlobalConfig.RunRecordAddr,
+				Signer: signer,
+				Log:    ctrl.Log.WithName("runrecord"),
+			}); err != nil {
+				setupLog.Error(err, "unable to serve the run record public key")
+				os.Exit(1)
+			}
+		}
+	}
+	if conf.GlobalConfig.EnableWebhooks {
+		if err = (&monitoringraisingthefloororgv1alpha1.HttpMonitor{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HttpMonitor")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")